@@ -0,0 +1,135 @@
+package wtf
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Audit sources describe how a state change entered the system.
+const (
+	AuditSourceWeb    = "web"     // browser session
+	AuditSourceAPIKey = "api_key" // request authenticated with an API key
+	AuditSourceSystem = "system"  // internal process such as a background job
+)
+
+// Audit actions. These are formatted as "<target type>.<verb>".
+const (
+	AuditActionDialCreate  = "dial.create"
+	AuditActionDialUpdate  = "dial.update"
+	AuditActionDialArchive = "dial.archive"
+	AuditActionDialRestore = "dial.restore"
+	AuditActionDialDelete  = "dial.delete"
+	AuditActionDialReset   = "dial.reset"
+
+	AuditActionDialMembershipCreate  = "dial_membership.create"
+	AuditActionDialMembershipUpdate  = "dial_membership.update"
+	AuditActionDialMembershipApprove = "dial_membership.approve"
+	AuditActionDialMembershipAway    = "dial_membership.away"
+	AuditActionDialMembershipDelete  = "dial_membership.delete"
+
+	AuditActionInvitationCreate  = "invitation.create"
+	AuditActionInvitationAccept  = "invitation.accept"
+	AuditActionInvitationDecline = "invitation.decline"
+	AuditActionInvitationDelete  = "invitation.delete"
+
+	AuditActionDialBanCreate = "dial_ban.create"
+	AuditActionDialBanDelete = "dial_ban.delete"
+
+	AuditActionDialAlertRuleCreate = "dial_alert_rule.create"
+	AuditActionDialAlertRuleUpdate = "dial_alert_rule.update"
+	AuditActionDialAlertRuleDelete = "dial_alert_rule.delete"
+
+	AuditActionDialReminderScheduleCreate = "dial_reminder_schedule.create"
+	AuditActionDialReminderScheduleUpdate = "dial_reminder_schedule.update"
+	AuditActionDialReminderScheduleDelete = "dial_reminder_schedule.delete"
+
+	AuditActionDialChildCreate = "dial_child.create"
+	AuditActionDialChildUpdate = "dial_child.update"
+	AuditActionDialChildDelete = "dial_child.delete"
+
+	AuditActionDialGoalCreate = "dial_goal.create"
+	AuditActionDialGoalUpdate = "dial_goal.update"
+	AuditActionDialGoalDelete = "dial_goal.delete"
+
+	AuditActionDialPeriodClose  = "dial_period.close"
+	AuditActionDialPeriodDelete = "dial_period.delete"
+
+	AuditActionUserCreate = "user.create"
+	AuditActionUserUpdate = "user.update"
+	AuditActionUserDelete = "user.delete"
+	AuditActionUserAPIKey = "user.api_key"
+
+	AuditActionAuthCreate = "auth.create"
+	AuditActionAuthUpdate = "auth.update"
+	AuditActionAuthDelete = "auth.delete"
+)
+
+// Audit target types.
+const (
+	AuditTargetDial                 = "dial"
+	AuditTargetDialMembership       = "dial_membership"
+	AuditTargetInvitation           = "invitation"
+	AuditTargetDialBan              = "dial_ban"
+	AuditTargetDialAlertRule        = "dial_alert_rule"
+	AuditTargetDialReminderSchedule = "dial_reminder_schedule"
+	AuditTargetDialChild            = "dial_child"
+	AuditTargetDialGoal             = "dial_goal"
+	AuditTargetDialPeriod           = "dial_period"
+	AuditTargetUser                 = "user"
+	AuditTargetAuth                 = "auth"
+)
+
+// AuditEntry represents a single recorded state change. Entries are written
+// by the storage layer inside the same transaction as the change itself so
+// the log cannot drift from the data it describes.
+type AuditEntry struct {
+	ID int `json:"id"`
+
+	// User who performed the change. This is zero for system changes.
+	UserID int   `json:"userID"`
+	User   *User `json:"user"`
+
+	// Action performed, such as "dial.update".
+	Action string `json:"action"`
+
+	// Type & ID of the object that was changed.
+	TargetType string `json:"targetType"`
+	TargetID   int    `json:"targetID"`
+
+	// Dial the change belongs to, if any. Used to build per-dial logs.
+	DialID int `json:"dialID,omitempty"`
+
+	// JSON snapshots of the target before & after the change. Before is empty
+	// for creations and After is empty for deletions.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+
+	// How the change was made (web, api_key, system).
+	Source string `json:"source"`
+
+	// Timestamp of when the change occurred.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AuditService represents a service for querying the audit log. Entries are
+// only written by the storage layer so there are no mutating methods.
+type AuditService interface {
+	// Retrieves a list of audit entries based on a filter. Dial owners can
+	// see all entries for their dials while other users can only see entries
+	// for changes they made themselves. Also returns a count of total matching
+	// entries which may differ if filter.Limit is set.
+	FindAuditEntries(ctx context.Context, filter AuditEntryFilter) ([]*AuditEntry, int, error)
+}
+
+// AuditEntryFilter represents a filter used by FindAuditEntries().
+type AuditEntryFilter struct {
+	ID     *int    `json:"id"`
+	DialID *int    `json:"dialID"`
+	UserID *int    `json:"userID"`
+	Action *string `json:"action"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
@@ -32,6 +32,10 @@ type Auth struct {
 	Source   string `json:"source"`
 	SourceID string `json:"sourceID"`
 
+	// The user's login name on the source provider. Unlike SourceID, this
+	// can change so it is updated every time the user signs in.
+	SourceLogin string `json:"sourceLogin,omitempty"`
+
 	// OAuth fields returned from the authentication provider.
 	// GitHub does not use refresh tokens but the field exists for future providers.
 	AccessToken  string     `json:"-"`
@@ -95,10 +99,11 @@ type AuthService interface {
 // AuthFilter represents a filter accepted by FindAuths().
 type AuthFilter struct {
 	// Filtering fields.
-	ID       *int    `json:"id"`
-	UserID   *int    `json:"userID"`
-	Source   *string `json:"source"`
-	SourceID *string `json:"sourceID"`
+	ID          *int    `json:"id"`
+	UserID      *int    `json:"userID"`
+	Source      *string `json:"source"`
+	SourceID    *string `json:"sourceID"`
+	SourceLogin *string `json:"sourceLogin"`
 
 	// Restricts results to a subset of the total range.
 	// Can be used for pagination.
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// AwayCommand is a command for marking yourself away on one or all dials.
+type AwayCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *AwayCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set with parameters for the away period.
+	fs := flag.NewFlagSet("wtf-away", flag.ContinueOnError)
+	until := fs.String("until", "", "date you return, in YYYY-MM-DD format")
+	back := fs.Bool("back", false, "mark yourself as returned")
+	dialID := fs.Int("dial", 0, "only mark yourself away on this dial")
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("Unexpected arguments. Use -dial to specify a dial.")
+	} else if *until == "" && !*back {
+		return fmt.Errorf("Return date required. Use -back if you have returned.")
+	} else if *until != "" && *back {
+		return fmt.Errorf("Please only specify one of -until or -back.")
+	}
+
+	// Parse the return date as the start of that day in local time.
+	var t time.Time
+	if *until != "" {
+		var err error
+		if t, err = time.ParseInLocation("2006-01-02", *until, time.Local); err != nil {
+			return fmt.Errorf("Invalid return date. Please use YYYY-MM-DD format.")
+		}
+	}
+
+	// Load the configuration.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate the user with the API key from the config.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Update a single dial if specified. Otherwise update all dials.
+	svc := http.NewDialMembershipService(http.NewClient(config.URL))
+	if *dialID != 0 {
+		membership, err := svc.SetDialMembershipAway(ctx, *dialID, t)
+		if err != nil {
+			return err
+		} else if t.IsZero() {
+			fmt.Printf("Welcome back! You count toward %q again.\n", membership.Dial.Name)
+		} else {
+			fmt.Printf("You are away from %q until %s.\n", membership.Dial.Name, t.Format("Jan 2, 2006"))
+		}
+		return nil
+	}
+
+	if err := svc.SetAway(ctx, t); err != nil {
+		return err
+	} else if t.IsZero() {
+		fmt.Println("Welcome back! You count toward all your dials again.")
+	} else {
+		fmt.Printf("You are away from all your dials until %s.\n", t.Format("Jan 2, 2006"))
+	}
+	return nil
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *AwayCommand) usage() {
+	fmt.Println(`
+Marks yourself as away, such as while on vacation. You do not count toward a
+dial's WTF level while away and are automatically marked as returned on the
+given date.
+
+Usage:
+
+	wtf away -until DATE [-dial DIAL_ID]
+	wtf away -back [-dial DIAL_ID]
+
+Arguments:
+
+	-until DATE
+	    Date you return, in YYYY-MM-DD format.
+
+	-back
+	    Mark yourself as returned before the away date.
+
+	-dial DIAL_ID
+	    Only change your away status on the given dial.
+`[1:])
+}
@@ -24,12 +24,38 @@ func (c *DialCommand) Run(ctx context.Context, args []string) error {
 		return (&DialListCommand{}).Run(ctx, args)
 	case "create":
 		return (&DialCreateCommand{}).Run(ctx, args)
+	case "clone":
+		return (&DialCloneCommand{}).Run(ctx, args)
+	case "archive":
+		return (&DialArchiveCommand{}).Run(ctx, args)
+	case "restore":
+		return (&DialRestoreCommand{}).Run(ctx, args)
 	case "delete":
 		return (&DialDeleteCommand{}).Run(ctx, args)
+	case "invite":
+		return (&DialInviteCommand{}).Run(ctx, args)
 	case "members":
 		return (&DialMembersCommand{}).Run(ctx, args)
 	case "set":
 		return (&DialSetCommand{}).Run(ctx, args)
+	case "pending":
+		return (&DialPendingCommand{}).Run(ctx, args)
+	case "approve":
+		return (&DialApproveCommand{}).Run(ctx, args)
+	case "reject":
+		return (&DialRejectCommand{}).Run(ctx, args)
+	case "bans":
+		return (&DialBansCommand{}).Run(ctx, args)
+	case "unban":
+		return (&DialUnbanCommand{}).Run(ctx, args)
+	case "anomalies":
+		return (&DialAnomaliesCommand{}).Run(ctx, args)
+	case "periods":
+		return (&DialPeriodsCommand{}).Run(ctx, args)
+	case "close":
+		return (&DialCloseCommand{}).Run(ctx, args)
+	case "goals":
+		return (&DialGoalsCommand{}).Run(ctx, args)
 	case "help":
 		c.usage()
 		return flag.ErrHelp
@@ -51,8 +77,21 @@ The commands are:
 
 	list        list all available dials
 	create      create a new dial
-	delete      remove an existing dial
+	clone       create a new dial from an existing dial
+	archive     hide a dial & make it read-only
+	restore     restore an archived dial
+	delete      permanently remove an archived dial
+	invite      invite a user to a dial
 	members     view list of members of a dial
 	set         set your WTF level for a dial
+	pending     view membership requests awaiting approval
+	approve     approve a membership request
+	reject      reject a membership request
+	bans        view list of users banned from a dial
+	unban       allow a banned user to rejoin a dial
+	anomalies   view unusual rises detected in a dial's value
+	periods     view closed periods of a dial
+	close       close a period, such as a sprint, on a dial
+	goals       view goals of a dial & their error budgets
 `[1:])
 }
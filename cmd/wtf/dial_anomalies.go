@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialAnomaliesCommand represents a command for listing anomalies detected on a dial.
+type DialAnomaliesCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialAnomaliesCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to read the config path & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-anomalies", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP anomaly service and fetch the most recent anomalies.
+	svc := http.NewDialAnomalyService(http.NewClient(config.URL))
+	anomalies, _, err := svc.FindDialAnomalies(ctx, wtf.DialAnomalyFilter{DialID: &id})
+	if err != nil {
+		return err
+	}
+
+	for _, anomaly := range anomalies {
+		fmt.Printf(
+			"%s\t%s\t%.0f\t%.0f\t%.1f\n",
+			anomaly.Timestamp.Local().Format(time.RFC3339),
+			anomaly.Kind,
+			anomaly.Value,
+			anomaly.Baseline,
+			anomaly.ZScore,
+		)
+	}
+
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialAnomaliesCommand) usage() {
+	fmt.Println(`
+List unusual rises detected in a dial's value, newest first. Each line shows
+the time, kind (spike or sustained), value, baseline & z-score.
+
+Usage:
+
+	wtf dial anomalies DIAL_ID
+`[1:])
+}
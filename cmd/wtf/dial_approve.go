@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialApproveCommand represents a command for approving a pending membership.
+type DialApproveCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialApproveCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the config path & read the membership ID.
+	fs := flag.NewFlagSet("wtf-dial-approve", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Membership ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one membership ID allowed.")
+	}
+
+	// Parse the membership ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid membership ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP service and issue approval.
+	svc := http.NewDialMembershipService(http.NewClient(config.URL))
+	membership, err := svc.ApproveDialMembership(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Notify user that the member has been approved.
+	fmt.Printf("%s can now contribute to your dial.\n", membership.User.Name)
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialApproveCommand) usage() {
+	fmt.Println(`
+Approve a pending membership request. Use "wtf dial pending" to find the
+membership ID.
+
+Usage:
+
+	wtf dial approve MEMBERSHIP_ID
+`[1:])
+}
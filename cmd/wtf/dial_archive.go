@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialArchiveCommand represents a command for archiving dials.
+type DialArchiveCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialArchiveCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the config path & read the ID.
+	fs := flag.NewFlagSet("wtf-dial-archive", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial dial ID allowed.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP service and issue archive.
+	svc := http.NewDialService(http.NewClient(config.URL))
+	dial, err := svc.ArchiveDial(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Notify user of the change.
+	fmt.Printf("Your %q dial has been archived.\n", dial.Name)
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialArchiveCommand) usage() {
+	fmt.Println(`
+Archive a dial you own. Archived dials are hidden from listings and are
+read-only until restored. Their history is kept.
+
+Usage:
+
+	wtf dial archive DIAL_ID
+`[1:])
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialBansCommand represents a command for listing users banned from a dial.
+type DialBansCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialBansCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to read the config path & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-bans", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP ban service and fetch the ban list.
+	svc := http.NewDialBanService(http.NewClient(config.URL))
+	bans, _, err := svc.FindDialBans(ctx, wtf.DialBanFilter{DialID: &id})
+	if err != nil {
+		return err
+	}
+
+	// Print the ban ID so it can be used with "wtf dial unban".
+	for _, ban := range bans {
+		fmt.Printf(
+			"%d\t%s\t%s\n",
+			ban.ID,
+			ban.User.Name,
+			ban.Reason,
+		)
+	}
+
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialBansCommand) usage() {
+	fmt.Println(`
+List users banned from a dial you own.
+
+Usage:
+
+	wtf dial bans DIAL_ID
+`[1:])
+}
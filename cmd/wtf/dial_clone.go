@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialCloneCommand represents a command for cloning dials.
+type DialCloneCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialCloneCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the clone options & read the ID.
+	fs := flag.NewFlagSet("wtf-dial-clone", flag.ContinueOnError)
+	name := fs.String("name", "", "name of the new dial")
+	members := fs.Bool("members", false, "add current members to the new dial")
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP service and issue clone.
+	svc := http.NewDialService(http.NewClient(config.URL))
+	dial, err := svc.CloneDial(ctx, id, wtf.DialClone{Name: *name, IncludeMembers: *members})
+	if err != nil {
+		return err
+	}
+
+	// Notify user of their new dial.
+	fmt.Printf("Your %q dial has been created!\n\n", dial.Name)
+	fmt.Printf("Please share this URL to invite others to contribute:\n\n")
+	fmt.Printf("%s\n\n", config.URL+"/invite/"+dial.InviteCode)
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialCloneCommand) usage() {
+	fmt.Println(`
+Create a new dial with the settings, alerts, check-ins & pending invitations
+of a dial you own. The new dial has its own invite URL.
+
+Usage:
+
+	wtf dial clone [arguments] DIAL_ID
+
+Arguments:
+
+	-name NAME
+	    The name of the new dial. Defaults to the name of the cloned dial.
+
+	-members
+	    Add the current members to the new dial. Their WTF levels start
+	    over at the bottom of the scale.
+`[1:])
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialCloseCommand represents a command for closing a period on a dial.
+type DialCloseCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialCloseCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the period options & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-close", flag.ContinueOnError)
+	name := fs.String("name", "", "name of the period")
+	start := fs.String("start", "", "start time of the period")
+	end := fs.String("end", "", "end time of the period")
+	threshold := fs.String("threshold", "", "dial value to measure time above")
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Parse the time range. The period ends now unless specified.
+	period := &wtf.DialPeriod{DialID: id, Name: *name, EndAt: time.Now()}
+	if period.StartAt, err = time.Parse(time.RFC3339, *start); err != nil {
+		return fmt.Errorf("Invalid start time. Use RFC 3339 format, e.g. 2006-01-02T15:04:05Z.")
+	} else if *end != "" {
+		if period.EndAt, err = time.Parse(time.RFC3339, *end); err != nil {
+			return fmt.Errorf("Invalid end time. Use RFC 3339 format, e.g. 2006-01-02T15:04:05Z.")
+		}
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+	client := http.NewClient(config.URL)
+
+	// Default the threshold to the middle of the dial's scale.
+	if *threshold != "" {
+		if period.Threshold, err = strconv.Atoi(*threshold); err != nil {
+			return fmt.Errorf("Invalid threshold.")
+		}
+	} else {
+		dial, err := http.NewDialService(client).FindDialByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		period.Threshold = dial.Scale.ValueAt(0.5)
+	}
+
+	// Instantiate HTTP service and close the period.
+	if err := http.NewDialPeriodService(client).CloseDialPeriod(ctx, period); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s closed.\n\n", period.Name)
+	fmt.Printf("Average: %.1f\n", period.Average)
+	fmt.Printf("Peak: %d\n", period.Peak)
+	fmt.Printf("Above %d: %d minutes\n", period.Threshold, period.MinutesAboveThreshold)
+	fmt.Printf("Notes: %d\n", len(period.Notes))
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialCloseCommand) usage() {
+	fmt.Println(`
+Close a period, such as a sprint, on a dial you own. The dial's average & peak
+level, each member's average, the notes left & the time spent above the
+threshold are stored so they can be compared in later retrospectives.
+
+Usage:
+
+	wtf dial close [arguments] DIAL_ID
+
+Arguments:
+
+	-name NAME
+	    The name of the period, such as "Sprint 14".
+
+	-start TIME
+	    The start of the period in RFC 3339 format.
+
+	-end TIME
+	    The end of the period in RFC 3339 format. Defaults to now.
+
+	-threshold VALUE
+	    Dial value to measure time spent above. Defaults to the middle of
+	    the dial's scale.
+`[1:])
+}
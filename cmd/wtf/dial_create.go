@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"strings"
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http"
@@ -19,6 +20,9 @@ func (c *DialCreateCommand) Run(ctx context.Context, args []string) error {
 	// Create a flag set with parameters for the dial fields.
 	fs := flag.NewFlagSet("wtf-dial-create", flag.ContinueOnError)
 	name := fs.String("name", "", "dial name")
+	requireApproval := fs.Bool("require-approval", false, "require approval for new members")
+	dimensions := fs.String("dimensions", "", "comma-separated list of dimension names")
+	expression := fs.String("expression", "", "expression computing the dial from other dials")
 	attachConfigFlags(fs, &c.ConfigPath)
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -34,7 +38,12 @@ func (c *DialCreateCommand) Run(ctx context.Context, args []string) error {
 	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
 
 	// Build dial from arguments and issue creation request over HTTP.
-	dial := &wtf.Dial{Name: *name}
+	dial := &wtf.Dial{Name: *name, RequireApproval: *requireApproval, Expression: *expression}
+	if *dimensions != "" {
+		for _, name := range strings.Split(*dimensions, ",") {
+			dial.Dimensions = append(dial.Dimensions, &wtf.DialDimension{Name: strings.TrimSpace(name)})
+		}
+	}
 	svc := http.NewDialService(http.NewClient(config.URL))
 	if err := svc.CreateDial(ctx, dial); err != nil {
 		return err
@@ -61,5 +70,16 @@ Arguments:
 
 	-name NAME
 	    The name of the dial you are creating. Required.
+
+	-require-approval
+	    Require the dial owner to approve users joining via the invite link.
+
+	-dimensions NAMES
+	    Comma-separated names of metrics members rate separately, such as
+	    "workload,clarity". A member's level is the average of them.
+
+	-expression EXPR
+	    Compute the dial from other dials you can view instead of from its
+	    members, such as "max(dial(3), dial(7)) * 0.8 + 10".
 `[1:])
 }
@@ -50,7 +50,7 @@ func (c *DialDeleteCommand) Run(ctx context.Context, args []string) error {
 	}
 
 	// Notify user that dial is gone.
-	fmt.Printf("Your dial has been deleted.\n")
+	fmt.Printf("Your dial has been permanently deleted.\n")
 
 	return nil
 }
@@ -58,7 +58,8 @@ func (c *DialDeleteCommand) Run(ctx context.Context, args []string) error {
 // usage prints the command usage information to STDOUT.
 func (c *DialDeleteCommand) usage() {
 	fmt.Println(`
-Delete an existing dial.
+Permanently delete an archived dial. Dials must be archived with
+"wtf dial archive" before they can be deleted.
 
 Usage:
 
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialGoalsCommand represents a command for listing goals on a dial.
+type DialGoalsCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialGoalsCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to read the config path & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-goals", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP goal service and fetch the goals with their status.
+	svc := http.NewDialGoalService(http.NewClient(config.URL))
+	goals, _, err := svc.FindDialGoals(ctx, wtf.DialGoalFilter{DialID: &id})
+	if err != nil {
+		return err
+	}
+
+	for _, goal := range goals {
+		var compliance float64
+		var remaining, budget int
+		if goal.Status != nil {
+			compliance = goal.Status.Compliance() * 100
+			remaining, budget = goal.Status.RemainingBudgetMinutes(), goal.Status.BudgetMinutes
+		}
+
+		fmt.Printf(
+			"%d\t%s\t%s\t%.1f%%\t%dm/%dm\n",
+			goal.ID,
+			goal.Name,
+			goal.Condition(),
+			compliance,
+			remaining,
+			budget,
+		)
+	}
+
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialGoalsCommand) usage() {
+	fmt.Println(`
+List the goals of a dial. Each line shows the ID, name, condition, compliance
+in the current window & the remaining minutes of the error budget.
+
+Usage:
+
+	wtf dial goals DIAL_ID
+`[1:])
+}
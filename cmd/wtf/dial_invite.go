@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialInviteCommand represents a command for inviting a user to a dial.
+type DialInviteCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialInviteCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set with parameters for identifying the invitee.
+	fs := flag.NewFlagSet("wtf-dial-invite", flag.ContinueOnError)
+	email := fs.String("email", "", "invitee email address")
+	githubLogin := fs.String("github", "", "invitee GitHub username")
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Build invitation from arguments and issue creation request over HTTP.
+	invitation := &wtf.Invitation{DialID: id, Email: *email, GitHubLogin: *githubLogin}
+	svc := http.NewInvitationService(http.NewClient(config.URL))
+	if err := svc.CreateInvitation(ctx, invitation); err != nil {
+		return err
+	}
+
+	// Notify user that the invitation has been sent.
+	fmt.Printf("%s has been invited to your dial.\n", invitation.Address())
+
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialInviteCommand) usage() {
+	fmt.Println(`
+Invite an existing user to a dial you own. The user will see the invitation
+on their dashboard and can accept or decline it.
+
+Usage:
+
+	wtf dial invite DIAL_ID [-email EMAIL | -github USERNAME]
+
+Arguments:
+
+	-email EMAIL
+	    The email address of the user to invite.
+
+	-github USERNAME
+	    The GitHub username of the user to invite. The user must have
+	    signed in to WTF Dial with GitHub at least once.
+`[1:])
+}
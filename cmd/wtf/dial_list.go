@@ -21,6 +21,7 @@ func (c *DialListCommand) Run(ctx context.Context, args []string) error {
 	// Build a flag set to retrieve the config path & verbose flag.
 	fs := flag.NewFlagSet("wtf-dial-list", flag.ContinueOnError)
 	verbose := fs.Bool("v", false, "verbose")
+	archived := fs.Bool("archived", false, "include archived dials")
 	attachConfigFlags(fs, &c.ConfigPath)
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -37,7 +38,7 @@ func (c *DialListCommand) Run(ctx context.Context, args []string) error {
 
 	// Build dial service and fetch list of dials user is a member of.
 	dialService := http.NewDialService(http.NewClient(config.URL))
-	dials, _, err := dialService.FindDials(ctx, wtf.DialFilter{})
+	dials, _, err := dialService.FindDials(ctx, wtf.DialFilter{IncludeArchived: *archived})
 	if err != nil {
 		return err
 	}
@@ -46,7 +47,11 @@ func (c *DialListCommand) Run(ctx context.Context, args []string) error {
 	for _, dial := range dials {
 		// If we are not in verbose mode, just print the name.
 		if !*verbose {
-			fmt.Println(dial.Name)
+			if dial.IsArchived() {
+				fmt.Printf("%s (archived)\n", dial.Name)
+			} else {
+				fmt.Println(dial.Name)
+			}
 			continue
 		}
 
@@ -75,5 +80,8 @@ Arguments:
 
 	-v
 	    Enable verbose output.
+
+	-archived
+	    Include dials that have been archived.
 `[1:])
 }
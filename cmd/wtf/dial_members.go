@@ -51,13 +51,21 @@ func (c *DialMembersCommand) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
-	// Iterate over membrships and print the name & value.
+	// Iterate over membrships and print the name & value. Requests awaiting
+	// approval are listed by "wtf dial pending" instead.
 	for _, membership := range dial.Memberships {
+		if membership.IsPending() {
+			continue
+		}
 		fmt.Printf(
-			"%s\t%d\n",
+			"%s\t%d",
 			membership.User.Name,
 			membership.Value,
 		)
+		for _, dim := range membership.Dimensions {
+			fmt.Printf("\t%s=%d", dim.Name, dim.Value)
+		}
+		fmt.Println()
 	}
 
 	return nil
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialPendingCommand represents a command for listing membership requests
+// that are awaiting approval by the dial owner.
+type DialPendingCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialPendingCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to read the config path & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-pending", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP membership service and fetch pending memberships.
+	status := wtf.DialMembershipStatusPending
+	svc := http.NewDialMembershipService(http.NewClient(config.URL))
+	memberships, _, err := svc.FindDialMemberships(ctx, wtf.DialMembershipFilter{
+		DialID: &id,
+		Status: &status,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print the membership ID & user name so they can be approved or rejected.
+	for _, membership := range memberships {
+		fmt.Printf(
+			"%d\t%s\n",
+			membership.ID,
+			membership.User.Name,
+		)
+	}
+
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialPendingCommand) usage() {
+	fmt.Println(`
+List membership requests awaiting approval for a dial you own.
+
+Usage:
+
+	wtf dial pending DIAL_ID
+`[1:])
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialPeriodsCommand represents a command for listing closed periods on a dial.
+type DialPeriodsCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialPeriodsCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to read the config path & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-periods", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP period service and fetch the closed periods.
+	svc := http.NewDialPeriodService(http.NewClient(config.URL))
+	periods, _, err := svc.FindDialPeriods(ctx, wtf.DialPeriodFilter{DialID: &id})
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		fmt.Printf(
+			"%d\t%s\t%s\t%s\t%.1f\t%d\t%dm\n",
+			period.ID,
+			period.Name,
+			period.StartAt.Local().Format("2006-01-02"),
+			period.EndAt.Local().Format("2006-01-02"),
+			period.Average,
+			period.Peak,
+			period.MinutesAboveThreshold,
+		)
+	}
+
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialPeriodsCommand) usage() {
+	fmt.Println(`
+List the closed periods of a dial, newest first. Each line shows the ID, name,
+start & end dates, average, peak & minutes spent above the threshold.
+
+Usage:
+
+	wtf dial periods DIAL_ID
+`[1:])
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialRejectCommand represents a command for rejecting a pending membership.
+type DialRejectCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialRejectCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the config path & read the membership ID.
+	fs := flag.NewFlagSet("wtf-dial-reject", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Membership ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one membership ID allowed.")
+	}
+
+	// Parse the membership ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid membership ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Rejecting a request removes the pending membership.
+	svc := http.NewDialMembershipService(http.NewClient(config.URL))
+	if err := svc.DeleteDialMembership(ctx, id); err != nil {
+		return err
+	}
+
+	// Notify user that the request is gone.
+	fmt.Printf("The membership request has been rejected.\n")
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialRejectCommand) usage() {
+	fmt.Println(`
+Reject a pending membership request. Use "wtf dial pending" to find the
+membership ID.
+
+Usage:
+
+	wtf dial reject MEMBERSHIP_ID
+`[1:])
+}
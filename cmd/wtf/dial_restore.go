@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialRestoreCommand represents a command for restoring archived dials.
+type DialRestoreCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialRestoreCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the config path & read the ID.
+	fs := flag.NewFlagSet("wtf-dial-restore", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial dial ID allowed.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP service and issue restore.
+	svc := http.NewDialService(http.NewClient(config.URL))
+	dial, err := svc.RestoreDial(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Notify user of the change.
+	fmt.Printf("Your %q dial has been restored.\n", dial.Name)
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialRestoreCommand) usage() {
+	fmt.Println(`
+Restore an archived dial you own so it can be changed again.
+
+Usage:
+
+	wtf dial restore DIAL_ID
+`[1:])
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http"
@@ -19,29 +20,53 @@ type DialSetCommand struct {
 func (c *DialSetCommand) Run(ctx context.Context, args []string) error {
 	// Create a flag set with parameters for the dial fields.
 	fs := flag.NewFlagSet("wtf-dial-set", flag.ContinueOnError)
+	note := fs.String("m", "", "note describing the change")
 	attachConfigFlags(fs, &c.ConfigPath)
-	if err := fs.Parse(args); err != nil {
-		return err
+
+	// Flags may appear before or after the positional arguments so that the
+	// note can be added to the end, e.g. "wtf dial set 1 80 -m NOTE".
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		} else if fs.NArg() == 0 {
+			break
+		}
+		positional, args = append(positional, fs.Arg(0)), fs.Args()[1:]
 	}
 
-	if fs.NArg() == 0 {
+	if len(positional) == 0 {
 		return fmt.Errorf("Dial ID required.")
-	} else if fs.NArg() == 1 {
+	} else if len(positional) == 1 {
 		return fmt.Errorf("WTF level required.")
-	} else if fs.NArg() > 2 {
-		return fmt.Errorf("Please only specify the dial ID and WTF level.")
 	}
 
 	// Parse the dial ID from the first arg.
-	id, err := strconv.Atoi(fs.Arg(0))
+	id, err := strconv.Atoi(positional[0])
 	if err != nil {
 		return fmt.Errorf("Invalid dial ID.")
 	}
 
-	// Parse the WTF level from the second arg.
-	value, err := strconv.Atoi(fs.Arg(1))
-	if err != nil {
-		return fmt.Errorf("Invalid WTF level.")
+	// Parse either a single WTF level or a list of NAME=LEVEL dimension
+	// levels from the remaining args.
+	var value int
+	var dimensions map[string]int
+	if !strings.Contains(positional[1], "=") {
+		if len(positional) > 2 {
+			return fmt.Errorf("Please only specify the dial ID and WTF level.")
+		} else if value, err = strconv.Atoi(positional[1]); err != nil {
+			return fmt.Errorf("Invalid WTF level.")
+		}
+	} else {
+		dimensions = make(map[string]int)
+		for _, arg := range positional[1:] {
+			pair := strings.SplitN(arg, "=", 2)
+			if len(pair) != 2 {
+				return fmt.Errorf("Dimension levels must be in the form NAME=LEVEL.")
+			} else if dimensions[pair[0]], err = strconv.Atoi(pair[1]); err != nil {
+				return fmt.Errorf("Invalid WTF level for %q.", pair[0])
+			}
+		}
 	}
 
 	// Load the configuration.
@@ -55,7 +80,12 @@ func (c *DialSetCommand) Run(ctx context.Context, args []string) error {
 
 	// Build dial from arguments and issue creation request over HTTP.
 	svc := http.NewDialService(http.NewClient(config.URL))
-	if err := svc.SetDialMembershipValue(ctx, id, value); err != nil {
+	if dimensions != nil {
+		err = svc.SetDialMembershipDimensionValues(ctx, id, dimensions, *note)
+	} else {
+		err = svc.SetDialMembershipValue(ctx, id, value, *note)
+	}
+	if err != nil {
 		return err
 	}
 
@@ -68,11 +98,17 @@ func (c *DialSetCommand) Run(ctx context.Context, args []string) error {
 // usage print usage information for the command to STDOUT.
 func (c *DialSetCommand) usage() {
 	fmt.Println(`
-Sets your WTF level for a dial you are a member of.
+Sets your WTF level for a dial you are a member of. On dials with dimensions,
+a single level sets every dimension. Individual dimensions can be set by name.
 
 Usage:
 
-	wtf dial set DIAL_ID WTF_LEVEL
+	wtf dial set DIAL_ID WTF_LEVEL [-m NOTE]
+	wtf dial set DIAL_ID NAME=LEVEL... [-m NOTE]
+
+Arguments:
 
+	-m NOTE
+	    Optional note explaining the change, e.g. "prod db at 98% disk".
 `[1:])
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialUnbanCommand represents a command for lifting a ban on a dial.
+type DialUnbanCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialUnbanCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the config path & read the ban ID.
+	fs := flag.NewFlagSet("wtf-dial-unban", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Ban ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one ban ID allowed.")
+	}
+
+	// Parse the ban ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid ban ID.")
+	}
+
+	// Load configuration file.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP service and lift the ban.
+	svc := http.NewDialBanService(http.NewClient(config.URL))
+	if err := svc.DeleteDialBan(ctx, id); err != nil {
+		return err
+	}
+
+	// Notify user that the ban is gone.
+	fmt.Printf("The user has been unbanned and may rejoin the dial.\n")
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialUnbanCommand) usage() {
+	fmt.Println(`
+Lift a ban so the user may rejoin the dial. Use "wtf dial bans" to find the
+ban ID.
+
+Usage:
+
+	wtf dial unban BAN_ID
+`[1:])
+}
@@ -63,6 +63,8 @@ func Run(ctx context.Context, args []string) error {
 	switch cmd {
 	case "dial":
 		return (&DialCommand{}).Run(ctx, args)
+	case "away":
+		return (&AwayCommand{}).Run(ctx, args)
 	case "", "-h", "help":
 		usage()
 		return flag.ErrHelp
@@ -83,6 +85,7 @@ Usage:
 The commands are:
 
 	dial        manage your dial
+	away        mark yourself away from your dials
 `[1:])
 }
 
@@ -11,11 +11,14 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
+	_ "time/tzdata" // embed time zone database for user time zones
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http"
 	"github.com/benbjohnson/wtf/http/html"
 	"github.com/benbjohnson/wtf/inmem"
+	"github.com/benbjohnson/wtf/smtp"
 	"github.com/benbjohnson/wtf/sqlite"
 	"github.com/pelletier/go-toml"
 	"github.com/rollbar/rollbar-go"
@@ -83,6 +86,9 @@ type Main struct {
 	// SQLite services are attached to it before running.
 	HTTPServer *http.Server
 
+	// Runs scheduled check-in reminders & value resets.
+	Scheduler *Scheduler
+
 	// Services exposed for end-to-end tests.
 	UserService wtf.UserService
 }
@@ -95,6 +101,7 @@ func NewMain() *Main {
 
 		DB:         sqlite.NewDB(""),
 		HTTPServer: http.NewServer(),
+		Scheduler:  NewScheduler(),
 	}
 }
 
@@ -105,6 +112,11 @@ func (m *Main) Close() error {
 			return err
 		}
 	}
+	if m.Scheduler != nil {
+		if err := m.Scheduler.Close(); err != nil {
+			return err
+		}
+	}
 	if m.DB != nil {
 		if err := m.DB.Close(); err != nil {
 			return err
@@ -173,14 +185,60 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	if m.DB.DSN, err = expandDSN(m.Config.DB.DSN); err != nil {
 		return fmt.Errorf("cannot expand dsn: %w", err)
 	}
+
+	// Parse the raw dial value retention period, if set.
+	if v := m.Config.DB.DialValueRetention; v != "" {
+		if m.DB.DialValueRetention, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("cannot parse dial value retention: %w", err)
+		}
+	}
+
+	// Parse the archived dial retention period, if set.
+	if v := m.Config.DB.DialArchiveRetention; v != "" {
+		if m.DB.DialArchiveRetention, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("cannot parse dial archive retention: %w", err)
+		}
+	}
+
+	// Deliver fired dial alerts to webhooks & by email through the SMTP relay.
+	m.DB.DialAlertWebhookNotifier = http.NewDialAlertWebhookNotifier()
+	emailNotifier := smtp.NewDialAlertNotifier()
+	emailNotifier.Addr = m.Config.SMTP.Addr
+	emailNotifier.From = m.Config.SMTP.From
+	emailNotifier.Username = m.Config.SMTP.Username
+	emailNotifier.Password = m.Config.SMTP.Password
+	if m.Config.HTTP.Domain != "" {
+		emailNotifier.URL = "https://" + m.Config.HTTP.Domain
+	}
+	m.DB.DialAlertEmailNotifier = emailNotifier
+
+	// Deliver check-in reminders the same way, emailing each reminded member.
+	m.DB.DialReminderWebhookNotifier = http.NewDialReminderWebhookNotifier()
+	reminderNotifier := smtp.NewDialReminderNotifier()
+	reminderNotifier.Addr = emailNotifier.Addr
+	reminderNotifier.From = emailNotifier.From
+	reminderNotifier.Username = emailNotifier.Username
+	reminderNotifier.Password = emailNotifier.Password
+	reminderNotifier.URL = emailNotifier.URL
+	m.DB.DialReminderEmailNotifier = reminderNotifier
+
 	if err := m.DB.Open(); err != nil {
 		return fmt.Errorf("cannot open db: %w", err)
 	}
 
 	// Instantiate SQLite-backed services.
+	auditService := sqlite.NewAuditService(m.DB)
 	authService := sqlite.NewAuthService(m.DB)
 	dialService := sqlite.NewDialService(m.DB)
+	dialAlertService := sqlite.NewDialAlertService(m.DB)
+	dialAnomalyService := sqlite.NewDialAnomalyService(m.DB)
+	dialBanService := sqlite.NewDialBanService(m.DB)
+	dialChildService := sqlite.NewDialChildService(m.DB)
+	dialGoalService := sqlite.NewDialGoalService(m.DB)
 	dialMembershipService := sqlite.NewDialMembershipService(m.DB)
+	dialPeriodService := sqlite.NewDialPeriodService(m.DB)
+	dialReminderService := sqlite.NewDialReminderService(m.DB)
+	invitationService := sqlite.NewInvitationService(m.DB)
 	userService := sqlite.NewUserService(m.DB)
 
 	// Attach user service to Main for testing.
@@ -198,10 +256,19 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	m.HTTPServer.GitHubClientSecret = m.Config.GitHub.ClientSecret
 
 	// Attach underlying services to the HTTP server.
+	m.HTTPServer.AuditService = auditService
 	m.HTTPServer.AuthService = authService
 	m.HTTPServer.DialService = dialService
+	m.HTTPServer.DialAlertService = dialAlertService
+	m.HTTPServer.DialAnomalyService = dialAnomalyService
+	m.HTTPServer.DialBanService = dialBanService
+	m.HTTPServer.DialChildService = dialChildService
+	m.HTTPServer.DialGoalService = dialGoalService
 	m.HTTPServer.DialMembershipService = dialMembershipService
+	m.HTTPServer.DialPeriodService = dialPeriodService
+	m.HTTPServer.DialReminderService = dialReminderService
 	m.HTTPServer.EventService = eventService
+	m.HTTPServer.InvitationService = invitationService
 	m.HTTPServer.UserService = userService
 
 	// Start the HTTP server.
@@ -209,6 +276,13 @@ func (m *Main) Run(ctx context.Context) (err error) {
 		return err
 	}
 
+	// Start running scheduled jobs against the services.
+	m.Scheduler.DialMembershipService = dialMembershipService
+	m.Scheduler.DialReminderService = dialReminderService
+	if err := m.Scheduler.Open(); err != nil {
+		return err
+	}
+
 	// If TLS enabled, redirect non-TLS connections to TLS.
 	if m.HTTPServer.UseTLS() {
 		go func() {
@@ -230,12 +304,26 @@ const (
 
 	// DefaultDSN is the default datasource name.
 	DefaultDSN = "~/.wtfd/db"
+
+	// DefaultSMTPAddr is the default address of the SMTP relay.
+	DefaultSMTPAddr = "localhost:25"
+
+	// DefaultSMTPFrom is the default sender address for alert & reminder emails.
+	DefaultSMTPFrom = "wtf@localhost"
 )
 
 // Config represents the CLI configuration file.
 type Config struct {
 	DB struct {
 		DSN string `toml:"dsn"`
+
+		// Duration to keep raw dial values, such as "720h". Older values are
+		// rolled up into hourly & daily tiers. Empty retains values forever.
+		DialValueRetention string `toml:"dial-value-retention"`
+
+		// Duration to keep archived dials before they are permanently
+		// deleted, such as "2160h". Empty keeps archived dials forever.
+		DialArchiveRetention string `toml:"dial-archive-retention"`
 	} `toml:"db"`
 
 	HTTP struct {
@@ -257,12 +345,22 @@ type Config struct {
 	Rollbar struct {
 		Token string `toml:"token"`
 	} `toml:"rollbar"`
+
+	// SMTP relay used to email dial alerts & check-in reminders.
+	SMTP struct {
+		Addr     string `toml:"addr"`
+		From     string `toml:"from"`
+		Username string `toml:"username"`
+		Password string `toml:"password"`
+	} `toml:"smtp"`
 }
 
 // DefaultConfig returns a new instance of Config with defaults set.
 func DefaultConfig() Config {
 	var config Config
 	config.DB.DSN = DefaultDSN
+	config.SMTP.Addr = DefaultSMTPAddr
+	config.SMTP.From = DefaultSMTPFrom
 	return config
 }
 
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// DefaultSchedulerInterval is the default time between scheduler runs.
+const DefaultSchedulerInterval = 10 * time.Second
+
+// Scheduler periodically runs jobs which act on a dial's schedule, such as
+// sending check-in reminders & resetting member values. Jobs go through the
+// services so history, audit entries & events are produced as usual.
+type Scheduler struct {
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+
+	// Time between runs.
+	Interval time.Duration
+
+	// Services used to perform scheduled work.
+	DialMembershipService *sqlite.DialMembershipService
+	DialReminderService   *sqlite.DialReminderService
+}
+
+// NewScheduler returns a new instance of Scheduler.
+func NewScheduler() *Scheduler {
+	s := &Scheduler{Interval: DefaultSchedulerInterval}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// Open starts running scheduled jobs in a background goroutine.
+func (s *Scheduler) Open() error {
+	s.wg.Add(1)
+	go func() { defer s.wg.Done(); s.run() }()
+	return nil
+}
+
+// Close stops the scheduler & waits for any in-progress run to finish.
+func (s *Scheduler) Close() error {
+	s.cancel()
+	s.wg.Wait()
+	return nil
+}
+
+// run executes all jobs on every tick until the scheduler is closed.
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.DialMembershipService.ResetDialValues(s.ctx); err != nil {
+			log.Printf("dial reset error: %s", err)
+		}
+		if err := s.DialReminderService.SendDialReminders(s.ctx); err != nil {
+			log.Printf("dial reminder error: %s", err)
+		}
+		if err := s.DialReminderService.DeliverDialReminders(s.ctx); err != nil {
+			log.Printf("dial reminder delivery error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/cmd/wtfd"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// Ensure the scheduler runs scheduled value resets in the background until
+// it is closed.
+func TestScheduler(t *testing.T) {
+	db := sqlite.NewDB(filepath.Join(t.TempDir(), "db"))
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	start := time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return start }
+
+	user := &wtf.User{Name: "jane"}
+	if err := sqlite.NewUserService(db).CreateUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	ctx := wtf.NewContextWithUser(context.Background(), user)
+
+	dialService := sqlite.NewDialService(db)
+	dial := &wtf.Dial{
+		Name:              "DIAL",
+		ResetIntervalDays: 1,
+		ResetValue:        10,
+		ResetTimezone:     "UTC",
+		NextResetAt:       start.Add(time.Hour),
+	}
+	if err := dialService.CreateDial(ctx, dial); err != nil {
+		t.Fatal(err)
+	} else if err := dialService.SetDialMembershipValue(ctx, dial.ID, 50, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move past the reset time before starting the scheduler.
+	db.Now = func() time.Time { return start.Add(2 * time.Hour) }
+
+	s := main.NewScheduler()
+	s.Interval = 10 * time.Millisecond
+	s.DialMembershipService = sqlite.NewDialMembershipService(db)
+	s.DialReminderService = sqlite.NewDialReminderService(db)
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the reset to be applied by a scheduler run.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		other, err := dialService.FindDialByID(ctx, dial.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if other.Value == 10 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for reset: value=%d", other.Value)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -19,6 +19,10 @@ const (
 	// related but both the "http" and "http/html" packages use it so it is
 	// easier to move it to the root.
 	flashContextKey
+
+	// Stores how the current request was made (web, API key or system) so
+	// that state changes can be attributed in the audit log.
+	auditSourceContextKey
 )
 
 // NewContextWithUser returns a new context with the given user.
@@ -51,3 +55,17 @@ func FlashFromContext(ctx context.Context) string {
 	v, _ := ctx.Value(flashContextKey).(string)
 	return v
 }
+
+// NewContextWithAuditSource returns a new context with the given audit source.
+func NewContextWithAuditSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, auditSourceContextKey, source)
+}
+
+// AuditSourceFromContext returns the audit source for the current request.
+// Returns AuditSourceSystem if no source has been set.
+func AuditSourceFromContext(ctx context.Context) string {
+	if v, _ := ctx.Value(auditSourceContextKey).(string); v != "" {
+		return v
+	}
+	return AuditSourceSystem
+}
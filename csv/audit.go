@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// AuditEntryEncoder encodes audit log entries in CSV format to a writer.
+type AuditEntryEncoder struct {
+	w *csv.Writer
+}
+
+// NewAuditEntryEncoder returns a new instance of AuditEntryEncoder that writes to w.
+func NewAuditEntryEncoder(w io.Writer) *AuditEntryEncoder {
+	enc := &AuditEntryEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"id",
+		"user_id",
+		"user",
+		"action",
+		"target_type",
+		"target_id",
+		"dial_id",
+		"before",
+		"after",
+		"source",
+		"created_at",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *AuditEntryEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeAuditEntry encodes an audit entry row to the underlying CSV writer.
+// The user name is left blank for system changes or deleted users.
+func (enc *AuditEntryEncoder) EncodeAuditEntry(entry *wtf.AuditEntry) error {
+	var userName string
+	if entry.User != nil {
+		userName = entry.User.Name
+	}
+
+	return enc.w.Write([]string{
+		strconv.Itoa(entry.ID),
+		strconv.Itoa(entry.UserID),
+		userName,
+		entry.Action,
+		entry.TargetType,
+		strconv.Itoa(entry.TargetID),
+		strconv.Itoa(entry.DialID),
+		string(entry.Before),
+		string(entry.After),
+		entry.Source,
+		entry.CreatedAt.Format(time.RFC3339),
+	})
+}
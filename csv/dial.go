@@ -23,6 +23,7 @@ func NewDialEncoder(w io.Writer) *DialEncoder {
 		"id",
 		"name",
 		"value",
+		"band",
 		"created_by",
 		"created_at",
 		"updated_at",
@@ -43,6 +44,7 @@ func (enc *DialEncoder) EncodeDial(dial *wtf.Dial) error {
 		strconv.Itoa(dial.ID),
 		dial.Name,
 		strconv.Itoa(dial.Value),
+		dial.Band,
 		dial.User.Name,
 		dial.CreatedAt.Format(time.RFC3339),
 		dial.UpdatedAt.Format(time.RFC3339),
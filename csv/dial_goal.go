@@ -0,0 +1,55 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialGoalStatusEncoder encodes the per-window compliance of a goal in CSV
+// format to a writer.
+type DialGoalStatusEncoder struct {
+	w *csv.Writer
+}
+
+// NewDialGoalStatusEncoder returns a new instance of DialGoalStatusEncoder that writes to w.
+func NewDialGoalStatusEncoder(w io.Writer) *DialGoalStatusEncoder {
+	enc := &DialGoalStatusEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"window_start",
+		"window_end",
+		"total_minutes",
+		"elapsed_minutes",
+		"missed_minutes",
+		"budget_minutes",
+		"remaining_budget_minutes",
+		"compliance",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *DialGoalStatusEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeDialGoalStatus encodes a single window to the underlying CSV writer.
+func (enc *DialGoalStatusEncoder) EncodeDialGoalStatus(status *wtf.DialGoalStatus) error {
+	return enc.w.Write([]string{
+		status.WindowStart.Format(time.RFC3339),
+		status.WindowEnd.Format(time.RFC3339),
+		strconv.Itoa(status.TotalMinutes),
+		strconv.Itoa(status.ElapsedMinutes),
+		strconv.Itoa(status.MissedMinutes),
+		strconv.Itoa(status.BudgetMinutes),
+		strconv.Itoa(status.RemainingBudgetMinutes()),
+		strconv.FormatFloat(status.Compliance(), 'f', 4, 64),
+	})
+}
@@ -0,0 +1,51 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialValueHeatmapCellEncoder encodes heatmap cells in CSV format to a writer.
+type DialValueHeatmapCellEncoder struct {
+	w *csv.Writer
+}
+
+// NewDialValueHeatmapCellEncoder returns a new instance of DialValueHeatmapCellEncoder that writes to w.
+func NewDialValueHeatmapCellEncoder(w io.Writer) *DialValueHeatmapCellEncoder {
+	enc := &DialValueHeatmapCellEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"weekday",
+		"hour",
+		"value",
+		"n",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *DialValueHeatmapCellEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeDialValueHeatmapCell encodes a heatmap cell row to the underlying CSV writer.
+// The value is left blank for cells without any hours in the range.
+func (enc *DialValueHeatmapCellEncoder) EncodeDialValueHeatmapCell(cell *wtf.DialValueHeatmapCell) error {
+	var value string
+	if cell.N > 0 {
+		value = strconv.Itoa(cell.Value)
+	}
+
+	return enc.w.Write([]string{
+		cell.Weekday.String(),
+		strconv.Itoa(cell.Hour),
+		value,
+		strconv.Itoa(cell.N),
+	})
+}
@@ -0,0 +1,75 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialPeriodEncoder encodes closed period summaries in CSV format to a writer.
+type DialPeriodEncoder struct {
+	w *csv.Writer
+}
+
+// NewDialPeriodEncoder returns a new instance of DialPeriodEncoder that writes to w.
+func NewDialPeriodEncoder(w io.Writer) *DialPeriodEncoder {
+	enc := &DialPeriodEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"id",
+		"name",
+		"start_at",
+		"end_at",
+		"threshold",
+		"minutes_above_threshold",
+		"member",
+		"average",
+		"peak",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *DialPeriodEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeDialPeriod encodes a period to the underlying CSV writer. The first
+// row holds the dial summary with a blank member column & it is followed by a
+// row for each member's summary.
+func (enc *DialPeriodEncoder) EncodeDialPeriod(period *wtf.DialPeriod) error {
+	prefix := []string{
+		strconv.Itoa(period.ID),
+		period.Name,
+		period.StartAt.Format(time.RFC3339),
+		period.EndAt.Format(time.RFC3339),
+		strconv.Itoa(period.Threshold),
+	}
+
+	if err := enc.w.Write(append(prefix,
+		strconv.Itoa(period.MinutesAboveThreshold),
+		"",
+		strconv.FormatFloat(period.Average, 'f', -1, 64),
+		strconv.Itoa(period.Peak),
+	)); err != nil {
+		return err
+	}
+
+	for _, m := range period.Members {
+		if err := enc.w.Write(append(prefix[:len(prefix):len(prefix)],
+			"",
+			m.Name,
+			strconv.FormatFloat(m.Average, 'f', -1, 64),
+			strconv.Itoa(m.Peak),
+		)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
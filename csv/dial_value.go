@@ -0,0 +1,71 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialValueRecordEncoder encodes report records in CSV format to a writer.
+type DialValueRecordEncoder struct {
+	w *csv.Writer
+}
+
+// NewDialValueRecordEncoder returns a new instance of DialValueRecordEncoder that writes to w.
+func NewDialValueRecordEncoder(w io.Writer) *DialValueRecordEncoder {
+	enc := &DialValueRecordEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"timestamp",
+		"value",
+		"band",
+		"min",
+		"max",
+		"avg",
+		"members",
+		"member_min",
+		"member_max",
+		"member_p50",
+		"member_p90",
+		"member_stddev",
+		"disagreement",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *DialValueRecordEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeDialValueRecord encodes a report record row to the underlying CSV writer.
+// Member stats columns are left blank for reports that do not include them.
+func (enc *DialValueRecordEncoder) EncodeDialValueRecord(record *wtf.DialValueRecord) error {
+	stats := make([]string, 7)
+	if s := record.Stats; s != nil {
+		stats = []string{
+			strconv.Itoa(s.N),
+			strconv.Itoa(s.Min),
+			strconv.Itoa(s.Max),
+			strconv.Itoa(s.P50),
+			strconv.Itoa(s.P90),
+			strconv.FormatFloat(s.StdDev, 'f', -1, 64),
+			strconv.FormatFloat(s.Disagreement, 'f', -1, 64),
+		}
+	}
+
+	return enc.w.Write(append([]string{
+		record.Timestamp.Format(time.RFC3339),
+		strconv.Itoa(record.Value),
+		record.Band,
+		strconv.Itoa(record.Min),
+		strconv.Itoa(record.Max),
+		strconv.Itoa(record.Avg),
+	}, stats...))
+}
@@ -3,7 +3,12 @@ package wtf
 import (
 	"context"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // Dial constants.
@@ -11,6 +16,50 @@ const (
 	MaxDialNameLen = 100
 )
 
+// Default scale for new dials.
+const (
+	DefaultDialScaleMin  = 0
+	DefaultDialScaleMax  = 100
+	DefaultDialScaleStep = 1
+)
+
+// Dial scale band limits.
+const (
+	MaxDialBands        = 10
+	MaxDialBandLabelLen = 32
+)
+
+// Dial dimension limits.
+const (
+	MaxDialDimensions       = 8
+	MaxDialDimensionNameLen = 50
+)
+
+// Stale membership policies. A member is stale once they have not updated
+// their membership for the dial's StaleAfterDays.
+const (
+	DialStalePolicyNone    = ""        // stale members count at their last value
+	DialStalePolicyExclude = "exclude" // stale members do not contribute to the dial value
+	DialStalePolicyDecay   = "decay"   // stale values decay toward the dial's stale baseline
+)
+
+// DialResetNote is the note set on each membership changed by a scheduled reset.
+const DialResetNote = "Scheduled reset"
+
+// DialScaleChangeNote is the note set on each membership moved onto a new
+// scale after the dial's scale changed.
+const DialScaleChangeNote = "Dial scale changed"
+
+// DialDimensionChangeNote is the note set on each membership whose overall
+// value changed after a dimension was removed from the dial.
+const DialDimensionChangeNote = "Dial dimensions changed"
+
+// Default stale membership settings for new dials.
+const (
+	DefaultDialStaleAfterDays    = 14
+	DefaultDialStaleHalfLifeDays = 7
+)
+
 // Dial represents an aggregate WTF level. They are used to roll up the WTF
 // levels of multiple members and show an average WTF level.
 //
@@ -36,10 +85,64 @@ type Dial struct {
 	// It allows the creation of a shareable link without explicitly inviting users.
 	InviteCode string `json:"inviteCode,omitempty"`
 
+	// If true, users joining through the invite code are added as pending
+	// members and must be approved by the dial owner before contributing.
+	RequireApproval bool `json:"requireApproval"`
+
+	// Z-score thresholds used to detect anomalies in the dial value. A spike
+	// is a single sample above the spike threshold while a sustained anomaly
+	// is a run of samples above the sustained threshold.
+	AnomalySpikeThreshold     float64 `json:"anomalySpikeThreshold"`
+	AnomalySustainedThreshold float64 `json:"anomalySustainedThreshold"`
+
+	// Policy for members who have not updated their value recently. Members
+	// are stale after StaleAfterDays without an update. Under the decay policy,
+	// a stale value moves halfway toward StaleBaseline every StaleHalfLifeDays.
+	StalePolicy       string `json:"stalePolicy"`
+	StaleAfterDays    int    `json:"staleAfterDays"`
+	StaleBaseline     int    `json:"staleBaseline"`
+	StaleHalfLifeDays int    `json:"staleHalfLifeDays"`
+
+	// Schedule for resetting every member's value to ResetValue, such as at
+	// the start of each sprint. Resets repeat every ResetIntervalDays from
+	// NextResetAt, keeping the same local time in ResetTimezone. Resets are
+	// disabled if ResetIntervalDays is zero.
+	ResetIntervalDays int       `json:"resetIntervalDays"`
+	ResetValue        int       `json:"resetValue"`
+	ResetTimezone     string    `json:"resetTimezone"`
+	NextResetAt       time.Time `json:"nextResetAt"`
+
+	// Range of values that members can choose from along with optional
+	// labelled bands, such as "calm" or "on fire".
+	Scale DialScale `json:"scale"`
+
+	// Optional named dimensions that members rate separately, such as "code
+	// quality" or "on-call load". A member's WTF level on a dial with
+	// dimensions is the average of their dimension values.
+	Dimensions []*DialDimension `json:"dimensions"`
+
+	// Optional expression over other dials, such as "max(dial(3), dial(7))".
+	// If set, the dial value is computed from the expression instead of from
+	// its members or child dials. See DialExpression for the syntax. Every
+	// active member must be able to view each dial the expression references.
+	Expression string `json:"expression"`
+
 	// Aggregate WTF level for the dial. This is a computed field based on the
 	// average value of each member's WTF level.
 	Value int `json:"value"`
 
+	// Label of the scale band containing the dial value. Empty if the value is
+	// not within a band. This is a computed field.
+	Band string `json:"band"`
+
+	// Spread of the active members' WTF levels. This is a computed field.
+	Stats *DialStats `json:"stats,omitempty"`
+
+	// Time the dial was archived by its owner. Archived dials are hidden from
+	// dial listings & are read-only but keep their history until they are
+	// restored or deleted. Zero if the dial is not archived.
+	ArchivedAt time.Time `json:"archivedAt"`
+
 	// Timestamps for dial creation & last update.
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -68,10 +171,164 @@ func (d *Dial) Validate() error {
 		return Errorf(EINVALID, "Dial name too long.")
 	} else if d.UserID == 0 {
 		return Errorf(EINVALID, "Dial creator required.")
+	} else if d.AnomalySpikeThreshold <= 0 || d.AnomalySustainedThreshold <= 0 {
+		return Errorf(EINVALID, "Anomaly thresholds must be greater than zero.")
+	} else if d.StalePolicy != DialStalePolicyNone && d.StalePolicy != DialStalePolicyExclude && d.StalePolicy != DialStalePolicyDecay {
+		return Errorf(EINVALID, "Invalid stale membership policy.")
+	} else if d.StaleAfterDays < 1 || d.StaleHalfLifeDays < 1 {
+		return Errorf(EINVALID, "Stale membership periods must be at least one day.")
+	} else if err := d.Scale.Validate(); err != nil {
+		return err
+	} else if d.StalePolicy == DialStalePolicyDecay && (d.StaleBaseline < d.Scale.Min || d.StaleBaseline > d.Scale.Max) {
+		return Errorf(EINVALID, "Stale baseline must be between %d & %d.", d.Scale.Min, d.Scale.Max)
+	} else if d.ResetIntervalDays < 0 {
+		return Errorf(EINVALID, "Reset interval must be zero or more days.")
+	} else if d.HasResetPolicy() && (d.ResetValue < d.Scale.Min || d.ResetValue > d.Scale.Max) {
+		return Errorf(EINVALID, "Reset value must be between %d & %d.", d.Scale.Min, d.Scale.Max)
+	} else if d.HasResetPolicy() && !d.Scale.Contains(d.ResetValue) {
+		return Errorf(EINVALID, "Reset value must be in steps of %d.", d.Scale.Step)
+	} else if _, err := time.LoadLocation(d.ResetTimezone); err != nil {
+		return Errorf(EINVALID, "Unknown reset time zone.")
+	} else if d.ResetIntervalDays > 0 && d.NextResetAt.IsZero() {
+		return Errorf(EINVALID, "Next reset time required.")
+	} else if err := d.ValidateDimensions(); err != nil {
+		return err
+	} else if d.IsComputed() {
+		if _, err := ParseDialExpression(d.Expression); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// ValidateDimensions returns an error if the dial's dimensions are invalid.
+func (d *Dial) ValidateDimensions() error {
+	if len(d.Dimensions) > MaxDialDimensions {
+		return Errorf(EINVALID, "Dial cannot have more than %d dimensions.", MaxDialDimensions)
+	}
+
+	for i, dim := range d.Dimensions {
+		if dim.Name == "" {
+			return Errorf(EINVALID, "Dimension name required.")
+		} else if utf8.RuneCountInString(dim.Name) > MaxDialDimensionNameLen {
+			return Errorf(EINVALID, "Dimension name must be %d characters or less.", MaxDialDimensionNameLen)
+		}
+		for _, other := range d.Dimensions[:i] {
+			if strings.EqualFold(dim.Name, other.Name) {
+				return Errorf(EINVALID, "Dimension %q is listed more than once.", dim.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// HasDimensions returns true if members rate the dial on separate dimensions.
+func (d *Dial) HasDimensions() bool {
+	return len(d.Dimensions) > 0
+}
+
+// DimensionByName returns the dimension with the given name, ignoring case.
+// Returns nil if the dial has no such dimension.
+func (d *Dial) DimensionByName(name string) *DialDimension {
+	for _, dim := range d.Dimensions {
+		if strings.EqualFold(dim.Name, strings.TrimSpace(name)) {
+			return dim
+		}
+	}
+	return nil
+}
+
+// IsArchived returns true if the dial has been archived.
+func (d *Dial) IsArchived() bool {
+	return !d.ArchivedAt.IsZero()
+}
+
+// IsComputed returns true if the dial value is computed from an expression.
+func (d *Dial) IsComputed() bool {
+	return d.Expression != ""
+}
+
+// HasResetPolicy returns true if member values are reset on a schedule.
+func (d *Dial) HasResetPolicy() bool {
+	return d.ResetIntervalDays > 0
+}
+
+// ResetLocation returns the time zone of the reset schedule. Returns UTC if
+// the time zone is no longer recognized.
+func (d *Dial) ResetLocation() *time.Location {
+	loc, err := time.LoadLocation(d.ResetTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NextResetAfter returns the first scheduled reset after t. Resets keep the
+// same local time across daylight saving changes. Returns the zero time if the
+// dial has no reset policy.
+func (d *Dial) NextResetAfter(t time.Time) time.Time {
+	if !d.HasResetPolicy() {
+		return time.Time{}
+	}
+
+	next := d.NextResetAt.In(d.ResetLocation())
+	for !next.After(t) {
+		next = next.AddDate(0, 0, d.ResetIntervalDays)
+	}
+	return next
+}
+
+// IsMembershipStale returns true if the membership has not been updated within
+// the dial's stale period. Always returns false if the dial has no stale policy.
+func (d *Dial) IsMembershipStale(m *DialMembership, now time.Time) bool {
+	if d.StalePolicy == DialStalePolicyNone {
+		return false
+	}
+	return !now.Before(m.UpdatedAt.AddDate(0, 0, d.StaleAfterDays))
+}
+
+// EffectiveMembershipValue returns the value that a membership contributes to
+// the dial after applying the dial's stale policy. Returns false if the
+// membership is excluded from the dial value.
+func (d *Dial) EffectiveMembershipValue(m *DialMembership, now time.Time) (int, bool) {
+	if !d.IsMembershipStale(m, now) {
+		return m.Value, true
+	}
+
+	switch d.StalePolicy {
+	case DialStalePolicyExclude:
+		return 0, false
+	case DialStalePolicyDecay:
+		elapsed := now.Sub(m.UpdatedAt.AddDate(0, 0, d.StaleAfterDays))
+		halfLife := time.Duration(d.StaleHalfLifeDays) * 24 * time.Hour
+		f := math.Pow(0.5, float64(elapsed)/float64(halfLife))
+		return int(math.Round(float64(d.StaleBaseline) + float64(m.Value-d.StaleBaseline)*f)), true
+	default:
+		return m.Value, true
+	}
+}
+
+// EffectiveMembershipDimensionValue returns the value that a membership
+// contributes to one of the dial's dimensions after applying the dial's stale
+// policy. Returns false if the membership is excluded from the dimension.
+func (d *Dial) EffectiveMembershipDimensionValue(m *DialMembership, dim *DialMembershipDimension, now time.Time) (int, bool) {
+	other := *m
+	other.Value = dim.Value
+	return d.EffectiveMembershipValue(&other, now)
+}
+
+// PendingMemberships returns the memberships awaiting approval by the owner.
+// Returns nil if memberships is unset.
+func (d *Dial) PendingMemberships() []*DialMembership {
+	var a []*DialMembership
+	for _, m := range d.Memberships {
+		if m.IsPending() {
+			a = append(a, m)
+		}
+	}
+	return a
+}
+
 // CanEditDial returns true if the current user can edit the dial.
 // Only the dial owner can edit the dial.
 func CanEditDial(ctx context.Context, dial *Dial) bool {
@@ -81,12 +338,13 @@ func CanEditDial(ctx context.Context, dial *Dial) bool {
 // DialService represents a service for managing dials.
 type DialService interface {
 	// Retrieves a single dial by ID along with associated memberships. Only
-	// the dial owner & members can see a dial. Returns ENOTFOUND if dial does
-	// not exist or user does not have permission to view it.
+	// the dial owner & active members can see a dial. Members awaiting
+	// approval cannot. Returns ENOTFOUND if dial does not exist or user does
+	// not have permission to view it.
 	FindDialByID(ctx context.Context, id int) (*Dial, error)
 
 	// Retrieves a list of dials based on a filter. Only returns dials that
-	// the user owns or is a member of. Also returns a count of total matching
+	// the user owns or is an active member of. Also returns a count of total matching
 	// dials which may different from the number of returned dials if the
 	// "Limit" field is set.
 	FindDials(ctx context.Context, filter DialFilter) ([]*Dial, int, error)
@@ -95,6 +353,15 @@ type DialService interface {
 	// The owner will automatically be added as a member of the new dial.
 	CreateDial(ctx context.Context, dial *Dial) error
 
+	// Creates a new dial owned by the current user with the name & settings
+	// of an existing dial, including its alert rules, check-in schedules and
+	// pending invitations. The clone has its own invite code & history. Only
+	// the dial owner can clone a dial.
+	//
+	// Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if user
+	// is not the dial owner.
+	CloneDial(ctx context.Context, id int, clone DialClone) (*Dial, error)
+
 	// Updates an existing dial by ID. Only the dial owner can update a dial.
 	// Returns the new dial state even if there was an error during update.
 	//
@@ -102,23 +369,75 @@ type DialService interface {
 	// is not the dial owner.
 	UpdateDial(ctx context.Context, id int, upd DialUpdate) (*Dial, error)
 
-	// Permanently removes a dial by ID. Only the dial owner may delete a dial.
+	// Archives a dial by ID. Archived dials are hidden from FindDials() by
+	// default and cannot be changed, although their history is kept. Only
+	// the dial owner can archive a dial.
+	//
 	// Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if user
-	// is not the dial owner.
+	// is not the dial owner. Returns ECONFLICT if the dial is already archived.
+	ArchiveDial(ctx context.Context, id int) (*Dial, error)
+
+	// Restores an archived dial by ID. Only the dial owner can restore a dial.
+	//
+	// Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if user
+	// is not the dial owner. Returns ECONFLICT if the dial is not archived.
+	RestoreDial(ctx context.Context, id int) (*Dial, error)
+
+	// Permanently removes a dial & its history by ID. A dial must be archived
+	// before it can be deleted. Only the dial owner may delete a dial.
+	//
+	// Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if user
+	// is not the dial owner. Returns ECONFLICT if the dial is not archived.
 	DeleteDial(ctx context.Context, id int) error
 
 	// Sets the value of the user's membership in a dial. This works the same
 	// as calling UpdateDialMembership() although it doesn't require that the
-	// user know their membership ID. Only the dial ID.
+	// user know their membership ID. Only the dial ID. The note is optional
+	// and is stored alongside the value in the membership's value history.
+	// If the dial has dimensions then every dimension is set to value.
 	//
 	// Returns ENOTFOUND if the membership does not exist.
-	SetDialMembershipValue(ctx context.Context, dialID, value int) error
+	SetDialMembershipValue(ctx context.Context, dialID, value int, note string) error
+
+	// Sets the values of named dimensions on the user's membership in a dial.
+	// Dimensions that are not listed keep their current value and the user's
+	// WTF level becomes the average of all their dimension values. Names are
+	// matched ignoring case.
+	//
+	// Returns ENOTFOUND if the membership does not exist. Returns EINVALID if
+	// the dial does not have a dimension with one of the given names.
+	SetDialMembershipDimensionValues(ctx context.Context, dialID int, values map[string]int, note string) error
 
 	// AverageDialValueReport returns a report of the average dial value across
-	// all dials that the user is a member of. Average values are computed
-	// between start & end time and are slotted into given intervals. The
-	// minimum interval size is one minute.
+	// all unarchived dials that the user is an active member of. Average
+	// values are computed between start & end time and are slotted into given
+	// intervals. The minimum interval size is one minute.
 	AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*DialValueReport, error)
+
+	// DialValueReport returns a report of a single dial's value between start
+	// & end time, slotted into given intervals. Each record includes the
+	// minimum, maximum & time-weighted average value within the slot. The
+	// minimum interval size is one minute. Returns ENOTFOUND if the dial does
+	// not exist or the user cannot view it.
+	DialValueReport(ctx context.Context, id int, start, end time.Time, interval time.Duration) (*DialValueReport, error)
+
+	// DialDimensionValueReport returns a report of the aggregate value of one
+	// of a dial's dimensions between start & end time, slotted into given
+	// intervals. Records are the same as DialValueReport() except that they
+	// do not include stats. Returns ENOTFOUND if the dimension does not exist
+	// or the user cannot view the dial.
+	DialDimensionValueReport(ctx context.Context, id, dimensionID int, start, end time.Time, interval time.Duration) (*DialValueReport, error)
+
+	// AverageDialValueHeatmap returns the average value across all dials that
+	// the user is a member of for each hour of each day of the week between
+	// start & end time. Hours are grouped by local time in the given location.
+	AverageDialValueHeatmap(ctx context.Context, start, end time.Time, loc *time.Location) (*DialValueHeatmap, error)
+
+	// DialValueHeatmap returns the time-weighted average value of a single
+	// dial for each hour of each day of the week between start & end time.
+	// Hours are grouped by local time in the given location. Returns
+	// ENOTFOUND if the dial does not exist or the user cannot view it.
+	DialValueHeatmap(ctx context.Context, id int, start, end time.Time, loc *time.Location) (*DialValueHeatmap, error)
 }
 
 // DialFilter represents a filter used by FindDials().
@@ -127,31 +446,397 @@ type DialFilter struct {
 	ID         *int    `json:"id"`
 	InviteCode *string `json:"inviteCode"`
 
+	// Archived dials are only returned if set or when filtering by ID.
+	IncludeArchived bool `json:"includeArchived"`
+
 	// Restrict to subset of range.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 }
 
+// DialClone represents a set of options used by CloneDial().
+type DialClone struct {
+	// Name of the new dial. Defaults to the name of the cloned dial.
+	Name string `json:"name"`
+
+	// If true, active members of the cloned dial are added to the new dial
+	// with their values reset to the bottom of the scale.
+	IncludeMembers bool `json:"includeMembers"`
+}
+
 // DialUpdate represents a set of fields to update on a dial.
 type DialUpdate struct {
-	Name *string `json:"name"`
+	Name            *string `json:"name"`
+	RequireApproval *bool   `json:"requireApproval"`
+
+	AnomalySpikeThreshold     *float64 `json:"anomalySpikeThreshold"`
+	AnomalySustainedThreshold *float64 `json:"anomalySustainedThreshold"`
+
+	StalePolicy       *string `json:"stalePolicy"`
+	StaleAfterDays    *int    `json:"staleAfterDays"`
+	StaleBaseline     *int    `json:"staleBaseline"`
+	StaleHalfLifeDays *int    `json:"staleHalfLifeDays"`
+
+	ResetIntervalDays *int       `json:"resetIntervalDays"`
+	ResetValue        *int       `json:"resetValue"`
+	ResetTimezone     *string    `json:"resetTimezone"`
+	NextResetAt       *time.Time `json:"nextResetAt"`
+
+	// Replaces the entire scale, including its bands. Member values outside
+	// of the new scale are moved to the nearest value on the scale.
+	Scale *DialScale `json:"scale"`
+
+	// Replaces the list of dimensions if non-nil. Dimensions with an ID are
+	// renamed & reordered, dimensions without an ID are added and missing
+	// dimensions are removed. An empty list removes all dimensions.
+	Dimensions []*DialDimension `json:"dimensions"`
+
+	// Replaces the dial's expression. An empty expression turns a computed
+	// dial back into one whose value comes from its members or child dials.
+	Expression *string `json:"expression"`
 }
 
-// DialValueReport represents a report generated by AverageDialValueReport().
-// Each record represents the average value within an interval of time.
+// DialScale represents the range of values members can choose for a dial,
+// such as 1-5 for a retro or 0-100 for an on-call dial. Values must be a
+// whole number of steps from the minimum.
+type DialScale struct {
+	Min  int `json:"min"`
+	Max  int `json:"max"`
+	Step int `json:"step"`
+
+	// Optional labelled ranges of the scale, ordered by value. Bands do not
+	// overlap but do not need to cover the entire scale.
+	Bands []*DialBand `json:"bands"`
+}
+
+// DefaultDialScale returns the scale used by dials that do not specify one.
+func DefaultDialScale() DialScale {
+	return DialScale{
+		Min:   DefaultDialScaleMin,
+		Max:   DefaultDialScaleMax,
+		Step:  DefaultDialScaleStep,
+		Bands: []*DialBand{},
+	}
+}
+
+// dialBandColorRegex matches a hex color, such as "#2c7be5".
+var dialBandColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Validate returns an error if the scale or its bands are invalid.
+func (s *DialScale) Validate() error {
+	if s.Min >= s.Max {
+		return Errorf(EINVALID, "Scale minimum must be less than the maximum.")
+	} else if s.Step < 1 {
+		return Errorf(EINVALID, "Scale step must be at least one.")
+	} else if (s.Max-s.Min)%s.Step != 0 {
+		return Errorf(EINVALID, "Scale step must evenly divide the scale range.")
+	} else if len(s.Bands) > MaxDialBands {
+		return Errorf(EINVALID, "Scale cannot have more than %d bands.", MaxDialBands)
+	}
+
+	for _, band := range s.Bands {
+		if band.Label == "" {
+			return Errorf(EINVALID, "Band label required.")
+		} else if utf8.RuneCountInString(band.Label) > MaxDialBandLabelLen {
+			return Errorf(EINVALID, "Band label must be %d characters or less.", MaxDialBandLabelLen)
+		} else if !dialBandColorRegex.MatchString(band.Color) {
+			return Errorf(EINVALID, "Band color must be a hex color such as #2c7be5.")
+		} else if band.Min > band.Max {
+			return Errorf(EINVALID, "Band %q minimum must not be greater than its maximum.", band.Label)
+		} else if band.Min < s.Min || band.Max > s.Max {
+			return Errorf(EINVALID, "Band %q must be within the scale.", band.Label)
+		}
+	}
+
+	// Check for overlaps against a sorted copy so bands may be in any order.
+	bands := make([]*DialBand, len(s.Bands))
+	copy(bands, s.Bands)
+	sort.Slice(bands, func(i, j int) bool { return bands[i].Min < bands[j].Min })
+	for i := 1; i < len(bands); i++ {
+		if bands[i].Min <= bands[i-1].Max {
+			return Errorf(EINVALID, "Bands %q & %q overlap.", bands[i-1].Label, bands[i].Label)
+		}
+	}
+	return nil
+}
+
+// ValidateValue returns an error if v is not a value on the scale.
+func (s *DialScale) ValidateValue(v int) error {
+	if v < s.Min || v > s.Max {
+		return Errorf(EINVALID, "Dial value must be between %d & %d.", s.Min, s.Max)
+	} else if !s.Contains(v) {
+		return Errorf(EINVALID, "Dial value must be in steps of %d.", s.Step)
+	}
+	return nil
+}
+
+// Contains returns true if v is within the scale & a whole number of steps
+// from the minimum.
+func (s *DialScale) Contains(v int) bool {
+	return v >= s.Min && v <= s.Max && (s.Step <= 1 || (v-s.Min)%s.Step == 0)
+}
+
+// Nearest returns the value on the scale closest to v. Ties round up.
+func (s *DialScale) Nearest(v int) int {
+	if v <= s.Min {
+		return s.Min
+	} else if v >= s.Max {
+		return s.Max
+	} else if s.Step <= 1 {
+		return v
+	}
+	n := int(math.Round(float64(v-s.Min) / float64(s.Step)))
+	return s.Min + n*s.Step
+}
+
+// Fraction returns the position of v within the scale from 0 to 1.
+func (s *DialScale) Fraction(v int) float64 {
+	if s.Max <= s.Min {
+		return 0
+	}
+	return math.Max(0, math.Min(1, float64(v-s.Min)/float64(s.Max-s.Min)))
+}
+
+// ValueAt returns the value on the scale nearest to position f, from 0 to 1.
+// This is the inverse of Fraction().
+func (s *DialScale) ValueAt(f float64) int {
+	return s.Nearest(s.Min + int(math.Round(f*float64(s.Max-s.Min))))
+}
+
+// Band returns the band containing v. Returns nil if v is not within a band.
+func (s *DialScale) Band(v int) *DialBand {
+	for _, band := range s.Bands {
+		if v >= band.Min && v <= band.Max {
+			return band
+		}
+	}
+	return nil
+}
+
+// BandLabel returns the label of the band containing v. Returns a blank
+// string if v is not within a band.
+func (s *DialScale) BandLabel(v int) string {
+	if band := s.Band(v); band != nil {
+		return band.Label
+	}
+	return ""
+}
+
+// Average returns the mean of values rounded to the nearest value on the
+// scale. Returns the scale minimum if there are no values.
+func (s *DialScale) Average(values []int) int {
+	if len(values) == 0 {
+		return s.Min
+	}
+	var sum int
+	for _, v := range values {
+		sum += v
+	}
+	return s.Nearest(int(math.Round(float64(sum) / float64(len(values)))))
+}
+
+// SortBands orders the bands by their minimum value.
+func (s *DialScale) SortBands() {
+	sort.SliceStable(s.Bands, func(i, j int) bool { return s.Bands[i].Min < s.Bands[j].Min })
+}
+
+// DialBand represents a labelled & colored range of a dial's scale. The range
+// is inclusive of both Min & Max.
+type DialBand struct {
+	Label string `json:"label"`
+	Color string `json:"color"`
+	Min   int    `json:"min"`
+	Max   int    `json:"max"`
+}
+
+// DialDimension represents a named aspect of a dial that members rate
+// separately, such as "code quality", "on-call load" or "process". Dimensions
+// share the dial's scale.
+type DialDimension struct {
+	ID     int `json:"id"`
+	DialID int `json:"dialID"`
+
+	// Human-readable name of the dimension. Unique within the dial.
+	Name string `json:"name"`
+
+	// Order of the dimension within the dial, starting from zero.
+	Position int `json:"position"`
+
+	// Average of the active members' values for the dimension & the label of
+	// the scale band containing it. These are computed fields.
+	Value int    `json:"value"`
+	Band  string `json:"band"`
+
+	// Timestamps for dimension creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DialPolarizedDisagreement is the disagreement score at which a dial's
+// members are considered polarized.
+const DialPolarizedDisagreement = 0.6
+
+// DialStats represents the spread of member values within a dial. A dial at 50
+// means something very different when every member is at 50 than when members
+// are split between both ends of the scale.
+type DialStats struct {
+	// Number of members the stats were computed from.
+	N int `json:"n"`
+
+	// Range & percentiles of member values.
+	Min int `json:"min"`
+	Max int `json:"max"`
+	P50 int `json:"p50"`
+	P90 int `json:"p90"`
+
+	// Population standard deviation of member values.
+	StdDev float64 `json:"stddev"`
+
+	// Disagreement score from 0 to 1. This is the standard deviation relative
+	// to the largest possible deviation, which occurs when members are evenly
+	// split between both ends of the dial's scale.
+	Disagreement float64 `json:"disagreement"`
+}
+
+// IsPolarized returns true if members are split into opposing camps.
+func (s *DialStats) IsPolarized() bool {
+	return s.N >= 2 && s.Disagreement >= DialPolarizedDisagreement
+}
+
+// DialValueReport represents a report generated by AverageDialValueReport(),
+// DialValueReport() or MembershipValueReport(). Each record represents the
+// value within an interval of time.
 type DialValueReport struct {
-	Records []*DialValueRecord
+	Records []*DialValueRecord `json:"records"`
+
+	// Scheduled resets within the report range. This is only set by
+	// DialValueReport().
+	Resets []*DialReset `json:"resets,omitempty"`
 }
 
-// DialValueRecord represents an average dial value at a given point in time
-// for the DialValueReport.
+// MaxDialValueReportSlots is the maximum number of intervals a value report
+// can be split into. This bounds the work done for a single request.
+const MaxDialValueReportSlots = 1000
+
+// ValidateDialValueReportRange returns EINVALID if a report range is empty or
+// reversed, if the interval is under a minute, or if the range would be split
+// into more than MaxDialValueReportSlots intervals.
+func ValidateDialValueReportRange(start, end time.Time, interval time.Duration) error {
+	if interval < time.Minute {
+		return Errorf(EINVALID, "Report interval must be at least one minute.")
+	} else if !end.After(start) {
+		return Errorf(EINVALID, "Report end time must be after start time.")
+	} else if end.Sub(start)/interval > MaxDialValueReportSlots {
+		return Errorf(EINVALID, "Report cannot have more than %d intervals.", MaxDialValueReportSlots)
+	}
+	return nil
+}
+
+// MaxDialValueHeatmapRange is the longest range of time a heatmap can cover.
+// Heatmaps are computed from hourly slots so this bounds the work done for a
+// single request while still allowing a full year.
+const MaxDialValueHeatmapRange = 366 * 24 * time.Hour
+
+// ValidateDialValueHeatmapRange returns EINVALID if a heatmap range is empty,
+// reversed or longer than MaxDialValueHeatmapRange.
+func ValidateDialValueHeatmapRange(start, end time.Time) error {
+	if !end.After(start) {
+		return Errorf(EINVALID, "Heatmap end time must be after start time.")
+	} else if end.Sub(start) > MaxDialValueHeatmapRange {
+		return Errorf(EINVALID, "Heatmap cannot cover more than %d days.", MaxDialValueHeatmapRange/(24*time.Hour))
+	}
+	return nil
+}
+
+// DialReset represents a scheduled reset of every member's value on a dial.
+type DialReset struct {
+	ID     int `json:"id"`
+	DialID int `json:"dialID"`
+
+	// Value that members were reset to.
+	Value int `json:"value"`
+
+	// Number of members whose value was changed by the reset.
+	N int `json:"n"`
+
+	// Time the reset was performed.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DialValueRecord represents a dial value at a given point in time for the
+// DialValueReport. For AverageDialValueReport() the value is the average
+// across dials. Otherwise it is the value in effect at the end of the slot.
 type DialValueRecord struct {
-	Value     int       `json:"value"`
+	Value int `json:"value"`
+
+	// Minimum, maximum & time-weighted average of the values in effect during
+	// the slot. These are not set by AverageDialValueReport().
+	Min int `json:"min"`
+	Max int `json:"max"`
+	Avg int `json:"avg"`
+
+	// Spread of member values at the end of the slot. This is only set by
+	// DialValueReport().
+	Stats *DialStats `json:"stats,omitempty"`
+
+	// Label of the dial's scale band containing the value. This is not set by
+	// AverageDialValueReport() as dials may use different scales.
+	Band string `json:"band,omitempty"`
+
 	Timestamp time.Time `json:"timestamp"`
 }
 
 // GoString prints a more easily readable representation for debugging.
 // The timestamp field is represented as an RFC 3339 string instead of a pointer.
 func (r *DialValueRecord) GoString() string {
-	return fmt.Sprintf("&wtf.DialValueRecord{Value:%d, Timestamp:%q}", r.Value, r.Timestamp.Format(time.RFC3339))
+	return fmt.Sprintf("&wtf.DialValueRecord{Value:%d, Min:%d, Max:%d, Avg:%d, Stats:%#v, Band:%q, Timestamp:%q}", r.Value, r.Min, r.Max, r.Avg, r.Stats, r.Band, r.Timestamp.Format(time.RFC3339))
+}
+
+// DialValueHeatmap represents the average dial value for every hour of every
+// day of the week. This shows recurring patterns such as Monday mornings
+// being worse than Friday afternoons.
+type DialValueHeatmap struct {
+	// Time zone that hours are grouped by.
+	Timezone string `json:"timezone"`
+
+	// Range of time the heatmap was computed over.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// One cell per hour of the week, ordered by weekday starting on Sunday
+	// and then by hour.
+	Cells []*DialValueHeatmapCell `json:"cells"`
+}
+
+// Cell returns the cell for a given weekday & hour.
+func (m *DialValueHeatmap) Cell(weekday time.Weekday, hour int) *DialValueHeatmapCell {
+	return m.Cells[int(weekday)*24+hour]
+}
+
+// DialValueHeatmapCell represents the average dial value for one hour of one
+// day of the week.
+type DialValueHeatmapCell struct {
+	Weekday time.Weekday `json:"weekday"`
+	Hour    int          `json:"hour"`
+
+	// Average value during the hour. Only valid if N is non-zero.
+	Value int `json:"value"`
+
+	// Number of hours in the range that fell in this cell.
+	N int `json:"n"`
+}
+
+// NewDialValueHeatmap returns a heatmap with an empty cell for every hour of
+// the week.
+func NewDialValueHeatmap(loc *time.Location, start, end time.Time) *DialValueHeatmap {
+	m := &DialValueHeatmap{
+		Timezone: loc.String(),
+		Start:    start,
+		End:      end,
+		Cells:    make([]*DialValueHeatmapCell, 7*24),
+	}
+	for i := range m.Cells {
+		m.Cells[i] = &DialValueHeatmapCell{Weekday: time.Weekday(i / 24), Hour: i % 24}
+	}
+	return m
 }
@@ -0,0 +1,249 @@
+package wtf
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+// Default cooldown for new alert rules, in minutes.
+const DefaultDialAlertCooldownMinutes = 60
+
+// Dial alert rule subjects. These specify which value a rule is compared against.
+const (
+	// The dial's computed value.
+	DialAlertSubjectDial = "dial"
+
+	// The highest (or lowest) value of any active member.
+	DialAlertSubjectMember = "member"
+)
+
+// Dial alert rule comparison operators.
+const (
+	DialAlertOperatorGTE = ">="
+	DialAlertOperatorLTE = "<="
+)
+
+// Dial alert firing delivery statuses.
+const (
+	// Waiting for webhook or email delivery by the background job.
+	DialAlertFiringStatusPending = "pending"
+
+	// All notification targets were delivered to.
+	DialAlertFiringStatusSent = "sent"
+
+	// One or more notification targets could not be delivered to.
+	DialAlertFiringStatusFailed = "failed"
+)
+
+// DialAlertRule represents a condition on a dial that notifies the dial's
+// members when it is met, such as "value >= 75 for 15 minutes" or "any member
+// at 100". Rules are evaluated whenever the dial's values change as well as
+// periodically so that duration-based conditions are noticed.
+type DialAlertRule struct {
+	ID int `json:"id"`
+
+	// Dial the rule is attached to. Only the dial owner can manage rules.
+	DialID int `json:"dialID"`
+
+	// Human-readable name of the rule.
+	Name string `json:"name"`
+
+	// Value compared by the rule. Either "dial" or "member".
+	Subject string `json:"subject"`
+
+	// Comparison operator & the value the subject is compared to.
+	Operator  string `json:"operator"`
+	Threshold int    `json:"threshold"`
+
+	// Number of minutes the condition must hold before the rule fires.
+	DurationMinutes int `json:"durationMinutes"`
+
+	// Minimum number of minutes between firings. A rule only fires once each
+	// time its condition is met and must clear before it can fire again.
+	CooldownMinutes int `json:"cooldownMinutes"`
+
+	// Notification targets. At least one target must be specified.
+	// In-app notifications are sent to all active members of the dial.
+	NotifyInApp bool   `json:"notifyInApp"`
+	WebhookURL  string `json:"webhookURL"`
+	Email       string `json:"email"`
+
+	// Time the condition was first met. Zero if the condition is not met.
+	ConditionSince time.Time `json:"conditionSince"`
+
+	// Time the rule last fired. Zero if the rule has never fired.
+	LastFiredAt time.Time `json:"lastFiredAt"`
+
+	// Timestamps for rule creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the rule contains invalid fields.
+// This only performs basic validation. The threshold is checked against the
+// dial's scale by the DialAlertService.
+func (r *DialAlertRule) Validate() error {
+	if r.DialID == 0 {
+		return Errorf(EINVALID, "Dial required.")
+	} else if r.Name == "" {
+		return Errorf(EINVALID, "Alert rule name required.")
+	} else if r.Subject != DialAlertSubjectDial && r.Subject != DialAlertSubjectMember {
+		return Errorf(EINVALID, "Invalid alert rule subject.")
+	} else if r.Operator != DialAlertOperatorGTE && r.Operator != DialAlertOperatorLTE {
+		return Errorf(EINVALID, "Invalid alert rule operator.")
+	} else if r.DurationMinutes < 0 {
+		return Errorf(EINVALID, "Alert rule duration must not be negative.")
+	} else if r.CooldownMinutes < 0 {
+		return Errorf(EINVALID, "Alert rule cooldown must not be negative.")
+	} else if !r.NotifyInApp && r.WebhookURL == "" && r.Email == "" {
+		return Errorf(EINVALID, "At least one alert rule notification target required.")
+	}
+
+	if r.WebhookURL != "" {
+		if u, err := url.Parse(r.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Errorf(EINVALID, "Invalid alert rule webhook URL.")
+		}
+	}
+	if r.Email != "" {
+		if _, err := mail.ParseAddress(r.Email); err != nil {
+			return Errorf(EINVALID, "Invalid alert rule email address.")
+		}
+	}
+	return nil
+}
+
+// Duration returns the amount of time the condition must hold before firing.
+func (r *DialAlertRule) Duration() time.Duration {
+	return time.Duration(r.DurationMinutes) * time.Minute
+}
+
+// Cooldown returns the minimum amount of time between firings.
+func (r *DialAlertRule) Cooldown() time.Duration {
+	return time.Duration(r.CooldownMinutes) * time.Minute
+}
+
+// Compare returns true if value meets the rule's condition.
+func (r *DialAlertRule) Compare(value int) bool {
+	switch r.Operator {
+	case DialAlertOperatorGTE:
+		return value >= r.Threshold
+	case DialAlertOperatorLTE:
+		return value <= r.Threshold
+	default:
+		return false
+	}
+}
+
+// Condition returns a human-readable description of the rule's condition, such
+// as "value >= 75 for 15 minutes" or "any member >= 100".
+func (r *DialAlertRule) Condition() string {
+	subject := "value"
+	if r.Subject == DialAlertSubjectMember {
+		subject = "any member"
+	}
+
+	s := fmt.Sprintf("%s %s %d", subject, r.Operator, r.Threshold)
+	if r.DurationMinutes == 1 {
+		s += " for 1 minute"
+	} else if r.DurationMinutes > 1 {
+		s += fmt.Sprintf(" for %d minutes", r.DurationMinutes)
+	}
+	return s
+}
+
+// DialAlertFiring represents a single time that an alert rule fired. Firings
+// make up the rule's history & track delivery to external targets.
+type DialAlertFiring struct {
+	ID int `json:"id"`
+
+	// Rule that fired & the dial it is attached to.
+	RuleID int            `json:"ruleID"`
+	Rule   *DialAlertRule `json:"rule"`
+	DialID int            `json:"dialID"`
+	Dial   *Dial          `json:"dial"`
+
+	// Subject value that met the rule's condition.
+	Value int `json:"value"`
+
+	// Delivery status & any errors from webhook or email targets.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// Timestamp of when the rule fired.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DialAlertService represents a service for managing dial alert rules.
+type DialAlertService interface {
+	// Retrieves a single rule by ID. Returns ENOTFOUND if the rule does not
+	// exist or the user is not a member of the rule's dial.
+	FindDialAlertRuleByID(ctx context.Context, id int) (*DialAlertRule, error)
+
+	// Retrieves a list of rules based on a filter. Only returns rules for
+	// dials that the user is a member of. Also returns a count of total
+	// matching rules which may differ if filter.Limit is set.
+	FindDialAlertRules(ctx context.Context, filter DialAlertRuleFilter) ([]*DialAlertRule, int, error)
+
+	// Creates a new rule on a dial. Only the dial owner can create rules.
+	// The rule is evaluated immediately so it may fire upon creation.
+	CreateDialAlertRule(ctx context.Context, rule *DialAlertRule) error
+
+	// Updates an existing rule. Only the dial owner can update rules. The
+	// rule's condition state is reset & the rule is re-evaluated.
+	UpdateDialAlertRule(ctx context.Context, id int, upd DialAlertRuleUpdate) (*DialAlertRule, error)
+
+	// Permanently deletes a rule & its firing history.
+	// Only the dial owner can delete rules.
+	DeleteDialAlertRule(ctx context.Context, id int) error
+
+	// Retrieves a list of firings based on a filter. Only returns firings for
+	// dials that the user is a member of. Firings are returned newest first.
+	// Also returns a count of total matching firings which may differ if
+	// filter.Limit is set.
+	FindDialAlertFirings(ctx context.Context, filter DialAlertFiringFilter) ([]*DialAlertFiring, int, error)
+}
+
+// DialAlertRuleFilter represents a filter used by FindDialAlertRules().
+type DialAlertRuleFilter struct {
+	ID     *int `json:"id"`
+	DialID *int `json:"dialID"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialAlertRuleUpdate represents a set of fields to update on a rule.
+type DialAlertRuleUpdate struct {
+	Name            *string `json:"name"`
+	Subject         *string `json:"subject"`
+	Operator        *string `json:"operator"`
+	Threshold       *int    `json:"threshold"`
+	DurationMinutes *int    `json:"durationMinutes"`
+	CooldownMinutes *int    `json:"cooldownMinutes"`
+	NotifyInApp     *bool   `json:"notifyInApp"`
+	WebhookURL      *string `json:"webhookURL"`
+	Email           *string `json:"email"`
+}
+
+// DialAlertFiringFilter represents a filter used by FindDialAlertFirings().
+type DialAlertFiringFilter struct {
+	ID     *int    `json:"id"`
+	RuleID *int    `json:"ruleID"`
+	DialID *int    `json:"dialID"`
+	Status *string `json:"status"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialAlertNotifier delivers a fired alert to an external target such as a
+// webhook or email address. Notifiers are called by a background job outside
+// of any database transaction so they may block on the network.
+type DialAlertNotifier interface {
+	NotifyDialAlert(ctx context.Context, firing *DialAlertFiring) error
+}
@@ -0,0 +1,71 @@
+package wtf
+
+import (
+	"context"
+	"time"
+)
+
+// Default z-score thresholds for new dials.
+const (
+	DefaultDialAnomalySpikeThreshold     = 3.0
+	DefaultDialAnomalySustainedThreshold = 2.0
+)
+
+// Dial anomaly kinds.
+const (
+	// A sudden jump in the dial value well above its recent baseline.
+	DialAnomalyKindSpike = "spike"
+
+	// The dial value has stayed above its recent baseline for a while.
+	DialAnomalyKindSustained = "sustained"
+)
+
+// DialAnomaly represents an unusual rise in a dial's value relative to its
+// rolling baseline. Anomalies are detected by a background job so that dial
+// members are notified of bad periods without watching the chart.
+type DialAnomaly struct {
+	ID int `json:"id"`
+
+	// Dial the anomaly was detected on.
+	DialID int `json:"dialID"`
+
+	// Type of anomaly. Either "spike" or "sustained".
+	Kind string `json:"kind"`
+
+	// Average dial value during the sample that triggered the anomaly along
+	// with the baseline it was compared against.
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+	StdDev   float64 `json:"stddev"`
+
+	// Number of standard deviations the value was above the baseline.
+	ZScore float64 `json:"zScore"`
+
+	// Start of the sample that triggered the anomaly.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Timestamp of when the anomaly was detected.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DialAnomalyService represents a service for listing detected anomalies.
+// Anomalies are only created by the storage layer's background detection job
+// so there are no mutating methods.
+type DialAnomalyService interface {
+	// Retrieves a list of anomalies based on a filter. Only returns anomalies
+	// for dials that the user is a member of. Anomalies are returned newest
+	// first. Also returns a count of total matching anomalies which may
+	// differ if filter.Limit is set.
+	FindDialAnomalies(ctx context.Context, filter DialAnomalyFilter) ([]*DialAnomaly, int, error)
+}
+
+// DialAnomalyFilter represents a filter used by FindDialAnomalies().
+type DialAnomalyFilter struct {
+	ID     *int    `json:"id"`
+	DialID *int    `json:"dialID"`
+	Kind   *string `json:"kind"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
@@ -0,0 +1,66 @@
+package wtf
+
+import (
+	"context"
+	"time"
+)
+
+// DialBan represents a user who has been removed from a dial and may not
+// rejoin it. Banned users cannot join through the invite link and cannot be
+// directly invited until the dial owner lifts the ban.
+type DialBan struct {
+	ID int `json:"id"`
+
+	// Dial the user is banned from.
+	DialID int `json:"dialID"`
+
+	// User who is banned.
+	UserID int   `json:"userID"`
+	User   *User `json:"user"`
+
+	// Optional note from the dial owner describing the ban.
+	Reason string `json:"reason"`
+
+	// Timestamp of when the user was banned.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate returns an error if the ban contains invalid fields.
+// This only performs basic validation.
+func (b *DialBan) Validate() error {
+	if b.DialID == 0 {
+		return Errorf(EINVALID, "Dial required for ban.")
+	} else if b.UserID == 0 {
+		return Errorf(EINVALID, "User required for ban.")
+	}
+	return nil
+}
+
+// DialBanService represents a service for managing dial ban lists.
+type DialBanService interface {
+	// Retrieves a list of bans based on a filter. The dial owner can see all
+	// bans for their dial while other users can only see their own bans.
+	// Also returns a count of total matching bans which may differ if
+	// filter.Limit is set.
+	FindDialBans(ctx context.Context, filter DialBanFilter) ([]*DialBan, int, error)
+
+	// Bans a user from a dial. Any existing membership is removed and pending
+	// invitations are revoked. Only the dial owner can ban users. Returns
+	// ECONFLICT if the user is already banned.
+	CreateDialBan(ctx context.Context, ban *DialBan) error
+
+	// Lifts a ban so the user may rejoin the dial. Only the dial owner can
+	// remove a ban. Returns ENOTFOUND if the ban does not exist.
+	DeleteDialBan(ctx context.Context, id int) error
+}
+
+// DialBanFilter represents a filter used by FindDialBans().
+type DialBanFilter struct {
+	ID     *int `json:"id"`
+	DialID *int `json:"dialID"`
+	UserID *int `json:"userID"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
@@ -0,0 +1,106 @@
+package wtf
+
+import (
+	"context"
+	"time"
+)
+
+// Dial child weight constraints.
+const (
+	DefaultDialChildWeight = 1
+	MaxDialChildWeight     = 100
+)
+
+// DialChild represents a dial that is attached to a parent dial, such as a
+// squad's dial attached to an engineering-wide dial. A parent dial with
+// children takes its value from them instead of from its own members. Each
+// child's value is placed on the parent's scale in proportion to where it sits
+// on its own scale & weighted against its siblings.
+//
+// Dials form a hierarchy so each dial may only be attached to one parent and a
+// dial cannot be attached below itself.
+type DialChild struct {
+	ID int `json:"id"`
+
+	// Parent dial that the child's value rolls up into.
+	ParentDialID int   `json:"parentDialID"`
+	ParentDial   *Dial `json:"parentDial"`
+
+	// Child dial that is attached to the parent.
+	ChildDialID int   `json:"childDialID"`
+	ChildDial   *Dial `json:"childDial"`
+
+	// Relative weight of the child's value against the other children of
+	// the parent. Defaults to DefaultDialChildWeight.
+	Weight float64 `json:"weight"`
+
+	// Timestamps for attachment & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the child contains invalid fields.
+// This only performs basic validation.
+func (c *DialChild) Validate() error {
+	if c.ParentDialID == 0 {
+		return Errorf(EINVALID, "Parent dial required.")
+	} else if c.ChildDialID == 0 {
+		return Errorf(EINVALID, "Child dial required.")
+	} else if c.ParentDialID == c.ChildDialID {
+		return Errorf(EINVALID, "Dial cannot be attached to itself.")
+	} else if c.Weight <= 0 || c.Weight > MaxDialChildWeight {
+		return Errorf(EINVALID, "Child dial weight must be greater than 0 & no more than %d.", MaxDialChildWeight)
+	}
+	return nil
+}
+
+// CanEditDialChild returns true if the current user can reweight or detach
+// the child. This is the owner of either the parent or the child dial.
+func CanEditDialChild(ctx context.Context, child *DialChild) bool {
+	userID := UserIDFromContext(ctx)
+	return (child.ParentDial != nil && child.ParentDial.UserID == userID) ||
+		(child.ChildDial != nil && child.ChildDial.UserID == userID)
+}
+
+// DialChildService represents a service for managing the hierarchy of dials.
+type DialChildService interface {
+	// Retrieves a single child by ID along with its parent & child dials.
+	// Returns ENOTFOUND if the child does not exist or the user is not a
+	// member of the parent dial & does not own the child dial.
+	FindDialChildByID(ctx context.Context, id int) (*DialChild, error)
+
+	// Retrieves a list of children based on a filter. Only returns children
+	// of dials the user is a member of & children of dials the user owns.
+	// Also returns a count of total matching children which may differ if
+	// filter.Limit is set.
+	FindDialChildren(ctx context.Context, filter DialChildFilter) ([]*DialChild, int, error)
+
+	// Attaches a dial to a parent dial & recomputes the parent's value. The
+	// user must own both the child & the parent dial. Returns ECONFLICT if the
+	// dial already has a parent.
+	CreateDialChild(ctx context.Context, child *DialChild) error
+
+	// Updates the weight of a child & recomputes the parent's value. Only the
+	// owner of the parent or child dial can update it.
+	UpdateDialChild(ctx context.Context, id int, upd DialChildUpdate) (*DialChild, error)
+
+	// Detaches a child from its parent & recomputes the parent's value. Only
+	// the owner of the parent or child dial can detach it.
+	DeleteDialChild(ctx context.Context, id int) error
+}
+
+// DialChildFilter represents a filter used by FindDialChildren().
+type DialChildFilter struct {
+	ID           *int `json:"id"`
+	ParentDialID *int `json:"parentDialID"`
+	ChildDialID  *int `json:"childDialID"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialChildUpdate represents a set of fields to update on a child.
+type DialChildUpdate struct {
+	Weight *float64 `json:"weight"`
+}
@@ -0,0 +1,455 @@
+package wtf
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dial expression limits.
+const (
+	MaxDialExpressionLen    = 500
+	MaxDialExpressionInputs = 20
+	MaxDialExpressionDepth  = 32
+)
+
+// DialExpression represents a parsed expression used to compute the value of
+// a dial from the values of other dials. Expressions support numbers, the
+// +, -, * & / operators, parentheses and the following functions:
+//
+//	dial(id)          value of another dial; id must be a literal integer
+//	min(x, ...)       smallest argument
+//	max(x, ...)       largest argument
+//	avg(x, ...)       average of the arguments
+//	abs(x)            absolute value
+//	round(x)          nearest integer
+//	clamp(x, lo, hi)  x limited to the range lo to hi
+//
+// For example, "max(dial(3), dial(7)) * 0.8 + 10".
+type DialExpression struct {
+	text   string
+	root   dialExpressionNode
+	inputs []int
+}
+
+// ParseDialExpression parses text into an expression. Returns EINVALID if the
+// expression is malformed or exceeds the expression limits.
+func ParseDialExpression(text string) (*DialExpression, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, Errorf(EINVALID, "Expression required.")
+	} else if len(text) > MaxDialExpressionLen {
+		return nil, Errorf(EINVALID, "Expression must be %d characters or less.", MaxDialExpressionLen)
+	}
+
+	p := &dialExpressionParser{text: text}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	} else if tok := p.next(); tok.kind != dialExpressionTokenEOF {
+		return nil, p.unexpected(tok)
+	}
+
+	// Collect the unique set of input dials.
+	inputs := make([]int, 0, len(p.inputs))
+	for id := range p.inputs {
+		inputs = append(inputs, id)
+	}
+	sort.Ints(inputs)
+	if len(inputs) > MaxDialExpressionInputs {
+		return nil, Errorf(EINVALID, "Expression cannot reference more than %d dials.", MaxDialExpressionInputs)
+	}
+
+	return &DialExpression{text: text, root: root, inputs: inputs}, nil
+}
+
+// String returns the expression text.
+func (e *DialExpression) String() string {
+	return e.text
+}
+
+// DialIDs returns the IDs of the dials referenced by the expression in order.
+func (e *DialExpression) DialIDs() []int {
+	return e.inputs
+}
+
+// Eval evaluates the expression using the given dial values. Returns EINVALID
+// if a referenced dial is missing from values or the result is not a number,
+// such as after a division by zero.
+func (e *DialExpression) Eval(values map[int]float64) (float64, error) {
+	v, err := e.root.eval(values)
+	if err != nil {
+		return 0, err
+	} else if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, Errorf(EINVALID, "Expression result is not a number.")
+	}
+	return v, nil
+}
+
+// dialExpressionNode represents a node in a parsed expression tree.
+type dialExpressionNode interface {
+	eval(values map[int]float64) (float64, error)
+}
+
+// dialExpressionNumber is a numeric literal.
+type dialExpressionNumber float64
+
+func (n dialExpressionNumber) eval(values map[int]float64) (float64, error) {
+	return float64(n), nil
+}
+
+// dialExpressionDial is a reference to the value of another dial.
+type dialExpressionDial int
+
+func (n dialExpressionDial) eval(values map[int]float64) (float64, error) {
+	v, ok := values[int(n)]
+	if !ok {
+		return 0, Errorf(EINVALID, "Expression input dial(%d) is not available.", int(n))
+	}
+	return v, nil
+}
+
+// dialExpressionNegate negates its operand.
+type dialExpressionNegate struct {
+	x dialExpressionNode
+}
+
+func (n *dialExpressionNegate) eval(values map[int]float64) (float64, error) {
+	x, err := n.x.eval(values)
+	return -x, err
+}
+
+// dialExpressionBinary applies an arithmetic operator to two operands.
+type dialExpressionBinary struct {
+	op   byte
+	x, y dialExpressionNode
+}
+
+func (n *dialExpressionBinary) eval(values map[int]float64) (float64, error) {
+	x, err := n.x.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	y, err := n.y.eval(values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return x + y, nil
+	case '-':
+		return x - y, nil
+	case '*':
+		return x * y, nil
+	default:
+		if y == 0 {
+			return 0, Errorf(EINVALID, "Expression divides by zero.")
+		}
+		return x / y, nil
+	}
+}
+
+// dialExpressionCall applies a built-in function to its arguments.
+type dialExpressionCall struct {
+	fn   *dialExpressionFunc
+	args []dialExpressionNode
+}
+
+func (n *dialExpressionCall) eval(values map[int]float64) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	return n.fn.call(args), nil
+}
+
+// dialExpressionFunc represents a built-in function. If maxArgs is zero then
+// the function accepts any number of arguments from minArgs.
+type dialExpressionFunc struct {
+	minArgs, maxArgs int
+	call             func(args []float64) float64
+}
+
+// dialExpressionFuncs are the built-in functions available to expressions.
+// The dial() function is handled separately by the parser.
+var dialExpressionFuncs = map[string]*dialExpressionFunc{
+	"min": {minArgs: 1, call: func(args []float64) float64 {
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = math.Min(v, arg)
+		}
+		return v
+	}},
+	"max": {minArgs: 1, call: func(args []float64) float64 {
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = math.Max(v, arg)
+		}
+		return v
+	}},
+	"avg": {minArgs: 1, call: func(args []float64) float64 {
+		var sum float64
+		for _, arg := range args {
+			sum += arg
+		}
+		return sum / float64(len(args))
+	}},
+	"abs": {minArgs: 1, maxArgs: 1, call: func(args []float64) float64 {
+		return math.Abs(args[0])
+	}},
+	"round": {minArgs: 1, maxArgs: 1, call: func(args []float64) float64 {
+		return math.Round(args[0])
+	}},
+	"clamp": {minArgs: 3, maxArgs: 3, call: func(args []float64) float64 {
+		return math.Max(args[1], math.Min(args[2], args[0]))
+	}},
+}
+
+// Expression token kinds.
+const (
+	dialExpressionTokenEOF = iota
+	dialExpressionTokenNumber
+	dialExpressionTokenIdent
+	dialExpressionTokenOp
+	dialExpressionTokenIllegal
+)
+
+// dialExpressionToken represents a lexical token & its position in the text.
+type dialExpressionToken struct {
+	kind int
+	pos  int
+	text string
+}
+
+// dialExpressionParser is a recursive descent parser for dial expressions.
+type dialExpressionParser struct {
+	text   string
+	pos    int
+	peeked *dialExpressionToken
+	inputs map[int]struct{}
+}
+
+// parseExpr parses a sum of terms.
+func (p *dialExpressionParser) parseExpr(depth int) (dialExpressionNode, error) {
+	x, err := p.parseTerm(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != dialExpressionTokenOp || (tok.text != "+" && tok.text != "-") {
+			return x, nil
+		}
+		p.next()
+
+		y, err := p.parseTerm(depth)
+		if err != nil {
+			return nil, err
+		}
+		x = &dialExpressionBinary{op: tok.text[0], x: x, y: y}
+	}
+}
+
+// parseTerm parses a product of unary expressions.
+func (p *dialExpressionParser) parseTerm(depth int) (dialExpressionNode, error) {
+	x, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != dialExpressionTokenOp || (tok.text != "*" && tok.text != "/") {
+			return x, nil
+		}
+		p.next()
+
+		y, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		x = &dialExpressionBinary{op: tok.text[0], x: x, y: y}
+	}
+}
+
+// parseUnary parses an optionally negated primary expression. Every level of
+// nesting passes through here so the depth limit is enforced here as well.
+func (p *dialExpressionParser) parseUnary(depth int) (dialExpressionNode, error) {
+	if depth > MaxDialExpressionDepth {
+		return nil, Errorf(EINVALID, "Expression is nested too deeply.")
+	}
+
+	if tok := p.peek(); tok.kind == dialExpressionTokenOp && tok.text == "-" {
+		p.next()
+		x, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &dialExpressionNegate{x: x}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+// parsePrimary parses a number, function call or parenthesized expression.
+func (p *dialExpressionParser) parsePrimary(depth int) (dialExpressionNode, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == dialExpressionTokenNumber:
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, Errorf(EINVALID, "Invalid number %q in expression.", tok.text)
+		}
+		return dialExpressionNumber(v), nil
+
+	case tok.kind == dialExpressionTokenIdent:
+		return p.parseCall(tok, depth)
+
+	case tok.kind == dialExpressionTokenOp && tok.text == "(":
+		x, err := p.parseExpr(depth + 1)
+		if err != nil {
+			return nil, err
+		} else if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+// parseCall parses the arguments of a function call after its name.
+func (p *dialExpressionParser) parseCall(name dialExpressionToken, depth int) (dialExpressionNode, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	// Dial references only accept a literal ID so that the inputs of an
+	// expression are known without evaluating it.
+	if strings.ToLower(name.text) == "dial" {
+		tok := p.next()
+		id, err := strconv.Atoi(tok.text)
+		if tok.kind != dialExpressionTokenNumber || err != nil || id <= 0 {
+			return nil, Errorf(EINVALID, "The dial() function requires a dial ID at position %d.", tok.pos+1)
+		} else if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+
+		if p.inputs == nil {
+			p.inputs = make(map[int]struct{})
+		}
+		p.inputs[id] = struct{}{}
+		return dialExpressionDial(id), nil
+	}
+
+	fn := dialExpressionFuncs[strings.ToLower(name.text)]
+	if fn == nil {
+		return nil, Errorf(EINVALID, "Unknown function %q in expression.", name.text)
+	}
+
+	var args []dialExpressionNode
+	if tok := p.peek(); tok.kind != dialExpressionTokenOp || tok.text != ")" {
+		for {
+			arg, err := p.parseExpr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if tok := p.peek(); tok.kind != dialExpressionTokenOp || tok.text != "," {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs > 0 && len(args) > fn.maxArgs) {
+		return nil, Errorf(EINVALID, "Wrong number of arguments to %s().", strings.ToLower(name.text))
+	}
+	return &dialExpressionCall{fn: fn, args: args}, nil
+}
+
+// expect reads the next token & returns an error if it is not the given operator.
+func (p *dialExpressionParser) expect(op string) error {
+	if tok := p.next(); tok.kind != dialExpressionTokenOp || tok.text != op {
+		return p.unexpected(tok)
+	}
+	return nil
+}
+
+// unexpected returns an error describing an unexpected token.
+func (p *dialExpressionParser) unexpected(tok dialExpressionToken) error {
+	if tok.kind == dialExpressionTokenEOF {
+		return Errorf(EINVALID, "Unexpected end of expression.")
+	}
+	return Errorf(EINVALID, "Unexpected %q at position %d in expression.", tok.text, tok.pos+1)
+}
+
+// peek returns the next token without consuming it.
+func (p *dialExpressionParser) peek() dialExpressionToken {
+	if p.peeked == nil {
+		tok := p.scan()
+		p.peeked = &tok
+	}
+	return *p.peeked
+}
+
+// next consumes & returns the next token.
+func (p *dialExpressionParser) next() dialExpressionToken {
+	tok := p.peek()
+	p.peeked = nil
+	return tok
+}
+
+// scan reads the next token from the text.
+func (p *dialExpressionParser) scan() dialExpressionToken {
+	for p.pos < len(p.text) && isDialExpressionSpace(p.text[p.pos]) {
+		p.pos++
+	}
+	if p.pos >= len(p.text) {
+		return dialExpressionToken{kind: dialExpressionTokenEOF, pos: p.pos}
+	}
+
+	start, ch := p.pos, p.text[p.pos]
+	switch {
+	case isDialExpressionDigit(ch) || ch == '.':
+		for p.pos < len(p.text) && (isDialExpressionDigit(p.text[p.pos]) || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		return dialExpressionToken{kind: dialExpressionTokenNumber, pos: start, text: p.text[start:p.pos]}
+
+	case isDialExpressionLetter(ch):
+		for p.pos < len(p.text) && (isDialExpressionLetter(p.text[p.pos]) || isDialExpressionDigit(p.text[p.pos])) {
+			p.pos++
+		}
+		return dialExpressionToken{kind: dialExpressionTokenIdent, pos: start, text: p.text[start:p.pos]}
+
+	case strings.IndexByte("+-*/(),", ch) != -1:
+		p.pos++
+		return dialExpressionToken{kind: dialExpressionTokenOp, pos: start, text: p.text[start:p.pos]}
+
+	default:
+		p.pos++
+		return dialExpressionToken{kind: dialExpressionTokenIllegal, pos: start, text: p.text[start:p.pos]}
+	}
+}
+
+func isDialExpressionSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+func isDialExpressionDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isDialExpressionLetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_'
+}
@@ -0,0 +1,93 @@
+package wtf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+)
+
+func TestParseDialExpression(t *testing.T) {
+	values := map[int]float64{3: 40, 7: 90}
+
+	// Ensure valid expressions evaluate with standard precedence.
+	for _, tt := range []struct {
+		text   string
+		inputs []int
+		value  float64
+	}{
+		{text: "max(dial(3), dial(7)) * 0.8 + 10", inputs: []int{3, 7}, value: 82},
+		{text: "dial(7) - dial(3) - 10", inputs: []int{3, 7}, value: 40},
+		{text: "-(dial(3) + 2) * 2", inputs: []int{3}, value: -84},
+		{text: "avg(dial(3), dial(3), 100) / 2", inputs: []int{3}, value: 30},
+		{text: "clamp(dial(7), 0, 50) + abs(-1) + round(0.6) + MIN(1, 2)", inputs: []int{7}, value: 53},
+		{text: "42", inputs: []int{}, value: 42},
+	} {
+		t.Run(tt.text, func(t *testing.T) {
+			expr, err := wtf.ParseDialExpression(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			} else if got := expr.DialIDs(); !reflect.DeepEqual(got, tt.inputs) {
+				t.Fatalf("DialIDs()=%v, want %v", got, tt.inputs)
+			}
+
+			if v, err := expr.Eval(values); err != nil {
+				t.Fatal(err)
+			} else if v != tt.value {
+				t.Fatalf("Eval()=%v, want %v", v, tt.value)
+			}
+		})
+	}
+
+	// Ensure malformed expressions are rejected.
+	for _, tt := range []struct {
+		text string
+		msg  string
+	}{
+		{text: "", msg: "Expression required."},
+		{text: "1 +", msg: "Unexpected end of expression."},
+		{text: "1 2", msg: `Unexpected "2" at position 3 in expression.`},
+		{text: "dial(x)", msg: "The dial() function requires a dial ID at position 6."},
+		{text: "dial(1 + 2)", msg: `Unexpected "+" at position 8 in expression.`},
+		{text: "pow(2, 3)", msg: `Unknown function "pow" in expression.`},
+		{text: "clamp(1, 2)", msg: "Wrong number of arguments to clamp()."},
+		{text: "1 % 2", msg: `Unexpected "%" at position 3 in expression.`},
+		{text: "1..2", msg: `Invalid number "1..2" in expression.`},
+	} {
+		t.Run(tt.text, func(t *testing.T) {
+			if _, err := wtf.ParseDialExpression(tt.text); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		})
+	}
+
+	// Ensure evaluation fails instead of producing an unusable value.
+	t.Run("ErrEval", func(t *testing.T) {
+		if expr, err := wtf.ParseDialExpression("dial(3) / (dial(7) - 90)"); err != nil {
+			t.Fatal(err)
+		} else if _, err := expr.Eval(values); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression divides by zero.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if expr, err := wtf.ParseDialExpression("dial(4)"); err != nil {
+			t.Fatal(err)
+		} else if _, err := expr.Eval(values); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression input dial(4) is not available.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure deeply nested expressions are rejected.
+	t.Run("ErrTooDeep", func(t *testing.T) {
+		text := ""
+		for i := 0; i < 40; i++ {
+			text += "("
+		}
+		text += "1"
+		for i := 0; i < 40; i++ {
+			text += ")"
+		}
+		if _, err := wtf.ParseDialExpression(text); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression is nested too deeply.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,306 @@
+package wtf
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Dial goal comparison operators.
+const (
+	DialGoalOperatorLT  = "<"
+	DialGoalOperatorLTE = "<="
+	DialGoalOperatorGT  = ">"
+	DialGoalOperatorGTE = ">="
+)
+
+// Dial goal windows. Compliance & the error budget start over at the
+// beginning of each window in the goal's time zone. Weeks start on Monday.
+const (
+	DialGoalWindowWeek  = "week"
+	DialGoalWindowMonth = "month"
+)
+
+// Dial goal report constants.
+const (
+	DefaultDialGoalReportWindows = 6
+	MaxDialGoalReportWindows     = 24
+)
+
+// DialGoal represents a service level objective on a dial, such as "value
+// below 50 for 90% of working hours each month". Compliance is tracked from
+// the dial's value history during working hours only. The time the goal may
+// be missed while still meeting its target is the goal's error budget. Active
+// members are notified once per window when the budget is exhausted.
+type DialGoal struct {
+	ID int `json:"id"`
+
+	// Dial the goal is attached to. Only the dial owner can manage goals.
+	DialID int `json:"dialID"`
+
+	// Human-readable name of the goal.
+	Name string `json:"name"`
+
+	// Comparison operator & the value the dial is compared to.
+	Operator  string `json:"operator"`
+	Threshold int    `json:"threshold"`
+
+	// Percentage of working time the comparison must hold, such as 90.
+	Target float64 `json:"target"`
+
+	// Length of the window that compliance is measured over.
+	Window string `json:"window"`
+
+	// Days of the week & hours of the day that count toward the goal in the
+	// goal's time zone. Hours cover StartHour up to, but not including,
+	// EndHour. The time zone is an IANA name such as "America/Denver".
+	Weekdays  []time.Weekday `json:"weekdays"`
+	StartHour int            `json:"startHour"`
+	EndHour   int            `json:"endHour"`
+	Timezone  string         `json:"timezone"`
+
+	// Time the error budget was last exhausted. Zero if it never has been.
+	BudgetExhaustedAt time.Time `json:"budgetExhaustedAt"`
+
+	// Compliance in the current window. Computed when the goal is read.
+	Status *DialGoalStatus `json:"status,omitempty"`
+
+	// Timestamps for goal creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the goal contains invalid fields.
+// This only performs basic validation. The threshold is checked against the
+// dial's scale by the DialGoalService.
+func (g *DialGoal) Validate() error {
+	if g.DialID == 0 {
+		return Errorf(EINVALID, "Dial required.")
+	} else if g.Name == "" {
+		return Errorf(EINVALID, "Goal name required.")
+	} else if g.Operator != DialGoalOperatorLT && g.Operator != DialGoalOperatorLTE && g.Operator != DialGoalOperatorGT && g.Operator != DialGoalOperatorGTE {
+		return Errorf(EINVALID, "Invalid goal operator.")
+	} else if g.Target <= 0 || g.Target > 100 {
+		return Errorf(EINVALID, "Goal target must be above 0%% & at most 100%%.")
+	} else if g.Window != DialGoalWindowWeek && g.Window != DialGoalWindowMonth {
+		return Errorf(EINVALID, "Invalid goal window.")
+	} else if len(g.Weekdays) == 0 {
+		return Errorf(EINVALID, "At least one working day required.")
+	} else if g.StartHour < 0 || g.EndHour > 24 || g.StartHour >= g.EndHour {
+		return Errorf(EINVALID, "Working hours must end after they start.")
+	} else if _, err := time.LoadLocation(g.Timezone); err != nil {
+		return Errorf(EINVALID, "Unknown time zone.")
+	}
+
+	for _, weekday := range g.Weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return Errorf(EINVALID, "Invalid working day.")
+		}
+	}
+	return nil
+}
+
+// Location returns the goal's time zone. Returns UTC if the time zone is no
+// longer recognized.
+func (g *DialGoal) Location() *time.Location {
+	loc, err := time.LoadLocation(g.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// HasWeekday returns true if the given day is a working day for the goal.
+func (g *DialGoal) HasWeekday(weekday time.Weekday) bool {
+	for _, v := range g.Weekdays {
+		if v == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns true if value meets the goal.
+func (g *DialGoal) Compare(value int) bool {
+	switch g.Operator {
+	case DialGoalOperatorLT:
+		return value < g.Threshold
+	case DialGoalOperatorLTE:
+		return value <= g.Threshold
+	case DialGoalOperatorGT:
+		return value > g.Threshold
+	case DialGoalOperatorGTE:
+		return value >= g.Threshold
+	default:
+		return false
+	}
+}
+
+// Condition returns a human-readable description of the goal, such as
+// "value < 50 for 90% of working hours each month".
+func (g *DialGoal) Condition() string {
+	return fmt.Sprintf("value %s %d for %s%% of working hours each %s", g.Operator, g.Threshold, strconv.FormatFloat(g.Target, 'f', -1, 64), g.Window)
+}
+
+// WorkingHours returns a human-readable description of the working hours,
+// such as "Weekdays 09:00-17:00 America/Denver".
+func (g *DialGoal) WorkingHours() string {
+	return fmt.Sprintf("%s %02d:00-%02d:00 %s", FormatWeekdays(g.Weekdays), g.StartHour, g.EndHour, g.Location())
+}
+
+// WindowAt returns the start & end of the window containing t.
+func (g *DialGoal) WindowAt(t time.Time) (start, end time.Time) {
+	local := t.In(g.Location())
+	switch g.Window {
+	case DialGoalWindowWeek:
+		offset := (int(local.Weekday()) + 6) % 7 // days since Monday
+		start = time.Date(local.Year(), local.Month(), local.Day()-offset, 0, 0, 0, 0, local.Location())
+		return start, start.AddDate(0, 0, 7)
+	default:
+		start = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, local.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+}
+
+// MaxWindowDuration returns the longest a single window can be, including an
+// extra hour for windows spanning a daylight saving change.
+func (g *DialGoal) MaxWindowDuration() time.Duration {
+	switch g.Window {
+	case DialGoalWindowWeek:
+		return 7*24*time.Hour + time.Hour
+	default:
+		return 31*24*time.Hour + time.Hour
+	}
+}
+
+// WorkingDuration returns the amount of working time between start & end.
+func (g *DialGoal) WorkingDuration(start, end time.Time) time.Duration {
+	local := start.In(g.Location())
+
+	var d time.Duration
+	for day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location()); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !g.HasWeekday(day.Weekday()) {
+			continue
+		}
+
+		// Clamp the day's working hours to the range.
+		from := time.Date(day.Year(), day.Month(), day.Day(), g.StartHour, 0, 0, 0, day.Location())
+		to := time.Date(day.Year(), day.Month(), day.Day(), g.EndHour, 0, 0, 0, day.Location())
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			d += to.Sub(from)
+		}
+	}
+	return d
+}
+
+// DialGoalStatus represents the compliance of a goal during a single window.
+// Only working time after the dial was created counts toward the goal.
+type DialGoalStatus struct {
+	WindowStart time.Time `json:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd"`
+
+	// Working minutes in the whole window & the number that have elapsed.
+	TotalMinutes   int `json:"totalMinutes"`
+	ElapsedMinutes int `json:"elapsedMinutes"`
+
+	// Elapsed working minutes during which the goal was not met.
+	MissedMinutes int `json:"missedMinutes"`
+
+	// Working minutes the goal may be missed during the window while still
+	// meeting its target.
+	BudgetMinutes int `json:"budgetMinutes"`
+}
+
+// Compliance returns the fraction, from 0 to 1, of elapsed working time that
+// the goal was met. Returns 1 if no working time has elapsed.
+func (s *DialGoalStatus) Compliance() float64 {
+	if s.ElapsedMinutes == 0 {
+		return 1
+	}
+	return float64(s.ElapsedMinutes-s.MissedMinutes) / float64(s.ElapsedMinutes)
+}
+
+// RemainingBudgetMinutes returns the number of working minutes the goal may
+// still be missed during the window. Never returns less than zero.
+func (s *DialGoalStatus) RemainingBudgetMinutes() int {
+	if v := s.BudgetMinutes - s.MissedMinutes; v > 0 {
+		return v
+	}
+	return 0
+}
+
+// RemainingBudgetRatio returns the fraction, from 0 to 1, of the error budget
+// that remains. Returns 0 if the goal has no budget.
+func (s *DialGoalStatus) RemainingBudgetRatio() float64 {
+	if s.BudgetMinutes == 0 {
+		return 0
+	}
+	return float64(s.RemainingBudgetMinutes()) / float64(s.BudgetMinutes)
+}
+
+// IsExhausted returns true if the goal has been missed for longer than its
+// error budget so it can no longer meet its target in the window.
+func (s *DialGoalStatus) IsExhausted() bool {
+	return s.MissedMinutes > s.BudgetMinutes
+}
+
+// DialGoalService represents a service for managing dial goals.
+type DialGoalService interface {
+	// Retrieves a single goal by ID along with its current status. Returns
+	// ENOTFOUND if the goal does not exist or the user is not a member of
+	// the goal's dial.
+	FindDialGoalByID(ctx context.Context, id int) (*DialGoal, error)
+
+	// Retrieves a list of goals based on a filter along with their current
+	// status. Only returns goals for dials that the user is a member of.
+	// Also returns a count of total matching goals which may differ if
+	// filter.Limit is set.
+	FindDialGoals(ctx context.Context, filter DialGoalFilter) ([]*DialGoal, int, error)
+
+	// Creates a new goal on a dial. Only the dial owner can create goals.
+	// The goal is evaluated immediately so members may be notified upon
+	// creation if its budget is already exhausted.
+	CreateDialGoal(ctx context.Context, goal *DialGoal) error
+
+	// Updates an existing goal. Only the dial owner can update goals. The
+	// goal's exhaustion state is reset & the goal is re-evaluated.
+	UpdateDialGoal(ctx context.Context, id int, upd DialGoalUpdate) (*DialGoal, error)
+
+	// Permanently deletes a goal. Only the dial owner can delete goals.
+	DeleteDialGoal(ctx context.Context, id int) error
+
+	// Returns the compliance of a goal over its most recent windows, oldest
+	// first. The last window is the current one. Windows before the dial was
+	// created or before raw dial values were pruned are omitted.
+	DialGoalReport(ctx context.Context, id, windows int) ([]*DialGoalStatus, error)
+}
+
+// DialGoalFilter represents a filter used by FindDialGoals().
+type DialGoalFilter struct {
+	ID     *int `json:"id"`
+	DialID *int `json:"dialID"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialGoalUpdate represents a set of fields to update on a goal.
+type DialGoalUpdate struct {
+	Name      *string         `json:"name"`
+	Operator  *string         `json:"operator"`
+	Threshold *int            `json:"threshold"`
+	Target    *float64        `json:"target"`
+	Window    *string         `json:"window"`
+	Weekdays  *[]time.Weekday `json:"weekdays"`
+	StartHour *int            `json:"startHour"`
+	EndHour   *int            `json:"endHour"`
+	Timezone  *string         `json:"timezone"`
+}
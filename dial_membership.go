@@ -2,9 +2,24 @@ package wtf
 
 import (
 	"context"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// Dial membership status values.
+//
+// Memberships are active unless the dial requires approval, in which case new
+// members start as pending until the dial owner approves them. Pending members
+// do not contribute to the dial's value.
+const (
+	DialMembershipStatusActive  = "active"
+	DialMembershipStatusPending = "pending"
+)
+
+// MaxDialMembershipNoteLen is the maximum length of a value-change note.
+const MaxDialMembershipNoteLen = 140
+
 // DialMembership represents a contributor to a Dial. Each membership is
 // aggregated to determine the total WTF value of the parent dial.
 //
@@ -21,15 +36,63 @@ type DialMembership struct {
 	UserID int   `json:"userID"`
 	User   *User `json:"user"`
 
-	// Current WTF level for the user for this dial.
-	// Updating this value will cause the parent dial's WTF level to be recomputed.
+	// Current WTF level for the user for this dial. This must be a value on
+	// the dial's scale. Updating this value will cause the parent dial's WTF
+	// level to be recomputed.
 	Value int `json:"value"`
 
+	// Label of the dial's scale band containing the value. Empty if the value
+	// is not within a band. This is a computed field.
+	Band string `json:"band"`
+
+	// Values for each of the dial's dimensions, in dimension order. Only set
+	// if the dial has dimensions, in which case Value is their average.
+	Dimensions []*DialMembershipDimension `json:"dimensions,omitempty"`
+
+	// Optional note explaining the most recent value change, such as
+	// "prod db at 98% disk". Cleared when the value changes without a note.
+	Note string `json:"note"`
+
+	// Approval status of the membership. See DialMembershipStatus constants.
+	Status string `json:"status"`
+
+	// Time until which the member is away, such as while on vacation. Away
+	// members do not contribute to the dial value & are automatically marked
+	// as returned once this time passes. Zero if the member is not away.
+	AwayUntil time.Time `json:"awayUntil"`
+
+	// True if the member has not updated their membership within the dial's
+	// stale period. EffectiveValue is the value contributed to the dial after
+	// applying the dial's stale policy. These are computed fields.
+	Stale          bool `json:"stale"`
+	EffectiveValue int  `json:"effectiveValue"`
+
 	// Timestamps for membership creation & last update.
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// DimensionByName returns the membership's value for the named dimension,
+// ignoring case. Returns nil if the dial has no such dimension.
+func (m *DialMembership) DimensionByName(name string) *DialMembershipDimension {
+	for _, dim := range m.Dimensions {
+		if strings.EqualFold(dim.Name, strings.TrimSpace(name)) {
+			return dim
+		}
+	}
+	return nil
+}
+
+// IsPending returns true if the membership is awaiting approval.
+func (m *DialMembership) IsPending() bool {
+	return m.Status == DialMembershipStatusPending
+}
+
+// IsAway returns true if the member is away at the given time.
+func (m *DialMembership) IsAway(now time.Time) bool {
+	return now.Before(m.AwayUntil)
+}
+
 // CanEditDialMembership returns true if the current user can edit membership.
 func CanEditDialMembership(ctx context.Context, membership *DialMembership) bool {
 	return membership.UserID == UserIDFromContext(ctx)
@@ -48,19 +111,39 @@ func CanDeleteDialMembership(ctx context.Context, membership *DialMembership) bo
 	return membership.UserID == userID // non-dial owner can delete own membership
 }
 
+// CanApproveDialMembership returns true if the current user can approve or
+// reject a pending membership. Only the dial owner can approve members since
+// dials have no admin role; the owner is the only user who manages a dial.
+func CanApproveDialMembership(ctx context.Context, membership *DialMembership) bool {
+	return membership.Dial != nil && membership.Dial.UserID == UserIDFromContext(ctx)
+}
+
 // Validate returns an error if membership fields are invalid.
-// Only performs basic validation.
+// Only performs basic validation. The value is checked against the dial's
+// scale by the DialMembershipService.
 func (m *DialMembership) Validate() error {
 	if m.DialID == 0 {
 		return Errorf(EINVALID, "Dial required for membership.")
 	} else if m.UserID == 0 {
 		return Errorf(EINVALID, "User required for membership.")
-	} else if m.Value < 0 || m.Value > 100 {
-		return Errorf(EINVALID, "Dial value must be between 0 & 100.")
+	} else if utf8.RuneCountInString(m.Note) > MaxDialMembershipNoteLen {
+		return Errorf(EINVALID, "Note must be %d characters or less.", MaxDialMembershipNoteLen)
 	}
 	return nil
 }
 
+// DialMembershipDimension represents a member's value for one of a dial's
+// dimensions.
+type DialMembershipDimension struct {
+	DimensionID int    `json:"dimensionID"`
+	Name        string `json:"name"`
+	Value       int    `json:"value"`
+
+	// Label of the dial's scale band containing the value. This is a computed
+	// field.
+	Band string `json:"band"`
+}
+
 // DialMembershipService represents a service for managing dial memberships.
 type DialMembershipService interface {
 	// Retrieves a membership by ID along with the associated dial & user.
@@ -75,7 +158,9 @@ type DialMembershipService interface {
 	FindDialMemberships(ctx context.Context, filter DialMembershipFilter) ([]*DialMembership, int, error)
 
 	// Creates a new membership on a dial for the current user. Returns
-	// EUNAUTHORIZED if there is no current user logged in.
+	// EUNAUTHORIZED if there is no current user logged in. If the dial
+	// requires approval then the membership is created as pending and the
+	// dial owner is notified.
 	CreateDialMembership(ctx context.Context, membership *DialMembership) error
 
 	// Updates the value of a membership. Only the owner of the membership can
@@ -83,9 +168,40 @@ type DialMembershipService interface {
 	// ENOTFOUND if the membership does not exist.
 	UpdateDialMembership(ctx context.Context, id int, upd DialMembershipUpdate) (*DialMembership, error)
 
+	// Approves a pending membership so that it contributes to the dial value.
+	// Only the dial owner can approve a membership. Returns ECONFLICT if the
+	// membership is not pending.
+	//
+	// Pending memberships are rejected by calling DeleteDialMembership().
+	ApproveDialMembership(ctx context.Context, id int) (*DialMembership, error)
+
+	// Marks the current user's membership in a dial as away until the given
+	// time so that it is excluded from the dial value. A zero time marks the
+	// member as returned. Returns ENOTFOUND if the user is not a member of the
+	// dial. Returns EINVALID if until is not in the future.
+	SetDialMembershipAway(ctx context.Context, dialID int, until time.Time) (*DialMembership, error)
+
+	// Marks all of the current user's memberships as away until the given
+	// time. A zero time marks the user as returned on all their dials.
+	// Returns EINVALID if until is not in the future.
+	SetAway(ctx context.Context, until time.Time) error
+
 	// Permanently deletes a membership by ID. Only the membership owner and
 	// the parent dial's owner can delete a membership.
 	DeleteDialMembership(ctx context.Context, id int) error
+
+	// MembershipValueReport returns a report of a single member's value on a
+	// dial between start & end time, slotted into given intervals. Each slot
+	// holds the last value the member set at or before the end of the slot.
+	// The minimum interval size is one minute. Returns ENOTFOUND if the user
+	// cannot view the dial.
+	MembershipValueReport(ctx context.Context, dialID, userID int, start, end time.Time, interval time.Duration) (*DialValueReport, error)
+
+	// MembershipValueReports returns a report for every active member of a
+	// dial, keyed by user ID. Each report is the same as the one returned by
+	// MembershipValueReport() but all members are fetched together. Returns
+	// ENOTFOUND if the user cannot view the dial.
+	MembershipValueReports(ctx context.Context, dialID int, start, end time.Time, interval time.Duration) (map[int]*DialValueReport, error)
 }
 
 // Dial membership sort options. Only specific sorting options are supported.
@@ -95,9 +211,10 @@ const (
 
 // DialMembershipFilter represents a filter used by FindDialMemberships().
 type DialMembershipFilter struct {
-	ID     *int `json:"id"`
-	DialID *int `json:"dialID"`
-	UserID *int `json:"userID"`
+	ID     *int    `json:"id"`
+	DialID *int    `json:"dialID"`
+	UserID *int    `json:"userID"`
+	Status *string `json:"status"`
 
 	// Restricts to a subset of the results.
 	Offset int `json:"offset"`
@@ -108,6 +225,13 @@ type DialMembershipFilter struct {
 }
 
 // DialMembershipUpdate represents a set of fields to update on a membership.
+//
+// On a dial with dimensions, Value sets every dimension while Dimensions sets
+// individual dimensions by name. Both may be used together, in which case the
+// named dimensions override Value. The membership value then becomes the
+// average of its dimension values.
 type DialMembershipUpdate struct {
-	Value *int `json:"value"`
+	Value      *int           `json:"value"`
+	Dimensions map[string]int `json:"dimensions"`
+	Note       *string        `json:"note"`
 }
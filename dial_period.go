@@ -0,0 +1,148 @@
+package wtf
+
+import (
+	"context"
+	"time"
+)
+
+// Dial period constants.
+const (
+	MaxDialPeriodNameLen = 100
+)
+
+// DialPeriod represents a closed period on a dial, such as a sprint. Closing a
+// period computes a summary of the dial over the date range from the dial's
+// value history & the history of its members' values. The summary is stored
+// when the period is closed so it does not change as the dial moves on.
+type DialPeriod struct {
+	ID int `json:"id"`
+
+	// Dial the period was closed on.
+	DialID int   `json:"dialID"`
+	Dial   *Dial `json:"dial,omitempty"`
+
+	// Human-readable name of the period, such as "Sprint 14".
+	Name string `json:"name"`
+
+	// Time range covered by the period. The end must not be in the future.
+	StartAt time.Time `json:"startAt"`
+	EndAt   time.Time `json:"endAt"`
+
+	// Dial value used to measure how long the dial spent above it.
+	Threshold int `json:"threshold"`
+
+	// Time-weighted average & highest value of the dial during the period
+	// along with the number of minutes the dial was above the threshold.
+	// These are computed when the period is closed.
+	Average               float64 `json:"average"`
+	Peak                  int     `json:"peak"`
+	MinutesAboveThreshold int     `json:"minutesAboveThreshold"`
+
+	// Time-weighted average of each member's value & the notes members left
+	// while changing their value during the period. These are computed when
+	// the period is closed.
+	Members []*DialPeriodMember `json:"members"`
+	Notes   []*DialPeriodNote   `json:"notes"`
+
+	// Timestamp of when the period was closed.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate returns an error if the period contains invalid fields.
+// This only performs basic validation.
+func (p *DialPeriod) Validate() error {
+	if p.DialID == 0 {
+		return Errorf(EINVALID, "Dial required.")
+	} else if p.Name == "" {
+		return Errorf(EINVALID, "Period name required.")
+	} else if len(p.Name) > MaxDialPeriodNameLen {
+		return Errorf(EINVALID, "Period name must be %d characters or less.", MaxDialPeriodNameLen)
+	} else if p.StartAt.IsZero() || p.EndAt.IsZero() {
+		return Errorf(EINVALID, "Period start & end required.")
+	} else if !p.EndAt.After(p.StartAt) {
+		return Errorf(EINVALID, "Period must end after it starts.")
+	}
+	return nil
+}
+
+// Duration returns the length of the period.
+func (p *DialPeriod) Duration() time.Duration {
+	return p.EndAt.Sub(p.StartAt)
+}
+
+// AboveThresholdRatio returns the fraction of the period, from 0 to 1, that
+// the dial spent above the threshold.
+func (p *DialPeriod) AboveThresholdRatio() float64 {
+	if d := p.Duration(); d > 0 {
+		return float64(time.Duration(p.MinutesAboveThreshold)*time.Minute) / float64(d)
+	}
+	return 0
+}
+
+// Member returns the summary for a user. Returns nil if the user was not a
+// member during the period.
+func (p *DialPeriod) Member(userID int) *DialPeriodMember {
+	for _, m := range p.Members {
+		if m.UserID == userID {
+			return m
+		}
+	}
+	return nil
+}
+
+// DialPeriodMember represents a member's values during a closed period.
+type DialPeriodMember struct {
+	UserID int    `json:"userID"`
+	Name   string `json:"name"`
+
+	// Time-weighted average & highest value of the member during the part
+	// of the period that they were a member.
+	Average float64 `json:"average"`
+	Peak    int     `json:"peak"`
+}
+
+// DialPeriodNote represents a note left by a member during a closed period.
+type DialPeriodNote struct {
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	Value     int       `json:"value"`
+	Note      string    `json:"note"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CanEditDialPeriod returns true if the current user can delete the period.
+// This is only the owner of the dial.
+func CanEditDialPeriod(ctx context.Context, period *DialPeriod) bool {
+	return period.Dial != nil && period.Dial.UserID == UserIDFromContext(ctx)
+}
+
+// DialPeriodService represents a service for closing periods on dials.
+type DialPeriodService interface {
+	// Retrieves a single period by ID along with its dial. Returns ENOTFOUND
+	// if the period does not exist or the user cannot view its dial.
+	FindDialPeriodByID(ctx context.Context, id int) (*DialPeriod, error)
+
+	// Retrieves a list of periods based on a filter, most recent first. Only
+	// returns periods on dials the user can view. Also returns a count of
+	// total matching periods which may differ if filter.Limit is set.
+	FindDialPeriods(ctx context.Context, filter DialPeriodFilter) ([]*DialPeriod, int, error)
+
+	// Closes a period on a dial by computing & storing its summary. Only the
+	// dial owner can close a period. Returns EINVALID if the period ends in
+	// the future or the threshold is not on the dial's scale.
+	CloseDialPeriod(ctx context.Context, period *DialPeriod) error
+
+	// Permanently removes a closed period. Only the dial owner can delete it.
+	DeleteDialPeriod(ctx context.Context, id int) error
+}
+
+// DialPeriodFilter represents a filter used by FindDialPeriods().
+type DialPeriodFilter struct {
+	ID     *int  `json:"id"`
+	DialID *int  `json:"dialID"`
+	IDs    []int `json:"ids"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
@@ -0,0 +1,255 @@
+package wtf
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dial reminder delivery statuses.
+const (
+	// Waiting for webhook or email delivery by the background job.
+	DialReminderStatusPending = "pending"
+
+	// All notification targets were delivered to.
+	DialReminderStatusSent = "sent"
+
+	// One or more notification targets could not be delivered to.
+	DialReminderStatusFailed = "failed"
+)
+
+// DialReminderSchedule represents recurring check-in times for a dial, such as
+// weekdays at 10:00 in the team's time zone. At each check-in, active members
+// who have not updated their value since the previous check-in are reminded to
+// do so. Members who are away are not reminded.
+type DialReminderSchedule struct {
+	ID int `json:"id"`
+
+	// Dial the schedule is attached to. Only the dial owner can manage schedules.
+	DialID int `json:"dialID"`
+
+	// Days of the week & time of day of each check-in in the schedule's
+	// time zone. The time zone is an IANA name such as "America/Denver".
+	Weekdays []time.Weekday `json:"weekdays"`
+	Hour     int            `json:"hour"`
+	Minute   int            `json:"minute"`
+	Timezone string         `json:"timezone"`
+
+	// Notification targets. At least one target must be specified. Email
+	// reminders are sent to each reminded member's account email address.
+	NotifyInApp bool   `json:"notifyInApp"`
+	NotifyEmail bool   `json:"notifyEmail"`
+	WebhookURL  string `json:"webhookURL"`
+
+	// Most recent check-in that reminders were sent for.
+	// Zero if no reminders have been sent.
+	LastCheckInAt time.Time `json:"lastCheckInAt"`
+
+	// Timestamps for schedule creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the schedule contains invalid fields.
+// This only performs basic validation.
+func (s *DialReminderSchedule) Validate() error {
+	if s.DialID == 0 {
+		return Errorf(EINVALID, "Dial required.")
+	} else if len(s.Weekdays) == 0 {
+		return Errorf(EINVALID, "At least one check-in day required.")
+	} else if s.Hour < 0 || s.Hour > 23 || s.Minute < 0 || s.Minute > 59 {
+		return Errorf(EINVALID, "Invalid check-in time.")
+	} else if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return Errorf(EINVALID, "Unknown time zone.")
+	} else if !s.NotifyInApp && !s.NotifyEmail && s.WebhookURL == "" {
+		return Errorf(EINVALID, "At least one reminder notification target required.")
+	}
+
+	for _, weekday := range s.Weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return Errorf(EINVALID, "Invalid check-in day.")
+		}
+	}
+
+	if s.WebhookURL != "" {
+		if u, err := url.Parse(s.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Errorf(EINVALID, "Invalid reminder webhook URL.")
+		}
+	}
+	return nil
+}
+
+// Location returns the schedule's time zone. Returns UTC if the time zone is
+// no longer recognized.
+func (s *DialReminderSchedule) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// HasWeekday returns true if the schedule has a check-in on the given day.
+func (s *DialReminderSchedule) HasWeekday(weekday time.Weekday) bool {
+	for _, v := range s.Weekdays {
+		if v == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// PrevCheckIn returns the most recent check-in at or before t. Returns the
+// zero time if the schedule has no check-in days.
+func (s *DialReminderSchedule) PrevCheckIn(t time.Time) time.Time {
+	local := t.In(s.Location())
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, -i)
+		checkIn := time.Date(day.Year(), day.Month(), day.Day(), s.Hour, s.Minute, 0, 0, local.Location())
+		if s.HasWeekday(checkIn.Weekday()) && !checkIn.After(t) {
+			return checkIn
+		}
+	}
+	return time.Time{}
+}
+
+// NextCheckIn returns the first check-in after t. Returns the zero time if the
+// schedule has no check-in days.
+func (s *DialReminderSchedule) NextCheckIn(t time.Time) time.Time {
+	local := t.In(s.Location())
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		checkIn := time.Date(day.Year(), day.Month(), day.Day(), s.Hour, s.Minute, 0, 0, local.Location())
+		if s.HasWeekday(checkIn.Weekday()) && checkIn.After(t) {
+			return checkIn
+		}
+	}
+	return time.Time{}
+}
+
+// Description returns a human-readable description of the schedule, such as
+// "Weekdays at 10:00 America/Denver".
+func (s *DialReminderSchedule) Description() string {
+	return fmt.Sprintf("%s at %02d:%02d %s", FormatWeekdays(s.Weekdays), s.Hour, s.Minute, s.Location())
+}
+
+// FormatWeekdays returns a short description of a set of days, such as
+// "Weekdays", "Every day" or "Mon, Wed, Fri".
+func FormatWeekdays(weekdays []time.Weekday) string {
+	a := make([]time.Weekday, len(weekdays))
+	copy(a, weekdays)
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+
+	switch fmt.Sprint(a) {
+	case fmt.Sprint([]time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}):
+		return "Weekdays"
+	case fmt.Sprint([]time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}):
+		return "Every day"
+	}
+
+	names := make([]string, len(a))
+	for i, weekday := range a {
+		names[i] = weekday.String()[:3]
+	}
+	return strings.Join(names, ", ")
+}
+
+// DialReminder represents a single reminder sent to a member at a check-in.
+// Reminders make up a schedule's history & track delivery to external targets.
+type DialReminder struct {
+	ID int `json:"id"`
+
+	// Schedule that sent the reminder & the dial it is attached to.
+	ScheduleID int                   `json:"scheduleID"`
+	Schedule   *DialReminderSchedule `json:"schedule"`
+	DialID     int                   `json:"dialID"`
+	Dial       *Dial                 `json:"dial"`
+
+	// Member who was reminded.
+	UserID int   `json:"userID"`
+	User   *User `json:"user"`
+
+	// Check-in that the member was reminded for.
+	CheckInAt time.Time `json:"checkInAt"`
+
+	// Delivery status & any errors from webhook or email targets.
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// Timestamp of when the reminder was created.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DialReminderService represents a service for managing dial check-in schedules.
+type DialReminderService interface {
+	// Retrieves a single schedule by ID. Returns ENOTFOUND if the schedule
+	// does not exist or the user is not a member of the schedule's dial.
+	FindDialReminderScheduleByID(ctx context.Context, id int) (*DialReminderSchedule, error)
+
+	// Retrieves a list of schedules based on a filter. Only returns schedules
+	// for dials that the user is a member of. Also returns a count of total
+	// matching schedules which may differ if filter.Limit is set.
+	FindDialReminderSchedules(ctx context.Context, filter DialReminderScheduleFilter) ([]*DialReminderSchedule, int, error)
+
+	// Creates a new schedule on a dial. Only the dial owner can create
+	// schedules. Reminders start with the first check-in after creation.
+	CreateDialReminderSchedule(ctx context.Context, schedule *DialReminderSchedule) error
+
+	// Updates an existing schedule. Only the dial owner can update schedules.
+	UpdateDialReminderSchedule(ctx context.Context, id int, upd DialReminderScheduleUpdate) (*DialReminderSchedule, error)
+
+	// Permanently deletes a schedule & its reminder history.
+	// Only the dial owner can delete schedules.
+	DeleteDialReminderSchedule(ctx context.Context, id int) error
+
+	// Retrieves a list of reminders based on a filter. Only returns the
+	// user's own reminders & reminders on dials the user owns. Reminders are
+	// returned newest first. Also returns a count of total matching
+	// reminders which may differ if filter.Limit is set.
+	FindDialReminders(ctx context.Context, filter DialReminderFilter) ([]*DialReminder, int, error)
+}
+
+// DialReminderScheduleFilter represents a filter used by FindDialReminderSchedules().
+type DialReminderScheduleFilter struct {
+	ID     *int `json:"id"`
+	DialID *int `json:"dialID"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialReminderScheduleUpdate represents a set of fields to update on a schedule.
+type DialReminderScheduleUpdate struct {
+	Weekdays    *[]time.Weekday `json:"weekdays"`
+	Hour        *int            `json:"hour"`
+	Minute      *int            `json:"minute"`
+	Timezone    *string         `json:"timezone"`
+	NotifyInApp *bool           `json:"notifyInApp"`
+	NotifyEmail *bool           `json:"notifyEmail"`
+	WebhookURL  *string         `json:"webhookURL"`
+}
+
+// DialReminderFilter represents a filter used by FindDialReminders().
+type DialReminderFilter struct {
+	ID         *int    `json:"id"`
+	ScheduleID *int    `json:"scheduleID"`
+	DialID     *int    `json:"dialID"`
+	UserID     *int    `json:"userID"`
+	Status     *string `json:"status"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialReminderNotifier delivers a check-in reminder to an external target such
+// as a webhook or the member's email address. Notifiers are called by a
+// background job outside of any database transaction so they may block on the
+// network.
+type DialReminderNotifier interface {
+	NotifyDialReminder(ctx context.Context, reminder *DialReminder) error
+}
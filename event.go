@@ -2,12 +2,24 @@ package wtf
 
 import (
 	"context"
+	"time"
 )
 
 // Event type constants.
 const (
 	EventTypeDialValueChanged           = "dial:value_changed"
+	EventTypeDialAnomalyDetected        = "dial:anomaly_detected"
+	EventTypeDialAlertFired             = "dial:alert_fired"
+	EventTypeDialGoalBudgetExhausted    = "dial:goal_budget_exhausted"
+	EventTypeDialCheckInReminder        = "dial:checkin_reminder"
+	EventTypeDialReset                  = "dial:reset"
+	EventTypeDialDimensionValueChanged  = "dial_dimension:value_changed"
+	EventTypeDialChildValueChanged      = "dial_child:value_changed"
 	EventTypeDialMembershipValueChanged = "dial_membership:value_changed"
+	EventTypeDialMembershipPending      = "dial_membership:pending"
+	EventTypeDialMembershipApproved     = "dial_membership:approved"
+	EventTypeDialMembershipAwayChanged  = "dial_membership:away_changed"
+	EventTypeInvitationCreated          = "invitation:created"
 )
 
 // Event represents an event that occurs in the system. Currently there are only
@@ -29,11 +41,124 @@ type DialValueChangedPayload struct {
 	Value int `json:"value"`
 }
 
+// DialAnomalyDetectedPayload represents the payload for an Event object with a
+// type of EventTypeDialAnomalyDetected. It is sent to all active dial members.
+type DialAnomalyDetectedPayload struct {
+	ID       int     `json:"id"`
+	DialID   int     `json:"dialID"`
+	DialName string  `json:"dialName"`
+	Kind     string  `json:"kind"`
+	Value    float64 `json:"value"`
+	Baseline float64 `json:"baseline"`
+	ZScore   float64 `json:"zScore"`
+}
+
+// DialAlertFiredPayload represents the payload for an Event object with a type
+// of EventTypeDialAlertFired. It is sent to all active dial members when a
+// rule with an in-app notification target fires.
+type DialAlertFiredPayload struct {
+	ID       int    `json:"id"`
+	RuleID   int    `json:"ruleID"`
+	RuleName string `json:"ruleName"`
+	DialID   int    `json:"dialID"`
+	DialName string `json:"dialName"`
+	Value    int    `json:"value"`
+}
+
+// DialGoalBudgetExhaustedPayload represents the payload for an Event object
+// with a type of EventTypeDialGoalBudgetExhausted. It is sent to all active
+// dial members the first time a goal's error budget is exhausted in a window.
+type DialGoalBudgetExhaustedPayload struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	DialID      int       `json:"dialID"`
+	DialName    string    `json:"dialName"`
+	WindowStart time.Time `json:"windowStart"`
+}
+
+// DialCheckInReminderPayload represents the payload for an Event object with a
+// type of EventTypeDialCheckInReminder. It is sent to each member who has not
+// updated their value since the dial's previous check-in.
+type DialCheckInReminderPayload struct {
+	ID         int       `json:"id"`
+	ScheduleID int       `json:"scheduleID"`
+	DialID     int       `json:"dialID"`
+	DialName   string    `json:"dialName"`
+	CheckInAt  time.Time `json:"checkInAt"`
+}
+
+// DialResetPayload represents the payload for an Event object with a type of
+// EventTypeDialReset. It is sent to all active dial members after a scheduled
+// reset of member values.
+type DialResetPayload struct {
+	ID       int    `json:"id"`
+	DialID   int    `json:"dialID"`
+	DialName string `json:"dialName"`
+	Value    int    `json:"value"`
+}
+
+// DialDimensionValueChangedPayload represents the payload for an Event object
+// with a type of EventTypeDialDimensionValueChanged.
+type DialDimensionValueChangedPayload struct {
+	ID     int `json:"id"`
+	DialID int `json:"dialID"`
+	Value  int `json:"value"`
+}
+
+// DialChildValueChangedPayload represents the payload for an Event object
+// with a type of EventTypeDialChildValueChanged. It is sent to all active
+// members of the parent dial when the child dial's value changes.
+type DialChildValueChangedPayload struct {
+	ID           int `json:"id"`
+	ParentDialID int `json:"parentDialID"`
+	ChildDialID  int `json:"childDialID"`
+	Value        int `json:"value"`
+}
+
 // DialMembershipValueChangedPayload represents the payload for an Event object
-// with a type of EventTypeDialMembershipValueChanged.
+// with a type of EventTypeDialMembershipValueChanged. Dimensions is only set
+// if the dial has dimensions.
 type DialMembershipValueChangedPayload struct {
-	ID    int `json:"id"`
-	Value int `json:"value"`
+	ID         int                        `json:"id"`
+	Value      int                        `json:"value"`
+	Note       string                     `json:"note"`
+	Dimensions []*DialMembershipDimension `json:"dimensions,omitempty"`
+}
+
+// DialMembershipPendingPayload represents the payload for an Event object with
+// a type of EventTypeDialMembershipPending. It is sent to the dial owner.
+type DialMembershipPendingPayload struct {
+	ID       int    `json:"id"`
+	DialID   int    `json:"dialID"`
+	DialName string `json:"dialName"`
+	UserName string `json:"userName"`
+}
+
+// DialMembershipApprovedPayload represents the payload for an Event object with
+// a type of EventTypeDialMembershipApproved. It is sent to the approved member.
+type DialMembershipApprovedPayload struct {
+	ID       int    `json:"id"`
+	DialID   int    `json:"dialID"`
+	DialName string `json:"dialName"`
+}
+
+// DialMembershipAwayChangedPayload represents the payload for an Event object
+// with a type of EventTypeDialMembershipAwayChanged. It is sent to all active
+// dial members when a member goes away or returns. AwayUntil is zero when the
+// member has returned.
+type DialMembershipAwayChangedPayload struct {
+	ID        int       `json:"id"`
+	DialID    int       `json:"dialID"`
+	AwayUntil time.Time `json:"awayUntil"`
+}
+
+// InvitationCreatedPayload represents the payload for an Event object with a
+// type of EventTypeInvitationCreated. It is sent to the invitee.
+type InvitationCreatedPayload struct {
+	ID          int    `json:"id"`
+	DialID      int    `json:"dialID"`
+	DialName    string `json:"dialName"`
+	InviterName string `json:"inviterName"`
 }
 
 // EventService represents a service for managing event dispatch and event
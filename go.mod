@@ -13,7 +13,7 @@ require (
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/securecookie v1.1.1
 	github.com/gorilla/websocket v1.4.2
-	github.com/hallgren/eventsourcing v0.0.13 // indirect
+	github.com/hallgren/eventsourcing v0.0.13
 	github.com/mattn/go-sqlite3 v1.14.4
 	github.com/pelletier/go-toml v1.8.1
 	github.com/prometheus/client_golang v1.9.0
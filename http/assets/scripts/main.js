@@ -14,14 +14,86 @@ function connect() {
 			}
 			break;
 
+		case "dial:anomaly_detected":
+			if (window.ondialanomalydetected !== undefined) {
+				window.ondialanomalydetected(e.payload)
+			}
+			break;
+
+		case "dial:reset":
+			showNotification('Values on ' + e.payload.dialName + ' were reset to ' + e.payload.value + '.', '/dials/' + e.payload.dialID)
+			if (window.ondialreset !== undefined) {
+				window.ondialreset(e.payload)
+			}
+			break;
+
+		case "dial:checkin_reminder":
+			showNotification('Time to check in on ' + e.payload.dialName + '.', '/dials/' + e.payload.dialID)
+			break;
+
+		case "dial:alert_fired":
+			showNotification('Alert "' + e.payload.ruleName + '" fired on ' + e.payload.dialName + ' at a value of ' + e.payload.value + '.', '/dials/' + e.payload.dialID)
+			if (window.ondialalertfired !== undefined) {
+				window.ondialalertfired(e.payload)
+			}
+			break;
+
+		case "dial:goal_budget_exhausted":
+			showNotification('The error budget for goal "' + e.payload.name + '" on ' + e.payload.dialName + ' is exhausted.', '/dials/' + e.payload.dialID)
+			break;
+
+		case "dial_dimension:value_changed":
+			document.querySelectorAll('.wtf-value[data-dial-dimension-id="'+e.payload.id+'"]:not([data-dial-membership-id])').forEach(
+				(node) => updateWTFValueNode(node, e.payload.value)
+			)
+			break;
+
+		case "dial_child:value_changed":
+			document.querySelectorAll('.wtf-value[data-dial-child-id="'+e.payload.id+'"]').forEach(
+				(node) => updateWTFValueNode(node, e.payload.value)
+			)
+			break;
+
 		case "dial_membership:value_changed":
-			document.querySelectorAll('.wtf-value[data-dial-membership-id="'+e.payload.id+'"]').forEach(
+			document.querySelectorAll('.wtf-value[data-dial-membership-id="'+e.payload.id+'"]:not([data-dial-dimension-id])').forEach(
 				(node) => updateWTFValueNode(node, e.payload.value)
 			)
+			;(e.payload.dimensions || []).forEach((dim) => {
+				document.querySelectorAll('.wtf-value[data-dial-membership-id="'+e.payload.id+'"][data-dial-dimension-id="'+dim.dimensionID+'"]').forEach(
+					(node) => updateWTFValueNode(node, dim.value)
+				)
+			})
+			document.querySelectorAll('.wtf-note[data-dial-membership-id="'+e.payload.id+'"]').forEach(
+				(node) => node.innerText = e.payload.note
+			)
 			if (window.ondialmembershipvaluechanged !== undefined) {
 				window.ondialmembershipvaluechanged(e.payload)
 			}
 			break;
+
+		case "dial_membership:pending":
+			if (window.ondialmembershippending !== undefined) {
+				window.ondialmembershippending(e.payload)
+			}
+			break;
+
+		case "dial_membership:approved":
+			if (window.ondialmembershipapproved !== undefined) {
+				window.ondialmembershipapproved(e.payload)
+			}
+			break;
+
+		case "dial_membership:away_changed":
+			if (window.ondialmembershipawaychanged !== undefined) {
+				window.ondialmembershipawaychanged(e.payload)
+			}
+			break;
+
+		case "invitation:created":
+			if (window.oninvitationcreated !== undefined) {
+				window.oninvitationcreated(e.payload)
+			}
+			break;
 		}
 	});
 }
@@ -34,16 +106,62 @@ function updateWTFValueNode(node, value) {
 	if (node.classList.contains('wtf-badge')) {
 		// Remove old color.
 		node.classList.remove("badge-soft-success", "badge-soft-info", "badge-soft-warning", "badge-soft-danger")
+		node.style.backgroundColor = ''
+		node.style.color = ''
+		node.removeAttribute('title')
 
-		// Set new color based on value.
-		if (value < 25) {
+		// Set new color based on the value's position on the dial's scale.
+		const scale = node.dataset.scale ? JSON.parse(node.dataset.scale) : {min: 0, max: 100, bands: []}
+		const f = (value - scale.min) / (scale.max - scale.min)
+		if (f < 0.25) {
 			node.classList.add("badge-soft-success")
-		} else if (value < 50) {
+		} else if (f < 0.5) {
 			node.classList.add("badge-soft-info")
-		} else if (value < 75) {
+		} else if (f < 0.75) {
 			node.classList.add("badge-soft-warning")
 		} else {
 			node.classList.add("badge-soft-danger")
 		}
+
+		// Use the color of the scale band containing the value, if any.
+		const band = dialScaleBand(scale, value)
+		if (band !== null) {
+			node.style.backgroundColor = band.color
+			node.style.color = '#fff'
+			node.title = band.label
+		}
 	}
+}
+
+// Returns the band of a dial scale containing value or null if there is none.
+function dialScaleBand(scale, value) {
+	return (scale.bands || []).find((band) => value >= band.min && value <= band.max) || null
+}
+
+// Displays a dismissable notification at the top of the page.
+function showNotification(text, href) {
+	const container = document.querySelector('[data-layout="container"]')
+	if (container === null) {
+		return
+	}
+
+	const node = document.createElement('div')
+	node.className = 'alert alert-warning alert-dismissible fade show mt-3'
+	node.setAttribute('role', 'alert')
+
+	const link = document.createElement('a')
+	link.className = 'alert-link'
+	link.href = href
+	link.innerText = text
+	node.appendChild(link)
+
+	const button = document.createElement('button')
+	button.className = 'btn-close'
+	button.type = 'button'
+	button.setAttribute('aria-label', 'Close')
+	button.addEventListener('click', () => node.remove())
+	node.appendChild(button)
+
+	const nav = container.querySelector('nav')
+	container.insertBefore(node, nav !== null ? nav.nextSibling : container.firstChild)
 }
\ No newline at end of file
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// registerAuditRoutes is a helper function for registering audit log routes.
+func (s *Server) registerAuditRoutes(r *mux.Router) {
+	// View & export the audit log for a single dial.
+	r.HandleFunc("/dials/{id}/audit", s.handleDialAuditIndex).Methods("GET")
+}
+
+// handleDialAuditIndex handles the "GET /dials/:id/audit" route. This route
+// is only available to the dial owner and lists all changes made to the dial.
+//
+// The endpoint works with HTML, JSON, & CSV formats. The CSV export includes
+// the full log while the other formats are paginated.
+func (s *Server) handleDialAuditIndex(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse optional filter object.
+	var filter wtf.AuditEntryFilter
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil && err != io.EOF {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+		if r.Header.Get("Accept") != "text/csv" {
+			filter.Limit = 20
+		}
+	}
+	filter.DialID = &id
+
+	// Only the dial owner may view the full log.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if !wtf.CanEditDial(r.Context(), dial) {
+		Error(w, r, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can view the audit log."))
+		return
+	}
+
+	// Fetch audit entries from the database.
+	entries, n, err := s.AuditService.FindAuditEntries(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(findAuditEntriesResponse{
+			AuditEntries: entries,
+			N:            n,
+		}); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewAuditEntryEncoder(w)
+		for _, entry := range entries {
+			if err := enc.EncodeAuditEntry(entry); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		tmpl := html.DialAuditTemplate{Dial: dial, Entries: entries, N: n, Filter: filter, URL: *r.URL}
+		tmpl.Render(r.Context(), w)
+	}
+}
+
+// findAuditEntriesResponse represents the output JSON struct for "GET /dials/:id/audit".
+type findAuditEntriesResponse struct {
+	AuditEntries []*wtf.AuditEntry `json:"auditEntries"`
+	N            int               `json:"n"`
+}
+
+// AuditService implements the wtf.AuditService over the HTTP protocol.
+type AuditService struct {
+	Client *Client
+}
+
+// NewAuditService returns a new instance of AuditService.
+func NewAuditService(client *Client) *AuditService {
+	return &AuditService{Client: client}
+}
+
+// FindAuditEntries retrieves the audit log for a dial. The filter must
+// specify a DialID as entries are listed per-dial over HTTP.
+func (s *AuditService) FindAuditEntries(ctx context.Context, filter wtf.AuditEntryFilter) ([]*wtf.AuditEntry, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Marshal filter into JSON format.
+	body, err := json.Marshal(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/audit", *filter.DialID), bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of entries & total count.
+	var jsonResponse findAuditEntriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.AuditEntries, jsonResponse.N, nil
+}
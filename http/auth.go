@@ -130,10 +130,12 @@ func (s *Server) handleOAuthGitHubCallback(w http.ResponseWriter, r *http.Reques
 		email = *u.Email
 	}
 
-	// Create an authentication object with an associated user.
+	// Create an authentication object with an associated user. The login is
+	// stored so other users can invite this user by their GitHub username.
 	auth := &wtf.Auth{
 		Source:       wtf.AuthSourceGitHub,
 		SourceID:     strconv.FormatInt(*u.ID, 10),
+		SourceLogin:  u.GetLogin(),
 		AccessToken:  tok.AccessToken,
 		RefreshToken: tok.RefreshToken,
 		User: &wtf.User{
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/wtf"
@@ -27,6 +29,9 @@ func (s *Server) registerDialRoutes(r *mux.Router) {
 	r.HandleFunc("/dials/new", s.handleDialNew).Methods("GET")
 	r.HandleFunc("/dials/new", s.handleDialCreate).Methods("POST")
 
+	// Weekly value heatmap across all of the user's dials.
+	r.HandleFunc("/dials/heatmap", s.handleDialHeatmap).Methods("GET")
+
 	// View a single dial.
 	r.HandleFunc("/dials/{id}", s.handleDialView).Methods("GET")
 
@@ -34,11 +39,24 @@ func (s *Server) registerDialRoutes(r *mux.Router) {
 	r.HandleFunc("/dials/{id}/edit", s.handleDialEdit).Methods("GET")
 	r.HandleFunc("/dials/{id}/edit", s.handleDialUpdate).Methods("PATCH")
 
-	// Removing a dial.
+	// Creating a new dial from the settings of an existing dial.
+	r.HandleFunc("/dials/{id}/clone", s.handleDialClone).Methods("POST")
+
+	// Archiving & restoring a dial.
+	r.HandleFunc("/dials/{id}/archive", s.handleDialArchive).Methods("POST")
+	r.HandleFunc("/dials/{id}/restore", s.handleDialRestore).Methods("POST")
+
+	// Permanently removing an archived dial.
 	r.HandleFunc("/dials/{id}", s.handleDialDelete).Methods("DELETE")
 
 	// Updating the value for the user's membership.
 	r.HandleFunc("/dials/{id}/membership", s.handleDialSetMembershipValue).Methods("PUT")
+
+	// Value history report for a single dial.
+	r.HandleFunc("/dials/{id}/report", s.handleDialReport).Methods("GET")
+
+	// Weekly value heatmap for a single dial.
+	r.HandleFunc("/dials/{id}/heatmap", s.handleDialHeatmap).Methods("GET")
 }
 
 // handleDialIndex handles the "GET /dials" route. This route can optionally
@@ -57,6 +75,7 @@ func (s *Server) handleDialIndex(w http.ResponseWriter, r *http.Request) {
 		}
 	default:
 		filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+		filter.IncludeArchived = r.URL.Query().Get("archived") == "true"
 		filter.Limit = 20
 	}
 
@@ -114,8 +133,14 @@ func (s *Server) handleDialView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch dial from the database.
+	// Fetch dial from the database. Users waiting for approval cannot view the
+	// dial yet so show them the waiting page instead.
 	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if wtf.ErrorCode(err) == wtf.ENOTFOUND && r.Header.Get("Accept") != "application/json" {
+		if s.renderDialMembershipPending(w, r, id) {
+			return
+		}
+	}
 	if err != nil {
 		Error(w, r, err)
 		return
@@ -142,10 +167,356 @@ func (s *Server) handleDialView(w http.ResponseWriter, r *http.Request) {
 			Dial:      dial,
 			InviteURL: fmt.Sprintf("%s/invite/%s", s.URL(), dial.InviteCode),
 		}
+
+		// Fetch the default history range for the dial's chart.
+		reportStart, reportEnd, reportInterval, err := parseDialReportRange(url.Values{}, time.Now())
+		if err != nil {
+			Error(w, r, err)
+			return
+		} else if tmpl.Report, err = s.DialService.DialValueReport(r.Context(), dial.ID, reportStart, reportEnd, reportInterval); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch the default heatmap range in the user's time zone.
+		heatmapStart, heatmapEnd, heatmapLoc, err := parseDialHeatmapRange(r.Context(), url.Values{}, time.Now())
+		if err != nil {
+			Error(w, r, err)
+			return
+		} else if tmpl.Heatmap, err = s.DialService.DialValueHeatmap(r.Context(), dial.ID, heatmapStart, heatmapEnd, heatmapLoc); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch the most recent anomalies detected on the dial.
+		if tmpl.Anomalies, _, err = s.DialAnomalyService.FindDialAnomalies(r.Context(), wtf.DialAnomalyFilter{DialID: &dial.ID, Limit: 5}); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch the dial's alert rules & their most recent firings.
+		if tmpl.AlertRules, _, err = s.DialAlertService.FindDialAlertRules(r.Context(), wtf.DialAlertRuleFilter{DialID: &dial.ID}); err != nil {
+			Error(w, r, err)
+			return
+		} else if tmpl.AlertFirings, _, err = s.DialAlertService.FindDialAlertFirings(r.Context(), wtf.DialAlertFiringFilter{DialID: &dial.ID, Limit: 10}); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch the dial's goals along with their status in the current window.
+		if tmpl.Goals, _, err = s.DialGoalService.FindDialGoals(r.Context(), wtf.DialGoalFilter{DialID: &dial.ID}); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch the dial's check-in schedules & recent reminders. Members only
+		// see their own reminders while the owner sees everyone's.
+		if tmpl.ReminderSchedules, _, err = s.DialReminderService.FindDialReminderSchedules(r.Context(), wtf.DialReminderScheduleFilter{DialID: &dial.ID}); err != nil {
+			Error(w, r, err)
+			return
+		} else if tmpl.Reminders, _, err = s.DialReminderService.FindDialReminders(r.Context(), wtf.DialReminderFilter{DialID: &dial.ID, Limit: 10}); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch the dial's children & the parent it rolls up into, if any.
+		if tmpl.Children, _, err = s.DialChildService.FindDialChildren(r.Context(), wtf.DialChildFilter{ParentDialID: &dial.ID}); err != nil {
+			Error(w, r, err)
+			return
+		} else if parents, _, err := s.DialChildService.FindDialChildren(r.Context(), wtf.DialChildFilter{ChildDialID: &dial.ID}); err != nil {
+			Error(w, r, err)
+			return
+		} else if len(parents) > 0 {
+			tmpl.Parent = parents[0]
+		}
+
+		// Fetch the other dials the user owns so they can attach one as a
+		// child. Only the owner of this dial can attach children to it.
+		if wtf.CanEditDial(r.Context(), dial) {
+			dials, _, err := s.DialService.FindDials(r.Context(), wtf.DialFilter{})
+			if err != nil {
+				Error(w, r, err)
+				return
+			}
+			for _, other := range dials {
+				if other.ID != dial.ID && wtf.CanEditDial(r.Context(), other) {
+					tmpl.OwnedDials = append(tmpl.OwnedDials, other)
+				}
+			}
+		}
+
+		// Fetch the last week of history for each member's sparkline. The end
+		// is rounded up so the current slot includes the latest values.
+		const sparklineInterval = 6 * time.Hour
+		end := time.Now().Truncate(sparklineInterval).Add(sparklineInterval)
+		if tmpl.MemberReports, err = s.DialMembershipService.MembershipValueReports(r.Context(), dial.ID, end.Add(-7*24*time.Hour), end, sparklineInterval); err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Fetch outstanding invitations & the ban list for the dial owner.
+		if wtf.CanEditDial(r.Context(), dial) {
+			if tmpl.Bans, _, err = s.DialBanService.FindDialBans(r.Context(), wtf.DialBanFilter{DialID: &dial.ID}); err != nil {
+				Error(w, r, err)
+				return
+			}
+
+			status, expired := wtf.InvitationStatusPending, false
+			if tmpl.Invitations, _, err = s.InvitationService.FindInvitations(r.Context(), wtf.InvitationFilter{
+				DialID:  &dial.ID,
+				Status:  &status,
+				Expired: &expired,
+			}); err != nil {
+				Error(w, r, err)
+				return
+			}
+		}
+
 		tmpl.Render(r.Context(), w)
 	}
 }
 
+// handleDialReport handles the "GET /dials/:id/report" route. It returns the
+// dial's value history with the min, max & average value for each slot.
+//
+// The range is set with the "range" query parameter to one of the presets
+// ("1h", "24h", "7d", "30d") or to "custom". Custom ranges require "start" &
+// "end" in RFC 3339 format and accept an optional "interval" duration. By
+// default the report covers the last 24 hours.
+//
+// The endpoint works with JSON & CSV formats.
+func (s *Server) handleDialReport(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse report range from the query parameters.
+	start, end, interval, err := parseDialReportRange(r.URL.Query(), time.Now())
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Parse the dimension to report on, if any.
+	var dimensionID int
+	if v := r.URL.Query().Get("dimension"); v != "" {
+		if dimensionID, err = strconv.Atoi(v); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid dimension ID format"))
+			return
+		}
+	}
+
+	// Generate the report for the dial or one of its dimensions.
+	var report *wtf.DialValueReport
+	if dimensionID != 0 {
+		report, err = s.DialService.DialDimensionValueReport(r.Context(), id, dimensionID, start, end, interval)
+	} else {
+		report, err = s.DialService.DialValueReport(r.Context(), id, start, end, interval)
+	}
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header. Defaults to JSON.
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewDialValueRecordEncoder(w)
+		for _, record := range report.Records {
+			if err := enc.EncodeDialValueRecord(record); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			LogError(r, err)
+			return
+		}
+	}
+}
+
+// dialReportRanges maps each report range preset to its duration & the size
+// of each slot within it.
+var dialReportRanges = map[string]struct {
+	Duration time.Duration
+	Interval time.Duration
+}{
+	"1h":  {time.Hour, time.Minute},
+	"24h": {24 * time.Hour, 15 * time.Minute},
+	"7d":  {7 * 24 * time.Hour, time.Hour},
+	"30d": {30 * 24 * time.Hour, 6 * time.Hour},
+}
+
+// defaultDialReportRange is the range preset used when none is specified.
+const defaultDialReportRange = "24h"
+
+// dialReportCustomSlotN is the approximate number of slots used for a custom
+// range when no interval is specified.
+const dialReportCustomSlotN = 120
+
+// parseDialReportRange returns the start, end & interval of a report from its
+// query parameters. Preset ranges end at the slot containing now so the most
+// recent values are included.
+func parseDialReportRange(q url.Values, now time.Time) (start, end time.Time, interval time.Duration, err error) {
+	name := q.Get("range")
+	if name == "" {
+		name = defaultDialReportRange
+	}
+
+	// Use the preset's duration & interval, if one matches.
+	if name != "custom" {
+		preset, ok := dialReportRanges[name]
+		if !ok {
+			return start, end, 0, wtf.Errorf(wtf.EINVALID, "Invalid report range.")
+		}
+		end = now.Truncate(preset.Interval).Add(preset.Interval)
+		return end.Add(-preset.Duration), end, preset.Interval, nil
+	}
+
+	// Custom ranges must specify both start & end times.
+	if start, err = time.Parse(time.RFC3339, q.Get("start")); err != nil {
+		return start, end, 0, wtf.Errorf(wtf.EINVALID, "Invalid start time format")
+	} else if end, err = time.Parse(time.RFC3339, q.Get("end")); err != nil {
+		return start, end, 0, wtf.Errorf(wtf.EINVALID, "Invalid end time format")
+	}
+
+	// Use the given interval or split the range into a fixed number of slots.
+	// Either way, the range is rejected if it would produce too many slots.
+	if v := q.Get("interval"); v != "" {
+		if interval, err = time.ParseDuration(v); err != nil {
+			return start, end, 0, wtf.Errorf(wtf.EINVALID, "Invalid interval format")
+		}
+	} else if interval = (end.Sub(start) / dialReportCustomSlotN).Truncate(time.Minute); interval < time.Minute {
+		interval = time.Minute
+	}
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return start, end, 0, err
+	}
+	return start, end, interval, nil
+}
+
+// handleDialHeatmap handles the "GET /dials/:id/heatmap" & "GET /dials/heatmap"
+// routes. It returns the average value for each hour of each day of the week
+// for a single dial or across all of the user's dials.
+//
+// The range is set with the "range" query parameter to one of the presets
+// ("30d", "90d", "365d") or to "custom" with "start" & "end" in RFC 3339
+// format. By default the heatmap covers the last 90 days. Hours are grouped by
+// the user's time zone unless a "tz" parameter is passed.
+//
+// The endpoint works with JSON & CSV formats.
+func (s *Server) handleDialHeatmap(w http.ResponseWriter, r *http.Request) {
+	// Parse heatmap range & time zone from the query parameters.
+	start, end, loc, err := parseDialHeatmapRange(r.Context(), r.URL.Query(), time.Now())
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Generate the heatmap for a single dial if an ID is in the path.
+	// Otherwise average across all the user's dials.
+	var heatmap *wtf.DialValueHeatmap
+	if v, ok := mux.Vars(r)["id"]; ok {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+			return
+		} else if heatmap, err = s.DialService.DialValueHeatmap(r.Context(), id, start, end, loc); err != nil {
+			Error(w, r, err)
+			return
+		}
+	} else if heatmap, err = s.DialService.AverageDialValueHeatmap(r.Context(), start, end, loc); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header. Defaults to JSON.
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewDialValueHeatmapCellEncoder(w)
+		for _, cell := range heatmap.Cells {
+			if err := enc.EncodeDialValueHeatmapCell(cell); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(heatmap); err != nil {
+			LogError(r, err)
+			return
+		}
+	}
+}
+
+// dialHeatmapRanges maps each heatmap range preset to its duration.
+var dialHeatmapRanges = map[string]time.Duration{
+	"30d":  30 * 24 * time.Hour,
+	"90d":  90 * 24 * time.Hour,
+	"365d": 365 * 24 * time.Hour,
+}
+
+// defaultDialHeatmapRange is the range preset used when none is specified.
+const defaultDialHeatmapRange = "90d"
+
+// parseDialHeatmapRange returns the start, end & time zone of a heatmap from
+// its query parameters. Preset ranges end at the last complete hour. The time
+// zone defaults to the current user's time zone.
+func parseDialHeatmapRange(ctx context.Context, q url.Values, now time.Time) (start, end time.Time, loc *time.Location, err error) {
+	// Use the requested time zone or fall back to the user's.
+	if v := q.Get("tz"); v != "" {
+		if loc, err = time.LoadLocation(v); err != nil {
+			return start, end, nil, wtf.Errorf(wtf.EINVALID, "Unknown time zone.")
+		}
+	} else if user := wtf.UserFromContext(ctx); user != nil {
+		loc = user.Location()
+	} else {
+		loc = time.UTC
+	}
+
+	name := q.Get("range")
+	if name == "" {
+		name = defaultDialHeatmapRange
+	}
+
+	// Use the preset's duration, if one matches.
+	if name != "custom" {
+		d, ok := dialHeatmapRanges[name]
+		if !ok {
+			return start, end, nil, wtf.Errorf(wtf.EINVALID, "Invalid heatmap range.")
+		}
+		end = now.Truncate(time.Hour)
+		return end.Add(-d), end, loc, nil
+	}
+
+	// Custom ranges must specify both start & end times.
+	if start, err = time.Parse(time.RFC3339, q.Get("start")); err != nil {
+		return start, end, nil, wtf.Errorf(wtf.EINVALID, "Invalid start time format")
+	} else if end, err = time.Parse(time.RFC3339, q.Get("end")); err != nil {
+		return start, end, nil, wtf.Errorf(wtf.EINVALID, "Invalid end time format")
+	} else if err := wtf.ValidateDialValueHeatmapRange(start, end); err != nil {
+		return start, end, nil, err
+	}
+	return start, end, loc, nil
+}
+
 // handleDialNew handles the "GET /dials/new" route.
 // It renders an HTML form for editing a new dial.
 func (s *Server) handleDialNew(w http.ResponseWriter, r *http.Request) {
@@ -166,6 +537,62 @@ func (s *Server) handleDialCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	default:
 		dial.Name = r.PostFormValue("name")
+		dial.RequireApproval = r.PostFormValue("require_approval") == "true"
+		spikeThreshold, sustainedThreshold, err := parseDialAnomalyThresholds(r)
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+		if spikeThreshold != nil {
+			dial.AnomalySpikeThreshold = *spikeThreshold
+		}
+		if sustainedThreshold != nil {
+			dial.AnomalySustainedThreshold = *sustainedThreshold
+		}
+		var upd wtf.DialUpdate
+		if err := parseDialStalePolicy(r, &upd); err != nil {
+			Error(w, r, err)
+			return
+		}
+		dial.StalePolicy = *upd.StalePolicy
+		if upd.StaleAfterDays != nil {
+			dial.StaleAfterDays = *upd.StaleAfterDays
+		}
+		if upd.StaleBaseline != nil {
+			dial.StaleBaseline = *upd.StaleBaseline
+		}
+		if upd.StaleHalfLifeDays != nil {
+			dial.StaleHalfLifeDays = *upd.StaleHalfLifeDays
+		}
+		if err := parseDialResetPolicy(r, &upd); err != nil {
+			Error(w, r, err)
+			return
+		}
+		if upd.ResetIntervalDays != nil {
+			dial.ResetIntervalDays = *upd.ResetIntervalDays
+		}
+		if upd.ResetTimezone != nil {
+			dial.ResetTimezone = *upd.ResetTimezone
+		}
+		if upd.ResetValue != nil {
+			dial.ResetValue = *upd.ResetValue
+		}
+		if upd.NextResetAt != nil {
+			dial.NextResetAt = *upd.NextResetAt
+		}
+		if err := parseDialScale(r, &upd); err != nil {
+			Error(w, r, err)
+			return
+		}
+		if upd.Scale != nil {
+			dial.Scale = *upd.Scale
+		}
+		if err := parseDialDimensions(r, &upd); err != nil {
+			Error(w, r, err)
+			return
+		}
+		dial.Dimensions = upd.Dimensions
+		dial.Expression = r.PostFormValue("expression")
 	}
 
 	// Create dial in the database.
@@ -242,6 +669,28 @@ func (s *Server) handleDialUpdate(w http.ResponseWriter, r *http.Request) {
 	var upd wtf.DialUpdate
 	name := r.PostFormValue("name")
 	upd.Name = &name
+	requireApproval := r.PostFormValue("require_approval") == "true"
+	upd.RequireApproval = &requireApproval
+	if upd.AnomalySpikeThreshold, upd.AnomalySustainedThreshold, err = parseDialAnomalyThresholds(r); err != nil {
+		Error(w, r, err)
+		return
+	} else if err := parseDialStalePolicy(r, &upd); err != nil {
+		Error(w, r, err)
+		return
+	} else if err := parseDialResetPolicy(r, &upd); err != nil {
+		Error(w, r, err)
+		return
+	} else if err := parseDialScale(r, &upd); err != nil {
+		Error(w, r, err)
+		return
+	} else if err := parseDialDimensions(r, &upd); err != nil {
+		Error(w, r, err)
+		return
+	}
+	if _, ok := r.PostForm["expression"]; ok {
+		expression := r.PostFormValue("expression")
+		upd.Expression = &expression
+	}
 
 	// Update the dial in the database.
 	dial, err := s.DialService.UpdateDial(r.Context(), id, upd)
@@ -260,9 +709,292 @@ func (s *Server) handleDialUpdate(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, fmt.Sprintf("/dials/%d", dial.ID), http.StatusFound)
 }
 
+// parseDialAnomalyThresholds reads the anomaly thresholds from the dial form.
+// Returns nil for blank fields so they are left unchanged.
+func parseDialAnomalyThresholds(r *http.Request) (spike, sustained *float64, err error) {
+	if v := r.PostFormValue("anomaly_spike_threshold"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, nil, wtf.Errorf(wtf.EINVALID, "Invalid spike threshold format")
+		}
+		spike = &f
+	}
+	if v := r.PostFormValue("anomaly_sustained_threshold"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, nil, wtf.Errorf(wtf.EINVALID, "Invalid sustained threshold format")
+		}
+		sustained = &f
+	}
+	return spike, sustained, nil
+}
+
+// parseDialStalePolicy reads the stale membership policy from the dial form
+// into upd. Returns nil for blank period & baseline fields so they are left
+// unchanged.
+func parseDialStalePolicy(r *http.Request, upd *wtf.DialUpdate) error {
+	policy := r.PostFormValue("stale_policy")
+	upd.StalePolicy = &policy
+
+	for _, field := range []struct {
+		name  string
+		label string
+		dst   **int
+	}{
+		{"stale_after_days", "stale after days", &upd.StaleAfterDays},
+		{"stale_baseline", "stale baseline", &upd.StaleBaseline},
+		{"stale_half_life_days", "stale half-life days", &upd.StaleHalfLifeDays},
+	} {
+		if v := r.PostFormValue(field.name); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return wtf.Errorf(wtf.EINVALID, "Invalid %s format", field.label)
+			}
+			*field.dst = &i
+		}
+	}
+	return nil
+}
+
+// parseDialResetPolicy reads the scheduled reset policy from the dial form into
+// upd. The next reset is entered as a local time in the reset time zone.
+// Returns nil for blank fields so they are left unchanged.
+func parseDialResetPolicy(r *http.Request, upd *wtf.DialUpdate) error {
+	if v := r.PostFormValue("reset_interval_days"); v != "" {
+		intervalDays, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid reset interval format")
+		}
+		upd.ResetIntervalDays = &intervalDays
+	}
+
+	timezone := r.PostFormValue("reset_timezone")
+	if timezone != "" {
+		upd.ResetTimezone = &timezone
+	}
+
+	if v := r.PostFormValue("reset_value"); v != "" {
+		value, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid reset value format")
+		}
+		upd.ResetValue = &value
+	}
+
+	if v := r.PostFormValue("next_reset_at"); v != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Unknown reset time zone.")
+		}
+		t, err := time.ParseInLocation("2006-01-02T15:04", v, loc)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid next reset format")
+		}
+		upd.NextResetAt = &t
+	}
+	return nil
+}
+
+// parseDialScale reads the scale & its bands from the dial form into upd. The
+// scale is left unchanged if the form does not include it. Band rows without
+// a label are ignored so the form can include blank rows for new bands.
+func parseDialScale(r *http.Request, upd *wtf.DialUpdate) error {
+	if err := r.ParseForm(); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid form")
+	} else if _, ok := r.PostForm["scale_min"]; !ok {
+		return nil
+	}
+
+	var scale wtf.DialScale
+	for _, field := range []struct {
+		name  string
+		label string
+		dst   *int
+	}{
+		{"scale_min", "scale minimum", &scale.Min},
+		{"scale_max", "scale maximum", &scale.Max},
+		{"scale_step", "scale step", &scale.Step},
+	} {
+		i, err := strconv.Atoi(r.PostFormValue(field.name))
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid %s format", field.label)
+		}
+		*field.dst = i
+	}
+
+	labels, mins, maxs, colors := r.PostForm["band_label"], r.PostForm["band_min"], r.PostForm["band_max"], r.PostForm["band_color"]
+	if len(mins) != len(labels) || len(maxs) != len(labels) || len(colors) != len(labels) {
+		return wtf.Errorf(wtf.EINVALID, "Invalid band format")
+	}
+
+	scale.Bands = []*wtf.DialBand{}
+	for i := range labels {
+		band := &wtf.DialBand{Label: strings.TrimSpace(labels[i]), Color: colors[i]}
+		if band.Label == "" {
+			continue
+		}
+
+		var err error
+		if band.Min, err = strconv.Atoi(mins[i]); err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid minimum format for band %q", band.Label)
+		} else if band.Max, err = strconv.Atoi(maxs[i]); err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid maximum format for band %q", band.Label)
+		}
+		scale.Bands = append(scale.Bands, band)
+	}
+	upd.Scale = &scale
+	return nil
+}
+
+// parseDialDimensions reads the list of dimensions from the dial form into
+// upd. The dimensions are left unchanged if the form does not include them.
+// Rows without a name are ignored so the form can include blank rows for new
+// dimensions and existing dimensions can be removed by clearing their name.
+func parseDialDimensions(r *http.Request, upd *wtf.DialUpdate) error {
+	if err := r.ParseForm(); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid form")
+	} else if _, ok := r.PostForm["dimension_name"]; !ok {
+		return nil
+	}
+
+	ids, names := r.PostForm["dimension_id"], r.PostForm["dimension_name"]
+	if len(ids) != len(names) {
+		return wtf.Errorf(wtf.EINVALID, "Invalid dimension format")
+	}
+
+	upd.Dimensions = []*wtf.DialDimension{}
+	for i := range names {
+		dim := &wtf.DialDimension{Name: strings.TrimSpace(names[i])}
+		if dim.Name == "" {
+			continue
+		}
+
+		if ids[i] != "" {
+			var err error
+			if dim.ID, err = strconv.Atoi(ids[i]); err != nil {
+				return wtf.Errorf(wtf.EINVALID, "Invalid dimension ID format")
+			}
+		}
+		upd.Dimensions = append(upd.Dimensions, dim)
+	}
+	return nil
+}
+
+// handleDialClone handles the "POST /dials/:id/clone" route. This route
+// creates a copy of the dial with a new invite code and redirects to the new
+// dial's page.
+func (s *Server) handleDialClone(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal clone options based on HTTP request's content type.
+	var clone wtf.DialClone
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&clone); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		clone.Name = strings.TrimSpace(r.PostFormValue("name"))
+		clone.IncludeMembers = r.PostFormValue("include_members") == "true"
+	}
+
+	// Create the clone in the database.
+	dial, err := s.DialService.CloneDial(r.Context(), id, clone)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(dial); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, "Dial successfully cloned.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", dial.ID), http.StatusFound)
+	}
+}
+
+// handleDialArchive handles the "POST /dials/:id/archive" route. This route
+// archives the dial so it is hidden from listings & becomes read-only. It then
+// redirects back to the dial listing page.
+func (s *Server) handleDialArchive(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Archive the dial in the database.
+	dial, err := s.DialService.ArchiveDial(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(dial); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, "Dial successfully archived.")
+		http.Redirect(w, r, "/dials", http.StatusFound)
+	}
+}
+
+// handleDialRestore handles the "POST /dials/:id/restore" route. This route
+// restores an archived dial and redirects back to the dial view page.
+func (s *Server) handleDialRestore(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Restore the dial in the database.
+	dial, err := s.DialService.RestoreDial(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(dial); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, "Dial successfully restored.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", dial.ID), http.StatusFound)
+	}
+}
+
 // handleDialDelete handles the "DELETE /dials/:id" route. This route
-// permanently deletes the dial and all its members and redirects to the
-// dial listing page.
+// permanently deletes an archived dial and all its members and redirects to
+// the dial listing page.
 func (s *Server) handleDialDelete(w http.ResponseWriter, r *http.Request) {
 	// Parse dial ID from path.
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
@@ -284,7 +1016,7 @@ func (s *Server) handleDialDelete(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{}`))
 
 	default:
-		SetFlash(w, "Dial successfully deleted.")
+		SetFlash(w, "Dial permanently deleted.")
 		http.Redirect(w, r, "/dials", http.StatusFound)
 	}
 }
@@ -304,8 +1036,14 @@ func (s *Server) handleDialSetMembershipValue(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Update value for the user's membership on the dial.
-	if err := s.DialService.SetDialMembershipValue(r.Context(), id, jsonRequest.Value); err != nil {
+	// Update value for the user's membership on the dial. Individual
+	// dimensions are set instead if any are specified.
+	if len(jsonRequest.Dimensions) > 0 {
+		err = s.DialService.SetDialMembershipDimensionValues(r.Context(), id, jsonRequest.Dimensions, jsonRequest.Note)
+	} else {
+		err = s.DialService.SetDialMembershipValue(r.Context(), id, jsonRequest.Value, jsonRequest.Note)
+	}
+	if err != nil {
 		Error(w, r, err)
 		return
 	}
@@ -316,7 +1054,9 @@ func (s *Server) handleDialSetMembershipValue(w http.ResponseWriter, r *http.Req
 }
 
 type jsonSetDialMembershipValueRequest struct {
-	Value int `json:"value"`
+	Value      int            `json:"value"`
+	Dimensions map[string]int `json:"dimensions,omitempty"`
+	Note       string         `json:"note"`
 }
 
 // DialService implements the wtf.DialService over the HTTP protocol.
@@ -426,9 +1166,81 @@ func (s *DialService) UpdateDial(ctx context.Context, id int, upd wtf.DialUpdate
 	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
 }
 
-// DeleteDial permanently removes a dial by ID. Only the dial owner may delete
-// a dial. Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if
-// user is not the dial owner.
+// CloneDial creates a new dial with the name & settings of an existing dial.
+// Only the dial owner may clone a dial. Returns ENOTFOUND if dial does not
+// exist. Returns EUNAUTHORIZED if user is not the dial owner.
+func (s *DialService) CloneDial(ctx context.Context, id int, clone wtf.DialClone) (*wtf.Dial, error) {
+	// Marshal clone options into JSON format.
+	body, err := json.Marshal(clone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/clone", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Treat non-201 status codes as errors.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusCreated {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned dial data.
+	var dial wtf.Dial
+	if err := json.NewDecoder(resp.Body).Decode(&dial); err != nil {
+		return nil, err
+	}
+	return &dial, nil
+}
+
+// ArchiveDial hides a dial from listings & makes it read-only. Only the dial
+// owner may archive a dial. Returns ENOTFOUND if dial does not exist. Returns
+// EUNAUTHORIZED if user is not the dial owner.
+func (s *DialService) ArchiveDial(ctx context.Context, id int) (*wtf.Dial, error) {
+	return s.setDialArchived(ctx, fmt.Sprintf("/dials/%d/archive", id))
+}
+
+// RestoreDial returns an archived dial to normal use. Only the dial owner may
+// restore a dial. Returns ECONFLICT if the dial is not archived.
+func (s *DialService) RestoreDial(ctx context.Context, id int) (*wtf.Dial, error) {
+	return s.setDialArchived(ctx, fmt.Sprintf("/dials/%d/restore", id))
+}
+
+// setDialArchived issues an archive or restore request to path.
+func (s *DialService) setDialArchived(ctx context.Context, path string) (*wtf.Dial, error) {
+	// Create a request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the returned dial data.
+	var dial wtf.Dial
+	if err := json.NewDecoder(resp.Body).Decode(&dial); err != nil {
+		return nil, err
+	}
+	return &dial, nil
+}
+
+// DeleteDial permanently removes an archived dial by ID. Only the dial owner
+// may delete a dial. Returns ENOTFOUND if dial does not exist. Returns
+// EUNAUTHORIZED if user is not the dial owner. Returns ECONFLICT if the dial
+// has not been archived.
 func (s *DialService) DeleteDial(ctx context.Context, id int) error {
 	// Create a request with API key.
 	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dials/%d", id), nil)
@@ -453,9 +1265,24 @@ func (s *DialService) DeleteDial(ctx context.Context, id int) error {
 // require that the user know their membership ID. Only the dial ID.
 //
 // Returns ENOTFOUND if the membership does not exist.
-func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int) error {
-	// Marshal value into JSON format.
-	body, err := json.Marshal(jsonSetDialMembershipValueRequest{Value: value})
+func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int, note string) error {
+	return s.setDialMembershipValue(ctx, dialID, jsonSetDialMembershipValueRequest{Value: value, Note: note})
+}
+
+// SetDialMembershipDimensionValues sets the values of named dimensions on the
+// user's membership in a dial.
+//
+// Returns ENOTFOUND if the membership does not exist.
+func (s *DialService) SetDialMembershipDimensionValues(ctx context.Context, dialID int, values map[string]int, note string) error {
+	if len(values) == 0 {
+		return wtf.Errorf(wtf.EINVALID, "At least one dimension value required.")
+	}
+	return s.setDialMembershipValue(ctx, dialID, jsonSetDialMembershipValueRequest{Dimensions: values, Note: note})
+}
+
+func (s *DialService) setDialMembershipValue(ctx context.Context, dialID int, jsonRequest jsonSetDialMembershipValueRequest) error {
+	// Marshal values & note into JSON format.
+	body, err := json.Marshal(jsonRequest)
 	if err != nil {
 		return err
 	}
@@ -478,6 +1305,94 @@ func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value
 	return nil
 }
 
+// DialValueReport returns a report of a dial's value between start & end
+// time, slotted into given intervals.
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	return s.findDialValueReport(ctx, id, url.Values{}, start, end, interval)
+}
+
+// DialDimensionValueReport returns a report of the value of one of a dial's
+// dimensions between start & end time, slotted into given intervals.
+func (s *DialService) DialDimensionValueReport(ctx context.Context, id, dimensionID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	q := url.Values{}
+	q.Set("dimension", strconv.Itoa(dimensionID))
+	return s.findDialValueReport(ctx, id, q, start, end, interval)
+}
+
+func (s *DialService) findDialValueReport(ctx context.Context, id int, q url.Values, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	// Build query parameters for a custom report range.
+	q.Set("range", "custom")
+	q.Set("start", start.Format(time.RFC3339))
+	q.Set("end", end.Format(time.RFC3339))
+	q.Set("interval", interval.String())
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/report?%s", id, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal report records.
+	var report wtf.DialValueReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// AverageDialValueHeatmap returns the average value across all of the user's
+// dials for each hour of each day of the week.
+func (s *DialService) AverageDialValueHeatmap(ctx context.Context, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	return s.findDialValueHeatmap(ctx, "/dials/heatmap", start, end, loc)
+}
+
+// DialValueHeatmap returns the average value of a single dial for each hour
+// of each day of the week.
+func (s *DialService) DialValueHeatmap(ctx context.Context, id int, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	return s.findDialValueHeatmap(ctx, fmt.Sprintf("/dials/%d/heatmap", id), start, end, loc)
+}
+
+// findDialValueHeatmap requests a heatmap for a custom range from the given path.
+func (s *DialService) findDialValueHeatmap(ctx context.Context, path string, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	// Build query parameters for a custom heatmap range.
+	q := url.Values{}
+	q.Set("range", "custom")
+	q.Set("start", start.Format(time.RFC3339))
+	q.Set("end", end.Format(time.RFC3339))
+	q.Set("tz", loc.String())
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", path+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal heatmap cells.
+	var heatmap wtf.DialValueHeatmap
+	if err := json.NewDecoder(resp.Body).Decode(&heatmap); err != nil {
+		return nil, err
+	}
+	return &heatmap, nil
+}
+
 // AverageDialValueReport is not implemented by the HTTP service.
 func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
 	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
@@ -0,0 +1,627 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// registerDialAlertRoutes is a helper function for registering alert rule routes.
+func (s *Server) registerDialAlertRoutes(r *mux.Router) {
+	// List & create rules on a dial.
+	r.HandleFunc("/dials/{id}/alert-rules", s.handleDialAlertRuleIndex).Methods("GET")
+	r.HandleFunc("/dials/{id}/alert-rules", s.handleDialAlertRuleCreate).Methods("POST")
+	r.HandleFunc("/dials/{id}/alert-rules/new", s.handleDialAlertRuleNew).Methods("GET")
+
+	// List the firing history of a dial's rules.
+	r.HandleFunc("/dials/{id}/alert-firings", s.handleDialAlertFiringIndex).Methods("GET")
+
+	// View, edit & delete a single rule.
+	r.HandleFunc("/dial-alert-rules/{id}", s.handleDialAlertRuleView).Methods("GET")
+	r.HandleFunc("/dial-alert-rules/{id}", s.handleDialAlertRuleUpdate).Methods("PATCH")
+	r.HandleFunc("/dial-alert-rules/{id}", s.handleDialAlertRuleDelete).Methods("DELETE")
+	r.HandleFunc("/dial-alert-rules/{id}/edit", s.handleDialAlertRuleEdit).Methods("GET")
+}
+
+// handleDialAlertRuleIndex handles the "GET /dials/:id/alert-rules" route.
+// This route is only available via the JSON API. The HTML rule list is shown
+// on the dial page.
+func (s *Server) handleDialAlertRuleIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Ensure the dial exists & the user can view it.
+	if _, err := s.DialService.FindDialByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch rules from the database.
+	rules, n, err := s.DialAlertService.FindDialAlertRules(r.Context(), wtf.DialAlertRuleFilter{DialID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write rules & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialAlertRulesResponse{
+		DialAlertRules: rules,
+		N:              n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialAlertRulesResponse represents the output JSON struct for "GET /dials/:id/alert-rules".
+type findDialAlertRulesResponse struct {
+	DialAlertRules []*wtf.DialAlertRule `json:"dialAlertRules"`
+	N              int                  `json:"n"`
+}
+
+// handleDialAlertRuleNew handles the "GET /dials/:id/alert-rules/new" route.
+// It renders an HTML form for editing a new rule.
+func (s *Server) handleDialAlertRuleNew(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch dial so the form can link back to it.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Default the threshold to three quarters of the way up the dial's scale.
+	tmpl := html.DialAlertRuleEditTemplate{
+		Dial: dial,
+		Rule: &wtf.DialAlertRule{
+			DialID:          id,
+			Subject:         wtf.DialAlertSubjectDial,
+			Operator:        wtf.DialAlertOperatorGTE,
+			Threshold:       dial.Scale.Nearest(dial.Scale.Min + (dial.Scale.Max-dial.Scale.Min)*3/4),
+			CooldownMinutes: wtf.DefaultDialAlertCooldownMinutes,
+			NotifyInApp:     true,
+		},
+	}
+	tmpl.Render(r.Context(), w)
+}
+
+// handleDialAlertRuleCreate handles the "POST /dials/:id/alert-rules" route.
+// It reads & writes data using with HTML or JSON.
+func (s *Server) handleDialAlertRuleCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var rule wtf.DialAlertRule
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		if err := parseDialAlertRuleForm(r, &rule); err != nil {
+			Error(w, r, err)
+			return
+		}
+	}
+	rule.DialID = id
+
+	// Create rule in the database.
+	err = s.DialAlertService.CreateDialAlertRule(r.Context(), &rule)
+
+	// Write new rule to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(rule); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Display validation errors on the form with the user's data.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			s.renderDialAlertRuleEdit(w, r, &rule, err)
+			return
+		}
+
+		SetFlash(w, "Alert rule successfully created.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleDialAlertRuleView handles the "GET /dial-alert-rules/:id" route. This
+// route is only available via the JSON API.
+func (s *Server) handleDialAlertRuleView(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse rule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch rule from the database.
+	rule, err := s.DialAlertService.FindDialAlertRuleByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(rule); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// handleDialAlertRuleEdit handles the "GET /dial-alert-rules/:id/edit" route.
+// It renders an HTML form for editing an existing rule.
+func (s *Server) handleDialAlertRuleEdit(w http.ResponseWriter, r *http.Request) {
+	// Parse rule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch rule from the database.
+	rule, err := s.DialAlertService.FindDialAlertRuleByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	s.renderDialAlertRuleEdit(w, r, rule, nil)
+}
+
+// handleDialAlertRuleUpdate handles the "PATCH /dial-alert-rules/:id" route.
+// It reads & writes data using with HTML or JSON.
+func (s *Server) handleDialAlertRuleUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse rule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse fields into an update object. The HTML form always sends every
+	// field so the whole rule is replaced.
+	var upd wtf.DialAlertRuleUpdate
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		var rule wtf.DialAlertRule
+		if err := parseDialAlertRuleForm(r, &rule); err != nil {
+			Error(w, r, err)
+			return
+		}
+		upd = wtf.DialAlertRuleUpdate{
+			Name:            &rule.Name,
+			Subject:         &rule.Subject,
+			Operator:        &rule.Operator,
+			Threshold:       &rule.Threshold,
+			DurationMinutes: &rule.DurationMinutes,
+			CooldownMinutes: &rule.CooldownMinutes,
+			NotifyInApp:     &rule.NotifyInApp,
+			WebhookURL:      &rule.WebhookURL,
+			Email:           &rule.Email,
+		}
+	}
+
+	// Update the rule in the database.
+	rule, err := s.DialAlertService.UpdateDialAlertRule(r.Context(), id, upd)
+
+	// Write updated rule to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(rule); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Display validation errors on the form with the user's data.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL || rule == nil {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			s.renderDialAlertRuleEdit(w, r, rule, err)
+			return
+		}
+
+		SetFlash(w, "Alert rule successfully updated.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", rule.DialID), http.StatusFound)
+	}
+}
+
+// handleDialAlertRuleDelete handles the "DELETE /dial-alert-rules/:id" route.
+// This route permanently deletes the rule & its firing history.
+func (s *Server) handleDialAlertRuleDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse rule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch rule first so we know which dial to redirect to.
+	rule, err := s.DialAlertService.FindDialAlertRuleByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if err := s.DialAlertService.DeleteDialAlertRule(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, "Alert rule successfully deleted.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", rule.DialID), http.StatusFound)
+	}
+}
+
+// renderDialAlertRuleEdit renders the rule form along with the rule's dial.
+func (s *Server) renderDialAlertRuleEdit(w http.ResponseWriter, r *http.Request, rule *wtf.DialAlertRule, err error) {
+	dial, e := s.DialService.FindDialByID(r.Context(), rule.DialID)
+	if e != nil {
+		Error(w, r, e)
+		return
+	}
+
+	tmpl := html.DialAlertRuleEditTemplate{Dial: dial, Rule: rule, Err: err}
+	tmpl.Render(r.Context(), w)
+}
+
+// parseDialAlertRuleForm reads the fields of the rule form into rule.
+func parseDialAlertRuleForm(r *http.Request, rule *wtf.DialAlertRule) (err error) {
+	rule.Name = r.PostFormValue("name")
+	rule.Subject = r.PostFormValue("subject")
+	rule.Operator = r.PostFormValue("operator")
+	if rule.Threshold, err = strconv.Atoi(r.PostFormValue("threshold")); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid threshold format")
+	}
+	if v := r.PostFormValue("duration_minutes"); v != "" {
+		if rule.DurationMinutes, err = strconv.Atoi(v); err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid duration format")
+		}
+	}
+	if v := r.PostFormValue("cooldown_minutes"); v != "" {
+		if rule.CooldownMinutes, err = strconv.Atoi(v); err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid cooldown format")
+		}
+	}
+	rule.NotifyInApp = r.PostFormValue("notify_in_app") == "true"
+	rule.WebhookURL = r.PostFormValue("webhook_url")
+	rule.Email = r.PostFormValue("email")
+	return nil
+}
+
+// handleDialAlertFiringIndex handles the "GET /dials/:id/alert-firings" route.
+// This route is only available via the JSON API. Recent firings are also shown
+// on the dial page.
+func (s *Server) handleDialAlertFiringIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse optional pagination, rule & status filters.
+	filter := wtf.DialAlertFiringFilter{DialID: &id, Limit: 20}
+	filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	if v := r.URL.Query().Get("rule_id"); v != "" {
+		ruleID, err := strconv.Atoi(v)
+		if err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid rule ID format"))
+			return
+		}
+		filter.RuleID = &ruleID
+	}
+	if v := r.URL.Query().Get("status"); v != "" {
+		filter.Status = &v
+	}
+
+	// Ensure the dial exists & the user can view it.
+	if _, err := s.DialService.FindDialByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch firings from the database.
+	firings, n, err := s.DialAlertService.FindDialAlertFirings(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write firings & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialAlertFiringsResponse{
+		DialAlertFirings: firings,
+		N:                n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialAlertFiringsResponse represents the output JSON struct for "GET /dials/:id/alert-firings".
+type findDialAlertFiringsResponse struct {
+	DialAlertFirings []*wtf.DialAlertFiring `json:"dialAlertFirings"`
+	N                int                    `json:"n"`
+}
+
+// DialAlertService implements the wtf.DialAlertService over the HTTP protocol.
+type DialAlertService struct {
+	Client *Client
+}
+
+// NewDialAlertService returns a new instance of DialAlertService.
+func NewDialAlertService(client *Client) *DialAlertService {
+	return &DialAlertService{Client: client}
+}
+
+// FindDialAlertRuleByID retrieves a single rule by ID.
+func (s *DialAlertService) FindDialAlertRuleByID(ctx context.Context, id int) (*wtf.DialAlertRule, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dial-alert-rules/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var rule wtf.DialAlertRule
+	if err := json.NewDecoder(resp.Body).Decode(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindDialAlertRules retrieves the rules on a dial. The filter must specify a
+// DialID as rules are listed per-dial over HTTP.
+func (s *DialAlertService) FindDialAlertRules(ctx context.Context, filter wtf.DialAlertRuleFilter) ([]*wtf.DialAlertRule, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/alert-rules", *filter.DialID), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of rules & total count.
+	var jsonResponse findDialAlertRulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialAlertRules, jsonResponse.N, nil
+}
+
+// CreateDialAlertRule creates a new rule on a dial.
+func (s *DialAlertService) CreateDialAlertRule(ctx context.Context, rule *wtf.DialAlertRule) error {
+	// Marshal rule into JSON format.
+	body, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/alert-rules", rule.DialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned rule data into the caller's object.
+	if err := json.NewDecoder(resp.Body).Decode(rule); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDialAlertRule updates the fields of an existing rule.
+func (s *DialAlertService) UpdateDialAlertRule(ctx context.Context, id int, upd wtf.DialAlertRuleUpdate) (*wtf.DialAlertRule, error) {
+	// Marshal update into JSON format.
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/dial-alert-rules/%d", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var rule wtf.DialAlertRule
+	if err := json.NewDecoder(resp.Body).Decode(&rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// DeleteDialAlertRule permanently deletes a rule & its firing history.
+func (s *DialAlertService) DeleteDialAlertRule(ctx context.Context, id int) error {
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-alert-rules/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// FindDialAlertFirings retrieves the firing history of a dial. The filter must
+// specify a DialID as firings are listed per-dial over HTTP.
+func (s *DialAlertService) FindDialAlertFirings(ctx context.Context, filter wtf.DialAlertFiringFilter) ([]*wtf.DialAlertFiring, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Build query parameters for pagination, rule & status.
+	q := url.Values{}
+	q.Set("offset", strconv.Itoa(filter.Offset))
+	if filter.RuleID != nil {
+		q.Set("rule_id", strconv.Itoa(*filter.RuleID))
+	}
+	if filter.Status != nil {
+		q.Set("status", *filter.Status)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/alert-firings?%s", *filter.DialID, q.Encode()), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of firings & total count.
+	var jsonResponse findDialAlertFiringsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialAlertFirings, jsonResponse.N, nil
+}
+
+// Ensure type implements interface.
+var _ wtf.DialAlertNotifier = (*DialAlertWebhookNotifier)(nil)
+
+// DialAlertWebhookNotifier delivers fired dial alerts by POSTing a
+// "dial:alert_fired" event as JSON to the rule's webhook URL.
+type DialAlertWebhookNotifier struct {
+	// Client used to deliver webhooks. Defaults to a client which only
+	// connects to public addresses.
+	Client *http.Client
+}
+
+// NewDialAlertWebhookNotifier returns a new instance of DialAlertWebhookNotifier.
+func NewDialAlertWebhookNotifier() *DialAlertWebhookNotifier {
+	return &DialAlertWebhookNotifier{
+		Client: NewWebhookClient(),
+	}
+}
+
+// NotifyDialAlert POSTs the firing to the rule's webhook URL. Any non-2xx
+// status code is considered an error.
+func (n *DialAlertWebhookNotifier) NotifyDialAlert(ctx context.Context, firing *wtf.DialAlertFiring) error {
+	return postWebhook(ctx, n.Client, firing.Rule.WebhookURL, wtf.Event{
+		Type: wtf.EventTypeDialAlertFired,
+		Payload: &wtf.DialAlertFiredPayload{
+			ID:       firing.ID,
+			RuleID:   firing.RuleID,
+			RuleName: firing.Rule.Name,
+			DialID:   firing.DialID,
+			DialName: firing.Dial.Name,
+			Value:    firing.Value,
+		},
+	})
+}
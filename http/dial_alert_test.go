@@ -0,0 +1,73 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+func TestDialAlertWebhookNotifier_NotifyDialAlert(t *testing.T) {
+	// Ensure the firing is POSTed as a JSON event.
+	t.Run("OK", func(t *testing.T) {
+		var event struct {
+			Type    string                    `json:"type"`
+			Payload wtf.DialAlertFiredPayload `json:"payload"`
+		}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+				t.Fatal(err)
+			}
+		}))
+		defer ts.Close()
+
+		// The test server listens on loopback so use an unrestricted client.
+		n := wtfhttp.NewDialAlertWebhookNotifier()
+		n.Client = ts.Client()
+		if err := n.NotifyDialAlert(context.Background(), &wtf.DialAlertFiring{
+			ID:     1,
+			RuleID: 2,
+			Rule:   &wtf.DialAlertRule{Name: "High", WebhookURL: ts.URL},
+			DialID: 3,
+			Dial:   &wtf.Dial{Name: "DIAL"},
+			Value:  80,
+		}); err != nil {
+			t.Fatal(err)
+		} else if got, want := event.Type, wtf.EventTypeDialAlertFired; got != want {
+			t.Fatalf("Type=%v, want %v", got, want)
+		} else if got, want := event.Payload, (wtf.DialAlertFiredPayload{ID: 1, RuleID: 2, RuleName: "High", DialID: 3, DialName: "DIAL", Value: 80}); got != want {
+			t.Fatalf("Payload=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure webhooks are not delivered to loopback, private or link-local
+	// addresses by default.
+	t.Run("ErrAddressNotAllowed", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("unexpected request")
+		}))
+		defer ts.Close()
+
+		n := wtfhttp.NewDialAlertWebhookNotifier()
+		for _, u := range []string{
+			ts.URL,
+			strings.Replace(ts.URL, "127.0.0.1", "localhost", 1),
+			"http://10.0.0.1/hook",
+			"http://169.254.169.254/latest/meta-data/",
+			"http://[::1]/hook",
+			"http://[::ffff:127.0.0.1]/hook",
+		} {
+			if err := n.NotifyDialAlert(context.Background(), &wtf.DialAlertFiring{
+				Rule: &wtf.DialAlertRule{WebhookURL: u},
+				Dial: &wtf.Dial{},
+			}); err == nil || !strings.Contains(err.Error(), "webhook address not allowed") {
+				t.Fatalf("unexpected error: url=%s err=%v", u, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/gorilla/mux"
+)
+
+// registerDialAnomalyRoutes is a helper function for registering anomaly routes.
+func (s *Server) registerDialAnomalyRoutes(r *mux.Router) {
+	// List anomalies detected on a dial.
+	r.HandleFunc("/dials/{id}/anomalies", s.handleDialAnomalyIndex).Methods("GET")
+}
+
+// handleDialAnomalyIndex handles the "GET /dials/:id/anomalies" route. This
+// route is only available via the JSON API. Recent anomalies are also shown
+// on the dial page.
+func (s *Server) handleDialAnomalyIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse optional pagination & kind filter.
+	filter := wtf.DialAnomalyFilter{DialID: &id, Limit: 20}
+	filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	if v := r.URL.Query().Get("kind"); v != "" {
+		filter.Kind = &v
+	}
+
+	// Ensure the dial exists & the user can view it.
+	if _, err := s.DialService.FindDialByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch anomalies from the database.
+	anomalies, n, err := s.DialAnomalyService.FindDialAnomalies(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write anomalies & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialAnomaliesResponse{
+		DialAnomalies: anomalies,
+		N:             n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialAnomaliesResponse represents the output JSON struct for "GET /dials/:id/anomalies".
+type findDialAnomaliesResponse struct {
+	DialAnomalies []*wtf.DialAnomaly `json:"dialAnomalies"`
+	N             int                `json:"n"`
+}
+
+// DialAnomalyService implements the wtf.DialAnomalyService over the HTTP protocol.
+type DialAnomalyService struct {
+	Client *Client
+}
+
+// NewDialAnomalyService returns a new instance of DialAnomalyService.
+func NewDialAnomalyService(client *Client) *DialAnomalyService {
+	return &DialAnomalyService{Client: client}
+}
+
+// FindDialAnomalies retrieves the anomalies detected on a dial. The filter
+// must specify a DialID as anomalies are listed per-dial over HTTP.
+func (s *DialAnomalyService) FindDialAnomalies(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Build query parameters for pagination & kind.
+	q := url.Values{}
+	q.Set("offset", strconv.Itoa(filter.Offset))
+	if filter.Kind != nil {
+		q.Set("kind", *filter.Kind)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/anomalies?%s", *filter.DialID, q.Encode()), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of anomalies & total count.
+	var jsonResponse findDialAnomaliesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialAnomalies, jsonResponse.N, nil
+}
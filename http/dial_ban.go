@@ -0,0 +1,234 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/gorilla/mux"
+)
+
+// registerDialBanRoutes is a helper function for registering ban list routes.
+func (s *Server) registerDialBanRoutes(r *mux.Router) {
+	// List & add to a dial's ban list.
+	r.HandleFunc("/dials/{id}/bans", s.handleDialBanIndex).Methods("GET")
+	r.HandleFunc("/dials/{id}/bans", s.handleDialBanCreate).Methods("POST")
+
+	// Lift a ban.
+	r.HandleFunc("/dial-bans/{id}", s.handleDialBanDelete).Methods("DELETE")
+}
+
+// handleDialBanIndex handles the "GET /dials/:id/bans" route. This route is
+// only available via the JSON API. The HTML ban list is shown on the dial page.
+func (s *Server) handleDialBanIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch bans from the database.
+	bans, n, err := s.DialBanService.FindDialBans(r.Context(), wtf.DialBanFilter{DialID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write bans & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialBansResponse{
+		DialBans: bans,
+		N:        n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialBansResponse represents the output JSON struct for "GET /dials/:id/bans".
+type findDialBansResponse struct {
+	DialBans []*wtf.DialBan `json:"dialBans"`
+	N        int            `json:"n"`
+}
+
+// handleDialBanCreate handles the "POST /dials/:id/bans" route. This route
+// removes a member from the dial and adds them to the ban list.
+func (s *Server) handleDialBanCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var ban wtf.DialBan
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&ban); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		if ban.UserID, err = strconv.Atoi(r.PostFormValue("user_id")); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid user ID format"))
+			return
+		}
+		ban.Reason = r.PostFormValue("reason")
+	}
+	ban.DialID = id
+
+	// Ban the user in the database.
+	if err := s.DialBanService.CreateDialBan(r.Context(), &ban); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write new ban to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(ban); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s has been removed and banned from the dial.", ban.User.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleDialBanDelete handles the "DELETE /dial-bans/:id" route. This route
+// lifts a ban so the user may rejoin the dial.
+func (s *Server) handleDialBanDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse ban ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch ban first so we know which dial to redirect to.
+	bans, _, err := s.DialBanService.FindDialBans(r.Context(), wtf.DialBanFilter{ID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if len(bans) == 0 {
+		Error(w, r, wtf.Errorf(wtf.ENOTFOUND, "Dial ban not found."))
+		return
+	} else if err := s.DialBanService.DeleteDialBan(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s has been unbanned.", bans[0].User.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", bans[0].DialID), http.StatusFound)
+	}
+}
+
+// DialBanService implements the wtf.DialBanService over the HTTP protocol.
+type DialBanService struct {
+	Client *Client
+}
+
+// NewDialBanService returns a new instance of DialBanService.
+func NewDialBanService(client *Client) *DialBanService {
+	return &DialBanService{Client: client}
+}
+
+// FindDialBans retrieves the ban list for a dial. The filter must specify a
+// DialID as bans are listed per-dial over HTTP.
+func (s *DialBanService) FindDialBans(ctx context.Context, filter wtf.DialBanFilter) ([]*wtf.DialBan, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/bans", *filter.DialID), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of bans & total count.
+	var jsonResponse findDialBansResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialBans, jsonResponse.N, nil
+}
+
+// CreateDialBan bans a user from a dial and removes their membership.
+func (s *DialBanService) CreateDialBan(ctx context.Context, ban *wtf.DialBan) error {
+	// Marshal ban into JSON format.
+	body, err := json.Marshal(ban)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/bans", ban.DialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned ban data.
+	if err := json.NewDecoder(resp.Body).Decode(&ban); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteDialBan lifts a ban so the user may rejoin the dial.
+func (s *DialBanService) DeleteDialBan(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-bans/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,383 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/gorilla/mux"
+)
+
+// registerDialChildRoutes is a helper function for registering dial hierarchy routes.
+func (s *Server) registerDialChildRoutes(r *mux.Router) {
+	// List & attach children of a parent dial.
+	r.HandleFunc("/dials/{id}/children", s.handleDialChildIndex).Methods("GET")
+	r.HandleFunc("/dials/{id}/children", s.handleDialChildCreate).Methods("POST")
+
+	// View, reweight & detach a child.
+	r.HandleFunc("/dial-children/{id}", s.handleDialChildView).Methods("GET")
+	r.HandleFunc("/dial-children/{id}", s.handleDialChildUpdate).Methods("PATCH")
+	r.HandleFunc("/dial-children/{id}", s.handleDialChildDelete).Methods("DELETE")
+}
+
+// handleDialChildIndex handles the "GET /dials/:id/children" route. This route
+// is only available via the JSON API. The HTML list of children is shown on
+// the parent dial's page.
+func (s *Server) handleDialChildIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse parent dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch children from the database.
+	children, n, err := s.DialChildService.FindDialChildren(r.Context(), wtf.DialChildFilter{ParentDialID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write children & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialChildrenResponse{
+		DialChildren: children,
+		N:            n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialChildrenResponse represents the output JSON struct for "GET /dials/:id/children".
+type findDialChildrenResponse struct {
+	DialChildren []*wtf.DialChild `json:"dialChildren"`
+	N            int              `json:"n"`
+}
+
+// handleDialChildCreate handles the "POST /dials/:id/children" route. This
+// route attaches one of the user's dials to the parent dial.
+func (s *Server) handleDialChildCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse parent dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var child wtf.DialChild
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&child); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		if child.ChildDialID, err = strconv.Atoi(r.PostFormValue("child_dial_id")); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid child dial ID format"))
+			return
+		} else if child.Weight, err = parseDialChildWeight(r); err != nil {
+			Error(w, r, err)
+			return
+		}
+	}
+	child.ParentDialID = id
+
+	// Attach the child in the database.
+	if err := s.DialChildService.CreateDialChild(r.Context(), &child); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write new child to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(child); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s now rolls up into this dial.", child.ChildDial.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleDialChildView handles the "GET /dial-children/:id" route. This route
+// is only available via the JSON API.
+func (s *Server) handleDialChildView(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse child ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch child from the database.
+	child, err := s.DialChildService.FindDialChildByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(child); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// handleDialChildUpdate handles the "PATCH /dial-children/:id" route. This
+// route changes the weight of a child within its parent.
+func (s *Server) handleDialChildUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse child ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var upd wtf.DialChildUpdate
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		weight, err := parseDialChildWeight(r)
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+		upd.Weight = &weight
+	}
+
+	// Update the child in the database.
+	child, err := s.DialChildService.UpdateDialChild(r.Context(), id, upd)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write new child state to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(child); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s weight updated.", child.ChildDial.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", child.ParentDialID), http.StatusFound)
+	}
+}
+
+// handleDialChildDelete handles the "DELETE /dial-children/:id" route. This
+// route detaches a child from its parent dial.
+func (s *Server) handleDialChildDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse child ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch child first so we know which dial to redirect to.
+	child, err := s.DialChildService.FindDialChildByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if err := s.DialChildService.DeleteDialChild(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s no longer rolls up into %s.", child.ChildDial.Name, child.ParentDial.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", child.ParentDialID), http.StatusFound)
+	}
+}
+
+// parseDialChildWeight reads the child weight from the form. Returns the
+// default weight if the field is blank.
+func parseDialChildWeight(r *http.Request) (float64, error) {
+	v := r.PostFormValue("weight")
+	if v == "" {
+		return wtf.DefaultDialChildWeight, nil
+	}
+
+	weight, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, wtf.Errorf(wtf.EINVALID, "Invalid weight format")
+	}
+	return weight, nil
+}
+
+// DialChildService implements the wtf.DialChildService over the HTTP protocol.
+type DialChildService struct {
+	Client *Client
+}
+
+// NewDialChildService returns a new instance of DialChildService.
+func NewDialChildService(client *Client) *DialChildService {
+	return &DialChildService{Client: client}
+}
+
+// FindDialChildByID retrieves a single child by ID.
+func (s *DialChildService) FindDialChildByID(ctx context.Context, id int) (*wtf.DialChild, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dial-children/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the child.
+	var child wtf.DialChild
+	if err := json.NewDecoder(resp.Body).Decode(&child); err != nil {
+		return nil, err
+	}
+	return &child, nil
+}
+
+// FindDialChildren retrieves the children of a dial. The filter must specify a
+// ParentDialID as children are listed per-dial over HTTP.
+func (s *DialChildService) FindDialChildren(ctx context.Context, filter wtf.DialChildFilter) ([]*wtf.DialChild, int, error) {
+	if filter.ParentDialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Parent dial ID required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/children", *filter.ParentDialID), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of children & total count.
+	var jsonResponse findDialChildrenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialChildren, jsonResponse.N, nil
+}
+
+// CreateDialChild attaches a dial to a parent dial.
+func (s *DialChildService) CreateDialChild(ctx context.Context, child *wtf.DialChild) error {
+	// Marshal child into JSON format.
+	body, err := json.Marshal(child)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/children", child.ParentDialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned child data.
+	if err := json.NewDecoder(resp.Body).Decode(&child); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDialChild updates the weight of a child.
+func (s *DialChildService) UpdateDialChild(ctx context.Context, id int, upd wtf.DialChildUpdate) (*wtf.DialChild, error) {
+	// Marshal update into JSON format.
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/dial-children/%d", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned child data.
+	var child wtf.DialChild
+	if err := json.NewDecoder(resp.Body).Decode(&child); err != nil {
+		return nil, err
+	}
+	return &child, nil
+}
+
+// DeleteDialChild detaches a child from its parent dial.
+func (s *DialChildService) DeleteDialChild(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-children/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
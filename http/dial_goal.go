@@ -0,0 +1,600 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// registerDialGoalRoutes is a helper function for registering goal routes.
+func (s *Server) registerDialGoalRoutes(r *mux.Router) {
+	// List & create goals on a dial.
+	r.HandleFunc("/dials/{id}/goals", s.handleDialGoalIndex).Methods("GET")
+	r.HandleFunc("/dials/{id}/goals", s.handleDialGoalCreate).Methods("POST")
+	r.HandleFunc("/dials/{id}/goals/new", s.handleDialGoalNew).Methods("GET")
+
+	// View, edit & delete a single goal.
+	r.HandleFunc("/dial-goals/{id}", s.handleDialGoalView).Methods("GET")
+	r.HandleFunc("/dial-goals/{id}", s.handleDialGoalUpdate).Methods("PATCH")
+	r.HandleFunc("/dial-goals/{id}", s.handleDialGoalDelete).Methods("DELETE")
+	r.HandleFunc("/dial-goals/{id}/edit", s.handleDialGoalEdit).Methods("GET")
+
+	// Report compliance over a goal's recent windows.
+	r.HandleFunc("/dial-goals/{id}/report", s.handleDialGoalReport).Methods("GET")
+}
+
+// handleDialGoalIndex handles the "GET /dials/:id/goals" route. This route is
+// only available via the JSON API. The HTML goal list is shown on the dial page.
+func (s *Server) handleDialGoalIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Ensure the dial exists & the user can view it.
+	if _, err := s.DialService.FindDialByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch goals & their current status from the database.
+	goals, n, err := s.DialGoalService.FindDialGoals(r.Context(), wtf.DialGoalFilter{DialID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write goals & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialGoalsResponse{
+		DialGoals: goals,
+		N:         n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialGoalsResponse represents the output JSON struct for "GET /dials/:id/goals".
+type findDialGoalsResponse struct {
+	DialGoals []*wtf.DialGoal `json:"dialGoals"`
+	N         int             `json:"n"`
+}
+
+// handleDialGoalNew handles the "GET /dials/:id/goals/new" route. It renders
+// an HTML form for editing a new goal. The goal defaults to keeping the dial
+// below the middle of its scale for 90% of weekday working hours each month
+// in the owner's time zone.
+func (s *Server) handleDialGoalNew(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch dial so the form can link back to it.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	tmpl := html.DialGoalEditTemplate{
+		Dial: dial,
+		Goal: &wtf.DialGoal{
+			DialID:    id,
+			Operator:  wtf.DialGoalOperatorLT,
+			Threshold: dial.Scale.Nearest((dial.Scale.Min + dial.Scale.Max) / 2),
+			Target:    90,
+			Window:    wtf.DialGoalWindowMonth,
+			Weekdays:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			StartHour: 9,
+			EndHour:   17,
+			Timezone:  userLocation(r.Context()).String(),
+		},
+	}
+	tmpl.Render(r.Context(), w)
+}
+
+// handleDialGoalCreate handles the "POST /dials/:id/goals" route.
+// It reads & writes data using with HTML or JSON.
+func (s *Server) handleDialGoalCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var goal wtf.DialGoal
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		if err := parseDialGoalForm(r, &goal); err != nil {
+			Error(w, r, err)
+			return
+		}
+	}
+	goal.DialID = id
+
+	// Create goal in the database.
+	err = s.DialGoalService.CreateDialGoal(r.Context(), &goal)
+
+	// Write new goal to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(goal); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Display validation errors on the form with the user's data.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			s.renderDialGoalEdit(w, r, &goal, err)
+			return
+		}
+
+		SetFlash(w, "Goal successfully created.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleDialGoalView handles the "GET /dial-goals/:id" route. This route is
+// only available via the JSON API.
+func (s *Server) handleDialGoalView(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse goal ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch goal & its current status from the database.
+	goal, err := s.DialGoalService.FindDialGoalByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(goal); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// handleDialGoalEdit handles the "GET /dial-goals/:id/edit" route.
+// It renders an HTML form for editing an existing goal.
+func (s *Server) handleDialGoalEdit(w http.ResponseWriter, r *http.Request) {
+	// Parse goal ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch goal from the database.
+	goal, err := s.DialGoalService.FindDialGoalByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	s.renderDialGoalEdit(w, r, goal, nil)
+}
+
+// handleDialGoalUpdate handles the "PATCH /dial-goals/:id" route.
+// It reads & writes data using with HTML or JSON.
+func (s *Server) handleDialGoalUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse goal ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse fields into an update object. The HTML form always sends every
+	// field so the whole goal is replaced.
+	var upd wtf.DialGoalUpdate
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		var goal wtf.DialGoal
+		if err := parseDialGoalForm(r, &goal); err != nil {
+			Error(w, r, err)
+			return
+		}
+		upd = wtf.DialGoalUpdate{
+			Name:      &goal.Name,
+			Operator:  &goal.Operator,
+			Threshold: &goal.Threshold,
+			Target:    &goal.Target,
+			Window:    &goal.Window,
+			Weekdays:  &goal.Weekdays,
+			StartHour: &goal.StartHour,
+			EndHour:   &goal.EndHour,
+			Timezone:  &goal.Timezone,
+		}
+	}
+
+	// Update the goal in the database.
+	goal, err := s.DialGoalService.UpdateDialGoal(r.Context(), id, upd)
+
+	// Write updated goal to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(goal); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Display validation errors on the form with the user's data.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL || goal == nil {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			s.renderDialGoalEdit(w, r, goal, err)
+			return
+		}
+
+		SetFlash(w, "Goal successfully updated.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", goal.DialID), http.StatusFound)
+	}
+}
+
+// handleDialGoalDelete handles the "DELETE /dial-goals/:id" route.
+// This route permanently deletes the goal.
+func (s *Server) handleDialGoalDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse goal ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch goal first so we know which dial to redirect to.
+	goal, err := s.DialGoalService.FindDialGoalByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if err := s.DialGoalService.DeleteDialGoal(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, "Goal successfully deleted.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", goal.DialID), http.StatusFound)
+	}
+}
+
+// handleDialGoalReport handles the "GET /dial-goals/:id/report" route. It
+// returns the goal's compliance over its most recent windows, oldest first,
+// as JSON or CSV. The number of windows is set by the "windows" query
+// parameter & defaults to wtf.DefaultDialGoalReportWindows.
+func (s *Server) handleDialGoalReport(w http.ResponseWriter, r *http.Request) {
+	// Parse goal ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Only CSV is available as an alternative to JSON.
+	if r.Header.Get("Accept") != "text/csv" {
+		r.Header.Set("Accept", "application/json")
+	}
+
+	// Parse the optional number of windows.
+	windows := wtf.DefaultDialGoalReportWindows
+	if v := r.URL.Query().Get("windows"); v != "" {
+		if windows, err = strconv.Atoi(v); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid windows format"))
+			return
+		}
+	}
+
+	// Compute compliance for each window.
+	report, err := s.DialGoalService.DialGoalReport(r.Context(), id, windows)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		if err := encodeDialGoalReportCSV(w, report); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(dialGoalReportResponse{Windows: report}); err != nil {
+			LogError(r, err)
+			return
+		}
+	}
+}
+
+// dialGoalReportResponse represents the output JSON struct for "GET /dial-goals/:id/report".
+type dialGoalReportResponse struct {
+	Windows []*wtf.DialGoalStatus `json:"windows"`
+}
+
+// encodeDialGoalReportCSV writes each window of a goal report as a CSV row.
+func encodeDialGoalReportCSV(w http.ResponseWriter, report []*wtf.DialGoalStatus) error {
+	enc := csv.NewDialGoalStatusEncoder(w)
+	for _, status := range report {
+		if err := enc.EncodeDialGoalStatus(status); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// renderDialGoalEdit renders the goal form along with the goal's dial.
+func (s *Server) renderDialGoalEdit(w http.ResponseWriter, r *http.Request, goal *wtf.DialGoal, err error) {
+	dial, e := s.DialService.FindDialByID(r.Context(), goal.DialID)
+	if e != nil {
+		Error(w, r, e)
+		return
+	}
+
+	tmpl := html.DialGoalEditTemplate{Dial: dial, Goal: goal, Err: err}
+	tmpl.Render(r.Context(), w)
+}
+
+// parseDialGoalForm reads the fields of the goal form into goal.
+func parseDialGoalForm(r *http.Request, goal *wtf.DialGoal) (err error) {
+	if err := r.ParseForm(); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid form")
+	}
+
+	goal.Name = r.PostFormValue("name")
+	goal.Operator = r.PostFormValue("operator")
+	if goal.Threshold, err = strconv.Atoi(r.PostFormValue("threshold")); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid threshold format")
+	}
+	if goal.Target, err = strconv.ParseFloat(r.PostFormValue("target"), 64); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid target format")
+	}
+	goal.Window = r.PostFormValue("window")
+
+	goal.Weekdays = []time.Weekday{}
+	for _, v := range r.PostForm["weekday"] {
+		weekday, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid working day format")
+		}
+		goal.Weekdays = append(goal.Weekdays, time.Weekday(weekday))
+	}
+
+	if goal.StartHour, err = strconv.Atoi(r.PostFormValue("start_hour")); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid start hour format")
+	}
+	if goal.EndHour, err = strconv.Atoi(r.PostFormValue("end_hour")); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid end hour format")
+	}
+	goal.Timezone = r.PostFormValue("timezone")
+	return nil
+}
+
+// DialGoalService implements the wtf.DialGoalService over the HTTP protocol.
+type DialGoalService struct {
+	Client *Client
+}
+
+// NewDialGoalService returns a new instance of DialGoalService.
+func NewDialGoalService(client *Client) *DialGoalService {
+	return &DialGoalService{Client: client}
+}
+
+// FindDialGoalByID retrieves a single goal by ID along with its current status.
+func (s *DialGoalService) FindDialGoalByID(ctx context.Context, id int) (*wtf.DialGoal, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dial-goals/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var goal wtf.DialGoal
+	if err := json.NewDecoder(resp.Body).Decode(&goal); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// FindDialGoals retrieves the goals on a dial. The filter must specify a
+// DialID as goals are listed per-dial over HTTP.
+func (s *DialGoalService) FindDialGoals(ctx context.Context, filter wtf.DialGoalFilter) ([]*wtf.DialGoal, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/goals", *filter.DialID), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of goals & total count.
+	var jsonResponse findDialGoalsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialGoals, jsonResponse.N, nil
+}
+
+// CreateDialGoal creates a new goal on a dial.
+func (s *DialGoalService) CreateDialGoal(ctx context.Context, goal *wtf.DialGoal) error {
+	// Marshal goal into JSON format.
+	body, err := json.Marshal(goal)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/goals", goal.DialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned goal data into the caller's object.
+	if err := json.NewDecoder(resp.Body).Decode(goal); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDialGoal updates the fields of an existing goal.
+func (s *DialGoalService) UpdateDialGoal(ctx context.Context, id int, upd wtf.DialGoalUpdate) (*wtf.DialGoal, error) {
+	// Marshal update into JSON format.
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/dial-goals/%d", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var goal wtf.DialGoal
+	if err := json.NewDecoder(resp.Body).Decode(&goal); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// DeleteDialGoal permanently deletes a goal.
+func (s *DialGoalService) DeleteDialGoal(ctx context.Context, id int) error {
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-goals/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// DialGoalReport returns the compliance of a goal over its most recent windows.
+func (s *DialGoalService) DialGoalReport(ctx context.Context, id, windows int) ([]*wtf.DialGoalStatus, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dial-goals/%d/report?windows=%d", id, windows), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse dialGoalReportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, err
+	}
+	return jsonResponse.Windows, nil
+}
@@ -1,12 +1,17 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
 	"github.com/benbjohnson/wtf/http/html"
 	"github.com/gorilla/mux"
 )
@@ -17,11 +22,25 @@ func (s *Server) registerDialMembershipRoutes(r *mux.Router) {
 	r.HandleFunc("/invite/{code}", s.handleDialMembershipNew).Methods("GET")
 	r.HandleFunc("/invite/{code}", s.handleDialMembershipCreate).Methods("POST")
 
+	// API endpoint for listing memberships.
+	r.HandleFunc("/dial-memberships", s.handleDialMembershipIndex).Methods("GET")
+
 	// Update membership WTF level.
 	r.HandleFunc("/dial-memberships/{id}", s.handleDialMembershipUpdate).Methods("PATCH")
 
+	// Approve a pending membership. Pending memberships are rejected by deleting them.
+	r.HandleFunc("/dial-memberships/{id}/approve", s.handleDialMembershipApprove).Methods("POST")
+
+	// Mark the user's membership in a dial, or all their memberships, as away.
+	r.HandleFunc("/dials/{id}/away", s.handleDialMembershipAway).Methods("POST")
+	r.HandleFunc("/away", s.handleAway).Methods("POST")
+
 	// Remove membership.
 	r.HandleFunc("/dial-memberships/{id}", s.handleDialMembershipDelete).Methods("DELETE")
+
+	// Value history reports for all active members or a single member of a dial.
+	r.HandleFunc("/dials/{id}/members/report", s.handleDialMembershipReports).Methods("GET")
+	r.HandleFunc("/dials/{id}/members/{userID}/report", s.handleDialMembershipReport).Methods("GET")
 }
 
 // handleDialMembershipNew handles the "GET /invite/:code" route. This route
@@ -45,24 +64,58 @@ func (s *Server) handleDialMembershipNew(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check if user is already a member. If so, redirect them to the dial's
-	// page automatically and add a flash message letting them know.
+	// page automatically and add a flash message letting them know. Pending
+	// members are shown that they are still waiting for approval.
 	if memberships, _, err := s.DialMembershipService.FindDialMemberships(r.Context(), wtf.DialMembershipFilter{
 		DialID: &dials[0].ID,
 		UserID: &userID,
 	}); err != nil {
 		Error(w, r, err)
 		return
+	} else if len(memberships) != 0 && memberships[0].IsPending() {
+		tmpl := html.DialMembershipCreateTemplate{Dial: dials[0], Membership: memberships[0]}
+		tmpl.Render(r.Context(), w)
+		return
 	} else if len(memberships) != 0 {
 		SetFlash(w, "You are already a member of this dial.")
 		http.Redirect(w, r, fmt.Sprintf("/dials/%d", memberships[0].DialID), http.StatusFound)
 		return
 	}
 
+	// Banned users are not shown the invitation.
+	if _, n, err := s.DialBanService.FindDialBans(r.Context(), wtf.DialBanFilter{
+		DialID: &dials[0].ID,
+		UserID: &userID,
+	}); err != nil {
+		Error(w, r, err)
+		return
+	} else if n != 0 {
+		Error(w, r, wtf.Errorf(wtf.EUNAUTHORIZED, "You have been banned from this dial."))
+		return
+	}
+
 	// Render HTML page asking user to confirm they want to join the dial.
 	tmpl := html.DialMembershipCreateTemplate{Dial: dials[0]}
 	tmpl.Render(r.Context(), w)
 }
 
+// renderDialMembershipPending renders the waiting page if the current user has
+// a pending membership on the dial. Returns false if nothing was rendered.
+func (s *Server) renderDialMembershipPending(w http.ResponseWriter, r *http.Request, dialID int) bool {
+	userID := wtf.UserIDFromContext(r.Context())
+	memberships, _, err := s.DialMembershipService.FindDialMemberships(r.Context(), wtf.DialMembershipFilter{
+		DialID: &dialID,
+		UserID: &userID,
+	})
+	if err != nil || len(memberships) == 0 || !memberships[0].IsPending() {
+		return false
+	}
+
+	tmpl := html.DialMembershipCreateTemplate{Dial: memberships[0].Dial, Membership: memberships[0]}
+	tmpl.Render(r.Context(), w)
+	return true
+}
+
 // handleDialMembershipCreate handles the "POST /invite/:code" route.
 // This route adds a new membership for the current user to a dial.
 func (s *Server) handleDialMembershipCreate(w http.ResponseWriter, r *http.Request) {
@@ -83,22 +136,66 @@ func (s *Server) handleDialMembershipCreate(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Create a new membership between the current user and the dial associated
-	// with the invite code.
+	// with the invite code. Members start at the bottom of the dial's scale.
 	membership := &wtf.DialMembership{
 		DialID: dials[0].ID,
 		UserID: userID,
+		Value:  dials[0].Scale.Min,
 	}
 	if err := s.DialMembershipService.CreateDialMembership(r.Context(), membership); err != nil {
 		Error(w, r, err)
 		return
 	}
 
-	// Let the user know they've joined the dial and then redirect them to the
-	// dial's page.
-	SetFlash(w, fmt.Sprintf("You have now joined the %q dial.", membership.Dial.Name))
+	// Let the user know they've joined the dial, or that they are waiting for
+	// approval, and then redirect them to the dial's page.
+	if membership.IsPending() {
+		SetFlash(w, fmt.Sprintf("Your request to join the %q dial is awaiting approval.", membership.Dial.Name))
+	} else {
+		SetFlash(w, fmt.Sprintf("You have now joined the %q dial.", membership.Dial.Name))
+	}
 	http.Redirect(w, r, fmt.Sprintf("/dials/%d", membership.DialID), http.StatusFound)
 }
 
+// handleDialMembershipIndex handles the "GET /dial-memberships" route. This
+// route is only available via the JSON API and accepts an optional filter.
+func (s *Server) handleDialMembershipIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse optional filter object from the JSON request body.
+	var filter wtf.DialMembershipFilter
+	if r.Header.Get("Content-type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	}
+
+	// Fetch memberships from the database.
+	memberships, n, err := s.DialMembershipService.FindDialMemberships(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write memberships & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialMembershipsResponse{
+		DialMemberships: memberships,
+		N:               n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialMembershipsResponse represents the output JSON struct for "GET /dial-memberships".
+type findDialMembershipsResponse struct {
+	DialMemberships []*wtf.DialMembership `json:"dialMemberships"`
+	N               int                   `json:"n"`
+}
+
 // handleDialMembershipUpdate handles the "PATCH /dial-memberships/:id" route.
 // This route is only called via JSON API on the dial view page.
 func (s *Server) handleDialMembershipUpdate(w http.ResponseWriter, r *http.Request) {
@@ -134,6 +231,157 @@ func (s *Server) handleDialMembershipUpdate(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// handleDialMembershipApprove handles the "POST /dial-memberships/:id/approve"
+// route. This route approves a pending membership and redirects the dial owner
+// back to the dial's page.
+func (s *Server) handleDialMembershipApprove(w http.ResponseWriter, r *http.Request) {
+	// Parse membership ID from the URL.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Approve the membership.
+	membership, err := s.DialMembershipService.ApproveDialMembership(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(membership); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s has been approved.", membership.User.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", membership.DialID), http.StatusFound)
+	}
+}
+
+// handleDialMembershipAway handles the "POST /dials/:id/away" route. It marks
+// the user's membership in the dial as away until the given time, or as
+// returned if no time is given, and redirects back to the dial's page.
+func (s *Server) handleDialMembershipAway(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the URL.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	until, err := parseAwayUntil(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Update the membership's away status.
+	membership, err := s.DialMembershipService.SetDialMembershipAway(r.Context(), id, until)
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(membership); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Internal errors & missing memberships display the standard error
+		// page. Otherwise the error is shown to the member on the dial's page.
+		if code := wtf.ErrorCode(err); code == wtf.EINTERNAL || code == wtf.ENOTFOUND {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			SetFlash(w, wtf.ErrorMessage(err))
+		} else if until.IsZero() {
+			SetFlash(w, "Welcome back! You are no longer away.")
+		} else {
+			SetFlash(w, fmt.Sprintf("You are away until %s.", until.In(wtf.UserFromContext(r.Context()).Location()).Format("Jan 2, 2006")))
+		}
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleAway handles the "POST /away" route. It marks all of the current
+// user's memberships as away until the given time, or as returned if no time
+// is given, and redirects back to the settings page.
+func (s *Server) handleAway(w http.ResponseWriter, r *http.Request) {
+	until, err := parseAwayUntil(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Update away status across all of the user's dials.
+	err = s.DialMembershipService.SetAway(r.Context(), until)
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		if wtf.ErrorCode(err) == wtf.EINTERNAL {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			SetFlash(w, wtf.ErrorMessage(err))
+		} else if until.IsZero() {
+			SetFlash(w, "Welcome back! You are no longer away on any dial.")
+		} else {
+			SetFlash(w, fmt.Sprintf("You are away on all your dials until %s.", until.In(wtf.UserFromContext(r.Context()).Location()).Format("Jan 2, 2006")))
+		}
+		http.Redirect(w, r, "/settings", http.StatusFound)
+	}
+}
+
+// setAwayRequest represents the JSON request body for the away routes.
+type setAwayRequest struct {
+	Until time.Time `json:"until"`
+}
+
+// parseAwayUntil reads the away time from a JSON request body or from the
+// "until" form field. Form values are dates which are interpreted as the start
+// of that day in the user's time zone. A blank value returns the zero time.
+func parseAwayUntil(r *http.Request) (time.Time, error) {
+	if r.Header.Get("Content-type") == "application/json" {
+		var req setAwayRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return time.Time{}, wtf.Errorf(wtf.EINVALID, "Invalid JSON body")
+		}
+		return req.Until, nil
+	}
+
+	v := r.PostFormValue("until")
+	if v == "" {
+		return time.Time{}, nil
+	}
+	until, err := time.ParseInLocation("2006-01-02", v, wtf.UserFromContext(r.Context()).Location())
+	if err != nil {
+		return time.Time{}, wtf.Errorf(wtf.EINVALID, "Invalid away date format")
+	}
+	return until, nil
+}
+
 // handleDialMembershipDelete handles the "DELETE /dial-memberships/:id" route.
 // This route deletes the given membership and redirects the user.
 func (s *Server) handleDialMembershipDelete(w http.ResponseWriter, r *http.Request) {
@@ -157,6 +405,13 @@ func (s *Server) handleDialMembershipDelete(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Write an empty response for JSON requests.
+	if r.Header.Get("Accept") == "application/json" {
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+		return
+	}
+
 	// Let user know the membership has been deleted.
 	SetFlash(w, "Dial membership successfully deleted.")
 
@@ -169,3 +424,352 @@ func (s *Server) handleDialMembershipDelete(w http.ResponseWriter, r *http.Reque
 		http.Redirect(w, r, "/dials", http.StatusFound)
 	}
 }
+
+// handleDialMembershipReport handles the "GET /dials/:id/members/:userID/report"
+// route. It returns the member's value history slotted into intervals.
+//
+// The range is set with the "start" & "end" query parameters in RFC 3339
+// format and the slot size with "interval" as a duration (e.g. "1h"). By
+// default the report covers the last seven days in one hour slots.
+//
+// The endpoint works with JSON & CSV formats.
+func (s *Server) handleDialMembershipReport(w http.ResponseWriter, r *http.Request) {
+	// Parse dial & user IDs from the path.
+	dialID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+	userID, err := strconv.Atoi(mux.Vars(r)["userID"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid user ID format"))
+		return
+	}
+
+	// Parse report range from the query parameters.
+	start, end, interval, err := parseDialMembershipReportRange(r.URL.Query())
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Generate the report.
+	report, err := s.DialMembershipService.MembershipValueReport(r.Context(), dialID, userID, start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header. Defaults to JSON.
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewDialValueRecordEncoder(w)
+		for _, record := range report.Records {
+			if err := enc.EncodeDialValueRecord(record); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			LogError(r, err)
+			return
+		}
+	}
+}
+
+// handleDialMembershipReports handles the "GET /dials/:id/members/report"
+// route. It returns the value history of every active member of the dial,
+// keyed by user ID. The range is set the same way as the single member
+// report. This route is only available via the JSON API.
+func (s *Server) handleDialMembershipReports(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	dialID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse report range from the query parameters.
+	start, end, interval, err := parseDialMembershipReportRange(r.URL.Query())
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Generate the reports.
+	reports, err := s.DialMembershipService.MembershipValueReports(r.Context(), dialID, start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(reports); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// parseDialMembershipReportRange parses the "start", "end" & "interval" query
+// parameters of a member report. By default the report covers the last seven
+// days in one hour slots.
+func parseDialMembershipReportRange(q url.Values) (start, end time.Time, interval time.Duration, err error) {
+	end = time.Now()
+	start, interval = end.Add(-7*24*time.Hour), time.Hour
+	if v := q.Get("start"); v != "" {
+		if start, err = time.Parse(time.RFC3339, v); err != nil {
+			return start, end, interval, wtf.Errorf(wtf.EINVALID, "Invalid start time format")
+		}
+	}
+	if v := q.Get("end"); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return start, end, interval, wtf.Errorf(wtf.EINVALID, "Invalid end time format")
+		}
+	}
+	if v := q.Get("interval"); v != "" {
+		if interval, err = time.ParseDuration(v); err != nil {
+			return start, end, interval, wtf.Errorf(wtf.EINVALID, "Invalid interval format")
+		}
+	}
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return start, end, interval, err
+	}
+	return start, end, interval, nil
+}
+
+// DialMembershipService implements the wtf.DialMembershipService over the HTTP protocol.
+type DialMembershipService struct {
+	Client *Client
+}
+
+// NewDialMembershipService returns a new instance of DialMembershipService.
+func NewDialMembershipService(client *Client) *DialMembershipService {
+	return &DialMembershipService{Client: client}
+}
+
+// FindDialMembershipByID is not implemented by the HTTP service.
+func (s *DialMembershipService) FindDialMembershipByID(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// FindDialMemberships retrieves a list of matching memberships based on filter.
+// Only returns memberships that belong to dials that the current user is a
+// member of. Also returns a count of total matching memberships which may
+// different if "Limit" is specified on the filter.
+func (s *DialMembershipService) FindDialMemberships(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+	// Marshal filter into JSON format.
+	body, err := json.Marshal(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/dial-memberships", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of memberships & total membership count.
+	var jsonResponse findDialMembershipsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialMemberships, jsonResponse.N, nil
+}
+
+// CreateDialMembership is not implemented by the HTTP service.
+func (s *DialMembershipService) CreateDialMembership(ctx context.Context, membership *wtf.DialMembership) error {
+	return wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// UpdateDialMembership is not implemented by the HTTP service.
+func (s *DialMembershipService) UpdateDialMembership(ctx context.Context, id int, upd wtf.DialMembershipUpdate) (*wtf.DialMembership, error) {
+	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// ApproveDialMembership approves a pending membership. Only the dial owner
+// can approve a membership. Returns ECONFLICT if the membership is not pending.
+func (s *DialMembershipService) ApproveDialMembership(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dial-memberships/%d/approve", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the approved membership.
+	var membership wtf.DialMembership
+	if err := json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// SetDialMembershipAway marks the current user's membership in a dial as away
+// until the given time. A zero time marks the member as returned.
+func (s *DialMembershipService) SetDialMembershipAway(ctx context.Context, dialID int, until time.Time) (*wtf.DialMembership, error) {
+	// Marshal away time into JSON format.
+	body, err := json.Marshal(setAwayRequest{Until: until})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/away", dialID), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the updated membership.
+	var membership wtf.DialMembership
+	if err := json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// SetAway marks all of the current user's memberships as away until the given
+// time. A zero time marks the user as returned on all their dials.
+func (s *DialMembershipService) SetAway(ctx context.Context, until time.Time) error {
+	// Marshal away time into JSON format.
+	body, err := json.Marshal(setAwayRequest{Until: until})
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", "/away", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// DeleteDialMembership permanently deletes a membership by ID. Only the
+// membership owner and the parent dial's owner can delete a membership.
+func (s *DialMembershipService) DeleteDialMembership(ctx context.Context, id int) error {
+	// Create a request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-memberships/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// MembershipValueReport returns a report of a single member's value on a dial
+// between start & end time, slotted into given intervals.
+func (s *DialMembershipService) MembershipValueReport(ctx context.Context, dialID, userID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	// Build query parameters for the report range.
+	q := url.Values{}
+	q.Set("start", start.Format(time.RFC3339))
+	q.Set("end", end.Format(time.RFC3339))
+	q.Set("interval", interval.String())
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/members/%d/report?%s", dialID, userID, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal report records.
+	var report wtf.DialValueReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// MembershipValueReports returns a report for every active member of a dial,
+// keyed by user ID.
+func (s *DialMembershipService) MembershipValueReports(ctx context.Context, dialID int, start, end time.Time, interval time.Duration) (map[int]*wtf.DialValueReport, error) {
+	// Build query parameters for the report range.
+	q := url.Values{}
+	q.Set("start", start.Format(time.RFC3339))
+	q.Set("end", end.Format(time.RFC3339))
+	q.Set("interval", interval.String())
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/members/report?%s", dialID, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal reports keyed by user ID.
+	var reports map[int]*wtf.DialValueReport
+	if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
@@ -0,0 +1,44 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Ensure the HTTP server returns the reports of all members in one request.
+func TestDialMembershipReports(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.AuthenticateAPIKeyFn = func(ctx context.Context, apiKey string) (*wtf.User, error) {
+		return user0, nil
+	}
+
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	reports := map[int]*wtf.DialValueReport{
+		1: {Records: []*wtf.DialValueRecord{{Value: 50, Min: 50, Max: 50, Avg: 50, Timestamp: start}}},
+		2: {Records: []*wtf.DialValueRecord{{Value: 20, Min: 20, Max: 20, Avg: 20, Timestamp: start}}},
+	}
+	s.DialMembershipService.MembershipValueReportsFn = func(ctx context.Context, dialID int, from, to time.Time, interval time.Duration) (map[int]*wtf.DialValueReport, error) {
+		if dialID != 100 {
+			t.Fatalf("unexpected dial id: %d", dialID)
+		} else if !from.Equal(start) || !to.Equal(start.Add(time.Hour)) || interval != time.Minute {
+			t.Fatalf("unexpected range: %s-%s/%s", from, to, interval)
+		}
+		return reports, nil
+	}
+
+	other, err := wtfhttp.NewDialMembershipService(wtfhttp.NewClient(s.URL())).MembershipValueReports(ctx0, 100, start, start.Add(time.Hour), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(other, reports); diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,416 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// dialPeriodTimeFormat is the format used by the period form's datetime inputs.
+const dialPeriodTimeFormat = "2006-01-02T15:04"
+
+// registerDialPeriodRoutes is a helper function for registering period routes.
+func (s *Server) registerDialPeriodRoutes(r *mux.Router) {
+	// List, compare & close periods on a dial.
+	r.HandleFunc("/dials/{id}/periods", s.handleDialPeriodIndex).Methods("GET")
+	r.HandleFunc("/dials/{id}/periods", s.handleDialPeriodCreate).Methods("POST")
+	r.HandleFunc("/dials/{id}/periods/compare", s.handleDialPeriodCompare).Methods("GET")
+
+	// View & delete a single period.
+	r.HandleFunc("/dial-periods/{id}", s.handleDialPeriodView).Methods("GET")
+	r.HandleFunc("/dial-periods/{id}", s.handleDialPeriodDelete).Methods("DELETE")
+}
+
+// handleDialPeriodIndex handles the "GET /dials/:id/periods" route. It lists
+// the closed periods of a dial, most recent first, as HTML, JSON, or CSV.
+func (s *Server) handleDialPeriodIndex(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch dial & its periods from the database.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	periods, n, err := s.DialPeriodService.FindDialPeriods(r.Context(), wtf.DialPeriodFilter{DialID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(findDialPeriodsResponse{
+			DialPeriods: periods,
+			N:           n,
+		}); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		if err := encodeDialPeriodsCSV(w, periods); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		tmpl := html.DialPeriodIndexTemplate{
+			Dial:     dial,
+			Periods:  periods,
+			N:        n,
+			Location: userLocation(r.Context()),
+			Now:      time.Now(),
+		}
+		tmpl.Render(r.Context(), w)
+	}
+}
+
+// findDialPeriodsResponse represents the output JSON struct for "GET /dials/:id/periods".
+type findDialPeriodsResponse struct {
+	DialPeriods []*wtf.DialPeriod `json:"dialPeriods"`
+	N           int               `json:"n"`
+}
+
+// handleDialPeriodCompare handles the "GET /dials/:id/periods/compare" route.
+// It shows the periods selected with the "id" query parameter side by side.
+func (s *Server) handleDialPeriodCompare(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse the selected period IDs from the query.
+	ids := make([]int, 0)
+	for _, v := range r.URL.Query()["id"] {
+		periodID, err := strconv.Atoi(v)
+		if err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid period ID format"))
+			return
+		}
+		ids = append(ids, periodID)
+	}
+	if len(ids) == 0 {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Select at least one period to compare."))
+		return
+	}
+
+	// Fetch dial & the selected periods from the database.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	periods, n, err := s.DialPeriodService.FindDialPeriods(r.Context(), wtf.DialPeriodFilter{DialID: &id, IDs: ids})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(findDialPeriodsResponse{
+			DialPeriods: periods,
+			N:           n,
+		}); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		if err := encodeDialPeriodsCSV(w, periods); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		tmpl := html.DialPeriodCompareTemplate{Dial: dial, Periods: periods}
+		tmpl.Render(r.Context(), w)
+	}
+}
+
+// handleDialPeriodCreate handles the "POST /dials/:id/periods" route. It
+// closes a period on the dial & stores its summary.
+func (s *Server) handleDialPeriodCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var period wtf.DialPeriod
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&period); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		// Form times are entered in the user's time zone.
+		loc := userLocation(r.Context())
+
+		period.Name = r.PostFormValue("name")
+		if period.StartAt, err = time.ParseInLocation(dialPeriodTimeFormat, r.PostFormValue("start_at"), loc); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid start time format"))
+			return
+		} else if period.EndAt, err = time.ParseInLocation(dialPeriodTimeFormat, r.PostFormValue("end_at"), loc); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid end time format"))
+			return
+		} else if period.Threshold, err = strconv.Atoi(r.PostFormValue("threshold")); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid threshold format"))
+			return
+		}
+	}
+	period.DialID = id
+
+	// Close the period in the database.
+	if err := s.DialPeriodService.CloseDialPeriod(r.Context(), &period); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write new period to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(period); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s closed.", period.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d/periods/compare?id=%d", id, period.ID), http.StatusFound)
+	}
+}
+
+// handleDialPeriodView handles the "GET /dial-periods/:id" route. This route
+// is only available via the JSON API. The HTML view of a period is the
+// comparison page with a single period selected.
+func (s *Server) handleDialPeriodView(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse period ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch period from the database.
+	period, err := s.DialPeriodService.FindDialPeriodByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(period); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// handleDialPeriodDelete handles the "DELETE /dial-periods/:id" route. This
+// route permanently removes a closed period.
+func (s *Server) handleDialPeriodDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse period ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch period first so we know which dial to redirect to.
+	period, err := s.DialPeriodService.FindDialPeriodByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if err := s.DialPeriodService.DeleteDialPeriod(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, fmt.Sprintf("%s deleted.", period.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d/periods", period.DialID), http.StatusFound)
+	}
+}
+
+// encodeDialPeriodsCSV writes a list of periods to w in CSV format.
+func encodeDialPeriodsCSV(w http.ResponseWriter, periods []*wtf.DialPeriod) error {
+	enc := csv.NewDialPeriodEncoder(w)
+	for _, period := range periods {
+		if err := enc.EncodeDialPeriod(period); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// userLocation returns the time zone of the current user. Defaults to UTC.
+func userLocation(ctx context.Context) *time.Location {
+	if user := wtf.UserFromContext(ctx); user != nil {
+		return user.Location()
+	}
+	return time.UTC
+}
+
+// DialPeriodService implements the wtf.DialPeriodService over the HTTP protocol.
+type DialPeriodService struct {
+	Client *Client
+}
+
+// NewDialPeriodService returns a new instance of DialPeriodService.
+func NewDialPeriodService(client *Client) *DialPeriodService {
+	return &DialPeriodService{Client: client}
+}
+
+// FindDialPeriodByID retrieves a single period by ID.
+func (s *DialPeriodService) FindDialPeriodByID(ctx context.Context, id int) (*wtf.DialPeriod, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dial-periods/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the period.
+	var period wtf.DialPeriod
+	if err := json.NewDecoder(resp.Body).Decode(&period); err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+// FindDialPeriods retrieves the closed periods of a dial. The filter must
+// specify a DialID as periods are listed per-dial over HTTP.
+func (s *DialPeriodService) FindDialPeriods(ctx context.Context, filter wtf.DialPeriodFilter) ([]*wtf.DialPeriod, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Select the comparison route when specific periods are requested.
+	path := fmt.Sprintf("/dials/%d/periods", *filter.DialID)
+	if len(filter.IDs) > 0 {
+		path += "/compare?"
+		for i, id := range filter.IDs {
+			if i > 0 {
+				path += "&"
+			}
+			path += "id=" + strconv.Itoa(id)
+		}
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of periods & total count.
+	var jsonResponse findDialPeriodsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialPeriods, jsonResponse.N, nil
+}
+
+// CloseDialPeriod closes a period on a dial.
+func (s *DialPeriodService) CloseDialPeriod(ctx context.Context, period *wtf.DialPeriod) error {
+	// Marshal period into JSON format.
+	body, err := json.Marshal(period)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/periods", period.DialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned period data.
+	if err := json.NewDecoder(resp.Body).Decode(&period); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteDialPeriod permanently removes a closed period.
+func (s *DialPeriodService) DeleteDialPeriod(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-periods/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,651 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// registerDialReminderRoutes is a helper function for registering check-in
+// schedule routes.
+func (s *Server) registerDialReminderRoutes(r *mux.Router) {
+	// List & create schedules on a dial.
+	r.HandleFunc("/dials/{id}/reminder-schedules", s.handleDialReminderScheduleIndex).Methods("GET")
+	r.HandleFunc("/dials/{id}/reminder-schedules", s.handleDialReminderScheduleCreate).Methods("POST")
+	r.HandleFunc("/dials/{id}/reminder-schedules/new", s.handleDialReminderScheduleNew).Methods("GET")
+
+	// List the reminders sent for a dial's schedules.
+	r.HandleFunc("/dials/{id}/reminders", s.handleDialReminderIndex).Methods("GET")
+
+	// View, edit & delete a single schedule.
+	r.HandleFunc("/dial-reminder-schedules/{id}", s.handleDialReminderScheduleView).Methods("GET")
+	r.HandleFunc("/dial-reminder-schedules/{id}", s.handleDialReminderScheduleUpdate).Methods("PATCH")
+	r.HandleFunc("/dial-reminder-schedules/{id}", s.handleDialReminderScheduleDelete).Methods("DELETE")
+	r.HandleFunc("/dial-reminder-schedules/{id}/edit", s.handleDialReminderScheduleEdit).Methods("GET")
+}
+
+// handleDialReminderScheduleIndex handles the "GET /dials/:id/reminder-schedules"
+// route. This route is only available via the JSON API. The HTML schedule list
+// is shown on the dial page.
+func (s *Server) handleDialReminderScheduleIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Ensure the dial exists & the user can view it.
+	if _, err := s.DialService.FindDialByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch schedules from the database.
+	schedules, n, err := s.DialReminderService.FindDialReminderSchedules(r.Context(), wtf.DialReminderScheduleFilter{DialID: &id})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write schedules & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialReminderSchedulesResponse{
+		DialReminderSchedules: schedules,
+		N:                     n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialReminderSchedulesResponse represents the output JSON struct for "GET /dials/:id/reminder-schedules".
+type findDialReminderSchedulesResponse struct {
+	DialReminderSchedules []*wtf.DialReminderSchedule `json:"dialReminderSchedules"`
+	N                     int                         `json:"n"`
+}
+
+// handleDialReminderScheduleNew handles the "GET /dials/:id/reminder-schedules/new"
+// route. It renders an HTML form for editing a new schedule. The schedule
+// defaults to weekday mornings in the owner's time zone.
+func (s *Server) handleDialReminderScheduleNew(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch dial so the form can link back to it.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	tmpl := html.DialReminderScheduleEditTemplate{
+		Dial: dial,
+		Schedule: &wtf.DialReminderSchedule{
+			DialID:      id,
+			Weekdays:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Hour:        10,
+			Timezone:    wtf.UserFromContext(r.Context()).Location().String(),
+			NotifyInApp: true,
+		},
+	}
+	tmpl.Render(r.Context(), w)
+}
+
+// handleDialReminderScheduleCreate handles the "POST /dials/:id/reminder-schedules"
+// route. It reads & writes data using with HTML or JSON.
+func (s *Server) handleDialReminderScheduleCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var schedule wtf.DialReminderSchedule
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		if err := parseDialReminderScheduleForm(r, &schedule); err != nil {
+			Error(w, r, err)
+			return
+		}
+	}
+	schedule.DialID = id
+
+	// Create schedule in the database.
+	err = s.DialReminderService.CreateDialReminderSchedule(r.Context(), &schedule)
+
+	// Write new schedule to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(schedule); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Display validation errors on the form with the user's data.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			s.renderDialReminderScheduleEdit(w, r, &schedule, err)
+			return
+		}
+
+		SetFlash(w, "Check-in schedule successfully created.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleDialReminderScheduleView handles the "GET /dial-reminder-schedules/:id"
+// route. This route is only available via the JSON API.
+func (s *Server) handleDialReminderScheduleView(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse schedule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch schedule from the database.
+	schedule, err := s.DialReminderService.FindDialReminderScheduleByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(schedule); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// handleDialReminderScheduleEdit handles the "GET /dial-reminder-schedules/:id/edit"
+// route. It renders an HTML form for editing an existing schedule.
+func (s *Server) handleDialReminderScheduleEdit(w http.ResponseWriter, r *http.Request) {
+	// Parse schedule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch schedule from the database.
+	schedule, err := s.DialReminderService.FindDialReminderScheduleByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	s.renderDialReminderScheduleEdit(w, r, schedule, nil)
+}
+
+// handleDialReminderScheduleUpdate handles the "PATCH /dial-reminder-schedules/:id"
+// route. It reads & writes data using with HTML or JSON.
+func (s *Server) handleDialReminderScheduleUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse schedule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse fields into an update object. The HTML form always sends every
+	// field so the whole schedule is replaced.
+	var upd wtf.DialReminderScheduleUpdate
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		var schedule wtf.DialReminderSchedule
+		if err := parseDialReminderScheduleForm(r, &schedule); err != nil {
+			Error(w, r, err)
+			return
+		}
+		upd = wtf.DialReminderScheduleUpdate{
+			Weekdays:    &schedule.Weekdays,
+			Hour:        &schedule.Hour,
+			Minute:      &schedule.Minute,
+			Timezone:    &schedule.Timezone,
+			NotifyInApp: &schedule.NotifyInApp,
+			NotifyEmail: &schedule.NotifyEmail,
+			WebhookURL:  &schedule.WebhookURL,
+		}
+	}
+
+	// Update the schedule in the database.
+	schedule, err := s.DialReminderService.UpdateDialReminderSchedule(r.Context(), id, upd)
+
+	// Write updated schedule to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(schedule); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Display validation errors on the form with the user's data.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL || schedule == nil {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			s.renderDialReminderScheduleEdit(w, r, schedule, err)
+			return
+		}
+
+		SetFlash(w, "Check-in schedule successfully updated.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", schedule.DialID), http.StatusFound)
+	}
+}
+
+// handleDialReminderScheduleDelete handles the "DELETE /dial-reminder-schedules/:id"
+// route. This route permanently deletes the schedule & its reminder history.
+func (s *Server) handleDialReminderScheduleDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse schedule ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch schedule first so we know which dial to redirect to.
+	schedule, err := s.DialReminderService.FindDialReminderScheduleByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if err := s.DialReminderService.DeleteDialReminderSchedule(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, "Check-in schedule successfully deleted.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", schedule.DialID), http.StatusFound)
+	}
+}
+
+// renderDialReminderScheduleEdit renders the schedule form along with the
+// schedule's dial.
+func (s *Server) renderDialReminderScheduleEdit(w http.ResponseWriter, r *http.Request, schedule *wtf.DialReminderSchedule, err error) {
+	dial, e := s.DialService.FindDialByID(r.Context(), schedule.DialID)
+	if e != nil {
+		Error(w, r, e)
+		return
+	}
+
+	tmpl := html.DialReminderScheduleEditTemplate{Dial: dial, Schedule: schedule, Err: err}
+	tmpl.Render(r.Context(), w)
+}
+
+// parseDialReminderScheduleForm reads the fields of the schedule form into
+// schedule. Check-in days are sent as one "weekday" field per checked day &
+// the time of day is sent in "HH:MM" format.
+func parseDialReminderScheduleForm(r *http.Request, schedule *wtf.DialReminderSchedule) error {
+	if err := r.ParseForm(); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid form")
+	}
+
+	schedule.Weekdays = []time.Weekday{}
+	for _, v := range r.PostForm["weekday"] {
+		weekday, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid check-in day format")
+		}
+		schedule.Weekdays = append(schedule.Weekdays, time.Weekday(weekday))
+	}
+
+	t, err := time.Parse("15:04", r.PostFormValue("time"))
+	if err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid check-in time format")
+	}
+	schedule.Hour, schedule.Minute = t.Hour(), t.Minute()
+
+	schedule.Timezone = r.PostFormValue("timezone")
+	schedule.NotifyInApp = r.PostFormValue("notify_in_app") == "true"
+	schedule.NotifyEmail = r.PostFormValue("notify_email") == "true"
+	schedule.WebhookURL = r.PostFormValue("webhook_url")
+	return nil
+}
+
+// handleDialReminderIndex handles the "GET /dials/:id/reminders" route. This
+// route is only available via the JSON API. Recent reminders are also shown on
+// the dial page.
+func (s *Server) handleDialReminderIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Parse optional pagination, schedule & status filters.
+	filter := wtf.DialReminderFilter{DialID: &id, Limit: 20}
+	filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	if v := r.URL.Query().Get("schedule_id"); v != "" {
+		scheduleID, err := strconv.Atoi(v)
+		if err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid schedule ID format"))
+			return
+		}
+		filter.ScheduleID = &scheduleID
+	}
+	if v := r.URL.Query().Get("status"); v != "" {
+		filter.Status = &v
+	}
+
+	// Ensure the dial exists & the user can view it.
+	if _, err := s.DialService.FindDialByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch reminders from the database.
+	reminders, n, err := s.DialReminderService.FindDialReminders(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write reminders & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialRemindersResponse{
+		DialReminders: reminders,
+		N:             n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialRemindersResponse represents the output JSON struct for "GET /dials/:id/reminders".
+type findDialRemindersResponse struct {
+	DialReminders []*wtf.DialReminder `json:"dialReminders"`
+	N             int                 `json:"n"`
+}
+
+// DialReminderService implements the wtf.DialReminderService over the HTTP protocol.
+type DialReminderService struct {
+	Client *Client
+}
+
+// NewDialReminderService returns a new instance of DialReminderService.
+func NewDialReminderService(client *Client) *DialReminderService {
+	return &DialReminderService{Client: client}
+}
+
+// FindDialReminderScheduleByID retrieves a single schedule by ID.
+func (s *DialReminderService) FindDialReminderScheduleByID(ctx context.Context, id int) (*wtf.DialReminderSchedule, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dial-reminder-schedules/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var schedule wtf.DialReminderSchedule
+	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindDialReminderSchedules retrieves the schedules on a dial. The filter must
+// specify a DialID as schedules are listed per-dial over HTTP.
+func (s *DialReminderService) FindDialReminderSchedules(ctx context.Context, filter wtf.DialReminderScheduleFilter) ([]*wtf.DialReminderSchedule, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/reminder-schedules", *filter.DialID), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of schedules & total count.
+	var jsonResponse findDialReminderSchedulesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialReminderSchedules, jsonResponse.N, nil
+}
+
+// CreateDialReminderSchedule creates a new schedule on a dial.
+func (s *DialReminderService) CreateDialReminderSchedule(ctx context.Context, schedule *wtf.DialReminderSchedule) error {
+	// Marshal schedule into JSON format.
+	body, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/reminder-schedules", schedule.DialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned schedule data into the caller's object.
+	if err := json.NewDecoder(resp.Body).Decode(schedule); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDialReminderSchedule updates the fields of an existing schedule.
+func (s *DialReminderService) UpdateDialReminderSchedule(ctx context.Context, id int, upd wtf.DialReminderScheduleUpdate) (*wtf.DialReminderSchedule, error) {
+	// Marshal update into JSON format.
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/dial-reminder-schedules/%d", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var schedule wtf.DialReminderSchedule
+	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// DeleteDialReminderSchedule permanently deletes a schedule & its reminder history.
+func (s *DialReminderService) DeleteDialReminderSchedule(ctx context.Context, id int) error {
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-reminder-schedules/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// FindDialReminders retrieves the reminders sent for a dial. The filter must
+// specify a DialID as reminders are listed per-dial over HTTP.
+func (s *DialReminderService) FindDialReminders(ctx context.Context, filter wtf.DialReminderFilter) ([]*wtf.DialReminder, int, error) {
+	if filter.DialID == nil {
+		return nil, 0, wtf.Errorf(wtf.EINVALID, "Dial ID required.")
+	}
+
+	// Build query parameters for pagination, schedule & status.
+	q := url.Values{}
+	q.Set("offset", strconv.Itoa(filter.Offset))
+	if filter.ScheduleID != nil {
+		q.Set("schedule_id", strconv.Itoa(*filter.ScheduleID))
+	}
+	if filter.Status != nil {
+		q.Set("status", *filter.Status)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/reminders?%s", *filter.DialID, q.Encode()), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of reminders & total count.
+	var jsonResponse findDialRemindersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialReminders, jsonResponse.N, nil
+}
+
+// Ensure type implements interface.
+var _ wtf.DialReminderNotifier = (*DialReminderWebhookNotifier)(nil)
+
+// DialReminderWebhookNotifier delivers check-in reminders by POSTing a
+// "dial:checkin_reminder" event as JSON to the schedule's webhook URL.
+type DialReminderWebhookNotifier struct {
+	// Client used to deliver webhooks. Defaults to a client which only
+	// connects to public addresses.
+	Client *http.Client
+}
+
+// NewDialReminderWebhookNotifier returns a new instance of DialReminderWebhookNotifier.
+func NewDialReminderWebhookNotifier() *DialReminderWebhookNotifier {
+	return &DialReminderWebhookNotifier{
+		Client: NewWebhookClient(),
+	}
+}
+
+// NotifyDialReminder POSTs the reminder to the schedule's webhook URL. The
+// payload includes the reminded member so the receiver can mention them. Any
+// non-2xx status code is considered an error.
+func (n *DialReminderWebhookNotifier) NotifyDialReminder(ctx context.Context, reminder *wtf.DialReminder) error {
+	return postWebhook(ctx, n.Client, reminder.Schedule.WebhookURL, dialReminderWebhookEvent{
+		Type: wtf.EventTypeDialCheckInReminder,
+		Payload: dialReminderWebhookPayload{
+			DialCheckInReminderPayload: wtf.DialCheckInReminderPayload{
+				ID:         reminder.ID,
+				ScheduleID: reminder.ScheduleID,
+				DialID:     reminder.DialID,
+				DialName:   reminder.Dial.Name,
+				CheckInAt:  reminder.CheckInAt,
+			},
+			UserID:   reminder.UserID,
+			UserName: reminder.User.Name,
+		},
+	})
+}
+
+// dialReminderWebhookEvent represents the JSON body POSTed to reminder webhooks.
+type dialReminderWebhookEvent struct {
+	Type    string                     `json:"type"`
+	Payload dialReminderWebhookPayload `json:"payload"`
+}
+
+// dialReminderWebhookPayload extends the in-app reminder payload with the
+// member who was reminded.
+type dialReminderWebhookPayload struct {
+	wtf.DialCheckInReminderPayload
+	UserID   int    `json:"userID"`
+	UserName string `json:"userName"`
+}
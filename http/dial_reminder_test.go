@@ -0,0 +1,64 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+func TestDialReminderWebhookNotifier_NotifyDialReminder(t *testing.T) {
+	// Ensure the reminder is POSTed as a JSON event naming the member.
+	t.Run("OK", func(t *testing.T) {
+		var event struct {
+			Type    string `json:"type"`
+			Payload struct {
+				DialID   int    `json:"dialID"`
+				UserID   int    `json:"userID"`
+				UserName string `json:"userName"`
+			} `json:"payload"`
+		}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+				t.Fatal(err)
+			}
+		}))
+		defer ts.Close()
+
+		// The test server listens on loopback so use an unrestricted client.
+		n := wtfhttp.NewDialReminderWebhookNotifier()
+		n.Client = ts.Client()
+		if err := n.NotifyDialReminder(context.Background(), &wtf.DialReminder{
+			DialID:    1,
+			Dial:      &wtf.Dial{Name: "DIAL"},
+			UserID:    2,
+			User:      &wtf.User{Name: "susy"},
+			Schedule:  &wtf.DialReminderSchedule{WebhookURL: ts.URL},
+			CheckInAt: time.Date(2000, time.January, 3, 17, 0, 0, 0, time.UTC),
+		}); err != nil {
+			t.Fatal(err)
+		} else if got, want := event.Type, wtf.EventTypeDialCheckInReminder; got != want {
+			t.Fatalf("Type=%v, want %v", got, want)
+		} else if event.Payload.DialID != 1 || event.Payload.UserID != 2 || event.Payload.UserName != "susy" {
+			t.Fatalf("unexpected payload: %#v", event.Payload)
+		}
+	})
+
+	// Ensure webhooks are not delivered to non-public addresses by default.
+	t.Run("ErrAddressNotAllowed", func(t *testing.T) {
+		n := wtfhttp.NewDialReminderWebhookNotifier()
+		if err := n.NotifyDialReminder(context.Background(), &wtf.DialReminder{
+			Dial:     &wtf.Dial{},
+			User:     &wtf.User{},
+			Schedule: &wtf.DialReminderSchedule{WebhookURL: "http://169.254.169.254/latest/meta-data/"},
+		}); err == nil || !strings.Contains(err.Error(), "webhook address not allowed") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
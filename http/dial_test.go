@@ -71,11 +71,11 @@ func TestDialIndex(t *testing.T) {
 	// Ensure server can generate JSON output.
 	t.Run("JSON", func(t *testing.T) {
 		// Mock user look up by API key for API calls.
-		s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
-			if filter.APIKey == nil || *filter.APIKey != "APIKEY" {
-				t.Fatalf("unexpected api key: %#v", filter.APIKey)
+		s.UserService.AuthenticateAPIKeyFn = func(ctx context.Context, apiKey string) (*wtf.User, error) {
+			if apiKey != "APIKEY" {
+				t.Fatalf("unexpected api key: %q", apiKey)
 			}
-			return []*wtf.User{user0}, 1, nil
+			return user0, nil
 		}
 
 		// Instantiate HTTP service and fetch dials.
@@ -91,3 +91,32 @@ func TestDialIndex(t *testing.T) {
 		}
 	})
 }
+
+// Ensure the HTTP server rejects heatmap ranges longer than the maximum
+// before generating the heatmap.
+func TestDialHeatmap_ErrInvalid(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.DialService.AverageDialValueHeatmapFn = func(ctx context.Context, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+		t.Fatal("unexpected heatmap generation")
+		return nil, nil
+	}
+
+	req := s.MustNewRequest(t, ctx0, "GET", "/dials/heatmap?range=custom&start=2000-01-01T00:00:00Z&end=2020-01-01T00:00:00Z", nil)
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+		t.Fatalf("StatusCode=%v, want %v", got, want)
+	}
+}
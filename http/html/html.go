@@ -8,6 +8,8 @@ import (
 	"io"
 	"io/fs"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http/assets"
@@ -117,7 +119,18 @@ type WTFBadge struct {
 	DialID           int
 	DialMembershipID int
 
+	// Set for the value of a dial dimension. If DialMembershipID is also set
+	// then the badge is the member's value for the dimension.
+	DialDimensionID int
+
+	// Set for the value of a child dial shown on its parent dial.
+	DialChildID int
+
 	Value int
+
+	// Scale of the dial the value belongs to. The badge is colored by the
+	// scale's band containing the value, if any. Defaults to a 0-100 scale.
+	Scale *wtf.DialScale
 }
 
 func (r *WTFBadge) Render(ctx context.Context, w io.Writer) {
@@ -125,14 +138,15 @@ func (r *WTFBadge) Render(ctx context.Context, w io.Writer) {
 	if HasTheme {
 		prefix = "badge-soft-"
 	}
+	scale := scaleOrDefault(r.Scale)
 
 	var class string
-	switch {
-	case r.Value < 25:
+	switch f := scale.Fraction(r.Value); {
+	case f < 0.25:
 		class = prefix + "success"
-	case r.Value < 50:
+	case f < 0.5:
 		class = prefix + "info"
-	case r.Value < 75:
+	case f < 0.75:
 		class = prefix + "warning"
 	default:
 		class = prefix + "danger"
@@ -140,12 +154,24 @@ func (r *WTFBadge) Render(ctx context.Context, w io.Writer) {
 
 	fmt.Fprintf(w, `<span`)
 	fmt.Fprintf(w, ` class="wtf-badge wtf-value badge rounded-pill %s"`, class)
+	if band := scale.Band(r.Value); band != nil {
+		fmt.Fprintf(w, ` style="background-color: %s; color: #fff" title="%s"`, html.EscapeString(band.Color), html.EscapeString(band.Label))
+	}
 	if r.DialID != 0 {
 		fmt.Fprintf(w, ` data-dial-id="%d"`, r.DialID)
 	}
 	if r.DialMembershipID != 0 {
 		fmt.Fprintf(w, ` data-dial-membership-id="%d"`, r.DialMembershipID)
 	}
+	if r.DialDimensionID != 0 {
+		fmt.Fprintf(w, ` data-dial-dimension-id="%d"`, r.DialDimensionID)
+	}
+	if r.DialChildID != 0 {
+		fmt.Fprintf(w, ` data-dial-child-id="%d"`, r.DialChildID)
+	}
+	if buf, err := json.Marshal(scale); err == nil {
+		fmt.Fprintf(w, ` data-scale="%s"`, html.EscapeString(string(buf)))
+	}
 	fmt.Fprint(w, `>`)
 
 	fmt.Fprint(w, r.Value)
@@ -153,6 +179,139 @@ func (r *WTFBadge) Render(ctx context.Context, w io.Writer) {
 	fmt.Fprint(w, `</span>`)
 }
 
+// Sparkline displays a small inline SVG line chart of report values. Values
+// are plotted on the full range of the dial's scale so sparklines on the same
+// dial are comparable.
+type Sparkline struct {
+	Report *wtf.DialValueReport
+
+	// Scale of the dial the report belongs to. Defaults to a 0-100 scale.
+	Scale *wtf.DialScale
+
+	// Optional link to the full report.
+	URL string
+}
+
+func (r *Sparkline) Render(ctx context.Context, w io.Writer) {
+	if r.Report == nil || len(r.Report.Records) < 2 {
+		return
+	}
+
+	const width, height = 80, 20
+	scale := scaleOrDefault(r.Scale)
+
+	if r.URL != "" {
+		fmt.Fprintf(w, `<a href="%s" title="View history">`, html.EscapeString(r.URL))
+	}
+	fmt.Fprintf(w, `<svg class="wtf-sparkline ml-2 align-middle" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprint(w, `<polyline fill="none" stroke="currentColor" stroke-width="1.5" points="`)
+	for i, record := range r.Report.Records {
+		x := float64(i) * width / float64(len(r.Report.Records)-1)
+		y := height - 1 - scale.Fraction(record.Value)*(height-2)
+		fmt.Fprintf(w, `%.1f,%.1f `, x, y)
+	}
+	fmt.Fprint(w, `"/>`)
+	fmt.Fprint(w, `</svg>`)
+	if r.URL != "" {
+		fmt.Fprint(w, `</a>`)
+	}
+}
+
+// Heatmap displays an inline SVG grid of the average value for each hour of
+// each day of the week. Cells are shaded across the dial's scale so that higher
+// values are darker & hours without any history are left blank.
+type Heatmap struct {
+	Heatmap *wtf.DialValueHeatmap
+
+	// Scale of the dial the heatmap belongs to. Defaults to a 0-100 scale.
+	Scale *wtf.DialScale
+}
+
+func (r *Heatmap) Render(ctx context.Context, w io.Writer) {
+	if r.Heatmap == nil {
+		return
+	}
+
+	const cell, gap, left, top = 16, 2, 32, 16
+	const width, height = left + 24*(cell+gap), top + 7*(cell+gap)
+	scale := scaleOrDefault(r.Scale)
+
+	fmt.Fprintf(w, `<svg class="wtf-heatmap" width="100%%" viewBox="0 0 %d %d" style="max-width: %dpx">`, width, height, width)
+
+	// Label every third hour along the top & each weekday down the side.
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(w, `<text x="%d" y="%d" font-size="10" fill="currentColor">%02d</text>`, left+hour*(cell+gap), top-4, hour)
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		fmt.Fprintf(w, `<text x="0" y="%d" font-size="10" fill="currentColor">%s</text>`, top+int(weekday)*(cell+gap)+cell-4, weekday.String()[:3])
+	}
+
+	for _, c := range r.Heatmap.Cells {
+		x, y := left+c.Hour*(cell+gap), top+int(c.Weekday)*(cell+gap)
+		if c.N == 0 {
+			fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="rgba(0,0,0,0.05)"><title>%s %02d:00 &ndash; no history</title></rect>`, x, y, cell, cell, c.Weekday, c.Hour)
+			continue
+		}
+		opacity := 0.1 + 0.9*scale.Fraction(c.Value)
+		fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="rgba(44,123,229,%.2f)"><title>%s %02d:00 &ndash; %d</title></rect>`, x, y, cell, cell, opacity, c.Weekday, c.Hour, c.Value)
+	}
+	fmt.Fprint(w, `</svg>`)
+}
+
+// scaleOrDefault returns scale, or the default dial scale if scale is nil.
+func scaleOrDefault(scale *wtf.DialScale) *wtf.DialScale {
+	if scale == nil {
+		other := wtf.DefaultDialScale()
+		return &other
+	}
+	return scale
+}
+
+// formatThreshold formats an anomaly threshold for a form input. Unset
+// thresholds on new dials display the default.
+func formatThreshold(v, defaultValue float64) string {
+	if v == 0 {
+		v = defaultValue
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatDays returns v, or defaultValue if v is unset.
+func formatDays(v, defaultValue int) int {
+	if v == 0 {
+		return defaultValue
+	}
+	return v
+}
+
+// formatResetInterval returns a description of a reset interval, such as
+// "every day" or "every 2 weeks".
+func formatResetInterval(days int) string {
+	switch {
+	case days == 1:
+		return "every day"
+	case days == 7:
+		return "every week"
+	case days%7 == 0:
+		return fmt.Sprintf("every %d weeks", days/7)
+	default:
+		return fmt.Sprintf("every %d days", days)
+	}
+}
+
+// formatMinutes returns a short description of a number of minutes, such as
+// "3h 15m".
+func formatMinutes(minutes int) string {
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
+
 func marshalJSONTo(w io.Writer, v interface{}) {
 	json.NewEncoder(w).Encode(v)
 }
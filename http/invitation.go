@@ -0,0 +1,369 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/gorilla/mux"
+)
+
+// registerInvitationRoutes is a helper function to register routes to a router.
+func (s *Server) registerInvitationRoutes(r *mux.Router) {
+	// API endpoint for listing invitations.
+	r.HandleFunc("/invitations", s.handleInvitationIndex).Methods("GET")
+
+	// Invite a user to a dial by email or GitHub account.
+	r.HandleFunc("/dials/{id}/invitations", s.handleInvitationCreate).Methods("POST")
+
+	// Respond to an invitation.
+	r.HandleFunc("/invitations/{id}/accept", s.handleInvitationAccept).Methods("POST")
+	r.HandleFunc("/invitations/{id}/decline", s.handleInvitationDecline).Methods("POST")
+
+	// Revoke an invitation.
+	r.HandleFunc("/invitations/{id}", s.handleInvitationDelete).Methods("DELETE")
+}
+
+// handleInvitationIndex handles the "GET /invitations" route. This route is
+// only available via the JSON API and accepts an optional filter.
+func (s *Server) handleInvitationIndex(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	// Parse optional filter object from the JSON request body.
+	var filter wtf.InvitationFilter
+	if r.Header.Get("Content-type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	}
+
+	// Fetch invitations from the database.
+	invitations, n, err := s.InvitationService.FindInvitations(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write invitations & total count as JSON response.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findInvitationsResponse{
+		Invitations: invitations,
+		N:           n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findInvitationsResponse represents the output JSON struct for "GET /invitations".
+type findInvitationsResponse struct {
+	Invitations []*wtf.Invitation `json:"invitations"`
+	N           int               `json:"n"`
+}
+
+// handleInvitationCreate handles the "POST /dials/:id/invitations" route. The
+// invitee is identified by either an email address or a GitHub username.
+func (s *Server) handleInvitationCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Unmarshal data based on HTTP request's content type.
+	var invitation wtf.Invitation
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&invitation); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		// The HTML form has a single field which is treated as an email
+		// address if it contains an "@" and as a GitHub user otherwise.
+		if v := strings.TrimSpace(r.PostFormValue("invitee")); strings.Contains(v, "@") {
+			invitation.Email = v
+		} else {
+			invitation.GitHubLogin = v
+		}
+	}
+	invitation.DialID = id
+
+	// Create invitation in the database.
+	err = s.InvitationService.CreateInvitation(r.Context(), &invitation)
+
+	// Write new invitation to response based on accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(invitation); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		// Internal errors display the standard error page. Otherwise the
+		// error is shown to the owner back on the dial's page.
+		if wtf.ErrorCode(err) == wtf.EINTERNAL {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			SetFlash(w, wtf.ErrorMessage(err))
+			http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+			return
+		}
+
+		SetFlash(w, fmt.Sprintf("%s has been invited to the dial.", invitation.Address()))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+	}
+}
+
+// handleInvitationAccept handles the "POST /invitations/:id/accept" route.
+// On success, the user is redirected to the dial they have joined.
+func (s *Server) handleInvitationAccept(w http.ResponseWriter, r *http.Request) {
+	// Parse invitation ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Accept the invitation which creates the membership.
+	membership, err := s.InvitationService.AcceptInvitation(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(membership); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		SetFlash(w, fmt.Sprintf("You have now joined the %q dial.", membership.Dial.Name))
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", membership.DialID), http.StatusFound)
+	}
+}
+
+// handleInvitationDecline handles the "POST /invitations/:id/decline" route.
+// On success, the user is redirected back to the dashboard.
+func (s *Server) handleInvitationDecline(w http.ResponseWriter, r *http.Request) {
+	// Parse invitation ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	if err := s.InvitationService.DeclineInvitation(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, "Invitation declined.")
+		http.Redirect(w, r, "/", http.StatusFound)
+	}
+}
+
+// handleInvitationDelete handles the "DELETE /invitations/:id" route. This
+// route permanently revokes an invitation.
+func (s *Server) handleInvitationDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse invitation ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Fetch invitation first so we know which dial to redirect to.
+	invitation, err := s.InvitationService.FindInvitationByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if err := s.InvitationService.DeleteInvitation(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, "Invitation successfully revoked.")
+		http.Redirect(w, r, fmt.Sprintf("/dials/%d", invitation.DialID), http.StatusFound)
+	}
+}
+
+// InvitationService implements the wtf.InvitationService over the HTTP protocol.
+type InvitationService struct {
+	Client *Client
+}
+
+// NewInvitationService returns a new instance of InvitationService.
+func NewInvitationService(client *Client) *InvitationService {
+	return &InvitationService{Client: client}
+}
+
+// FindInvitationByID is not implemented by the HTTP service.
+func (s *InvitationService) FindInvitationByID(ctx context.Context, id int) (*wtf.Invitation, error) {
+	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// FindInvitations retrieves a list of invitations sent or received by the
+// current user. Also returns a count of total matching invitations which may
+// differ if filter.Limit is set.
+func (s *InvitationService) FindInvitations(ctx context.Context, filter wtf.InvitationFilter) ([]*wtf.Invitation, int, error) {
+	// Marshal filter into JSON format.
+	body, err := json.Marshal(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/invitations", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of invitations & total count.
+	var jsonResponse findInvitationsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.Invitations, jsonResponse.N, nil
+}
+
+// CreateInvitation invites a user to a dial by email or GitHub ID.
+// On success, the invitation.ID is set to the new invitation's ID.
+func (s *InvitationService) CreateInvitation(ctx context.Context, invitation *wtf.Invitation) error {
+	// Marshal invitation into JSON format.
+	body, err := json.Marshal(invitation)
+	if err != nil {
+		return err
+	}
+
+	// Create request with API key attached.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/dials/%d/invitations", invitation.DialID), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request to server. Any non-201 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned invitation data.
+	if err := json.NewDecoder(resp.Body).Decode(&invitation); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AcceptInvitation accepts a pending invitation and returns the new membership.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/invitations/%d/accept", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the new membership.
+	var membership wtf.DialMembership
+	if err := json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// DeclineInvitation declines a pending invitation.
+func (s *InvitationService) DeclineInvitation(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/invitations/%d/decline", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// DeleteInvitation permanently revokes an invitation. Only the inviter can
+// delete an invitation.
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/invitations/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -65,10 +65,19 @@ type Server struct {
 	GitHubClientSecret string
 
 	// Servics used by the various HTTP routes.
+	AuditService          wtf.AuditService
 	AuthService           wtf.AuthService
 	DialService           wtf.DialService
+	DialAlertService      wtf.DialAlertService
+	DialAnomalyService    wtf.DialAnomalyService
+	DialBanService        wtf.DialBanService
+	DialChildService      wtf.DialChildService
+	DialGoalService       wtf.DialGoalService
 	DialMembershipService wtf.DialMembershipService
+	DialPeriodService     wtf.DialPeriodService
+	DialReminderService   wtf.DialReminderService
 	EventService          wtf.EventService
+	InvitationService     wtf.InvitationService
 	UserService           wtf.UserService
 }
 
@@ -120,9 +129,19 @@ func NewServer() *Server {
 		r := router.PathPrefix("/").Subrouter()
 		r.Use(s.requireAuth)
 		r.HandleFunc("/settings", s.handleSettings).Methods("GET")
+		r.HandleFunc("/settings", s.handleSettingsUpdate).Methods("PATCH")
 		s.registerDialRoutes(r)
 		s.registerDialMembershipRoutes(r)
+		s.registerDialBanRoutes(r)
+		s.registerDialChildRoutes(r)
+		s.registerDialAnomalyRoutes(r)
+		s.registerDialAlertRoutes(r)
+		s.registerDialReminderRoutes(r)
+		s.registerDialPeriodRoutes(r)
+		s.registerDialGoalRoutes(r)
 		s.registerEventRoutes(r)
+		s.registerInvitationRoutes(r)
+		s.registerAuditRoutes(r)
 	}
 
 	return s
@@ -276,25 +295,26 @@ func (s *Server) authenticate(next http.Handler) http.Handler {
 		if v := r.Header.Get("Authorization"); strings.HasPrefix(v, "Bearer ") {
 			apiKey := strings.TrimPrefix(v, "Bearer ")
 
-			// Lookup user by API key. Display error if not found.
-			// Otherwise set
-			users, _, err := s.UserService.FindUsers(r.Context(), wtf.UserFilter{APIKey: &apiKey})
+			// Lookup user by API key & record its use. Display error if not found.
+			user, err := s.UserService.AuthenticateAPIKey(r.Context(), apiKey)
 			if err != nil {
 				Error(w, r, err)
 				return
-			} else if len(users) == 0 {
-				Error(w, r, wtf.Errorf(wtf.EUNAUTHORIZED, "Invalid API key."))
-				return
 			}
 
-			// Update request context to include authenticated user.
-			r = r.WithContext(wtf.NewContextWithUser(r.Context(), users[0]))
+			// Update request context to include authenticated user. Changes
+			// made through this request are attributed to the API key.
+			ctx := wtf.NewContextWithUser(r.Context(), user)
+			r = r.WithContext(wtf.NewContextWithAuditSource(ctx, wtf.AuditSourceAPIKey))
 
 			// Delegate to next HTTP handler.
 			next.ServeHTTP(w, r)
 			return
 		}
 
+		// All other requests come from the browser.
+		r = r.WithContext(wtf.NewContextWithAuditSource(r.Context(), wtf.AuditSourceWeb))
+
 		// Read session from secure cookie.
 		session, _ := s.session(r)
 
@@ -436,13 +456,24 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	var err error
 	var tmpl html.IndexTemplate
 
+	// Fetch pending invitations the user has received which have not expired.
+	userID, status, expired := wtf.UserIDFromContext(r.Context()), wtf.InvitationStatusPending, false
+	if tmpl.Invitations, _, err = s.InvitationService.FindInvitations(r.Context(), wtf.InvitationFilter{
+		InviteeID: &userID,
+		Status:    &status,
+		Expired:   &expired,
+	}); err != nil {
+		Error(w, r, err)
+		return
+	}
+
 	// Fetch all dials the user is a member of.
-	// If user is not a member of any dials, redirect to dial list which
-	// includes a description of how to start.
+	// If user is not a member of any dials & has no invitations, redirect to
+	// dial list which includes a description of how to start.
 	if tmpl.Dials, _, err = s.DialService.FindDials(r.Context(), wtf.DialFilter{}); err != nil {
 		Error(w, r, err)
 		return
-	} else if len(tmpl.Dials) == 0 {
+	} else if len(tmpl.Dials) == 0 && len(tmpl.Invitations) == 0 {
 		http.Redirect(w, r, "/dials", http.StatusFound)
 		return
 	}
@@ -475,6 +506,28 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 	tmpl.Render(r.Context(), w)
 }
 
+// handleSettingsUpdate handles the "PATCH /settings" route. It updates the
+// current user's preferences & redirects back to the settings page.
+func (s *Server) handleSettingsUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse fields into an update object.
+	var upd wtf.UserUpdate
+	timezone := strings.TrimSpace(r.PostFormValue("timezone"))
+	upd.Timezone = &timezone
+
+	// Update the user in the database.
+	if _, err := s.UserService.UpdateUser(r.Context(), wtf.UserIDFromContext(r.Context()), upd); wtf.ErrorCode(err) == wtf.EINTERNAL {
+		Error(w, r, err)
+		return
+	} else if err != nil {
+		tmpl := html.SettingsTemplate{Timezone: timezone, Err: err}
+		tmpl.Render(r.Context(), w)
+		return
+	}
+
+	SetFlash(w, "Settings successfully updated.")
+	http.Redirect(w, r, "/settings", http.StatusFound)
+}
+
 // handleVersion displays the deployed version.
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
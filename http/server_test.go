@@ -25,10 +25,13 @@ type Server struct {
 	*wtfhttp.Server
 
 	// Mock services.
+	AuditService          mock.AuditService
 	AuthService           mock.AuthService
 	DialService           mock.DialService
+	DialBanService        mock.DialBanService
 	DialMembershipService mock.DialMembershipService
 	EventService          mock.EventService
+	InvitationService     mock.InvitationService
 	UserService           mock.UserService
 }
 
@@ -45,10 +48,13 @@ func MustOpenServer(tb testing.TB) *Server {
 	s.GitHubClientSecret = TestGitHubClientSecret
 
 	// Assign mocks to actual server's services.
+	s.Server.AuditService = &s.AuditService
 	s.Server.AuthService = &s.AuthService
 	s.Server.DialService = &s.DialService
+	s.Server.DialBanService = &s.DialBanService
 	s.Server.DialMembershipService = &s.DialMembershipService
 	s.Server.EventService = &s.EventService
+	s.Server.InvitationService = &s.InvitationService
 	s.Server.UserService = &s.UserService
 
 	// Begin running test server.
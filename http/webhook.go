@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// DefaultWebhookTimeout is the default time allowed to deliver a webhook.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// NewWebhookClient returns an HTTP client for delivering webhooks to
+// user-provided URLs. Connections are only made to public addresses. The
+// check runs after DNS resolution & on every redirect so hostnames cannot be
+// used to reach the loopback, private or link-local networks.
+func NewWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: DefaultWebhookTimeout,
+		Control: webhookDialControl,
+	}
+	return &http.Client{
+		Timeout: DefaultWebhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: DefaultWebhookTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// webhookDialControl rejects connections to non-public addresses. It is
+// called with the resolved IP address just before each connection is made.
+func webhookDialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("webhook address not allowed: %s", host)
+	}
+	return nil
+}
+
+// nonPublicNetworks are the address ranges that webhooks cannot be sent to.
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"10.0.0.0/8",     // private
+	"100.64.0.0/10",  // carrier-grade NAT
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local, including cloud metadata services
+	"172.16.0.0/12",  // private
+	"192.0.0.0/24",   // IETF protocol assignments
+	"192.168.0.0/16", // private
+	"198.18.0.0/15",  // benchmarking
+	"224.0.0.0/4",    // multicast
+	"240.0.0.0/4",    // reserved & broadcast
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"64:ff9b::/96",   // IPv4/IPv6 translation
+	"fc00::/7",       // unique local
+	"fe80::/10",      // link-local
+	"ff00::/8",       // multicast
+)
+
+// isPublicIP returns true if ip is not within any of the non-public ranges.
+// IPv4-mapped IPv6 addresses are checked as IPv4 addresses.
+func isPublicIP(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	for _, n := range nonPublicNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// mustParseCIDRs parses a list of CIDR ranges. Panics on error.
+func mustParseCIDRs(a ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(a))
+	for i, s := range a {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = n
+	}
+	return networks
+}
+
+// postWebhook POSTs v as JSON to a webhook URL using client. Any non-2xx
+// status code is considered an error.
+func postWebhook(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("User-Agent", "wtf-dial")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
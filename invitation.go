@@ -0,0 +1,148 @@
+package wtf
+
+import (
+	"context"
+	"time"
+)
+
+// Invitation constants.
+const (
+	// InvitationTTL is the amount of time an invitation can be accepted after
+	// it has been created.
+	InvitationTTL = 7 * 24 * time.Hour
+)
+
+// Invitation status values.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+)
+
+// Invitation represents a direct invitation from a dial owner to a specific
+// person. Unlike the shareable invite link, an invitation targets a single
+// email address or GitHub account. If no user has that address yet, the
+// invitation is delivered when someone signs in with it.
+//
+// Invitees see their pending invitations on their dashboard and can accept or
+// decline them. Accepting an invitation creates a DialMembership. Invitations
+// expire after InvitationTTL and can no longer be accepted.
+type Invitation struct {
+	ID int `json:"id"`
+
+	// Dial the user is being invited to.
+	DialID int   `json:"dialID"`
+	Dial   *Dial `json:"dial"`
+
+	// User who sent the invitation. This is always the dial owner.
+	InviterID int   `json:"inviterID"`
+	Inviter   *User `json:"inviter"`
+
+	// User who received the invitation. This is resolved from either Email
+	// or GitHubLogin & is zero until a user with that address signs in. It is
+	// only visible to the inviter once the invitation has been accepted so
+	// that invitations cannot be used to discover accounts.
+	InviteeID int   `json:"inviteeID"`
+	Invitee   *User `json:"invitee"`
+
+	// Identifier used to look up the invitee. Only one should be set.
+	// GitHubLogin is matched against the Auth.SourceLogin of the user's
+	// GitHub auth so the user must have signed in at least once.
+	Email       string `json:"email,omitempty"`
+	GitHubLogin string `json:"githubLogin,omitempty"`
+
+	// Current state of the invitation. See InvitationStatus constants.
+	Status string `json:"status"`
+
+	// Time after which the invitation can no longer be accepted.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Timestamps for invitation creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if invitation fields are invalid.
+// Only performs basic validation.
+func (i *Invitation) Validate() error {
+	if i.DialID == 0 {
+		return Errorf(EINVALID, "Dial required for invitation.")
+	} else if i.Email == "" && i.GitHubLogin == "" {
+		return Errorf(EINVALID, "Email or GitHub user required for invitation.")
+	} else if i.Email != "" && i.GitHubLogin != "" {
+		return Errorf(EINVALID, "Only one of email or GitHub user allowed for invitation.")
+	}
+	return nil
+}
+
+// Address returns the email address or GitHub login the invitation was sent to.
+func (i *Invitation) Address() string {
+	if i.Email != "" {
+		return i.Email
+	}
+	return i.GitHubLogin
+}
+
+// IsPending returns true if the invitation has not been accepted or declined.
+func (i *Invitation) IsPending() bool {
+	return i.Status == InvitationStatusPending
+}
+
+// IsExpired returns true if the invitation can no longer be accepted at t.
+func (i *Invitation) IsExpired(t time.Time) bool {
+	return !t.Before(i.ExpiresAt)
+}
+
+// CanRespondToInvitation returns true if the current user can accept or
+// decline the invitation. Only the invitee can respond.
+func CanRespondToInvitation(ctx context.Context, invitation *Invitation) bool {
+	return invitation.InviteeID != 0 && invitation.InviteeID == UserIDFromContext(ctx)
+}
+
+// InvitationService represents a service for managing dial invitations.
+type InvitationService interface {
+	// Retrieves a single invitation by ID along with the associated dial and
+	// users. Only the inviter & invitee can view an invitation. Returns
+	// ENOTFOUND if invitation does not exist or user does not have permission.
+	FindInvitationByID(ctx context.Context, id int) (*Invitation, error)
+
+	// Retrieves a list of invitations based on a filter. Only returns
+	// invitations the current user sent or received. Also returns a count of
+	// total matching invitations which may differ if filter.Limit is set.
+	FindInvitations(ctx context.Context, filter InvitationFilter) ([]*Invitation, int, error)
+
+	// Creates a new invitation from the current user. The invitee is looked up
+	// by Email or GitHubLogin. The invitation is created whether or not a user
+	// has that address so the result does not reveal which accounts exist.
+	// Only the dial owner can invite users. Returns ECONFLICT if the invitee is
+	// already a member or already has a pending invitation.
+	CreateInvitation(ctx context.Context, invitation *Invitation) error
+
+	// Accepts a pending invitation and creates a membership for the current
+	// user. A membership awaiting approval is approved instead. Returns
+	// EUNAUTHORIZED if the current user is not the invitee.
+	// Returns ECONFLICT if the invitation is expired or no longer pending.
+	AcceptInvitation(ctx context.Context, id int) (*DialMembership, error)
+
+	// Declines a pending invitation. Returns EUNAUTHORIZED if the current user
+	// is not the invitee. Returns ECONFLICT if the invitation is not pending.
+	DeclineInvitation(ctx context.Context, id int) error
+
+	// Permanently removes an invitation. Only the inviter can delete it.
+	DeleteInvitation(ctx context.Context, id int) error
+}
+
+// InvitationFilter represents a filter used by FindInvitations().
+type InvitationFilter struct {
+	ID        *int    `json:"id"`
+	DialID    *int    `json:"dialID"`
+	InviteeID *int    `json:"inviteeID"`
+	Status    *string `json:"status"`
+
+	// If set, restricts results to invitations which have or have not expired.
+	Expired *bool `json:"expired"`
+
+	// Restricts results to a subset of the total range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.AuditService = (*AuditService)(nil)
+
+type AuditService struct {
+	FindAuditEntriesFn func(ctx context.Context, filter wtf.AuditEntryFilter) ([]*wtf.AuditEntry, int, error)
+}
+
+func (s *AuditService) FindAuditEntries(ctx context.Context, filter wtf.AuditEntryFilter) ([]*wtf.AuditEntry, int, error) {
+	return s.FindAuditEntriesFn(ctx, filter)
+}
@@ -11,13 +11,21 @@ var _ wtf.DialService = (*DialService)(nil)
 
 // DialService represents a mock of wtf.DialService.
 type DialService struct {
-	FindDialByIDFn           func(ctx context.Context, id int) (*wtf.Dial, error)
-	FindDialsFn              func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error)
-	CreateDialFn             func(ctx context.Context, dial *wtf.Dial) error
-	UpdateDialFn             func(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error)
-	DeleteDialFn             func(ctx context.Context, id int) error
-	SetDialMembershipValueFn func(ctx context.Context, dialID, value int) error
-	AverageDialValueReportFn func(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error)
+	FindDialByIDFn                     func(ctx context.Context, id int) (*wtf.Dial, error)
+	FindDialsFn                        func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error)
+	CreateDialFn                       func(ctx context.Context, dial *wtf.Dial) error
+	CloneDialFn                        func(ctx context.Context, id int, clone wtf.DialClone) (*wtf.Dial, error)
+	UpdateDialFn                       func(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error)
+	ArchiveDialFn                      func(ctx context.Context, id int) (*wtf.Dial, error)
+	RestoreDialFn                      func(ctx context.Context, id int) (*wtf.Dial, error)
+	DeleteDialFn                       func(ctx context.Context, id int) error
+	SetDialMembershipValueFn           func(ctx context.Context, dialID, value int, note string) error
+	SetDialMembershipDimensionValuesFn func(ctx context.Context, dialID int, values map[string]int, note string) error
+	AverageDialValueReportFn           func(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error)
+	DialValueReportFn                  func(ctx context.Context, id int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error)
+	DialDimensionValueReportFn         func(ctx context.Context, id, dimensionID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error)
+	AverageDialValueHeatmapFn          func(ctx context.Context, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error)
+	DialValueHeatmapFn                 func(ctx context.Context, id int, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error)
 }
 
 func (s *DialService) FindDialByID(ctx context.Context, id int) (*wtf.Dial, error) {
@@ -32,18 +40,50 @@ func (s *DialService) CreateDial(ctx context.Context, dial *wtf.Dial) error {
 	return s.CreateDialFn(ctx, dial)
 }
 
+func (s *DialService) CloneDial(ctx context.Context, id int, clone wtf.DialClone) (*wtf.Dial, error) {
+	return s.CloneDialFn(ctx, id, clone)
+}
+
 func (s *DialService) UpdateDial(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error) {
 	return s.UpdateDialFn(ctx, id, upd)
 }
 
+func (s *DialService) ArchiveDial(ctx context.Context, id int) (*wtf.Dial, error) {
+	return s.ArchiveDialFn(ctx, id)
+}
+
+func (s *DialService) RestoreDial(ctx context.Context, id int) (*wtf.Dial, error) {
+	return s.RestoreDialFn(ctx, id)
+}
+
 func (s *DialService) DeleteDial(ctx context.Context, id int) error {
 	return s.DeleteDialFn(ctx, id)
 }
 
-func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int) error {
-	return s.SetDialMembershipValueFn(ctx, dialID, value)
+func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int, note string) error {
+	return s.SetDialMembershipValueFn(ctx, dialID, value, note)
+}
+
+func (s *DialService) SetDialMembershipDimensionValues(ctx context.Context, dialID int, values map[string]int, note string) error {
+	return s.SetDialMembershipDimensionValuesFn(ctx, dialID, values, note)
 }
 
 func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
 	return s.AverageDialValueReportFn(ctx, start, end, interval)
 }
+
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	return s.DialValueReportFn(ctx, id, start, end, interval)
+}
+
+func (s *DialService) DialDimensionValueReport(ctx context.Context, id, dimensionID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	return s.DialDimensionValueReportFn(ctx, id, dimensionID, start, end, interval)
+}
+
+func (s *DialService) AverageDialValueHeatmap(ctx context.Context, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	return s.AverageDialValueHeatmapFn(ctx, start, end, loc)
+}
+
+func (s *DialService) DialValueHeatmap(ctx context.Context, id int, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	return s.DialValueHeatmapFn(ctx, id, start, end, loc)
+}
@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialAlertService = (*DialAlertService)(nil)
+
+type DialAlertService struct {
+	FindDialAlertRuleByIDFn func(ctx context.Context, id int) (*wtf.DialAlertRule, error)
+	FindDialAlertRulesFn    func(ctx context.Context, filter wtf.DialAlertRuleFilter) ([]*wtf.DialAlertRule, int, error)
+	CreateDialAlertRuleFn   func(ctx context.Context, rule *wtf.DialAlertRule) error
+	UpdateDialAlertRuleFn   func(ctx context.Context, id int, upd wtf.DialAlertRuleUpdate) (*wtf.DialAlertRule, error)
+	DeleteDialAlertRuleFn   func(ctx context.Context, id int) error
+	FindDialAlertFiringsFn  func(ctx context.Context, filter wtf.DialAlertFiringFilter) ([]*wtf.DialAlertFiring, int, error)
+}
+
+func (s *DialAlertService) FindDialAlertRuleByID(ctx context.Context, id int) (*wtf.DialAlertRule, error) {
+	return s.FindDialAlertRuleByIDFn(ctx, id)
+}
+
+func (s *DialAlertService) FindDialAlertRules(ctx context.Context, filter wtf.DialAlertRuleFilter) ([]*wtf.DialAlertRule, int, error) {
+	return s.FindDialAlertRulesFn(ctx, filter)
+}
+
+func (s *DialAlertService) CreateDialAlertRule(ctx context.Context, rule *wtf.DialAlertRule) error {
+	return s.CreateDialAlertRuleFn(ctx, rule)
+}
+
+func (s *DialAlertService) UpdateDialAlertRule(ctx context.Context, id int, upd wtf.DialAlertRuleUpdate) (*wtf.DialAlertRule, error) {
+	return s.UpdateDialAlertRuleFn(ctx, id, upd)
+}
+
+func (s *DialAlertService) DeleteDialAlertRule(ctx context.Context, id int) error {
+	return s.DeleteDialAlertRuleFn(ctx, id)
+}
+
+func (s *DialAlertService) FindDialAlertFirings(ctx context.Context, filter wtf.DialAlertFiringFilter) ([]*wtf.DialAlertFiring, int, error) {
+	return s.FindDialAlertFiringsFn(ctx, filter)
+}
+
+var _ wtf.DialAlertNotifier = (*DialAlertNotifier)(nil)
+
+type DialAlertNotifier struct {
+	NotifyDialAlertFn func(ctx context.Context, firing *wtf.DialAlertFiring) error
+}
+
+func (n *DialAlertNotifier) NotifyDialAlert(ctx context.Context, firing *wtf.DialAlertFiring) error {
+	return n.NotifyDialAlertFn(ctx, firing)
+}
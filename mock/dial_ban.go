@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialBanService = (*DialBanService)(nil)
+
+type DialBanService struct {
+	FindDialBansFn  func(ctx context.Context, filter wtf.DialBanFilter) ([]*wtf.DialBan, int, error)
+	CreateDialBanFn func(ctx context.Context, ban *wtf.DialBan) error
+	DeleteDialBanFn func(ctx context.Context, id int) error
+}
+
+func (s *DialBanService) FindDialBans(ctx context.Context, filter wtf.DialBanFilter) ([]*wtf.DialBan, int, error) {
+	return s.FindDialBansFn(ctx, filter)
+}
+
+func (s *DialBanService) CreateDialBan(ctx context.Context, ban *wtf.DialBan) error {
+	return s.CreateDialBanFn(ctx, ban)
+}
+
+func (s *DialBanService) DeleteDialBan(ctx context.Context, id int) error {
+	return s.DeleteDialBanFn(ctx, id)
+}
@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialChildService = (*DialChildService)(nil)
+
+type DialChildService struct {
+	FindDialChildByIDFn func(ctx context.Context, id int) (*wtf.DialChild, error)
+	FindDialChildrenFn  func(ctx context.Context, filter wtf.DialChildFilter) ([]*wtf.DialChild, int, error)
+	CreateDialChildFn   func(ctx context.Context, child *wtf.DialChild) error
+	UpdateDialChildFn   func(ctx context.Context, id int, upd wtf.DialChildUpdate) (*wtf.DialChild, error)
+	DeleteDialChildFn   func(ctx context.Context, id int) error
+}
+
+func (s *DialChildService) FindDialChildByID(ctx context.Context, id int) (*wtf.DialChild, error) {
+	return s.FindDialChildByIDFn(ctx, id)
+}
+
+func (s *DialChildService) FindDialChildren(ctx context.Context, filter wtf.DialChildFilter) ([]*wtf.DialChild, int, error) {
+	return s.FindDialChildrenFn(ctx, filter)
+}
+
+func (s *DialChildService) CreateDialChild(ctx context.Context, child *wtf.DialChild) error {
+	return s.CreateDialChildFn(ctx, child)
+}
+
+func (s *DialChildService) UpdateDialChild(ctx context.Context, id int, upd wtf.DialChildUpdate) (*wtf.DialChild, error) {
+	return s.UpdateDialChildFn(ctx, id, upd)
+}
+
+func (s *DialChildService) DeleteDialChild(ctx context.Context, id int) error {
+	return s.DeleteDialChildFn(ctx, id)
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialGoalService = (*DialGoalService)(nil)
+
+type DialGoalService struct {
+	FindDialGoalByIDFn func(ctx context.Context, id int) (*wtf.DialGoal, error)
+	FindDialGoalsFn    func(ctx context.Context, filter wtf.DialGoalFilter) ([]*wtf.DialGoal, int, error)
+	CreateDialGoalFn   func(ctx context.Context, goal *wtf.DialGoal) error
+	UpdateDialGoalFn   func(ctx context.Context, id int, upd wtf.DialGoalUpdate) (*wtf.DialGoal, error)
+	DeleteDialGoalFn   func(ctx context.Context, id int) error
+	DialGoalReportFn   func(ctx context.Context, id, windows int) ([]*wtf.DialGoalStatus, error)
+}
+
+func (s *DialGoalService) FindDialGoalByID(ctx context.Context, id int) (*wtf.DialGoal, error) {
+	return s.FindDialGoalByIDFn(ctx, id)
+}
+
+func (s *DialGoalService) FindDialGoals(ctx context.Context, filter wtf.DialGoalFilter) ([]*wtf.DialGoal, int, error) {
+	return s.FindDialGoalsFn(ctx, filter)
+}
+
+func (s *DialGoalService) CreateDialGoal(ctx context.Context, goal *wtf.DialGoal) error {
+	return s.CreateDialGoalFn(ctx, goal)
+}
+
+func (s *DialGoalService) UpdateDialGoal(ctx context.Context, id int, upd wtf.DialGoalUpdate) (*wtf.DialGoal, error) {
+	return s.UpdateDialGoalFn(ctx, id, upd)
+}
+
+func (s *DialGoalService) DeleteDialGoal(ctx context.Context, id int) error {
+	return s.DeleteDialGoalFn(ctx, id)
+}
+
+func (s *DialGoalService) DialGoalReport(ctx context.Context, id, windows int) ([]*wtf.DialGoalStatus, error) {
+	return s.DialGoalReportFn(ctx, id, windows)
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/benbjohnson/wtf"
 )
@@ -13,7 +14,12 @@ type DialMembershipService struct {
 	FindDialMembershipsFn    func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error)
 	CreateDialMembershipFn   func(ctx context.Context, membership *wtf.DialMembership) error
 	UpdateDialMembershipFn   func(ctx context.Context, id int, upd wtf.DialMembershipUpdate) (*wtf.DialMembership, error)
+	ApproveDialMembershipFn  func(ctx context.Context, id int) (*wtf.DialMembership, error)
+	SetDialMembershipAwayFn  func(ctx context.Context, dialID int, until time.Time) (*wtf.DialMembership, error)
+	SetAwayFn                func(ctx context.Context, until time.Time) error
 	DeleteDialMembershipFn   func(ctx context.Context, id int) error
+	MembershipValueReportFn  func(ctx context.Context, dialID, userID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error)
+	MembershipValueReportsFn func(ctx context.Context, dialID int, start, end time.Time, interval time.Duration) (map[int]*wtf.DialValueReport, error)
 }
 
 func (s *DialMembershipService) FindDialMembershipByID(ctx context.Context, id int) (*wtf.DialMembership, error) {
@@ -32,6 +38,26 @@ func (s *DialMembershipService) UpdateDialMembership(ctx context.Context, id int
 	return s.UpdateDialMembershipFn(ctx, id, upd)
 }
 
+func (s *DialMembershipService) ApproveDialMembership(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	return s.ApproveDialMembershipFn(ctx, id)
+}
+
+func (s *DialMembershipService) SetDialMembershipAway(ctx context.Context, dialID int, until time.Time) (*wtf.DialMembership, error) {
+	return s.SetDialMembershipAwayFn(ctx, dialID, until)
+}
+
+func (s *DialMembershipService) SetAway(ctx context.Context, until time.Time) error {
+	return s.SetAwayFn(ctx, until)
+}
+
 func (s *DialMembershipService) DeleteDialMembership(ctx context.Context, id int) error {
 	return s.DeleteDialMembershipFn(ctx, id)
 }
+
+func (s *DialMembershipService) MembershipValueReport(ctx context.Context, dialID, userID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	return s.MembershipValueReportFn(ctx, dialID, userID, start, end, interval)
+}
+
+func (s *DialMembershipService) MembershipValueReports(ctx context.Context, dialID int, start, end time.Time, interval time.Duration) (map[int]*wtf.DialValueReport, error) {
+	return s.MembershipValueReportsFn(ctx, dialID, start, end, interval)
+}
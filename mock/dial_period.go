@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialPeriodService = (*DialPeriodService)(nil)
+
+type DialPeriodService struct {
+	FindDialPeriodByIDFn func(ctx context.Context, id int) (*wtf.DialPeriod, error)
+	FindDialPeriodsFn    func(ctx context.Context, filter wtf.DialPeriodFilter) ([]*wtf.DialPeriod, int, error)
+	CloseDialPeriodFn    func(ctx context.Context, period *wtf.DialPeriod) error
+	DeleteDialPeriodFn   func(ctx context.Context, id int) error
+}
+
+func (s *DialPeriodService) FindDialPeriodByID(ctx context.Context, id int) (*wtf.DialPeriod, error) {
+	return s.FindDialPeriodByIDFn(ctx, id)
+}
+
+func (s *DialPeriodService) FindDialPeriods(ctx context.Context, filter wtf.DialPeriodFilter) ([]*wtf.DialPeriod, int, error) {
+	return s.FindDialPeriodsFn(ctx, filter)
+}
+
+func (s *DialPeriodService) CloseDialPeriod(ctx context.Context, period *wtf.DialPeriod) error {
+	return s.CloseDialPeriodFn(ctx, period)
+}
+
+func (s *DialPeriodService) DeleteDialPeriod(ctx context.Context, id int) error {
+	return s.DeleteDialPeriodFn(ctx, id)
+}
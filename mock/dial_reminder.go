@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialReminderService = (*DialReminderService)(nil)
+
+type DialReminderService struct {
+	FindDialReminderScheduleByIDFn func(ctx context.Context, id int) (*wtf.DialReminderSchedule, error)
+	FindDialReminderSchedulesFn    func(ctx context.Context, filter wtf.DialReminderScheduleFilter) ([]*wtf.DialReminderSchedule, int, error)
+	CreateDialReminderScheduleFn   func(ctx context.Context, schedule *wtf.DialReminderSchedule) error
+	UpdateDialReminderScheduleFn   func(ctx context.Context, id int, upd wtf.DialReminderScheduleUpdate) (*wtf.DialReminderSchedule, error)
+	DeleteDialReminderScheduleFn   func(ctx context.Context, id int) error
+	FindDialRemindersFn            func(ctx context.Context, filter wtf.DialReminderFilter) ([]*wtf.DialReminder, int, error)
+}
+
+func (s *DialReminderService) FindDialReminderScheduleByID(ctx context.Context, id int) (*wtf.DialReminderSchedule, error) {
+	return s.FindDialReminderScheduleByIDFn(ctx, id)
+}
+
+func (s *DialReminderService) FindDialReminderSchedules(ctx context.Context, filter wtf.DialReminderScheduleFilter) ([]*wtf.DialReminderSchedule, int, error) {
+	return s.FindDialReminderSchedulesFn(ctx, filter)
+}
+
+func (s *DialReminderService) CreateDialReminderSchedule(ctx context.Context, schedule *wtf.DialReminderSchedule) error {
+	return s.CreateDialReminderScheduleFn(ctx, schedule)
+}
+
+func (s *DialReminderService) UpdateDialReminderSchedule(ctx context.Context, id int, upd wtf.DialReminderScheduleUpdate) (*wtf.DialReminderSchedule, error) {
+	return s.UpdateDialReminderScheduleFn(ctx, id, upd)
+}
+
+func (s *DialReminderService) DeleteDialReminderSchedule(ctx context.Context, id int) error {
+	return s.DeleteDialReminderScheduleFn(ctx, id)
+}
+
+func (s *DialReminderService) FindDialReminders(ctx context.Context, filter wtf.DialReminderFilter) ([]*wtf.DialReminder, int, error) {
+	return s.FindDialRemindersFn(ctx, filter)
+}
+
+var _ wtf.DialReminderNotifier = (*DialReminderNotifier)(nil)
+
+type DialReminderNotifier struct {
+	NotifyDialReminderFn func(ctx context.Context, reminder *wtf.DialReminder) error
+}
+
+func (n *DialReminderNotifier) NotifyDialReminder(ctx context.Context, reminder *wtf.DialReminder) error {
+	return n.NotifyDialReminderFn(ctx, reminder)
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.InvitationService = (*InvitationService)(nil)
+
+type InvitationService struct {
+	FindInvitationByIDFn func(ctx context.Context, id int) (*wtf.Invitation, error)
+	FindInvitationsFn    func(ctx context.Context, filter wtf.InvitationFilter) ([]*wtf.Invitation, int, error)
+	CreateInvitationFn   func(ctx context.Context, invitation *wtf.Invitation) error
+	AcceptInvitationFn   func(ctx context.Context, id int) (*wtf.DialMembership, error)
+	DeclineInvitationFn  func(ctx context.Context, id int) error
+	DeleteInvitationFn   func(ctx context.Context, id int) error
+}
+
+func (s *InvitationService) FindInvitationByID(ctx context.Context, id int) (*wtf.Invitation, error) {
+	return s.FindInvitationByIDFn(ctx, id)
+}
+
+func (s *InvitationService) FindInvitations(ctx context.Context, filter wtf.InvitationFilter) ([]*wtf.Invitation, int, error) {
+	return s.FindInvitationsFn(ctx, filter)
+}
+
+func (s *InvitationService) CreateInvitation(ctx context.Context, invitation *wtf.Invitation) error {
+	return s.CreateInvitationFn(ctx, invitation)
+}
+
+func (s *InvitationService) AcceptInvitation(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	return s.AcceptInvitationFn(ctx, id)
+}
+
+func (s *InvitationService) DeclineInvitation(ctx context.Context, id int) error {
+	return s.DeclineInvitationFn(ctx, id)
+}
+
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id int) error {
+	return s.DeleteInvitationFn(ctx, id)
+}
@@ -9,11 +9,12 @@ import (
 var _ wtf.UserService = (*UserService)(nil)
 
 type UserService struct {
-	FindUserByIDFn func(ctx context.Context, id int) (*wtf.User, error)
-	FindUsersFn    func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error)
-	CreateUserFn   func(ctx context.Context, user *wtf.User) error
-	UpdateUserFn   func(ctx context.Context, id int, upd wtf.UserUpdate) (*wtf.User, error)
-	DeleteUserFn   func(ctx context.Context, id int) error
+	FindUserByIDFn       func(ctx context.Context, id int) (*wtf.User, error)
+	FindUsersFn          func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error)
+	AuthenticateAPIKeyFn func(ctx context.Context, apiKey string) (*wtf.User, error)
+	CreateUserFn         func(ctx context.Context, user *wtf.User) error
+	UpdateUserFn         func(ctx context.Context, id int, upd wtf.UserUpdate) (*wtf.User, error)
+	DeleteUserFn         func(ctx context.Context, id int) error
 }
 
 func (s *UserService) FindUserByID(ctx context.Context, id int) (*wtf.User, error) {
@@ -24,6 +25,10 @@ func (s *UserService) FindUsers(ctx context.Context, filter wtf.UserFilter) ([]*
 	return s.FindUsersFn(ctx, filter)
 }
 
+func (s *UserService) AuthenticateAPIKey(ctx context.Context, apiKey string) (*wtf.User, error) {
+	return s.AuthenticateAPIKeyFn(ctx, apiKey)
+}
+
 func (s *UserService) CreateUser(ctx context.Context, user *wtf.User) error {
 	return s.CreateUserFn(ctx, user)
 }
@@ -0,0 +1,82 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure type implements interface.
+var _ wtf.DialAlertNotifier = (*DialAlertNotifier)(nil)
+
+// DialAlertNotifier delivers fired dial alerts by email through an SMTP relay.
+// This is typically a relay running on the local machine so no authentication
+// is used unless a username is set. Alerts are only sent to the rule's email
+// address, which the DialAlertService limits to the account addresses of
+// members of the dial.
+type DialAlertNotifier struct {
+	// Address of the SMTP relay, in "host:port" format.
+	Addr string
+
+	// Sender address for alert emails.
+	From string
+
+	// Optional credentials for PLAIN authentication.
+	Username string
+	Password string
+
+	// Base URL of the web application. Used to link to the dial.
+	URL string
+}
+
+// NewDialAlertNotifier returns a new instance of DialAlertNotifier.
+func NewDialAlertNotifier() *DialAlertNotifier {
+	return &DialAlertNotifier{
+		Addr: "localhost:25",
+		From: "wtf@localhost",
+	}
+}
+
+// NotifyDialAlert emails the firing to the rule's email address.
+func (n *DialAlertNotifier) NotifyDialAlert(ctx context.Context, firing *wtf.DialAlertFiring) error {
+	from, err := mail.ParseAddress(n.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(firing.Rule.Email)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+
+	return sendMail(n.Addr, n.Username, n.Password, from, to, n.message(from, to, firing))
+}
+
+// message returns the email message for a firing, including headers.
+// Header values are encoded so user-provided names cannot inject headers.
+func (n *DialAlertNotifier) message(from, to *mail.Address, firing *wtf.DialAlertFiring) []byte {
+	subject := fmt.Sprintf("Alert %q fired on %s", firing.Rule.Name, firing.Dial.Name)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", firing.CreatedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&buf, "\r\n")
+
+	fmt.Fprintf(&buf, "The alert rule %q on the %q dial fired.\r\n\r\n", firing.Rule.Name, firing.Dial.Name)
+	fmt.Fprintf(&buf, "Condition: %s\r\n", firing.Rule.Condition())
+	fmt.Fprintf(&buf, "Value: %d\r\n", firing.Value)
+	fmt.Fprintf(&buf, "Fired at: %s\r\n", firing.CreatedAt.Format(time.RFC1123))
+	if n.URL != "" {
+		fmt.Fprintf(&buf, "\r\n%s/dials/%d\r\n", strings.TrimSuffix(n.URL, "/"), firing.DialID)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,62 @@
+package smtp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/smtp"
+)
+
+func TestDialAlertNotifier_NotifyDialAlert(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		s := MustOpenServer(t)
+		defer s.Close()
+
+		n := smtp.NewDialAlertNotifier()
+		n.Addr = s.Addr()
+		n.From = "wtf@example.com"
+		n.URL = "https://wtf.example.com/"
+
+		createdAt := time.Date(2000, time.January, 3, 17, 0, 0, 0, time.UTC)
+		if err := n.NotifyDialAlert(context.Background(), &wtf.DialAlertFiring{
+			DialID:    10,
+			Dial:      &wtf.Dial{ID: 10, Name: "DIAL"},
+			Rule:      &wtf.DialAlertRule{Name: "High", Subject: wtf.DialAlertSubjectDial, Operator: wtf.DialAlertOperatorGTE, Threshold: 75, DurationMinutes: 15, Email: "susy@example.com"},
+			Value:     80,
+			CreatedAt: createdAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		msg := s.Message()
+		if got, want := msg.From, "wtf@example.com"; got != want {
+			t.Fatalf("From=%q, want %q", got, want)
+		} else if got, want := msg.To, "susy@example.com"; got != want {
+			t.Fatalf("To=%q, want %q", got, want)
+		}
+		for _, s := range []string{
+			`Subject: Alert "High" fired on DIAL`,
+			"Condition: value >= 75 for 15 minutes",
+			"Value: 80",
+			"Fired at: Mon, 03 Jan 2000 17:00:00 UTC",
+			"https://wtf.example.com/dials/10",
+		} {
+			if !strings.Contains(msg.Data, s) {
+				t.Fatalf("expected %q in message:\n%s", s, msg.Data)
+			}
+		}
+	})
+
+	t.Run("ErrInvalidAddress", func(t *testing.T) {
+		n := smtp.NewDialAlertNotifier()
+		if err := n.NotifyDialAlert(context.Background(), &wtf.DialAlertFiring{
+			Dial: &wtf.Dial{Name: "DIAL"},
+			Rule: &wtf.DialAlertRule{Name: "High", Email: "bad"},
+		}); err == nil || !strings.Contains(err.Error(), "invalid to address") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
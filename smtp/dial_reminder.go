@@ -0,0 +1,79 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure type implements interface.
+var _ wtf.DialReminderNotifier = (*DialReminderNotifier)(nil)
+
+// DialReminderNotifier delivers check-in reminders by email through an SMTP
+// relay. Reminders are sent to the reminded member's account email address.
+type DialReminderNotifier struct {
+	// Address of the SMTP relay, in "host:port" format.
+	Addr string
+
+	// Sender address for reminder emails.
+	From string
+
+	// Optional credentials for PLAIN authentication.
+	Username string
+	Password string
+
+	// Base URL of the web application. Used to link to the dial.
+	URL string
+}
+
+// NewDialReminderNotifier returns a new instance of DialReminderNotifier.
+func NewDialReminderNotifier() *DialReminderNotifier {
+	return &DialReminderNotifier{
+		Addr: "localhost:25",
+		From: "wtf@localhost",
+	}
+}
+
+// NotifyDialReminder emails the reminder to the member's email address.
+func (n *DialReminderNotifier) NotifyDialReminder(ctx context.Context, reminder *wtf.DialReminder) error {
+	from, err := mail.ParseAddress(n.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(reminder.User.Email)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+
+	return sendMail(n.Addr, n.Username, n.Password, from, to, n.message(from, to, reminder))
+}
+
+// message returns the email message for a reminder, including headers.
+// Header values are encoded so user-provided names cannot inject headers.
+func (n *DialReminderNotifier) message(from, to *mail.Address, reminder *wtf.DialReminder) []byte {
+	subject := fmt.Sprintf("Time to check in on %s", reminder.Dial.Name)
+	checkInAt := reminder.CheckInAt.In(reminder.Schedule.Location())
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", reminder.CreatedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&buf, "\r\n")
+
+	fmt.Fprintf(&buf, "You haven't updated your value on the %q dial since the last check-in.\r\n\r\n", reminder.Dial.Name)
+	fmt.Fprintf(&buf, "Schedule: %s\r\n", reminder.Schedule.Description())
+	fmt.Fprintf(&buf, "Check-in: %s\r\n", checkInAt.Format(time.RFC1123))
+	if n.URL != "" {
+		fmt.Fprintf(&buf, "\r\n%s/dials/%d\r\n", strings.TrimSuffix(n.URL, "/"), reminder.DialID)
+	}
+	return buf.Bytes()
+}
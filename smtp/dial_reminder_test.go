@@ -0,0 +1,157 @@
+package smtp_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/smtp"
+)
+
+func TestDialReminderNotifier_NotifyDialReminder(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		s := MustOpenServer(t)
+		defer s.Close()
+
+		n := smtp.NewDialReminderNotifier()
+		n.Addr = s.Addr()
+		n.From = "wtf@example.com"
+		n.URL = "https://wtf.example.com/"
+
+		checkInAt := time.Date(2000, time.January, 3, 17, 0, 0, 0, time.UTC)
+		if err := n.NotifyDialReminder(context.Background(), &wtf.DialReminder{
+			DialID:    10,
+			Dial:      &wtf.Dial{ID: 10, Name: "DIAL"},
+			User:      &wtf.User{Name: "susy", Email: "susy@example.com"},
+			Schedule:  &wtf.DialReminderSchedule{Weekdays: []time.Weekday{time.Monday}, Hour: 10, Timezone: "America/Denver"},
+			CheckInAt: checkInAt,
+			CreatedAt: checkInAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		msg := s.Message()
+		if got, want := msg.From, "wtf@example.com"; got != want {
+			t.Fatalf("From=%q, want %q", got, want)
+		} else if got, want := msg.To, "susy@example.com"; got != want {
+			t.Fatalf("To=%q, want %q", got, want)
+		}
+		for _, s := range []string{
+			"Subject: Time to check in on DIAL",
+			"Schedule: Mon at 10:00 America/Denver",
+			"Check-in: Mon, 03 Jan 2000 10:00:00 MST",
+			"https://wtf.example.com/dials/10",
+		} {
+			if !strings.Contains(msg.Data, s) {
+				t.Fatalf("expected %q in message:\n%s", s, msg.Data)
+			}
+		}
+	})
+
+	t.Run("ErrInvalidAddress", func(t *testing.T) {
+		n := smtp.NewDialReminderNotifier()
+		if err := n.NotifyDialReminder(context.Background(), &wtf.DialReminder{
+			Dial: &wtf.Dial{Name: "DIAL"},
+			User: &wtf.User{Email: "bad"},
+		}); err == nil || !strings.Contains(err.Error(), "invalid to address") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// Server is a fake SMTP relay that accepts a single message.
+type Server struct {
+	ln  net.Listener
+	msg chan Message
+}
+
+// Message is a message received by the fake SMTP relay.
+type Message struct {
+	From string
+	To   string
+	Data string
+}
+
+// MustOpenServer starts a fake SMTP relay on a random local port.
+func MustOpenServer(tb testing.TB) *Server {
+	tb.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	s := &Server{ln: ln, msg: make(chan Message, 1)}
+	go s.serve()
+	return s
+}
+
+// Close stops the server.
+func (s *Server) Close() error { return s.ln.Close() }
+
+// Addr returns the "host:port" address of the server.
+func (s *Server) Addr() string { return s.ln.Addr().String() }
+
+// Message returns the received message. Returns an empty message if nothing
+// is received within a second.
+func (s *Server) Message() Message {
+	select {
+	case msg := <-s.msg:
+		return msg
+	case <-time.After(time.Second):
+		return Message{}
+	}
+}
+
+func (s *Server) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var msg Message
+	r := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "220 localhost ESMTP\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+		case "EHLO", "HELO":
+			fmt.Fprintf(conn, "250 localhost\r\n")
+		case "MAIL":
+			msg.From = strings.Trim(strings.TrimPrefix(line[len("MAIL FROM:"):], " "), "<>")
+			fmt.Fprintf(conn, "250 OK\r\n")
+		case "RCPT":
+			msg.To = strings.Trim(strings.TrimPrefix(line[len("RCPT TO:"):], " "), "<>")
+			fmt.Fprintf(conn, "250 OK\r\n")
+		case "DATA":
+			fmt.Fprintf(conn, "354 End data with <CR><LF>.<CR><LF>\r\n")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				} else if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			msg.Data = data.String()
+			fmt.Fprintf(conn, "250 OK\r\n")
+		case "QUIT":
+			fmt.Fprintf(conn, "221 Bye\r\n")
+			s.msg <- msg
+			return
+		default:
+			fmt.Fprintf(conn, "502 Not implemented\r\n")
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package smtp
+
+import (
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// sendMail sends msg through the SMTP relay at addr. PLAIN authentication is
+// only used if a username is set.
+func sendMail(addr, username, password string, from, to *mail.Address, msg []byte) error {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return smtp.SendMail(addr, auth, from.Address, []string{to.Address}, msg)
+}
@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.AuditService = (*AuditService)(nil)
+
+// AuditService represents a service for querying the audit log.
+type AuditService struct {
+	db *DB
+}
+
+// NewAuditService returns a new instance of AuditService.
+func NewAuditService(db *DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// FindAuditEntries retrieves a list of audit entries based on a filter. Dial
+// owners can see all entries for their dials while other users can only see
+// the changes they made themselves.
+func (s *AuditService) FindAuditEntries(ctx context.Context, filter wtf.AuditEntryFilter) ([]*wtf.AuditEntry, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Fetch list of matching entries.
+	entries, n, err := findAuditEntries(ctx, tx, filter)
+	if err != nil {
+		return entries, n, err
+	}
+
+	// Attach the acting user to each entry.
+	for _, entry := range entries {
+		if err := attachAuditEntryAssociations(ctx, tx, entry); err != nil {
+			return entries, n, err
+		}
+	}
+	return entries, n, nil
+}
+
+// findAuditEntries retrieves a list of matching audit entries. Also returns a
+// total matching count which may differ from the number of results if
+// filter.Limit is set.
+func findAuditEntries(ctx context.Context, tx *Tx, filter wtf.AuditEntryFilter) (_ []*wtf.AuditEntry, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "e.id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "e.dial_id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "e.user_id = ?"), append(args, *v)
+	}
+	if v := filter.Action; v != nil {
+		where, args = append(where, "e.action = ?"), append(args, *v)
+	}
+
+	// Limit to entries on dials the user owns or changes made by the user.
+	// Once a dial is purged, the owner recorded on the entry is used instead.
+	userID := wtf.UserIDFromContext(ctx)
+	where = append(where, "(d.user_id = ? OR (d.id IS NULL AND e.dial_user_id = ?) OR e.user_id = ?)")
+	args = append(args, userID, userID, userID)
+
+	// Execute query with limiting WHERE clause and LIMIT/OFFSET injected.
+	// Entries are not linked by foreign key so they outlive deleted objects.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    e.id,
+		    e.user_id,
+		    e.action,
+		    e.target_type,
+		    e.target_id,
+		    IFNULL(e.dial_id, 0),
+		    e.before,
+		    e.after,
+		    e.source,
+		    e.created_at,
+		    COUNT(*) OVER()
+		FROM audit_entries e
+		LEFT JOIN dials d ON e.dial_id = d.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY e.id DESC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into AuditEntry objects.
+	entries := make([]*wtf.AuditEntry, 0)
+	for rows.Next() {
+		var entry wtf.AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.DialID,
+			&before,
+			&after,
+			&entry.Source,
+			(*NullTime)(&entry.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if before.Valid {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			entry.After = json.RawMessage(after.String)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, n, nil
+}
+
+// createAuditEntry records a state change in the audit log. The actor is the
+// current user unless entry.UserID is already set, such as during signup
+// before a user is logged in. The before & after states are snapshots of the
+// target object and are stored as JSON.
+func createAuditEntry(ctx context.Context, tx *Tx, entry *wtf.AuditEntry, before, after interface{}) (err error) {
+	if entry.UserID == 0 {
+		entry.UserID = wtf.UserIDFromContext(ctx)
+	}
+	entry.Source = wtf.AuditSourceFromContext(ctx)
+	entry.CreatedAt = tx.now
+
+	if entry.Before, err = marshalAuditState(before); err != nil {
+		return fmt.Errorf("marshal before state: %w", err)
+	} else if entry.After, err = marshalAuditState(after); err != nil {
+		return fmt.Errorf("marshal after state: %w", err)
+	}
+
+	// Store zero dial IDs as NULL so non-dial entries are easy to distinguish.
+	// The dial owner is copied onto the entry so it remains visible to them
+	// after the dial is deleted.
+	var dialID *int
+	if entry.DialID != 0 {
+		dialID = &entry.DialID
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_entries (
+			user_id,
+			action,
+			target_type,
+			target_id,
+			dial_id,
+			dial_user_id,
+			before,
+			after,
+			source,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, (SELECT user_id FROM dials WHERE id = ?), ?, ?, ?, ?)
+	`,
+		entry.UserID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		dialID,
+		dialID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		entry.Source,
+		(*NullTime)(&entry.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new entry ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+
+	return nil
+}
+
+// marshalAuditState encodes an object for storage in the audit log.
+// Associations are stripped so that only the object's own fields are kept.
+func marshalAuditState(v interface{}) (json.RawMessage, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case *wtf.Dial:
+		other := *v
+		other.User, other.Memberships, other.Stats = nil, nil, nil
+		return json.Marshal(&other)
+	case *wtf.DialMembership:
+		other := *v
+		other.Dial, other.User = nil, nil
+		return json.Marshal(&other)
+	case *wtf.Invitation:
+		other := *v
+		other.Dial, other.Inviter, other.Invitee = nil, nil, nil
+		return json.Marshal(&other)
+	case *wtf.DialBan:
+		other := *v
+		other.User = nil
+		return json.Marshal(&other)
+	case *wtf.User:
+		other := *v
+		other.Auths = nil
+		return json.Marshal(&other)
+	case *wtf.Auth:
+		other := *v
+		other.User = nil
+		return json.Marshal(&other)
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// nullableJSON returns nil for empty JSON so it is stored as a NULL.
+func nullableJSON(v json.RawMessage) *string {
+	if len(v) == 0 {
+		return nil
+	}
+	s := string(v)
+	return &s
+}
+
+// attachAuditEntryAssociations attaches the acting user. The user is left
+// blank for system changes & for users that have since been deleted.
+func attachAuditEntryAssociations(ctx context.Context, tx *Tx, entry *wtf.AuditEntry) (err error) {
+	if entry.UserID == 0 {
+		return nil
+	}
+	if entry.User, err = findUserByID(ctx, tx, entry.UserID); err != nil && wtf.ErrorCode(err) != wtf.ENOTFOUND {
+		return fmt.Errorf("attach audit entry user: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestAuditService_FindAuditEntries(t *testing.T) {
+	// Ensure changes to a dial are recorded with their before & after state.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuditService(db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		ctx0 = wtf.NewContextWithAuditSource(ctx0, wtf.AuditSourceAPIKey)
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		newName := "NEW"
+		if _, err := sqlite.NewDialService(db).UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Name: &newName}); err != nil {
+			t.Fatal(err)
+		}
+
+		action := wtf.AuditActionDialUpdate
+		entries, n, err := s.FindAuditEntries(ctx0, wtf.AuditEntryFilter{DialID: &dial.ID, Action: &action})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		entry := entries[0]
+		if got, want := entry.UserID, user0.ID; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		} else if got, want := entry.User.Name, "jane"; got != want {
+			t.Fatalf("User.Name=%v, want %v", got, want)
+		} else if got, want := entry.TargetType, wtf.AuditTargetDial; got != want {
+			t.Fatalf("TargetType=%v, want %v", got, want)
+		} else if got, want := entry.Source, wtf.AuditSourceAPIKey; got != want {
+			t.Fatalf("Source=%v, want %v", got, want)
+		}
+
+		var before, after wtf.Dial
+		if err := json.Unmarshal(entry.Before, &before); err != nil {
+			t.Fatal(err)
+		} else if err := json.Unmarshal(entry.After, &after); err != nil {
+			t.Fatal(err)
+		} else if got, want := before.Name, "DIAL"; got != want {
+			t.Fatalf("Before.Name=%v, want %v", got, want)
+		} else if got, want := after.Name, "NEW"; got != want {
+			t.Fatalf("After.Name=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure bans are recorded and members only see their own changes.
+	t.Run("DialBan", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuditService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := sqlite.NewDialBanService(db).CreateDialBan(ctx0, &wtf.DialBan{DialID: dial.ID, UserID: user1.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Owner sees dial create, both memberships & the ban.
+		if entries, n, err := s.FindAuditEntries(ctx0, wtf.AuditEntryFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 4; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := entries[0].Action, wtf.AuditActionDialBanCreate; got != want {
+			t.Fatalf("Action=%v, want %v", got, want)
+		} else if got, want := entries[0].Source, wtf.AuditSourceSystem; got != want {
+			t.Fatalf("Source=%v, want %v", got, want)
+		}
+
+		// Banned member only sees their own membership creation.
+		if entries, n, err := s.FindAuditEntries(ctx1, wtf.AuditEntryFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := entries[0].Action, wtf.AuditActionDialMembershipCreate; got != want {
+			t.Fatalf("Action=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the owner can still see a dial's log after the dial is purged by
+	// the system while members still only see their own changes.
+	t.Run("Purged", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuditService(db)
+
+		now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+		db.DialArchiveRetention = 24 * time.Hour
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		if _, err := sqlite.NewDialService(db).ArchiveDial(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		now = now.Add(48 * time.Hour)
+		if err := db.PurgeArchivedDials(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// Owner sees create, both memberships, archive & the system deletion.
+		if entries, n, err := s.FindAuditEntries(ctx0, wtf.AuditEntryFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 5; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := entries[0].Action, wtf.AuditActionDialDelete; got != want {
+			t.Fatalf("Action=%v, want %v", got, want)
+		} else if got, want := entries[0].UserID, 0; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		}
+
+		// Member still only sees their own membership creation.
+		if _, n, err := s.FindAuditEntries(ctx1, wtf.AuditEntryFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+	})
+}
@@ -83,7 +83,7 @@ func (s *AuthService) CreateAuth(ctx context.Context, auth *wtf.Auth) error {
 	// Check to see if the auth already exists for the given source.
 	if other, err := findAuthBySourceID(ctx, tx, auth.Source, auth.SourceID); err == nil {
 		// If an auth already exists for the source user, update with the new tokens.
-		if other, err = updateAuth(ctx, tx, other.ID, auth.AccessToken, auth.RefreshToken, auth.Expiry); err != nil {
+		if other, err = updateAuth(ctx, tx, other.ID, auth.SourceLogin, auth.AccessToken, auth.RefreshToken, auth.Expiry); err != nil {
 			return fmt.Errorf("cannot update auth: id=%d err=%w", other.ID, err)
 		} else if err := attachAuthAssociations(ctx, tx, other); err != nil {
 			return err
@@ -163,6 +163,19 @@ func findAuthBySourceID(ctx context.Context, tx *Tx, source, sourceID string) (*
 	return auths[0], nil
 }
 
+// findAuthBySourceLogin is a helper function to return an auth object by the
+// login name on the source. Logins are matched case-insensitively.
+// Returns ENOTFOUND if auth doesn't exist.
+func findAuthBySourceLogin(ctx context.Context, tx *Tx, source, sourceLogin string) (*wtf.Auth, error) {
+	auths, _, err := findAuths(ctx, tx, wtf.AuthFilter{Source: &source, SourceLogin: &sourceLogin})
+	if err != nil {
+		return nil, err
+	} else if len(auths) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Auth not found."}
+	}
+	return auths[0], nil
+}
+
 // findAuths returns a list of auth objects that match a filter. Also returns
 // a total count of matches which may differ from results if filter.Limit is set.
 func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Auth, n int, err error) {
@@ -184,6 +197,9 @@ func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Aut
 	if v := filter.SourceID; v != nil {
 		where, args = append(where, "source_id = ?"), append(args, *v)
 	}
+	if v := filter.SourceLogin; v != nil {
+		where, args = append(where, "source_login = ? COLLATE NOCASE"), append(args, *v)
+	}
 
 	// Execute the query with WHERE clause and LIMIT/OFFSET injected.
 	rows, err := tx.QueryContext(ctx, `
@@ -192,6 +208,7 @@ func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Aut
 		    user_id,
 		    source,
 		    source_id,
+		    source_login,
 		    access_token,
 		    refresh_token,
 		    expiry,
@@ -215,11 +232,12 @@ func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Aut
 	for rows.Next() {
 		var auth wtf.Auth
 		var expiry sql.NullString
-		if rows.Scan(
+		if err := rows.Scan(
 			&auth.ID,
 			&auth.UserID,
 			&auth.Source,
 			&auth.SourceID,
+			&auth.SourceLogin,
 			&auth.AccessToken,
 			&auth.RefreshToken,
 			&expiry,
@@ -270,17 +288,19 @@ func createAuth(ctx context.Context, tx *Tx, auth *wtf.Auth) error {
 			user_id,
 			source,
 			source_id,
+			source_login,
 			access_token,
 			refresh_token,
 			expiry,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		auth.UserID,
 		auth.Source,
 		auth.SourceID,
+		auth.SourceLogin,
 		auth.AccessToken,
 		auth.RefreshToken,
 		expiry,
@@ -298,19 +318,38 @@ func createAuth(ctx context.Context, tx *Tx, auth *wtf.Auth) error {
 	}
 	auth.ID = int(id)
 
+	// Record creation in the audit log on behalf of the linked user.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		UserID:     auth.UserID,
+		Action:     wtf.AuditActionAuthCreate,
+		TargetType: wtf.AuditTargetAuth,
+		TargetID:   auth.ID,
+	}, nil, auth); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Deliver any invitations sent to the GitHub login before the user signed up.
+	if err := claimInvitations(ctx, tx, auth.UserID); err != nil {
+		return fmt.Errorf("claim invitations: %w", err)
+	}
+
 	return nil
 }
 
-// updateAuth updates tokens & expiry on exist auth object.
+// updateAuth updates the login, tokens & expiry on exist auth object.
 // Returns new state of the auth object.
-func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken string, expiry *time.Time) (*wtf.Auth, error) {
+func updateAuth(ctx context.Context, tx *Tx, id int, sourceLogin, accessToken, refreshToken string, expiry *time.Time) (*wtf.Auth, error) {
 	// Fetch current object state.
 	auth, err := findAuthByID(ctx, tx, id)
 	if err != nil {
 		return auth, err
 	}
 
+	// Save state of auth for the audit log. Tokens are never serialized.
+	prev := *auth
+
 	// Update fields & last updated date.
+	auth.SourceLogin = sourceLogin
 	auth.AccessToken = accessToken
 	auth.RefreshToken = refreshToken
 	auth.Expiry = expiry
@@ -331,12 +370,14 @@ func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken s
 	// Execute SQL update query.
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE auths
-		SET access_token = ?,
+		SET source_login = ?,
+		    access_token = ?,
 		    refresh_token = ?,
 		    expiry = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
+		auth.SourceLogin,
 		auth.AccessToken,
 		auth.RefreshToken,
 		expiryStr,
@@ -346,13 +387,29 @@ func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken s
 		return auth, FormatError(err)
 	}
 
+	// Record token refresh in the audit log on behalf of the linked user.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		UserID:     auth.UserID,
+		Action:     wtf.AuditActionAuthUpdate,
+		TargetType: wtf.AuditTargetAuth,
+		TargetID:   auth.ID,
+	}, &prev, auth); err != nil {
+		return auth, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// The user may have been invited under a GitHub login they just renamed to.
+	if err := claimInvitations(ctx, tx, auth.UserID); err != nil {
+		return auth, fmt.Errorf("claim invitations: %w", err)
+	}
+
 	return auth, nil
 }
 
 // deleteAuth permanently removes an auth object by ID.
 func deleteAuth(ctx context.Context, tx *Tx, id int) error {
 	// Verify object exists & that the user is the owner of the auth.
-	if auth, err := findAuthByID(ctx, tx, id); err != nil {
+	auth, err := findAuthByID(ctx, tx, id)
+	if err != nil {
 		return err
 	} else if auth.UserID != wtf.UserIDFromContext(ctx) {
 		return wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to delete this auth.")
@@ -362,6 +419,15 @@ func deleteAuth(ctx context.Context, tx *Tx, id int) error {
 	if _, err := tx.ExecContext(ctx, `DELETE FROM auths WHERE id = ?`, id); err != nil {
 		return FormatError(err)
 	}
+
+	// Record deletion in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionAuthDelete,
+		TargetType: wtf.AuditTargetAuth,
+		TargetID:   auth.ID,
+	}, auth, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
 	return nil
 }
 
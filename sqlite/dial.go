@@ -5,8 +5,10 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"time"
 
@@ -90,6 +92,29 @@ func (s *DialService) CreateDial(ctx context.Context, dial *wtf.Dial) error {
 	return tx.Commit()
 }
 
+// CloneDial creates a new dial with the name & settings of an existing dial.
+// Members are copied with reset values if clone.IncludeMembers is set. Only the
+// dial owner can clone a dial.
+//
+// Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if user
+// is not the dial owner.
+func (s *DialService) CloneDial(ctx context.Context, id int, clone wtf.DialClone) (*wtf.Dial, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Clone the dial and attach associated owner user.
+	dial, err := cloneDial(ctx, tx, id, clone)
+	if err != nil {
+		return nil, err
+	} else if err := attachDialAssociations(ctx, tx, dial); err != nil {
+		return nil, err
+	}
+	return dial, tx.Commit()
+}
+
 // UpdateDial updates an existing dial by ID. Only the dial owner can update a dial.
 // Returns the new dial state even if there was an error during update.
 //
@@ -112,9 +137,47 @@ func (s *DialService) UpdateDial(ctx context.Context, id int, upd wtf.DialUpdate
 	return dial, tx.Commit()
 }
 
-// DeleteDial permanently removes a dial by ID. Only the dial owner may delete
-// a dial. Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if
-// user is not the dial owner.
+// ArchiveDial hides a dial from listings & makes it read-only while keeping
+// its history. Only the dial owner may archive a dial. Returns ENOTFOUND if
+// dial does not exist. Returns EUNAUTHORIZED if user is not the dial owner.
+func (s *DialService) ArchiveDial(ctx context.Context, id int) (*wtf.Dial, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dial, err := archiveDial(ctx, tx, id)
+	if err != nil {
+		return dial, err
+	} else if err := attachDialAssociations(ctx, tx, dial); err != nil {
+		return dial, err
+	}
+	return dial, tx.Commit()
+}
+
+// RestoreDial returns an archived dial to its normal state. Only the dial
+// owner may restore a dial. Returns ENOTFOUND if dial does not exist. Returns
+// EUNAUTHORIZED if user is not the dial owner.
+func (s *DialService) RestoreDial(ctx context.Context, id int) (*wtf.Dial, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	dial, err := restoreDial(ctx, tx, id)
+	if err != nil {
+		return dial, err
+	} else if err := attachDialAssociations(ctx, tx, dial); err != nil {
+		return dial, err
+	}
+	return dial, tx.Commit()
+}
+
+// DeleteDial permanently removes an archived dial by ID. Only the dial owner
+// may delete a dial. Returns ENOTFOUND if dial does not exist. Returns
+// EUNAUTHORIZED if user is not the dial owner.
 func (s *DialService) DeleteDial(ctx context.Context, id int) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -130,20 +193,53 @@ func (s *DialService) DeleteDial(ctx context.Context, id int) error {
 
 // Sets the value of the user's membership in a dial. This works the same
 // as calling UpdateDialMembership() although it doesn't require that the
-// user know their membership ID. Only the dial ID.
+// user know their membership ID. Only the dial ID. If the dial has dimensions
+// then every dimension is set to value.
 //
 // Returns ENOTFOUND if the membership does not exist.
-func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int) error {
+func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int, note string) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Fetch current user.
-	userID := wtf.UserIDFromContext(ctx)
+	// Update value & note on the user's membership.
+	if err := setDialMembershipValue(ctx, tx, dialID, wtf.DialMembershipUpdate{Value: &value, Note: &note}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetDialMembershipDimensionValues sets the values of named dimensions on the
+// user's membership in a dial. The user's value becomes the average of all
+// their dimension values.
+//
+// Returns ENOTFOUND if the membership does not exist. Returns EINVALID if the
+// dial does not have a dimension with one of the given names.
+func (s *DialService) SetDialMembershipDimensionValues(ctx context.Context, dialID int, values map[string]int, note string) error {
+	if len(values) == 0 {
+		return wtf.Errorf(wtf.EINVALID, "At least one dimension value required.")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Update dimension values & note on the user's membership.
+	if err := setDialMembershipValue(ctx, tx, dialID, wtf.DialMembershipUpdate{Dimensions: values, Note: &note}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
+// setDialMembershipValue applies an update to the current user's membership
+// in a dial. Returns ENOTFOUND if the user is not a member of the dial.
+func setDialMembershipValue(ctx context.Context, tx *Tx, dialID int, upd wtf.DialMembershipUpdate) error {
 	// Find user's membership.
+	userID := wtf.UserIDFromContext(ctx)
 	memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{
 		DialID: &dialID,
 		UserID: &userID,
@@ -154,11 +250,8 @@ func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value
 		return wtf.Errorf(wtf.ENOTFOUND, "User is not a member of this dial.")
 	}
 
-	// Update value on membership.
-	if _, err := updateDialMembership(ctx, tx, memberships[0].ID, wtf.DialMembershipUpdate{Value: &value}); err != nil {
-		return err
-	}
-	return tx.Commit()
+	_, err = updateDialMembership(ctx, tx, memberships[0].ID, upd)
+	return err
 }
 
 // DialValues returns a list of all stored historical values for a dial.
@@ -201,6 +294,10 @@ func (s *DialService) DialValues(ctx context.Context, id int) ([]int, error) {
 // between start & end time and are slotted into given intervals. The
 // minimum interval size is one minute.
 func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -211,49 +308,186 @@ func (s *DialService) AverageDialValueReport(ctx context.Context, start, end tim
 	start = start.Truncate(interval).UTC()
 	end = end.Truncate(interval).UTC()
 
-	// Compute the number of slots between start & end.
-	slotN := int(end.Sub(start) / interval)
+	// Compute the average value across the user's dials for each slot.
+	values, err := findAverageDialValueSlotsBetween(ctx, tx, start, end, interval)
+	if err != nil {
+		return nil, fmt.Errorf("average dial values between: %w", err)
+	}
+
 	report := &wtf.DialValueReport{
-		Records: make([]*wtf.DialValueRecord, slotN),
+		Records: make([]*wtf.DialValueRecord, len(values)),
+	}
+	for i, value := range values {
+		report.Records[i] = &wtf.DialValueRecord{
+			Timestamp: start.Add(time.Duration(i) * interval),
+			Value:     value,
+		}
 	}
+	return report, nil
+}
 
-	// Fetch all dials which user is a member or owner.
-	dials, _, err := findDials(ctx, tx, wtf.DialFilter{})
+// DialValueReport returns a report of a single dial's value between start &
+// end time, slotted into given intervals. The minimum interval size is one
+// minute.
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("find dials: %w", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Iterate over each dial and compute value at each slot.
-	valuesSlice := make([][]int, len(dials))
-	for i, dial := range dials {
-		values, err := findDialValueSlotsBetween(ctx, tx, dial.ID, start, end, interval)
-		if err != nil {
-			return nil, fmt.Errorf("dial values between: id=%d err=%w", dial.ID, err)
-		}
-		valuesSlice[i] = values
+	// Ensure the current user can view the dial.
+	dial, err := findDialByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	// Compute average for each slot.
-	for i := 0; i < slotN; i++ {
-		var avg int
-		if len(dials) != 0 {
-			var sum int
-			for j := range dials {
-				sum += valuesSlice[j][i]
-			}
-			avg = sum / len(valuesSlice)
-		}
+	// Ensure start/end line up with the interval unit.
+	start = start.Truncate(interval).UTC()
+	end = end.Truncate(interval).UTC()
 
-		// Append record for avg value at a given time.
-		report.Records[i] = &wtf.DialValueRecord{
-			Timestamp: start.Add(time.Duration(i) * interval),
-			Value:     avg,
-		}
+	// Fetch the aggregate value for each slot.
+	buckets, err := findDialValueBuckets(ctx, tx, id, start, end, interval)
+	if err != nil {
+		return nil, fmt.Errorf("dial value buckets: %w", err)
 	}
 
+	// Fetch the spread of member values for each slot.
+	stats, err := findDialStatsSlots(ctx, tx, id, start, end, interval)
+	if err != nil {
+		return nil, fmt.Errorf("dial stats: %w", err)
+	}
+
+	// Fetch scheduled resets so they can be marked on the report.
+	resets, err := findDialResetsBetween(ctx, tx, id, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("dial resets: %w", err)
+	}
+
+	report := &wtf.DialValueReport{
+		Records: make([]*wtf.DialValueRecord, len(buckets)),
+		Resets:  resets,
+	}
+	for i := range buckets {
+		report.Records[i] = buckets[i].record()
+		report.Records[i].Stats = stats[i]
+		report.Records[i].Band = dial.Scale.BandLabel(report.Records[i].Value)
+	}
 	return report, nil
 }
 
+// DialDimensionValueReport returns a report of the aggregate value of one of a
+// dial's dimensions between start & end time, slotted into given intervals.
+// The minimum interval size is one minute.
+//
+// Dimension values are not rolled up so the report is always computed from
+// the full value history.
+func (s *DialService) DialDimensionValueReport(ctx context.Context, id, dimensionID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Ensure the current user can view the dial & it has the dimension.
+	dial, err := findDialByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	for _, dim := range dial.Dimensions {
+		found = found || dim.ID == dimensionID
+	}
+	if !found {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Dial dimension not found.")
+	}
+
+	// Ensure start/end line up with the interval unit.
+	start = start.Truncate(interval).UTC()
+	end = end.Truncate(interval).UTC()
+
+	// Fetch the dimension's value changes within the time range.
+	initial, changes, err := findDialDimensionValueChangesBetween(ctx, tx, dimensionID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("dimension value changes: %w", err)
+	}
+
+	report := &wtf.DialValueReport{Records: buildDialValueRecords(initial, changes, start, end, interval)}
+	for _, record := range report.Records {
+		record.Band = dial.Scale.BandLabel(record.Value)
+	}
+	return report, nil
+}
+
+// AverageDialValueHeatmap returns the average value across all dials that the
+// user is a member of for each hour of each day of the week between start &
+// end time. Hours are grouped by local time in the given location.
+func (s *DialService) AverageDialValueHeatmap(ctx context.Context, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	start, end = start.Truncate(time.Hour).UTC(), end.Truncate(time.Hour).UTC()
+	if err := wtf.ValidateDialValueHeatmapRange(start, end); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Compute the average value across the user's dials for each hour.
+	slots, err := findAverageDialValueSlotsBetween(ctx, tx, start, end, time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("average dial values between: %w", err)
+	}
+
+	values := make([]float64, len(slots))
+	for i, v := range slots {
+		values[i] = float64(v)
+	}
+	return buildDialValueHeatmap(values, start, end, loc), nil
+}
+
+// DialValueHeatmap returns the time-weighted average value of a single dial
+// for each hour of each day of the week between start & end time. Hours are
+// grouped by local time in the given location.
+func (s *DialService) DialValueHeatmap(ctx context.Context, id int, start, end time.Time, loc *time.Location) (*wtf.DialValueHeatmap, error) {
+	start, end = start.Truncate(time.Hour).UTC(), end.Truncate(time.Hour).UTC()
+	if err := wtf.ValidateDialValueHeatmapRange(start, end); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Ensure the current user can view the dial.
+	if _, err := findDialByID(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	// Fetch the time-weighted average value for each hour.
+	buckets, err := findDialValueBuckets(ctx, tx, id, start, end, time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("dial value buckets: %w", err)
+	}
+
+	values := make([]float64, len(buckets))
+	for i := range buckets {
+		values[i] = buckets[i].Avg
+	}
+	return buildDialValueHeatmap(values, start, end, loc), nil
+}
+
 // findDialByID is a helper function to retrieve a dial by ID.
 // Returns ENOTFOUND if dial doesn't exist.
 func findDialByID(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
@@ -266,19 +500,75 @@ func findDialByID(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
 	return dials[0], nil
 }
 
-// checkDialExists returns nil if a dial does not exist. Otherwise returns ENOTFOUND.
-// This is used to avoid permissions checks when inserting related objects.
+// findPendingDialByID returns a dial that the current user has a pending
+// membership on. Only the fields needed to show the user that they are waiting
+// for approval are returned so the dial's value is not revealed. Returns
+// ENOTFOUND if the user does not have a pending membership on the dial.
+func findPendingDialByID(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
+	dials, _, err := queryDials(ctx, tx, []string{
+		"id = ?",
+		"id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)",
+	}, []interface{}{id, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusPending}, "")
+	if err != nil {
+		return nil, err
+	} else if len(dials) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	}
+
+	dial := dials[0]
+	return &wtf.Dial{
+		ID:              dial.ID,
+		UserID:          dial.UserID,
+		Name:            dial.Name,
+		RequireApproval: dial.RequireApproval,
+		Scale:           dial.Scale,
+		ArchivedAt:      dial.ArchivedAt,
+		CreatedAt:       dial.CreatedAt,
+		UpdatedAt:       dial.UpdatedAt,
+	}, nil
+}
+
+// findDialScale returns the scale of a dial. Returns ENOTFOUND if the dial
+// does not exist. This is used to avoid permissions checks when inserting
+// related objects.
 //
 // Unfortunately, SQLite provides poor FOREIGN KEY error descriptions but
-// otherwise we would just use those.
-func checkDialExists(ctx context.Context, tx *Tx, id int) error {
-	var n int
-	if err := tx.QueryRowContext(ctx, `SELECT COUNT(1) FROM dials WHERE id = ?`, id).Scan(&n); err != nil {
-		return FormatError(err)
-	} else if n == 0 {
-		return &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+// otherwise we would just use those to check that the dial exists.
+func findDialScale(ctx context.Context, tx *Tx, id int) (*wtf.DialScale, error) {
+	var scale wtf.DialScale
+	var bands string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT scale_min, scale_max, scale_step, scale_bands
+		FROM dials
+		WHERE id = ?
+	`,
+		id,
+	).Scan(&scale.Min, &scale.Max, &scale.Step, &bands); err == sql.ErrNoRows {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	} else if err != nil {
+		return nil, FormatError(err)
+	} else if scale.Bands, err = unmarshalDialBands(bands); err != nil {
+		return nil, err
 	}
-	return nil
+	return &scale, nil
+}
+
+// findDialApprovalSettings returns the owner ID of a dial and whether new
+// memberships require approval. This bypasses permission checks so that it can
+// be used by users who are not yet members of the dial.
+func findDialApprovalSettings(ctx context.Context, tx *Tx, id int) (userID int, requireApproval bool, err error) {
+	if err := tx.QueryRowContext(ctx, `
+		SELECT user_id, require_approval
+		FROM dials
+		WHERE id = ?
+	`,
+		id,
+	).Scan(&userID, &requireApproval); err == sql.ErrNoRows {
+		return 0, false, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	} else if err != nil {
+		return 0, false, FormatError(err)
+	}
+	return userID, requireApproval, nil
 }
 
 // findDials retrieves a list of matching dials. Also returns a total matching
@@ -291,17 +581,32 @@ func findDials(ctx context.Context, tx *Tx, filter wtf.DialFilter) (_ []*wtf.Dia
 		where, args = append(where, "id = ?"), append(args, *v)
 	}
 
-	// Limit to dials user is a member of unless searching by invite code.
+	// Hide archived dials unless requested. They can always be found by ID so
+	// that they can be viewed & restored.
+	if !filter.IncludeArchived && filter.ID == nil {
+		where = append(where, "archived_at IS NULL")
+	}
+
+	// Limit to dials user owns or is an active member of unless searching by
+	// invite code. Pending members cannot view the dial until they are
+	// approved. See findPendingDialByID() for the waiting page.
 	if v := filter.InviteCode; v != nil {
 		where, args = append(where, "invite_code = ?"), append(args, *v)
 	} else {
 		userID := wtf.UserIDFromContext(ctx)
 		where = append(where, `(
-			id IN (SELECT dial_id FROM dial_memberships dm WHERE dm.user_id = ?)
+			user_id = ? OR
+			id IN (SELECT dial_id FROM dial_memberships dm WHERE dm.user_id = ? AND dm.status = ?)
 		)`)
-		args = append(args, userID)
+		args = append(args, userID, userID, wtf.DialMembershipStatusActive)
 	}
 
+	return queryDials(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// queryDials executes a query for dials with the given WHERE clause parts.
+// This performs no permission checks so callers must add them.
+func queryDials(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.Dial, n int, err error) {
 	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
 	rows, err := tx.QueryContext(ctx, `
 		SELECT 
@@ -310,13 +615,30 @@ func findDials(ctx context.Context, tx *Tx, filter wtf.DialFilter) (_ []*wtf.Dia
 		    name,
 		    value,
 		    invite_code,
+		    require_approval,
+		    anomaly_spike_threshold,
+		    anomaly_sustained_threshold,
+		    stale_policy,
+		    stale_after_days,
+		    stale_baseline,
+		    stale_half_life_days,
+		    reset_interval_days,
+		    reset_value,
+		    reset_timezone,
+		    next_reset_at,
+		    scale_min,
+		    scale_max,
+		    scale_step,
+		    scale_bands,
+		    expression,
+		    archived_at,
 		    created_at,
 		    updated_at,
 		    COUNT(*) OVER()
 		FROM dials
 		WHERE `+strings.Join(where, " AND ")+`
 		ORDER BY id ASC
-		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		`+limitOffset,
 		args...,
 	)
 	if err != nil {
@@ -328,23 +650,52 @@ func findDials(ctx context.Context, tx *Tx, filter wtf.DialFilter) (_ []*wtf.Dia
 	dials := make([]*wtf.Dial, 0)
 	for rows.Next() {
 		var dial wtf.Dial
-		if rows.Scan(
+		var bands string
+		if err := rows.Scan(
 			&dial.ID,
 			&dial.UserID,
 			&dial.Name,
 			&dial.Value,
 			&dial.InviteCode,
+			&dial.RequireApproval,
+			&dial.AnomalySpikeThreshold,
+			&dial.AnomalySustainedThreshold,
+			&dial.StalePolicy,
+			&dial.StaleAfterDays,
+			&dial.StaleBaseline,
+			&dial.StaleHalfLifeDays,
+			&dial.ResetIntervalDays,
+			&dial.ResetValue,
+			&dial.ResetTimezone,
+			(*NullTime)(&dial.NextResetAt),
+			&dial.Scale.Min,
+			&dial.Scale.Max,
+			&dial.Scale.Step,
+			&bands,
+			&dial.Expression,
+			(*NullTime)(&dial.ArchivedAt),
 			(*NullTime)(&dial.CreatedAt),
 			(*NullTime)(&dial.UpdatedAt),
 			&n,
 		); err != nil {
 			return nil, 0, err
+		} else if dial.Scale.Bands, err = unmarshalDialBands(bands); err != nil {
+			return nil, 0, err
 		}
+		dial.Band = dial.Scale.BandLabel(dial.Value)
 		dials = append(dials, &dial)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
+	rows.Close()
+
+	// Attach dimensions once the dial rows have been read.
+	for _, dial := range dials {
+		if dial.Dimensions, err = findDialDimensions(ctx, tx, dial.ID, &dial.Scale); err != nil {
+			return nil, 0, fmt.Errorf("dial dimensions: %w", err)
+		}
+	}
 
 	return dials, n, nil
 }
@@ -366,31 +717,107 @@ func createDial(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
 	}
 	dial.InviteCode = hex.EncodeToString(inviteCode)
 
+	// Use the default anomaly thresholds unless they are specified.
+	if dial.AnomalySpikeThreshold == 0 {
+		dial.AnomalySpikeThreshold = wtf.DefaultDialAnomalySpikeThreshold
+	}
+	if dial.AnomalySustainedThreshold == 0 {
+		dial.AnomalySustainedThreshold = wtf.DefaultDialAnomalySustainedThreshold
+	}
+
+	// Use the default stale membership periods unless they are specified.
+	if dial.StaleAfterDays == 0 {
+		dial.StaleAfterDays = wtf.DefaultDialStaleAfterDays
+	}
+	if dial.StaleHalfLifeDays == 0 {
+		dial.StaleHalfLifeDays = wtf.DefaultDialStaleHalfLifeDays
+	}
+
+	// Use the default scale unless one is specified. Members start at the
+	// bottom of the scale.
+	if dial.Scale.Min == 0 && dial.Scale.Max == 0 {
+		dial.Scale.Min, dial.Scale.Max = wtf.DefaultDialScaleMin, wtf.DefaultDialScaleMax
+	}
+	if dial.Scale.Step == 0 {
+		dial.Scale.Step = wtf.DefaultDialScaleStep
+	}
+	normalizeDialScale(&dial.Scale)
+	dial.Value = dial.Scale.Min
+	dial.Band = dial.Scale.BandLabel(dial.Value)
+	dial.Expression = strings.TrimSpace(dial.Expression)
+
+	// Dimensions are positioned in the order they are given.
+	if err := applyDialDimensionsUpdate(dial, dial.Dimensions); err != nil {
+		return err
+	}
+
 	// Set timestamps to current time.
 	dial.CreatedAt = tx.now
 	dial.UpdatedAt = dial.CreatedAt
+	dial.NextResetAt = normalizeDialNextResetAt(dial.NextResetAt)
 
 	// Perform basic field validation & ensure user exists.
 	if err := dial.Validate(); err != nil {
 		return err
+	} else if err := validateDialNextResetAt(tx, dial); err != nil {
+		return err
 	} else if _, err := findUserByID(ctx, tx, dial.UserID); err != nil {
 		return err
 	}
 
+	bands, err := marshalDialBands(dial.Scale.Bands)
+	if err != nil {
+		return err
+	}
+
 	// Insert row into database.
 	result, err := tx.ExecContext(ctx, `
 		INSERT INTO dials (
 			user_id,
 			name,
+			value,
 			invite_code,
+			require_approval,
+			anomaly_spike_threshold,
+			anomaly_sustained_threshold,
+			stale_policy,
+			stale_after_days,
+			stale_baseline,
+			stale_half_life_days,
+			reset_interval_days,
+			reset_value,
+			reset_timezone,
+			next_reset_at,
+			scale_min,
+			scale_max,
+			scale_step,
+			scale_bands,
+			expression,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		dial.UserID,
 		dial.Name,
+		dial.Value,
 		dial.InviteCode,
+		dial.RequireApproval,
+		dial.AnomalySpikeThreshold,
+		dial.AnomalySustainedThreshold,
+		dial.StalePolicy,
+		dial.StaleAfterDays,
+		dial.StaleBaseline,
+		dial.StaleHalfLifeDays,
+		dial.ResetIntervalDays,
+		dial.ResetValue,
+		dial.ResetTimezone,
+		(*NullTime)(&dial.NextResetAt),
+		dial.Scale.Min,
+		dial.Scale.Max,
+		dial.Scale.Step,
+		bands,
+		dial.Expression,
 		(*NullTime)(&dial.CreatedAt),
 		(*NullTime)(&dial.UpdatedAt),
 	)
@@ -410,10 +837,32 @@ func createDial(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
 		return fmt.Errorf("insert initial value: %w", err)
 	}
 
+	// Insert dimensions, if any.
+	if _, err := saveDialDimensions(ctx, tx, dial, nil); err != nil {
+		return fmt.Errorf("save dimensions: %w", err)
+	}
+
+	// Record the dials referenced by the expression, if any. The value is
+	// computed once the self membership is added below.
+	if err := saveDialExpressionInputs(ctx, tx, dial); err != nil {
+		return err
+	}
+
+	// Record creation in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialCreate,
+		TargetType: wtf.AuditTargetDial,
+		TargetID:   dial.ID,
+		DialID:     dial.ID,
+	}, nil, dial); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
 	// Create self membership automatically.
 	if err := createDialMembership(ctx, tx, &wtf.DialMembership{
 		DialID: dial.ID,
 		UserID: dial.UserID,
+		Value:  dial.Scale.Min,
 	}); err != nil {
 		return fmt.Errorf("create self-membership: %w", err)
 	}
@@ -429,82 +878,403 @@ func updateDial(ctx context.Context, tx *Tx, id int, upd wtf.DialUpdate) (*wtf.D
 		return dial, err
 	} else if !wtf.CanEditDial(ctx, dial) {
 		return dial, wtf.Errorf(wtf.EUNAUTHORIZED, "You must be the owner can edit a dial.")
+	} else if dial.IsArchived() {
+		return dial, wtf.Errorf(wtf.ECONFLICT, "Archived dials cannot be changed. Restore the dial first.")
 	}
 
+	// Save state of dial for the audit log.
+	prev := *dial
+
 	// Update fields, if set.
 	if v := upd.Name; v != nil {
 		dial.Name = *v
 	}
+	if v := upd.RequireApproval; v != nil {
+		dial.RequireApproval = *v
+	}
+	if v := upd.AnomalySpikeThreshold; v != nil {
+		dial.AnomalySpikeThreshold = *v
+	}
+	if v := upd.AnomalySustainedThreshold; v != nil {
+		dial.AnomalySustainedThreshold = *v
+	}
+	if v := upd.StalePolicy; v != nil {
+		dial.StalePolicy = *v
+	}
+	if v := upd.StaleAfterDays; v != nil {
+		dial.StaleAfterDays = *v
+	}
+	if v := upd.StaleBaseline; v != nil {
+		dial.StaleBaseline = *v
+	}
+	if v := upd.StaleHalfLifeDays; v != nil {
+		dial.StaleHalfLifeDays = *v
+	}
+	if v := upd.ResetIntervalDays; v != nil {
+		dial.ResetIntervalDays = *v
+	}
+	if v := upd.ResetValue; v != nil {
+		dial.ResetValue = *v
+	}
+	if v := upd.ResetTimezone; v != nil {
+		dial.ResetTimezone = *v
+	}
+	if v := upd.NextResetAt; v != nil {
+		dial.NextResetAt = normalizeDialNextResetAt(*v)
+	}
+	if v := upd.Scale; v != nil {
+		dial.Scale = *v
+		normalizeDialScale(&dial.Scale)
+	}
+	if v := upd.Dimensions; v != nil {
+		if err := applyDialDimensionsUpdate(dial, v); err != nil {
+			return dial, err
+		}
+	}
+	if v := upd.Expression; v != nil {
+		dial.Expression = strings.TrimSpace(*v)
+	}
 	dial.UpdatedAt = tx.now
 
-	// Perform basic field validation.
+	// Perform basic field validation. The next reset is only checked if it
+	// changed as the scheduler moves it forward after each reset.
 	if err := dial.Validate(); err != nil {
 		return dial, err
+	} else if !dial.NextResetAt.Equal(prev.NextResetAt) || (dial.HasResetPolicy() && !prev.HasResetPolicy()) {
+		if err := validateDialNextResetAt(tx, dial); err != nil {
+			return dial, err
+		}
+	}
+
+	bands, err := marshalDialBands(dial.Scale.Bands)
+	if err != nil {
+		return dial, err
 	}
 
 	// Execute update query.
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE dials
 		SET name = ?,
+		    require_approval = ?,
+		    anomaly_spike_threshold = ?,
+		    anomaly_sustained_threshold = ?,
+		    stale_policy = ?,
+		    stale_after_days = ?,
+		    stale_baseline = ?,
+		    stale_half_life_days = ?,
+		    reset_interval_days = ?,
+		    reset_value = ?,
+		    reset_timezone = ?,
+		    next_reset_at = ?,
+		    scale_min = ?,
+		    scale_max = ?,
+		    scale_step = ?,
+		    scale_bands = ?,
+		    expression = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
 		dial.Name,
+		dial.RequireApproval,
+		dial.AnomalySpikeThreshold,
+		dial.AnomalySustainedThreshold,
+		dial.StalePolicy,
+		dial.StaleAfterDays,
+		dial.StaleBaseline,
+		dial.StaleHalfLifeDays,
+		dial.ResetIntervalDays,
+		dial.ResetValue,
+		dial.ResetTimezone,
+		(*NullTime)(&dial.NextResetAt),
+		dial.Scale.Min,
+		dial.Scale.Max,
+		dial.Scale.Step,
+		bands,
+		dial.Expression,
 		(*NullTime)(&dial.UpdatedAt),
 		id,
 	); err != nil {
 		return dial, FormatError(err)
 	}
 
+	// Add, rename & remove dimensions.
+	removed, err := saveDialDimensions(ctx, tx, dial, prev.Dimensions)
+	if err != nil {
+		return dial, fmt.Errorf("save dimensions: %w", err)
+	}
+
+	// Replace the dials referenced by the expression, if it changed.
+	if dial.Expression != prev.Expression {
+		if err := saveDialExpressionInputs(ctx, tx, dial); err != nil {
+			return dial, err
+		}
+	}
+
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialUpdate,
+		TargetType: wtf.AuditTargetDial,
+		TargetID:   dial.ID,
+		DialID:     dial.ID,
+	}, &prev, dial); err != nil {
+		return dial, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Move member values onto the new scale, if it changed.
+	if dial.Scale.Min != prev.Scale.Min || dial.Scale.Max != prev.Scale.Max || dial.Scale.Step != prev.Scale.Step {
+		if err := moveDialMembershipsOntoScale(ctx, tx, dial); err != nil {
+			return dial, fmt.Errorf("move memberships onto scale: %w", err)
+		}
+	}
+
+	// Recompute member values from their remaining dimensions.
+	if removed {
+		if err := rollUpDialMembershipDimensions(ctx, tx, dial); err != nil {
+			return dial, fmt.Errorf("roll up member dimensions: %w", err)
+		}
+	}
+
+	// Recompute the dial value in case the stale policy changed.
+	if err := refreshDialValue(ctx, tx, dial.ID); err != nil {
+		return dial, fmt.Errorf("refresh dial value: %w", err)
+	}
+
+	// The dial's position on a new scale may differ even if its value did not
+	// change so the parent dial is recomputed as well.
+	if dial.Scale.Min != prev.Scale.Min || dial.Scale.Max != prev.Scale.Max {
+		if parent, err := findDialParent(ctx, tx, dial.ID); err != nil {
+			return dial, err
+		} else if parent != nil {
+			if err := refreshDialValue(ctx, tx, parent.ParentDialID); err != nil {
+				return dial, fmt.Errorf("refresh parent dial value: %w", err)
+			}
+		}
+	}
+
+	if err := tx.QueryRowContext(ctx, `SELECT value FROM dials WHERE id = ?`, dial.ID).Scan(&dial.Value); err != nil {
+		return dial, FormatError(err)
+	} else if dial.Dimensions, err = findDialDimensions(ctx, tx, dial.ID, &dial.Scale); err != nil {
+		return dial, fmt.Errorf("dial dimensions: %w", err)
+	}
+	dial.Band = dial.Scale.BandLabel(dial.Value)
 	return dial, nil
 }
 
-// deleteDial permanently deletes a dial by ID. Returns EUNAUTHORIZED if user
-// does not own the dial.
+// moveDialMembershipsOntoScale moves each member value that is not on the
+// dial's scale to the nearest value on the scale. Values are changed the same
+// way as a member update so the value history, audit log & events reflect it.
+func moveDialMembershipsOntoScale(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
+	memberships, err := listDialMemberships(ctx, tx, dial.ID)
+	if err != nil {
+		return err
+	}
+
+	note := wtf.DialScaleChangeNote
+	for _, membership := range memberships {
+		upd := wtf.DialMembershipUpdate{Note: &note}
+		if len(membership.Dimensions) == 0 {
+			if dial.Scale.Contains(membership.Value) {
+				continue
+			}
+			value := dial.Scale.Nearest(membership.Value)
+			upd.Value = &value
+		} else {
+			// Move each dimension. The membership value is rolled up from
+			// the dimensions so it may move even if they do not.
+			upd.Dimensions = make(map[string]int)
+			for _, dim := range membership.Dimensions {
+				if !dial.Scale.Contains(dim.Value) {
+					upd.Dimensions[dim.Name] = dial.Scale.Nearest(dim.Value)
+				}
+			}
+			if len(upd.Dimensions) == 0 && dial.Scale.Contains(membership.Value) {
+				continue
+			}
+		}
+
+		if err := applyDialMembershipUpdate(ctx, tx, membership, upd); err != nil {
+			return fmt.Errorf("move membership: id=%d err=%w", membership.ID, err)
+		}
+	}
+	return nil
+}
+
+// normalizeDialScale sorts the bands of a scale by value & ensures the list
+// of bands is non-nil so it encodes consistently.
+func normalizeDialScale(scale *wtf.DialScale) {
+	if scale.Bands == nil {
+		scale.Bands = []*wtf.DialBand{}
+	}
+	scale.SortBands()
+}
+
+// marshalDialBands encodes scale bands for storage in the dials table.
+func marshalDialBands(bands []*wtf.DialBand) (string, error) {
+	if bands == nil {
+		bands = []*wtf.DialBand{}
+	}
+	buf, err := json.Marshal(bands)
+	if err != nil {
+		return "", fmt.Errorf("marshal dial bands: %w", err)
+	}
+	return string(buf), nil
+}
+
+// unmarshalDialBands decodes scale bands stored in the dials table.
+func unmarshalDialBands(s string) ([]*wtf.DialBand, error) {
+	bands := make([]*wtf.DialBand, 0)
+	if err := json.Unmarshal([]byte(s), &bands); err != nil {
+		return nil, fmt.Errorf("unmarshal dial bands: %w", err)
+	}
+	return bands, nil
+}
+
+// normalizeDialNextResetAt returns t in UTC with second precision to match
+// how times are stored.
+func normalizeDialNextResetAt(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC().Truncate(time.Second)
+}
+
+// validateDialNextResetAt returns an error if the dial has a reset policy but
+// its next reset is not in the future.
+func validateDialNextResetAt(tx *Tx, dial *wtf.Dial) error {
+	if dial.HasResetPolicy() && !dial.NextResetAt.After(tx.now) {
+		return wtf.Errorf(wtf.EINVALID, "Next reset must be in the future.")
+	}
+	return nil
+}
+
+// deleteDial permanently deletes an archived dial by ID. Returns
+// EUNAUTHORIZED if user does not own the dial.
 func deleteDial(ctx context.Context, tx *Tx, id int) error {
 	// Verify object exists & the current user is the owner.
-	if dial, err := findDialByID(ctx, tx, id); err != nil {
+	dial, err := findDialByID(ctx, tx, id)
+	if err != nil {
 		return err
 	} else if !wtf.CanEditDial(ctx, dial) {
 		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner can delete a dial.")
+	} else if !dial.IsArchived() {
+		return wtf.Errorf(wtf.ECONFLICT, "Dial must be archived before it can be deleted.")
+	}
+	return purgeDial(ctx, tx, dial)
+}
+
+// purgeDial permanently deletes a dial along with its history. This performs
+// no permission checks so callers must verify the deletion is allowed.
+func purgeDial(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
+	// Find the dial's parent, if any, so it can be recomputed without the dial.
+	parent, err := findDialParent(ctx, tx, dial.ID)
+	if err != nil {
+		return err
+	}
+
+	// Record deletion in the audit log. The entry outlives the dial but is
+	// written first so the dial owner is recorded on it.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialDelete,
+		TargetType: wtf.AuditTargetDial,
+		TargetID:   dial.ID,
+		DialID:     dial.ID,
+	}, dial, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
 	}
 
 	// Remove row from database.
-	if _, err := tx.ExecContext(ctx, `DELETE FROM dials WHERE id = ?`, id); err != nil {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dials WHERE id = ?`, dial.ID); err != nil {
 		return FormatError(err)
 	}
+
+	// Recompute the parent from its remaining children.
+	if parent != nil {
+		if err := refreshDialValue(ctx, tx, parent.ParentDialID); err != nil {
+			return fmt.Errorf("refresh parent dial value: %w", err)
+		}
+	}
 	return nil
 }
 
 // refreshDialValue recomputes the WTF level of a dial by ID and saves it in dials.value.
+// Changes are rolled up through the dial's parents & dependent computed dials.
 func refreshDialValue(ctx context.Context, tx *Tx, id int) error {
-	// Fetch current dial value.
+	// Fetch current dial value. Archived dials keep the value they had when
+	// they were archived.
 	var oldValue int
-	if err := tx.QueryRowContext(ctx, `SELECT value FROM dials WHERE id = ? `, id).Scan(&oldValue); err == sql.ErrNoRows {
+	var archived bool
+	if err := tx.QueryRowContext(ctx, `SELECT value, archived_at IS NOT NULL FROM dials WHERE id = ? `, id).Scan(&oldValue, &archived); err == sql.ErrNoRows {
 		return nil // no dial, skip
 	} else if err != nil {
 		return FormatError(err)
+	} else if archived {
+		return nil
 	}
 
-	// Compute average value from dial memberships.
-	var newValue int
-	if err := tx.QueryRowContext(ctx, `
-		SELECT CAST(ROUND(IFNULL(AVG(value), 0)) AS INTEGER)
-		FROM dial_memberships
-		WHERE dial_id = ?
-	`,
-		id,
-	).Scan(
-		&newValue,
-	); err != nil && err != sql.ErrNoRows {
-		return FormatError(err)
+	// Computed dials take their value from their expression. Otherwise, dials
+	// with children take their value from them instead of members.
+	newValue, ok, err := evaluateDialExpression(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("evaluate dial expression: %w", err)
+	} else if !ok {
+		if newValue, ok, err = rollUpDialChildren(ctx, tx, id); err != nil {
+			return fmt.Errorf("roll up child dials: %w", err)
+		}
 	}
 
-	// Exit if the value will not change.
-	if oldValue == newValue {
-		return nil
+	// Otherwise, compute average value from active dial memberships after
+	// applying the dial's stale policy. Pending memberships do not contribute
+	// until they are approved. Without any contributors the dial sits at the
+	// bottom of its scale.
+	if !ok {
+		values, err := findEffectiveDialMemberValues(ctx, tx, id)
+		if err != nil {
+			return fmt.Errorf("effective member values: %w", err)
+		}
+		if len(values) > 0 {
+			var sum int
+			for _, v := range values {
+				sum += v
+			}
+			newValue = int(math.Round(float64(sum) / float64(len(values))))
+		} else {
+			scale, err := findDialScale(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			newValue = scale.Min
+		}
 	}
 
+	// Update value, record history & notify members if the value changed.
+	// The change is then rolled up into the dial's parent, if any, and into
+	// any computed dials that reference it.
+	if oldValue != newValue {
+		if err := updateDialValue(ctx, tx, id, newValue); err != nil {
+			return err
+		} else if err := propagateDialValue(ctx, tx, id, newValue); err != nil {
+			return err
+		} else if err := refreshDependentDials(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+
+	// Update the aggregate value of each dimension.
+	if err := refreshDialDimensionValues(ctx, tx, id); err != nil {
+		return fmt.Errorf("refresh dimension values: %w", err)
+	}
+
+	// Evaluate alert rules. Member conditions may be met even if the dial
+	// value itself has not changed.
+	if err := evaluateDialAlertRules(ctx, tx, id); err != nil {
+		return fmt.Errorf("evaluate dial alert rules: %w", err)
+	}
+	return nil
+}
+
+// updateDialValue sets a new computed value on a dial, records it in the
+// dial's history & notifies active members.
+func updateDialValue(ctx context.Context, tx *Tx, id, newValue int) error {
 	// Update value on dial.
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE dials
@@ -556,50 +1326,34 @@ func insertDialValue(ctx context.Context, tx *Tx, id int, value int, timestamp t
 	return nil
 }
 
-// findDialValueSlotsBetween returns the value of a dial at given intervals in a time range.
-//
-// This function is implemented naively so that we build a set of slots, insert
-// values when they've changed, and then we backfill the empty slots with the
-// previous value.
-//
-// There's probably a fancier way to do this in SQL but this was pretty easy.
-func findDialValueSlotsBetween(ctx context.Context, tx *Tx, id int, start, end time.Time, interval time.Duration) ([]int, error) {
-	values := make([]int, end.Sub(start)/interval)
-	if len(values) == 0 {
-		return values, nil
-	}
-
-	// Mark slots empty. We'll fill them in later.
-	for i := range values {
-		values[i] = -1
-	}
+// dialValueChange represents a value recorded at a point in time.
+type dialValueChange struct {
+	Value     int
+	Timestamp time.Time
+}
 
+// findDialValueChangesBetween returns the dial value in effect at start and
+// the list of recorded values between start & end, in time order.
+func findDialValueChangesBetween(ctx context.Context, tx *Tx, id int, start, end time.Time) (initial int, changes []dialValueChange, err error) {
 	// Determine initial value at start of report time range.
-	var value int
 	if err := tx.QueryRowContext(ctx, `
 		SELECT value
 		FROM dial_values
-		WHERE dial_id = ?
-		  AND "timestamp" <= ?
+		WHERE dial_id = ? AND "timestamp" < ?
 		ORDER BY "timestamp" DESC
 		LIMIT 1
-		`,
+	`,
 		id,
 		(*NullTime)(&start),
-	).Scan(
-		&value,
-	); err != nil && err != sql.ErrNoRows {
-		return nil, err
+	).Scan(&initial); err != nil && err != sql.ErrNoRows {
+		return 0, nil, FormatError(err)
 	}
-	values[0] = value
 
 	// Find all values between start & end.
 	rows, err := tx.QueryContext(ctx, `
 		SELECT value, "timestamp"
 		FROM dial_values
-		WHERE dial_id = ?
-		  AND "timestamp" >= ?
-		  AND "timestamp" < ?
+		WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ?
 		ORDER BY "timestamp" ASC
 	`,
 		id,
@@ -607,43 +1361,383 @@ func findDialValueSlotsBetween(ctx context.Context, tx *Tx, id int, start, end t
 		(*NullTime)(&end),
 	)
 	if err != nil {
-		return nil, FormatError(err)
+		return 0, nil, FormatError(err)
 	}
 	defer rows.Close()
 
-	// Iterate over rows and assign values to slots.
 	for rows.Next() {
-		var timestamp time.Time
-		if rows.Scan(&value, (*NullTime)(&timestamp)); err != nil {
+		var change dialValueChange
+		if err := rows.Scan(&change.Value, (*NullTime)(&change.Timestamp)); err != nil {
+			return 0, nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return initial, changes, nil
+}
+
+// dialValueBucket represents the aggregate value of a dial over one slot.
+type dialValueBucket struct {
+	Timestamp time.Time // start of slot
+	Value     int       // value at end of slot
+	Min       int
+	Max       int
+	Avg       float64 // time-weighted average
+}
+
+// record returns the bucket as a report record.
+func (b *dialValueBucket) record() *wtf.DialValueRecord {
+	return &wtf.DialValueRecord{
+		Value:     b.Value,
+		Min:       b.Min,
+		Max:       b.Max,
+		Avg:       int(math.Round(b.Avg)),
+		Timestamp: b.Timestamp,
+	}
+}
+
+// buildDialValueRecords folds a time-ordered list of value changes into report
+// records between start & end. The initial value is the value in effect at
+// start. Each record holds the value at the end of the slot along with the
+// minimum, maximum & time-weighted average value during the slot.
+func buildDialValueRecords(initial int, changes []dialValueChange, start, end time.Time, interval time.Duration) []*wtf.DialValueRecord {
+	buckets := buildDialValueBuckets(initial, changes, start, end, interval)
+	records := make([]*wtf.DialValueRecord, len(buckets))
+	for i := range buckets {
+		records[i] = buckets[i].record()
+	}
+	return records
+}
+
+// buildDialValueBuckets folds a time-ordered list of value changes into
+// buckets between start & end. The initial value is the value in effect at
+// start.
+func buildDialValueBuckets(initial int, changes []dialValueChange, start, end time.Time, interval time.Duration) []dialValueBucket {
+	buckets := make([]dialValueBucket, end.Sub(start)/interval)
+
+	value := initial
+	for i := range buckets {
+		slotStart := start.Add(time.Duration(i) * interval)
+		slotEnd := slotStart.Add(interval)
+		bucket := dialValueBucket{Timestamp: slotStart, Min: -1, Max: -1}
+
+		// Track a value in the min/max once it has been in effect.
+		observe := func(v int) {
+			if bucket.Min == -1 || v < bucket.Min {
+				bucket.Min = v
+			}
+			if bucket.Max == -1 || v > bucket.Max {
+				bucket.Max = v
+			}
+		}
+
+		// Apply each change within the slot, weighting the previous value by
+		// how long it was in effect.
+		var sum float64
+		t := slotStart
+		for len(changes) > 0 && changes[0].Timestamp.Before(slotEnd) {
+			change := changes[0]
+			changes = changes[1:]
+
+			if change.Timestamp.After(t) {
+				observe(value)
+				sum += float64(value) * float64(change.Timestamp.Sub(t))
+				t = change.Timestamp
+			}
+			value = change.Value
+		}
+		observe(value)
+		sum += float64(value) * float64(slotEnd.Sub(t))
+
+		bucket.Value = value
+		bucket.Avg = sum / float64(interval)
+		buckets[i] = bucket
+	}
+	return buckets
+}
+
+// fillDialValueBuckets expands a sparse, time-ordered list of rolled up
+// buckets into one bucket per interval between start & end. Slots without a
+// bucket carry the previous value forward. The initial value is the value in
+// effect at start.
+func fillDialValueBuckets(initial int, sparse []dialValueBucket, start, end time.Time, interval time.Duration) []dialValueBucket {
+	buckets := make([]dialValueBucket, end.Sub(start)/interval)
+
+	value := initial
+	for i := range buckets {
+		t := start.Add(time.Duration(i) * interval)
+		if len(sparse) > 0 && sparse[0].Timestamp.Equal(t) {
+			buckets[i], sparse = sparse[0], sparse[1:]
+		} else {
+			buckets[i] = dialValueBucket{Timestamp: t, Value: value, Min: value, Max: value, Avg: float64(value)}
+		}
+		value = buckets[i].Value
+	}
+	return buckets
+}
+
+// mergeDialValueBuckets combines every n consecutive buckets into a single
+// bucket. Buckets must be contiguous & of equal size.
+func mergeDialValueBuckets(buckets []dialValueBucket, n int) []dialValueBucket {
+	if n <= 1 {
+		return buckets
+	}
+
+	merged := make([]dialValueBucket, 0, len(buckets)/n)
+	for len(buckets) >= n {
+		group := buckets[:n]
+		buckets = buckets[n:]
+
+		bucket := group[0]
+		for _, other := range group[1:] {
+			if other.Min < bucket.Min {
+				bucket.Min = other.Min
+			}
+			if other.Max > bucket.Max {
+				bucket.Max = other.Max
+			}
+			bucket.Avg += other.Avg
+		}
+		bucket.Value = group[n-1].Value
+		bucket.Avg /= float64(n)
+		merged = append(merged, bucket)
+	}
+	return merged
+}
+
+// findDialValueBuckets returns the aggregate value of a dial for each interval
+// between start & end. Start & end must be aligned to the interval.
+//
+// Intervals that no rollup tier divides are computed entirely from raw values
+// so EINVALID is returned if they start before the retention cutoff.
+func findDialValueBuckets(ctx context.Context, tx *Tx, id int, start, end time.Time, interval time.Duration) ([]dialValueBucket, error) {
+	return findTieredDialValueBuckets(ctx, tx, planDialValueTiers(interval), id, start, end, interval)
+}
+
+// findTieredDialValueBuckets computes buckets using the first tier up to the
+// point it has been rolled up to. The remainder of the range is computed from
+// the next finer tier, falling back to raw values after the last tier.
+func findTieredDialValueBuckets(ctx context.Context, tx *Tx, tiers []*dialValueTier, id int, start, end time.Time, interval time.Duration) ([]dialValueBucket, error) {
+	if len(tiers) == 0 {
+		if err := checkRawDialValuesRetained(ctx, tx, start); err != nil {
 			return nil, err
 		}
 
-		i := int(timestamp.Sub(start) / interval)
-		values[i] = value
+		initial, changes, err := findDialValueChangesBetween(ctx, tx, id, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return buildDialValueBuckets(initial, changes, start, end, interval), nil
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	} else if err := rows.Close(); err != nil {
+	tier := tiers[0]
+
+	// Split the range at the point the tier has been rolled up to.
+	mid, err := findDialValueRollupWatermark(ctx, tx, tier)
+	if err != nil {
 		return nil, err
+	} else if mid.Before(start) {
+		mid = start
+	} else if mid.After(end) {
+		mid = end
 	}
 
-	// Iterate over values to fill empty slots.
-	var lastValue int
+	// Read rolled up buckets for the first part of the range.
+	var buckets []dialValueBucket
+	if mid.After(start) {
+		initial, sparse, err := findDialValueRollupsBetween(ctx, tx, tier, id, start, mid)
+		if err != nil {
+			return nil, err
+		}
+		buckets = fillDialValueBuckets(initial, sparse, start, mid, tier.Interval)
+	}
+
+	// Compute buckets that have not been rolled up yet from finer tiers.
+	if end.After(mid) {
+		other, err := findTieredDialValueBuckets(ctx, tx, tiers[1:], id, mid, end, tier.Interval)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, other...)
+	}
+
+	return mergeDialValueBuckets(buckets, int(interval/tier.Interval)), nil
+}
+
+// checkRawDialValuesRetained returns EINVALID if a report would read raw dial
+// values from before the retention cutoff. Those values may have been pruned
+// so the report would silently be missing changes.
+func checkRawDialValuesRetained(ctx context.Context, tx *Tx, start time.Time) error {
+	if cutoff, err := findDialValueRetentionCutoff(ctx, tx); err != nil {
+		return err
+	} else if start.Before(cutoff) {
+		return wtf.Errorf(wtf.EINVALID, "Reports with intervals that are not whole hours must start within the last %s while raw dial values are retained.", tx.db.DialValueRetention)
+	}
+	return nil
+}
+
+// buildDialValueHeatmap averages hourly values into cells by the local
+// weekday & hour that each hour starts in. Converting each hour separately
+// means daylight saving changes are handled by the location. Locations with
+// offsets that are not whole hours are grouped by the local hour that the
+// UTC hour starts in.
+func buildDialValueHeatmap(values []float64, start, end time.Time, loc *time.Location) *wtf.DialValueHeatmap {
+	heatmap := wtf.NewDialValueHeatmap(loc, start, end)
+
+	var sums [7 * 24]float64
 	for i, v := range values {
-		if v != -1 {
-			lastValue = v
-			continue
+		t := start.Add(time.Duration(i) * time.Hour).In(loc)
+		cell := heatmap.Cell(t.Weekday(), t.Hour())
+		sums[int(cell.Weekday)*24+cell.Hour] += v
+		cell.N++
+	}
+
+	for i, cell := range heatmap.Cells {
+		if cell.N > 0 {
+			cell.Value = int(math.Round(sums[i] / float64(cell.N)))
 		}
-		values[i] = lastValue
+	}
+	return heatmap
+}
+
+// findAverageDialValueSlotsBetween returns the average value of all dials the
+// current user is a member of at the end of each interval in a time range.
+// Start & end must be aligned to the interval.
+//
+// The report is computed in a single query across all dials. The last value of
+// each dial within each slot is found with a window function and converted
+// into the change from the dial's previous known value. A running total of
+// these changes gives the sum of all dial values at every slot, which fills
+// slots without changes without expanding every dial into every slot.
+func findAverageDialValueSlotsBetween(ctx context.Context, tx *Tx, start, end time.Time, interval time.Duration) ([]int, error) {
+	values := make([]int, end.Sub(start)/interval)
+	if len(values) == 0 {
+		return values, nil
+	}
+
+	// Determine which tables hold values for each part of the timeline.
+	sources, err := findDialValueSources(ctx, tx, interval)
+	if err != nil {
+		return nil, err
 	}
 
+	// Raw values are read from the end of the last rolled up tier onward.
+	from := start
+	if raw := sources[len(sources)-1]; raw.Min.After(from) {
+		from = raw.Min
+	}
+	if err := checkRawDialValuesRetained(ctx, tx, from); err != nil {
+		return nil, err
+	}
+
+	// Build a lookup of the value of each dial at the start of the range. Each
+	// source is checked from the most recent so the latest value is used.
+	var initials []string
+	var initialArgs []interface{}
+	for i := len(sources) - 1; i >= 0; i-- {
+		where, args := sources[i].where()
+		initials = append(initials, `(
+			SELECT value
+			FROM `+sources[i].Table+`
+			WHERE dial_id = d.dial_id AND "timestamp" < ? AND `+where+`
+			ORDER BY "timestamp" DESC
+			LIMIT 1
+		)`)
+		initialArgs = append(append(initialArgs, (*NullTime)(&start)), args...)
+	}
+
+	// Build a list of all values recorded within the range.
+	var changes []string
+	var changeArgs []interface{}
+	for _, src := range sources {
+		where, args := src.where()
+		changes = append(changes, `
+			SELECT dial_id, "timestamp", value
+			FROM `+src.Table+`
+			WHERE dial_id IN (SELECT dial_id FROM user_dials)
+			  AND "timestamp" >= ? AND "timestamp" < ? AND `+where)
+		changeArgs = append(append(changeArgs, (*NullTime)(&start), (*NullTime)(&end)), args...)
+	}
+
+	args := []interface{}{len(values), wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive}
+	args = append(args, initialArgs...)
+	args = append(args, start.Unix(), int64(interval/time.Second))
+	args = append(args, changeArgs...)
+
+	// Slot -1 holds each dial's value before the range so every dial has a
+	// known value to carry forward into the first slot.
+	rows, err := tx.QueryContext(ctx, `
+		WITH RECURSIVE
+		slots (i) AS (
+			SELECT -1
+			UNION ALL
+			SELECT i + 1 FROM slots WHERE i + 1 < ?
+		),
+		user_dials (dial_id) AS (
+			SELECT dm.dial_id
+			FROM dial_memberships dm
+			INNER JOIN dials d ON d.id = dm.dial_id
+			WHERE dm.user_id = ? AND dm.status = ? AND d.archived_at IS NULL
+		),
+		known (dial_id, i, value) AS (
+			SELECT d.dial_id, -1, COALESCE(`+strings.Join(initials, ", ")+`, 0)
+			FROM user_dials d
+			UNION ALL
+			SELECT dial_id, i, value
+			FROM (
+				SELECT dial_id, i, value, ROW_NUMBER() OVER (PARTITION BY dial_id, i ORDER BY "timestamp" DESC) AS rn
+				FROM (
+					SELECT dial_id, "timestamp", value, (CAST(strftime('%s', "timestamp") AS INTEGER) - ?) / ? AS i
+					FROM (`+strings.Join(changes, " UNION ALL ")+`)
+				)
+			)
+			WHERE rn = 1
+		),
+		deltas (i, delta) AS (
+			SELECT i, SUM(delta)
+			FROM (
+				SELECT i, value - IFNULL(LAG(value) OVER (PARTITION BY dial_id ORDER BY i), 0) AS delta
+				FROM known
+			)
+			GROUP BY i
+		)
+		SELECT i, IFNULL(total / NULLIF((SELECT COUNT(*) FROM user_dials), 0), 0)
+		FROM (
+			SELECT s.i, SUM(IFNULL(d.delta, 0)) OVER (ORDER BY s.i) AS total
+			FROM slots s
+			LEFT JOIN deltas d ON d.i = s.i
+		)
+		WHERE i >= 0
+		ORDER BY i
+	`, args...)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i, value int
+		if err := rows.Scan(&i, &value); err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return values, nil
 }
 
-// publishDialEvent publishes event to the dial members.
+// publishDialEvent publishes event to the active dial members.
 func publishDialEvent(ctx context.Context, tx *Tx, id int, event wtf.Event) error {
-	// Find all users who are members of the dial.
-	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM dial_memberships WHERE dial_id = ?`, id)
+	// Find all users who are active members of the dial.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id
+		FROM dial_memberships
+		WHERE dial_id = ?
+		  AND status = ?
+	`, id, wtf.DialMembershipStatusActive)
 	if err != nil {
 		return FormatError(err)
 	}
@@ -664,10 +1758,13 @@ func publishDialEvent(ctx context.Context, tx *Tx, id int, event wtf.Event) erro
 	return nil
 }
 
-// attachDialAssociations is a helper function to look up and attach the owner user to the dial.
+// attachDialAssociations is a helper function to look up and attach the owner
+// user & the spread of member values to the dial.
 func attachDialAssociations(ctx context.Context, tx *Tx, dial *wtf.Dial) (err error) {
 	if dial.User, err = findUserByID(ctx, tx, dial.UserID); err != nil {
 		return fmt.Errorf("attach dial user: %w", err)
+	} else if dial.Stats, err = findDialStats(ctx, tx, dial.ID); err != nil {
+		return fmt.Errorf("attach dial stats: %w", err)
 	}
 	return nil
 }
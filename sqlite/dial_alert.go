@@ -0,0 +1,900 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.DialAlertService = (*DialAlertService)(nil)
+
+// DialAlertService represents a service for managing dial alert rules.
+type DialAlertService struct {
+	db *DB
+}
+
+// NewDialAlertService returns a new instance of DialAlertService.
+func NewDialAlertService(db *DB) *DialAlertService {
+	return &DialAlertService{db: db}
+}
+
+// FindDialAlertRuleByID retrieves a single rule by ID. Returns ENOTFOUND if
+// the rule does not exist or the user is not a member of the rule's dial.
+func (s *DialAlertService) FindDialAlertRuleByID(ctx context.Context, id int) (*wtf.DialAlertRule, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return findDialAlertRuleByID(ctx, tx, id)
+}
+
+// FindDialAlertRules retrieves a list of rules based on a filter. Only returns
+// rules for dials that the user is a member of.
+func (s *DialAlertService) FindDialAlertRules(ctx context.Context, filter wtf.DialAlertRuleFilter) ([]*wtf.DialAlertRule, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	return findDialAlertRules(ctx, tx, filter)
+}
+
+// CreateDialAlertRule creates a new rule on a dial. Only the dial owner can
+// create rules. The rule is evaluated immediately so it may fire upon creation.
+func (s *DialAlertService) CreateDialAlertRule(ctx context.Context, rule *wtf.DialAlertRule) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createDialAlertRule(ctx, tx, rule); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateDialAlertRule updates an existing rule. Only the dial owner can
+// update rules. The rule's condition state is reset & it is re-evaluated.
+func (s *DialAlertService) UpdateDialAlertRule(ctx context.Context, id int, upd wtf.DialAlertRuleUpdate) (*wtf.DialAlertRule, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rule, err := updateDialAlertRule(ctx, tx, id, upd)
+	if err != nil {
+		return rule, err
+	} else if err := tx.Commit(); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// DeleteDialAlertRule permanently deletes a rule & its firing history.
+// Only the dial owner can delete rules.
+func (s *DialAlertService) DeleteDialAlertRule(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteDialAlertRule(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindDialAlertFirings retrieves a list of firings based on a filter. Only
+// returns firings for dials that the user is a member of.
+func (s *DialAlertService) FindDialAlertFirings(ctx context.Context, filter wtf.DialAlertFiringFilter) ([]*wtf.DialAlertFiring, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Fetch list of matching firings.
+	firings, n, err := findDialAlertFirings(ctx, tx, filter)
+	if err != nil {
+		return firings, n, err
+	}
+
+	// Attach the rule that fired to each firing.
+	for _, firing := range firings {
+		if firing.Rule, err = findDialAlertRuleByID(ctx, tx, firing.RuleID); err != nil {
+			return firings, n, fmt.Errorf("attach firing rule: %w", err)
+		}
+	}
+	return firings, n, nil
+}
+
+// EvaluateDialAlertRules evaluates the rules on every dial. Rules are also
+// evaluated whenever a dial's values change but this catches conditions that
+// have held for their required duration or whose cooldown has passed. This is
+// called periodically by the background monitor but can also be called
+// directly, such as from tests.
+func (db *DB) EvaluateDialAlertRules(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Find all dials which have at least one rule.
+	// Archived dials are frozen so their rules are not evaluated.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT dial_id
+		FROM dial_alert_rules
+		WHERE dial_id IN (SELECT id FROM dials WHERE archived_at IS NULL)
+		ORDER BY dial_id
+	`)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	var dialIDs []int
+	for rows.Next() {
+		var dialID int
+		if err := rows.Scan(&dialID); err != nil {
+			return err
+		}
+		dialIDs = append(dialIDs, dialID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, dialID := range dialIDs {
+		if err := evaluateDialAlertRules(ctx, tx, dialID); err != nil {
+			return fmt.Errorf("evaluate dial alert rules: id=%d err=%w", dialID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeliverDialAlertFirings sends pending firings to their webhook & email
+// targets. Notifiers are called outside of a transaction so slow targets do
+// not block writes. Each firing is attempted once & is marked as failed if any
+// target could not be delivered to. This is called periodically by the
+// background monitor but can also be called directly, such as from tests.
+func (db *DB) DeliverDialAlertFirings(ctx context.Context) error {
+	firings, err := db.findPendingDialAlertFirings(ctx)
+	if err != nil {
+		return fmt.Errorf("find pending firings: %w", err)
+	}
+
+	for _, firing := range firings {
+		firing.Status, firing.Error = wtf.DialAlertFiringStatusSent, ""
+		if err := db.notifyDialAlert(ctx, firing); err != nil {
+			firing.Status, firing.Error = wtf.DialAlertFiringStatusFailed, err.Error()
+		}
+
+		if err := db.updateDialAlertFiringStatus(ctx, firing); err != nil {
+			return fmt.Errorf("update firing status: id=%d err=%w", firing.ID, err)
+		}
+	}
+	return nil
+}
+
+// findPendingDialAlertFirings returns all firings awaiting delivery along with
+// their rule & dial. This bypasses permission checks as it is used by the
+// background delivery job.
+func (db *DB) findPendingDialAlertFirings(ctx context.Context) ([]*wtf.DialAlertFiring, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	firings, _, err := queryDialAlertFirings(ctx, tx, []string{"status = ?"}, []interface{}{wtf.DialAlertFiringStatusPending}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, firing := range firings {
+		rules, _, err := queryDialAlertRules(ctx, tx, []string{"r.id = ?"}, []interface{}{firing.RuleID}, "")
+		if err != nil {
+			return nil, err
+		} else if len(rules) == 0 {
+			return nil, fmt.Errorf("firing rule not found: id=%d", firing.RuleID)
+		}
+		firing.Rule = rules[0]
+
+		firing.Dial = &wtf.Dial{ID: firing.DialID}
+		if err := tx.QueryRowContext(ctx, `SELECT user_id, name, value FROM dials WHERE id = ?`, firing.DialID).Scan(
+			&firing.Dial.UserID,
+			&firing.Dial.Name,
+			&firing.Dial.Value,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+	}
+	return firings, nil
+}
+
+// notifyDialAlert sends a firing to each of its rule's external targets.
+// Returns an error describing every target that could not be delivered to.
+func (db *DB) notifyDialAlert(ctx context.Context, firing *wtf.DialAlertFiring) error {
+	var errs []string
+	if firing.Rule.WebhookURL != "" {
+		if db.DialAlertWebhookNotifier == nil {
+			errs = append(errs, "webhook: notifier not configured")
+		} else if err := db.DialAlertWebhookNotifier.NotifyDialAlert(ctx, firing); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook: %s", err))
+		}
+	}
+	if firing.Rule.Email != "" {
+		if ok, err := db.isDialMemberEmail(ctx, firing.DialID, firing.Rule.Email); err != nil {
+			return err
+		} else if !ok {
+			errs = append(errs, "email: address does not belong to a member of the dial")
+		} else if db.DialAlertEmailNotifier == nil {
+			errs = append(errs, "email: notifier not configured")
+		} else if err := db.DialAlertEmailNotifier.NotifyDialAlert(ctx, firing); err != nil {
+			errs = append(errs, fmt.Sprintf("email: %s", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// isDialMemberEmail returns true if email is still the account address of an
+// active member of the dial. Members may leave after a rule is saved so this
+// is checked again before each email is sent.
+func (db *DB) isDialMemberEmail(ctx context.Context, dialID int, email string) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	return isDialMemberEmail(ctx, tx, dialID, email)
+}
+
+// updateDialAlertFiringStatus saves the delivery status of a firing.
+func (db *DB) updateDialAlertFiringStatus(ctx context.Context, firing *wtf.DialAlertFiring) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_alert_firings
+		SET status = ?,
+		    error = ?
+		WHERE id = ?
+	`,
+		firing.Status,
+		firing.Error,
+		firing.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return tx.Commit()
+}
+
+// findDialAlertRuleByID is a helper function to retrieve a rule by ID.
+// Returns ENOTFOUND if rule doesn't exist.
+func findDialAlertRuleByID(ctx context.Context, tx *Tx, id int) (*wtf.DialAlertRule, error) {
+	rules, _, err := findDialAlertRules(ctx, tx, wtf.DialAlertRuleFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(rules) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial alert rule not found."}
+	}
+	return rules[0], nil
+}
+
+// findDialAlertRules retrieves a list of matching rules. Also returns a total
+// matching count which may differ from the number of results if filter.Limit
+// is set.
+func findDialAlertRules(ctx context.Context, tx *Tx, filter wtf.DialAlertRuleFilter) (_ []*wtf.DialAlertRule, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "r.id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "r.dial_id = ?"), append(args, *v)
+	}
+
+	// Limit to rules on dials the user is a member of.
+	where = append(where, `r.dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)`)
+	args = append(args, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive)
+
+	return queryDialAlertRules(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// queryDialAlertRules executes a query for rules with the given WHERE clause
+// parts. This performs no permission checks so callers must add them.
+func queryDialAlertRules(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.DialAlertRule, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    r.id,
+		    r.dial_id,
+		    r.name,
+		    r.subject,
+		    r.operator,
+		    r.threshold,
+		    r.duration_minutes,
+		    r.cooldown_minutes,
+		    r.notify_in_app,
+		    r.webhook_url,
+		    r.email,
+		    r.condition_since,
+		    r.last_fired_at,
+		    r.created_at,
+		    r.updated_at,
+		    COUNT(*) OVER()
+		FROM dial_alert_rules r
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY r.id ASC
+		`+limitOffset,
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialAlertRule objects.
+	rules := make([]*wtf.DialAlertRule, 0)
+	for rows.Next() {
+		var rule wtf.DialAlertRule
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.DialID,
+			&rule.Name,
+			&rule.Subject,
+			&rule.Operator,
+			&rule.Threshold,
+			&rule.DurationMinutes,
+			&rule.CooldownMinutes,
+			&rule.NotifyInApp,
+			&rule.WebhookURL,
+			&rule.Email,
+			(*NullTime)(&rule.ConditionSince),
+			(*NullTime)(&rule.LastFiredAt),
+			(*NullTime)(&rule.CreatedAt),
+			(*NullTime)(&rule.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return rules, n, nil
+}
+
+// createDialAlertRule creates a new rule on a dial & evaluates it.
+// Returns EUNAUTHORIZED if the current user is not the dial owner.
+func createDialAlertRule(ctx context.Context, tx *Tx, rule *wtf.DialAlertRule) error {
+	rule.ConditionSince, rule.LastFiredAt = time.Time{}, time.Time{}
+	rule.CreatedAt = tx.now
+	rule.UpdatedAt = rule.CreatedAt
+
+	// Perform basic field validation.
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	// Only the dial owner can create rules.
+	dial, err := findDialByID(ctx, tx, rule.DialID)
+	if err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can create alert rules.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return err
+	} else if err := validateDialAlertRuleThreshold(rule, dial); err != nil {
+		return err
+	} else if err := validateDialAlertRuleEmail(ctx, tx, rule); err != nil {
+		return err
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_alert_rules (
+			dial_id,
+			name,
+			subject,
+			operator,
+			threshold,
+			duration_minutes,
+			cooldown_minutes,
+			notify_in_app,
+			webhook_url,
+			email,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		rule.DialID,
+		rule.Name,
+		rule.Subject,
+		rule.Operator,
+		rule.Threshold,
+		rule.DurationMinutes,
+		rule.CooldownMinutes,
+		rule.NotifyInApp,
+		rule.WebhookURL,
+		rule.Email,
+		(*NullTime)(&rule.CreatedAt),
+		(*NullTime)(&rule.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new rule ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rule.ID = int(id)
+
+	// Record the new rule in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialAlertRuleCreate,
+		TargetType: wtf.AuditTargetDialAlertRule,
+		TargetID:   rule.ID,
+		DialID:     rule.DialID,
+	}, nil, rule); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Evaluate the new rule against the dial's current values.
+	if err := evaluateDialAlertRules(ctx, tx, rule.DialID); err != nil {
+		return fmt.Errorf("evaluate dial alert rules: %w", err)
+	}
+
+	// Read back the rule's condition state after evaluation.
+	other, err := findDialAlertRuleByID(ctx, tx, rule.ID)
+	if err != nil {
+		return err
+	}
+	rule.ConditionSince, rule.LastFiredAt = other.ConditionSince, other.LastFiredAt
+	return nil
+}
+
+// updateDialAlertRule updates fields on a rule by ID, resets its condition
+// state & re-evaluates it. Returns EUNAUTHORIZED if the current user is not
+// the dial owner.
+func updateDialAlertRule(ctx context.Context, tx *Tx, id int, upd wtf.DialAlertRuleUpdate) (*wtf.DialAlertRule, error) {
+	// Fetch current object state & verify the current user owns the dial.
+	rule, err := findDialAlertRuleByID(ctx, tx, id)
+	if err != nil {
+		return rule, err
+	}
+	dial, err := findDialByID(ctx, tx, rule.DialID)
+	if err != nil {
+		return rule, err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return rule, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can update alert rules.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return rule, err
+	}
+
+	// Save state of rule for the audit log.
+	prev := *rule
+
+	// Update fields, if set.
+	if v := upd.Name; v != nil {
+		rule.Name = *v
+	}
+	if v := upd.Subject; v != nil {
+		rule.Subject = *v
+	}
+	if v := upd.Operator; v != nil {
+		rule.Operator = *v
+	}
+	if v := upd.Threshold; v != nil {
+		rule.Threshold = *v
+	}
+	if v := upd.DurationMinutes; v != nil {
+		rule.DurationMinutes = *v
+	}
+	if v := upd.CooldownMinutes; v != nil {
+		rule.CooldownMinutes = *v
+	}
+	if v := upd.NotifyInApp; v != nil {
+		rule.NotifyInApp = *v
+	}
+	if v := upd.WebhookURL; v != nil {
+		rule.WebhookURL = *v
+	}
+	if v := upd.Email; v != nil {
+		rule.Email = *v
+	}
+	rule.ConditionSince = time.Time{}
+	rule.UpdatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := rule.Validate(); err != nil {
+		return rule, err
+	} else if err := validateDialAlertRuleThreshold(rule, dial); err != nil {
+		return rule, err
+	} else if err := validateDialAlertRuleEmail(ctx, tx, rule); err != nil {
+		return rule, err
+	}
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_alert_rules
+		SET name = ?,
+		    subject = ?,
+		    operator = ?,
+		    threshold = ?,
+		    duration_minutes = ?,
+		    cooldown_minutes = ?,
+		    notify_in_app = ?,
+		    webhook_url = ?,
+		    email = ?,
+		    condition_since = NULL,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		rule.Name,
+		rule.Subject,
+		rule.Operator,
+		rule.Threshold,
+		rule.DurationMinutes,
+		rule.CooldownMinutes,
+		rule.NotifyInApp,
+		rule.WebhookURL,
+		rule.Email,
+		(*NullTime)(&rule.UpdatedAt),
+		id,
+	); err != nil {
+		return rule, FormatError(err)
+	}
+
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialAlertRuleUpdate,
+		TargetType: wtf.AuditTargetDialAlertRule,
+		TargetID:   rule.ID,
+		DialID:     rule.DialID,
+	}, &prev, rule); err != nil {
+		return rule, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Re-evaluate the rule with its new condition.
+	if err := evaluateDialAlertRules(ctx, tx, rule.DialID); err != nil {
+		return rule, fmt.Errorf("evaluate dial alert rules: %w", err)
+	}
+	return findDialAlertRuleByID(ctx, tx, id)
+}
+
+// validateDialAlertRuleThreshold returns an error if the rule's threshold is
+// outside of the dial's scale.
+func validateDialAlertRuleThreshold(rule *wtf.DialAlertRule, dial *wtf.Dial) error {
+	if rule.Threshold < dial.Scale.Min || rule.Threshold > dial.Scale.Max {
+		return wtf.Errorf(wtf.EINVALID, "Alert rule threshold must be between %d & %d.", dial.Scale.Min, dial.Scale.Max)
+	}
+	return nil
+}
+
+// validateDialAlertRuleEmail returns EINVALID if the rule's email address is
+// not the account address of an active member of the dial. Account addresses
+// come from the sign in provider so alerts cannot be sent to arbitrary
+// addresses through the relay.
+func validateDialAlertRuleEmail(ctx context.Context, tx *Tx, rule *wtf.DialAlertRule) error {
+	if rule.Email == "" {
+		return nil
+	} else if ok, err := isDialMemberEmail(ctx, tx, rule.DialID, rule.Email); err != nil {
+		return err
+	} else if !ok {
+		return wtf.Errorf(wtf.EINVALID, "Alert rule email must belong to a member of the dial.")
+	}
+	return nil
+}
+
+// isDialMemberEmail returns true if email is the account address of an
+// active member of the dial.
+func isDialMemberEmail(ctx context.Context, tx *Tx, dialID int, email string) (bool, error) {
+	var n int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM dial_memberships m
+		INNER JOIN users u ON u.id = m.user_id
+		WHERE m.dial_id = ? AND m.status = ? AND u.email = ? COLLATE NOCASE
+	`,
+		dialID,
+		wtf.DialMembershipStatusActive,
+		email,
+	).Scan(&n); err != nil {
+		return false, FormatError(err)
+	}
+	return n > 0, nil
+}
+
+// deleteDialAlertRule permanently removes a rule by ID. Firings are removed
+// by cascade. Returns EUNAUTHORIZED if the current user is not the dial owner.
+func deleteDialAlertRule(ctx context.Context, tx *Tx, id int) error {
+	// Verify rule exists & the current user owns the dial.
+	rule, err := findDialAlertRuleByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if dial, err := findDialByID(ctx, tx, rule.DialID); err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can delete alert rules.")
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_alert_rules WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the deleted rule in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialAlertRuleDelete,
+		TargetType: wtf.AuditTargetDialAlertRule,
+		TargetID:   rule.ID,
+		DialID:     rule.DialID,
+	}, rule, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// evaluateDialAlertRules checks each rule on a dial against the dial's current
+// values. Rules whose condition has held for their duration fire once per
+// breach, subject to their cooldown. This bypasses permission checks as it is
+// run on behalf of whichever user changed the dial's values.
+func evaluateDialAlertRules(ctx context.Context, tx *Tx, dialID int) error {
+	rules, _, err := queryDialAlertRules(ctx, tx, []string{"r.dial_id = ?"}, []interface{}{dialID}, "")
+	if err != nil {
+		return err
+	} else if len(rules) == 0 {
+		return nil
+	}
+
+	// Fetch the dial value & the range of effective active member values.
+	// The range is invalid if no active members contribute to the dial.
+	var dialName string
+	var value int
+	if err := tx.QueryRowContext(ctx, `SELECT name, value FROM dials WHERE id = ?`, dialID).Scan(&dialName, &value); err != nil {
+		return FormatError(err)
+	}
+
+	values, err := findEffectiveDialMemberValues(ctx, tx, dialID)
+	if err != nil {
+		return fmt.Errorf("effective member values: %w", err)
+	}
+	var maxValue, minValue sql.NullInt64
+	for _, v := range values {
+		if !maxValue.Valid || int64(v) > maxValue.Int64 {
+			maxValue = sql.NullInt64{Int64: int64(v), Valid: true}
+		}
+		if !minValue.Valid || int64(v) < minValue.Int64 {
+			minValue = sql.NullInt64{Int64: int64(v), Valid: true}
+		}
+	}
+
+	for _, rule := range rules {
+		// Member rules compare the member closest to meeting the condition.
+		v, ok := value, true
+		if rule.Subject == wtf.DialAlertSubjectMember {
+			if v, ok = int(maxValue.Int64), maxValue.Valid; rule.Operator == wtf.DialAlertOperatorLTE {
+				v = int(minValue.Int64)
+			}
+		}
+
+		if err := evaluateDialAlertRule(ctx, tx, rule, dialName, v, ok && rule.Compare(v)); err != nil {
+			return fmt.Errorf("evaluate rule: id=%d err=%w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// evaluateDialAlertRule updates the condition state of a single rule & fires
+// it if the condition has held long enough, it has not already fired for the
+// current breach, and its cooldown has passed.
+func evaluateDialAlertRule(ctx context.Context, tx *Tx, rule *wtf.DialAlertRule, dialName string, value int, met bool) error {
+	conditionSince, lastFiredAt := rule.ConditionSince, rule.LastFiredAt
+
+	// Track when the condition was first met. A cleared condition re-arms the rule.
+	if !met {
+		rule.ConditionSince = time.Time{}
+	} else if rule.ConditionSince.IsZero() {
+		rule.ConditionSince = tx.now
+	}
+
+	if met &&
+		!tx.now.Before(rule.ConditionSince.Add(rule.Duration())) &&
+		rule.LastFiredAt.Before(rule.ConditionSince) &&
+		!tx.now.Before(rule.LastFiredAt.Add(rule.Cooldown())) {
+		if err := fireDialAlertRule(ctx, tx, rule, dialName, value); err != nil {
+			return fmt.Errorf("fire rule: %w", err)
+		}
+	}
+
+	// Only write state if it has changed.
+	if rule.ConditionSince.Equal(conditionSince) && rule.LastFiredAt.Equal(lastFiredAt) {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_alert_rules
+		SET condition_since = ?,
+		    last_fired_at = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&rule.ConditionSince),
+		(*NullTime)(&rule.LastFiredAt),
+		rule.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// fireDialAlertRule records a firing for the rule & sends in-app notifications
+// to the dial's active members. Webhook & email targets are left pending for
+// the background delivery job.
+func fireDialAlertRule(ctx context.Context, tx *Tx, rule *wtf.DialAlertRule, dialName string, value int) error {
+	rule.LastFiredAt = tx.now
+
+	firing := &wtf.DialAlertFiring{
+		RuleID: rule.ID,
+		DialID: rule.DialID,
+		Value:  value,
+		Status: wtf.DialAlertFiringStatusSent,
+	}
+	if rule.WebhookURL != "" || rule.Email != "" {
+		firing.Status = wtf.DialAlertFiringStatusPending
+	}
+	if err := createDialAlertFiring(ctx, tx, firing); err != nil {
+		return fmt.Errorf("create firing: %w", err)
+	}
+
+	if rule.NotifyInApp {
+		if err := publishDialEvent(ctx, tx, rule.DialID, wtf.Event{
+			Type: wtf.EventTypeDialAlertFired,
+			Payload: &wtf.DialAlertFiredPayload{
+				ID:       firing.ID,
+				RuleID:   rule.ID,
+				RuleName: rule.Name,
+				DialID:   rule.DialID,
+				DialName: dialName,
+				Value:    value,
+			},
+		}); err != nil {
+			return fmt.Errorf("publish dial event: %w", err)
+		}
+	}
+	return nil
+}
+
+// findDialAlertFirings retrieves a list of matching firings. Also returns a
+// total matching count which may differ from the number of results if
+// filter.Limit is set.
+func findDialAlertFirings(ctx context.Context, tx *Tx, filter wtf.DialAlertFiringFilter) (_ []*wtf.DialAlertFiring, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.RuleID; v != nil {
+		where, args = append(where, "rule_id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "dial_id = ?"), append(args, *v)
+	}
+	if v := filter.Status; v != nil {
+		where, args = append(where, "status = ?"), append(args, *v)
+	}
+
+	// Limit to firings on dials the user is a member of.
+	where = append(where, `dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)`)
+	args = append(args, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive)
+
+	return queryDialAlertFirings(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// queryDialAlertFirings executes a query for firings with the given WHERE
+// clause parts. This performs no permission checks so callers must add them.
+func queryDialAlertFirings(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.DialAlertFiring, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    rule_id,
+		    dial_id,
+		    value,
+		    status,
+		    error,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM dial_alert_firings
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at DESC, id DESC
+		`+limitOffset,
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialAlertFiring objects.
+	firings := make([]*wtf.DialAlertFiring, 0)
+	for rows.Next() {
+		var firing wtf.DialAlertFiring
+		if err := rows.Scan(
+			&firing.ID,
+			&firing.RuleID,
+			&firing.DialID,
+			&firing.Value,
+			&firing.Status,
+			&firing.Error,
+			(*NullTime)(&firing.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		firings = append(firings, &firing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return firings, n, nil
+}
+
+// createDialAlertFiring inserts a firing into the rule's history.
+func createDialAlertFiring(ctx context.Context, tx *Tx, firing *wtf.DialAlertFiring) error {
+	firing.CreatedAt = tx.now
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_alert_firings (
+			rule_id,
+			dial_id,
+			value,
+			status,
+			error,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		firing.RuleID,
+		firing.DialID,
+		firing.Value,
+		firing.Status,
+		firing.Error,
+		(*NullTime)(&firing.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new firing ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	firing.ID = int(id)
+
+	return nil
+}
@@ -0,0 +1,460 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialAlertService_CreateDialAlertRule(t *testing.T) {
+	// Ensure a rule can be created by the dial owner.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		rule := &wtf.DialAlertRule{
+			DialID:          dial.ID,
+			Name:            "High",
+			Subject:         wtf.DialAlertSubjectDial,
+			Operator:        wtf.DialAlertOperatorGTE,
+			Threshold:       75,
+			DurationMinutes: 15,
+			CooldownMinutes: 60,
+			NotifyInApp:     true,
+		}
+		if err := s.CreateDialAlertRule(ctx0, rule); err != nil {
+			t.Fatal(err)
+		} else if got, want := rule.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if rule.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		// Fetch rule from database & compare.
+		if other, err := s.FindDialAlertRuleByID(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(rule, other) {
+			t.Fatalf("mismatch: %#v != %#v", rule, other)
+		}
+	})
+
+	// Ensure an error is returned if no notification target is specified.
+	t.Run("ErrTargetRequired", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		if err := s.CreateDialAlertRule(ctx0, &wtf.DialAlertRule{
+			DialID:    dial.ID,
+			Name:      "High",
+			Subject:   wtf.DialAlertSubjectDial,
+			Operator:  wtf.DialAlertOperatorGTE,
+			Threshold: 75,
+		}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `At least one alert rule notification target required.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure alerts can only be emailed to the account address of a member.
+	t.Run("ErrEmailNotMember", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@example.com"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@example.com"})
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "susy", Email: "susy@example.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		for _, email := range []string{"JIM@example.com", "jane@example.com"} {
+			if err := s.CreateDialAlertRule(ctx0, &wtf.DialAlertRule{DialID: dial.ID, Name: "High", Subject: wtf.DialAlertSubjectDial, Operator: wtf.DialAlertOperatorGTE, Threshold: 75, Email: email}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, email := range []string{"susy@example.com", "nobody@example.com"} {
+			if err := s.CreateDialAlertRule(ctx0, &wtf.DialAlertRule{DialID: dial.ID, Name: "High", Subject: wtf.DialAlertSubjectDial, Operator: wtf.DialAlertOperatorGTE, Threshold: 75, Email: email}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Alert rule email must belong to a member of the dial.` {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+	})
+
+	// Ensure members who do not own the dial cannot create rules.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.CreateDialAlertRule(ctx1, &wtf.DialAlertRule{
+			DialID:      dial.ID,
+			Name:        "High",
+			Subject:     wtf.DialAlertSubjectDial,
+			Operator:    wtf.DialAlertOperatorGTE,
+			Threshold:   75,
+			NotifyInApp: true,
+		}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialAlertService_UpdateDialAlertRule(t *testing.T) {
+	// Ensure updating a rule resets its condition state & re-evaluates it.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 50)
+		rule := MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Threshold: 75, DurationMinutes: 15})
+
+		threshold := 40
+		if rule, err := s.UpdateDialAlertRule(ctx0, rule.ID, wtf.DialAlertRuleUpdate{Threshold: &threshold}); err != nil {
+			t.Fatal(err)
+		} else if got, want := rule.Threshold, 40; got != want {
+			t.Fatalf("Threshold=%v, want %v", got, want)
+		} else if rule.ConditionSince.IsZero() {
+			t.Fatal("expected condition to be met")
+		}
+	})
+
+	// Ensure members who do not own the dial cannot update rules.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		rule := MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Threshold: 75})
+
+		name := "Renamed"
+		if _, err := s.UpdateDialAlertRule(ctx1, rule.ID, wtf.DialAlertRuleUpdate{Name: &name}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialAlertService_DeleteDialAlertRule(t *testing.T) {
+	// Ensure a rule & its history can be deleted by the dial owner.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		rule := MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Threshold: 75})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 100)
+
+		if err := s.DeleteDialAlertRule(ctx0, rule.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDialAlertRuleByID(ctx0, rule.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, n, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+}
+
+func TestDB_EvaluateDialAlertRules(t *testing.T) {
+	// Ensure a rule only fires once its condition has held for its duration
+	// & only fires once per breach.
+	t.Run("Duration", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		rule := MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Threshold: 75, DurationMinutes: 15})
+
+		// Track events sent to the other member.
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if userID == 2 && event.Type == wtf.EventTypeDialAlertFired {
+					events = append(events, event)
+				}
+			},
+		}
+
+		// Raise the dial value. The rule should not fire until later.
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+		MustSetDialMembershipValue(t, ctx1, db, 2, 80)
+		if other := MustFindDialAlertRuleByID(t, ctx0, db, rule.ID); !other.ConditionSince.Equal(start) {
+			t.Fatalf("ConditionSince=%v, want %v", other.ConditionSince, start)
+		} else if len(events) != 0 {
+			t.Fatalf("unexpected events: %#v", events)
+		}
+
+		// Evaluate after the duration has passed, twice.
+		db.Now = func() time.Time { return start.Add(15 * time.Minute) }
+		for i := 0; i < 2; i++ {
+			if err := db.EvaluateDialAlertRules(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		firings, n, err := s.FindDialAlertFirings(ctx1, wtf.DialAlertFiringFilter{DialID: &dial.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := firings[0].Value, 80; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if got, want := firings[0].Status, wtf.DialAlertFiringStatusSent; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		} else if got, want := firings[0].Rule.Name, rule.Name; got != want {
+			t.Fatalf("Rule.Name=%v, want %v", got, want)
+		}
+
+		if got, want := len(events), 1; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		} else if got, want := events[0].Payload, (&wtf.DialAlertFiredPayload{ID: 1, RuleID: rule.ID, RuleName: rule.Name, DialID: dial.ID, DialName: "DIAL", Value: 80}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Payload=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure member rules compare against the highest member value.
+	t.Run("Member", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Subject: wtf.DialAlertSubjectMember, Threshold: 100})
+
+		MustSetDialMembershipValue(t, ctx1, db, 2, 100)
+
+		if firings, n, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := firings[0].Value, 100; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 50; got != want {
+			t.Fatalf("dial Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a rule does not fire again until its cooldown has passed.
+	t.Run("Cooldown", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Threshold: 75, CooldownMinutes: 60})
+
+		// Breach, clear & breach again within the cooldown.
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+		db.Now = func() time.Time { return start.Add(10 * time.Minute) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+		db.Now = func() time.Time { return start.Add(20 * time.Minute) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 90)
+
+		if _, n, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		// The timer fires the rule once the cooldown has passed.
+		db.Now = func() time.Time { return start.Add(60 * time.Minute) }
+		if err := db.EvaluateDialAlertRules(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if firings, n, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := firings[0].Value, 90; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+}
+
+func TestDB_DeliverDialAlertFirings(t *testing.T) {
+	// Ensure pending firings are delivered to their external targets & that
+	// failures are recorded on the firing.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		var webhooks []*wtf.DialAlertFiring
+		db.DialAlertWebhookNotifier = &mock.DialAlertNotifier{
+			NotifyDialAlertFn: func(ctx context.Context, firing *wtf.DialAlertFiring) error {
+				webhooks = append(webhooks, firing)
+				return nil
+			},
+		}
+		db.DialAlertEmailNotifier = &mock.DialAlertNotifier{
+			NotifyDialAlertFn: func(ctx context.Context, firing *wtf.DialAlertFiring) error {
+				return errors.New("connection refused")
+			},
+		}
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@example.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Name: "WEBHOOK", Threshold: 75, NotifyInApp: false, WebhookURL: "https://example.com/hook"})
+		MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Name: "EMAIL", Threshold: 75, NotifyInApp: false, Email: "jane@example.com"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+
+		// Firings wait for the delivery job.
+		status := wtf.DialAlertFiringStatusPending
+		if _, n, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{Status: &status}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		if err := db.DeliverDialAlertFirings(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := len(webhooks), 1; got != want {
+			t.Fatalf("len(webhooks)=%v, want %v", got, want)
+		} else if got, want := webhooks[0].Dial.Name, "DIAL"; got != want {
+			t.Fatalf("Dial.Name=%v, want %v", got, want)
+		} else if got, want := webhooks[0].Rule.WebhookURL, "https://example.com/hook"; got != want {
+			t.Fatalf("Rule.WebhookURL=%v, want %v", got, want)
+		}
+
+		firings, _, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{DialID: &dial.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(firings), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+		for _, firing := range firings {
+			switch firing.Rule.Name {
+			case "WEBHOOK":
+				if got, want := firing.Status, wtf.DialAlertFiringStatusSent; got != want {
+					t.Fatalf("Status=%v, want %v", got, want)
+				}
+			case "EMAIL":
+				if got, want := firing.Status, wtf.DialAlertFiringStatusFailed; got != want {
+					t.Fatalf("Status=%v, want %v", got, want)
+				} else if got, want := firing.Error, "email: connection refused"; got != want {
+					t.Fatalf("Error=%v, want %v", got, want)
+				}
+			}
+		}
+	})
+
+	// Ensure email is not sent once the address no longer belongs to a member.
+	t.Run("EmailFormerMember", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		db.DialAlertEmailNotifier = &mock.DialAlertNotifier{
+			NotifyDialAlertFn: func(ctx context.Context, firing *wtf.DialAlertFiring) error {
+				t.Fatal("unexpected email")
+				return nil
+			},
+		}
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@example.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		MustCreateDialAlertRule(t, ctx0, db, &wtf.DialAlertRule{DialID: dial.ID, Threshold: 75, Email: "jim@example.com"})
+		if err := sqlite.NewDialMembershipService(db).DeleteDialMembership(ctx1, membership.ID); err != nil {
+			t.Fatal(err)
+		}
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+
+		if err := db.DeliverDialAlertFirings(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if firings, _, err := s.FindDialAlertFirings(ctx0, wtf.DialAlertFiringFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(firings), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := firings[0].Status, wtf.DialAlertFiringStatusFailed; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		} else if got, want := firings[0].Error, "email: address does not belong to a member of the dial"; got != want {
+			t.Fatalf("Error=%v, want %v", got, want)
+		}
+	})
+}
+
+// MustCreateDialAlertRule creates a rule in the database. Unset fields default
+// to an in-app rule on the dial value with a ">=" operator.
+func MustCreateDialAlertRule(tb testing.TB, ctx context.Context, db *sqlite.DB, rule *wtf.DialAlertRule) *wtf.DialAlertRule {
+	tb.Helper()
+	if rule.Name == "" {
+		rule.Name = "RULE"
+	}
+	if rule.Subject == "" {
+		rule.Subject = wtf.DialAlertSubjectDial
+	}
+	if rule.Operator == "" {
+		rule.Operator = wtf.DialAlertOperatorGTE
+	}
+	if rule.WebhookURL == "" && rule.Email == "" {
+		rule.NotifyInApp = true
+	}
+	if err := sqlite.NewDialAlertService(db).CreateDialAlertRule(ctx, rule); err != nil {
+		tb.Fatal(err)
+	}
+	return rule
+}
+
+// MustFindDialAlertRuleByID finds a rule by ID. Fatal on error.
+func MustFindDialAlertRuleByID(tb testing.TB, ctx context.Context, db *sqlite.DB, id int) *wtf.DialAlertRule {
+	tb.Helper()
+	rule, err := sqlite.NewDialAlertService(db).FindDialAlertRuleByID(ctx, id)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return rule
+}
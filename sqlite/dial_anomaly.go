@@ -0,0 +1,409 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Parameters for the rolling baseline used to detect dial anomalies.
+const (
+	// Size of each sample. Samples are the time-weighted average dial value.
+	dialAnomalyInterval = 15 * time.Minute
+
+	// Smoothing factor for the exponentially weighted moving average & variance.
+	dialAnomalyAlpha = 0.1
+
+	// Smoothing factor used for samples above the sustained threshold. This
+	// keeps an elevated period from quickly becoming the new baseline.
+	dialAnomalyOutlierAlpha = 0.01
+
+	// Number of samples required before anomalies are reported.
+	dialAnomalyWarmupN = 8
+
+	// Minimum standard deviation used when computing z-scores. This stops small
+	// movements on a dial that rarely changes from being reported.
+	dialAnomalyMinStdDev = 5.0
+
+	// Number of consecutive samples above the sustained threshold required to
+	// report a sustained anomaly.
+	dialAnomalySustainedN = 4
+
+	// Amount of history used to seed the baseline of a dial seen for the
+	// first time.
+	dialAnomalyBackfill = 24 * time.Hour
+
+	// Maximum amount of history processed for a dial in a single pass.
+	dialAnomalyMaxSpan = 7 * 24 * time.Hour
+)
+
+// Ensure service implements interface.
+var _ wtf.DialAnomalyService = (*DialAnomalyService)(nil)
+
+// DialAnomalyService represents a service for listing detected dial anomalies.
+type DialAnomalyService struct {
+	db *DB
+}
+
+// NewDialAnomalyService returns a new instance of DialAnomalyService.
+func NewDialAnomalyService(db *DB) *DialAnomalyService {
+	return &DialAnomalyService{db: db}
+}
+
+// FindDialAnomalies retrieves a list of anomalies based on a filter. Only
+// returns anomalies for dials that the user is a member of.
+func (s *DialAnomalyService) FindDialAnomalies(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	return findDialAnomalies(ctx, tx, filter)
+}
+
+// DetectDialAnomalies compares recent samples of each dial's value against a
+// rolling baseline & records an anomaly for any unusual rise. Members of the
+// dial are notified with a "dial:anomaly_detected" event. This is called
+// periodically by the background monitor but can also be called directly,
+// such as from tests.
+func (db *DB) DetectDialAnomalies(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only process samples that have completed.
+	end := tx.now.Truncate(dialAnomalyInterval)
+
+	dials, baselines, err := findDialAnomalyBaselines(ctx, tx, end)
+	if err != nil {
+		return fmt.Errorf("find baselines: %w", err)
+	}
+	for i := range dials {
+		if err := detectDialAnomalies(ctx, tx, dials[i], baselines[i], end); err != nil {
+			return fmt.Errorf("detect dial anomalies: id=%d err=%w", dials[i].ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// findDialAnomalies retrieves a list of matching anomalies. Also returns a
+// total matching count which may differ from the number of results if
+// filter.Limit is set.
+func findDialAnomalies(ctx context.Context, tx *Tx, filter wtf.DialAnomalyFilter) (_ []*wtf.DialAnomaly, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "dial_id = ?"), append(args, *v)
+	}
+	if v := filter.Kind; v != nil {
+		where, args = append(where, "kind = ?"), append(args, *v)
+	}
+
+	// Limit to anomalies on dials the user is a member of.
+	where = append(where, `dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)`)
+	args = append(args, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive)
+
+	// Execute query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    dial_id,
+		    kind,
+		    value,
+		    baseline,
+		    stddev,
+		    z_score,
+		    "timestamp",
+		    created_at,
+		    COUNT(*) OVER()
+		FROM dial_anomalies
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY "timestamp" DESC, id DESC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialAnomaly objects.
+	anomalies := make([]*wtf.DialAnomaly, 0)
+	for rows.Next() {
+		var anomaly wtf.DialAnomaly
+		if err := rows.Scan(
+			&anomaly.ID,
+			&anomaly.DialID,
+			&anomaly.Kind,
+			&anomaly.Value,
+			&anomaly.Baseline,
+			&anomaly.StdDev,
+			&anomaly.ZScore,
+			(*NullTime)(&anomaly.Timestamp),
+			(*NullTime)(&anomaly.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		anomalies = append(anomalies, &anomaly)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return anomalies, n, nil
+}
+
+// createDialAnomaly records a detected anomaly.
+func createDialAnomaly(ctx context.Context, tx *Tx, anomaly *wtf.DialAnomaly) error {
+	anomaly.CreatedAt = tx.now
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_anomalies (
+			dial_id,
+			kind,
+			value,
+			baseline,
+			stddev,
+			z_score,
+			"timestamp",
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		anomaly.DialID,
+		anomaly.Kind,
+		anomaly.Value,
+		anomaly.Baseline,
+		anomaly.StdDev,
+		anomaly.ZScore,
+		(*NullTime)(&anomaly.Timestamp),
+		(*NullTime)(&anomaly.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new anomaly ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	anomaly.ID = int(id)
+
+	return nil
+}
+
+// dialAnomalyBaseline represents the rolling baseline of a dial's value.
+type dialAnomalyBaseline struct {
+	DialID    int
+	Timestamp time.Time // start of the next unprocessed sample
+	N         int       // number of samples observed
+	Mean      float64
+	Variance  float64
+	Spiking   bool // true if the last sample was above the spike threshold
+	Run       int  // consecutive samples above the sustained threshold
+}
+
+// observe compares a sample against the baseline & then folds the sample into
+// the baseline. Returns any anomalies triggered by the sample.
+//
+// Only rises in the dial value are reported. Spikes are reported when the
+// value first crosses the spike threshold & sustained anomalies are reported
+// once per run of samples above the sustained threshold.
+func (b *dialAnomalyBaseline) observe(dial *wtf.Dial, x float64, timestamp time.Time) (anomalies []*wtf.DialAnomaly) {
+	alpha := dialAnomalyAlpha
+	if b.N >= dialAnomalyWarmupN {
+		stddev := math.Max(math.Sqrt(b.Variance), dialAnomalyMinStdDev)
+		z := (x - b.Mean) / stddev
+
+		newAnomaly := func(kind string) *wtf.DialAnomaly {
+			return &wtf.DialAnomaly{
+				DialID:    dial.ID,
+				Kind:      kind,
+				Value:     math.Round(x*100) / 100,
+				Baseline:  math.Round(b.Mean*100) / 100,
+				StdDev:    math.Round(stddev*100) / 100,
+				ZScore:    math.Round(z*100) / 100,
+				Timestamp: timestamp,
+			}
+		}
+
+		if z >= dial.AnomalySpikeThreshold {
+			if !b.Spiking {
+				anomalies = append(anomalies, newAnomaly(wtf.DialAnomalyKindSpike))
+			}
+			b.Spiking = true
+		} else {
+			b.Spiking = false
+		}
+
+		if z >= dial.AnomalySustainedThreshold {
+			if b.Run++; b.Run == dialAnomalySustainedN {
+				anomalies = append(anomalies, newAnomaly(wtf.DialAnomalyKindSustained))
+			}
+			alpha = dialAnomalyOutlierAlpha
+		} else {
+			b.Run = 0
+		}
+	}
+
+	// Update exponentially weighted moving average & variance.
+	if b.N == 0 {
+		b.Mean = x
+	} else {
+		diff := x - b.Mean
+		incr := alpha * diff
+		b.Mean += incr
+		b.Variance = (1 - alpha) * (b.Variance + diff*incr)
+	}
+	b.N++
+
+	return anomalies
+}
+
+// findDialAnomalyBaselines returns every dial along with its baseline. Dials
+// without a baseline are given a new one that starts a fixed period before
+// end, or when the dial was created if that is later. This bypasses
+// permission checks as it is used by the background detection job.
+func findDialAnomalyBaselines(ctx context.Context, tx *Tx, end time.Time) (dials []*wtf.Dial, baselines []*dialAnomalyBaseline, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    d.id,
+		    d.name,
+		    d.anomaly_spike_threshold,
+		    d.anomaly_sustained_threshold,
+		    d.created_at,
+		    b."timestamp",
+		    IFNULL(b.n, 0),
+		    IFNULL(b.mean, 0),
+		    IFNULL(b.variance, 0),
+		    IFNULL(b.spiking, 0),
+		    IFNULL(b.run, 0)
+		FROM dials d
+		LEFT JOIN dial_anomaly_baselines b ON b.dial_id = d.id
+		ORDER BY d.id
+	`)
+	if err != nil {
+		return nil, nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dial wtf.Dial
+		var baseline dialAnomalyBaseline
+		if err := rows.Scan(
+			&dial.ID,
+			&dial.Name,
+			&dial.AnomalySpikeThreshold,
+			&dial.AnomalySustainedThreshold,
+			(*NullTime)(&dial.CreatedAt),
+			(*NullTime)(&baseline.Timestamp),
+			&baseline.N,
+			&baseline.Mean,
+			&baseline.Variance,
+			&baseline.Spiking,
+			&baseline.Run,
+		); err != nil {
+			return nil, nil, err
+		}
+		baseline.DialID = dial.ID
+
+		// Seed new baselines from recent history.
+		if baseline.Timestamp.IsZero() {
+			baseline.Timestamp = dial.CreatedAt.Truncate(dialAnomalyInterval)
+			if t := end.Add(-dialAnomalyBackfill); t.After(baseline.Timestamp) {
+				baseline.Timestamp = t
+			}
+		}
+
+		dials, baselines = append(dials, &dial), append(baselines, &baseline)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return dials, baselines, nil
+}
+
+// detectDialAnomalies feeds the samples of a dial between the baseline's
+// timestamp & end into its baseline. Any anomalies are recorded & published
+// to the dial's members. The updated baseline is saved.
+func detectDialAnomalies(ctx context.Context, tx *Tx, dial *wtf.Dial, baseline *dialAnomalyBaseline, end time.Time) error {
+	start := baseline.Timestamp
+
+	// Raw values before the retention cutoff may have been pruned so samples
+	// before then are skipped.
+	if cutoff, err := findDialValueRetentionCutoff(ctx, tx); err != nil {
+		return err
+	} else if start.Before(cutoff) {
+		if start = cutoff.Truncate(dialAnomalyInterval); start.Before(cutoff) {
+			start = start.Add(dialAnomalyInterval)
+		}
+	}
+
+	if !end.After(start) {
+		return nil
+	} else if end.Sub(start) > dialAnomalyMaxSpan {
+		end = start.Add(dialAnomalyMaxSpan)
+	}
+
+	buckets, err := findDialValueBuckets(ctx, tx, dial.ID, start, end, dialAnomalyInterval)
+	if err != nil {
+		return fmt.Errorf("dial value buckets: %w", err)
+	}
+
+	for _, bucket := range buckets {
+		for _, anomaly := range baseline.observe(dial, bucket.Avg, bucket.Timestamp) {
+			if err := createDialAnomaly(ctx, tx, anomaly); err != nil {
+				return fmt.Errorf("create dial anomaly: %w", err)
+			}
+
+			if err := publishDialEvent(ctx, tx, dial.ID, wtf.Event{
+				Type: wtf.EventTypeDialAnomalyDetected,
+				Payload: &wtf.DialAnomalyDetectedPayload{
+					ID:       anomaly.ID,
+					DialID:   dial.ID,
+					DialName: dial.Name,
+					Kind:     anomaly.Kind,
+					Value:    anomaly.Value,
+					Baseline: anomaly.Baseline,
+					ZScore:   anomaly.ZScore,
+				},
+			}); err != nil {
+				return fmt.Errorf("publish dial event: %w", err)
+			}
+		}
+	}
+	baseline.Timestamp = end
+
+	return saveDialAnomalyBaseline(ctx, tx, baseline)
+}
+
+// saveDialAnomalyBaseline inserts or replaces the baseline for a dial.
+func saveDialAnomalyBaseline(ctx context.Context, tx *Tx, baseline *dialAnomalyBaseline) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO dial_anomaly_baselines (dial_id, "timestamp", n, mean, variance, spiking, run)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		baseline.DialID,
+		(*NullTime)(&baseline.Timestamp),
+		baseline.N,
+		baseline.Mean,
+		baseline.Variance,
+		baseline.Spiking,
+		baseline.Run,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDB_DetectDialAnomalies(t *testing.T) {
+	// Ensure a jump after a steady period is reported as a spike & then as a
+	// sustained anomaly once the value stays elevated.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAnomalyService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+		MustSetDialMembershipValue(t, ctx1, db, 2, 20)
+
+		// Jump after four steady hours & stay elevated.
+		db.Now = func() time.Time { return start.Add(4 * time.Hour) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+		MustSetDialMembershipValue(t, ctx1, db, 2, 80)
+
+		// Track events sent to the other member.
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if userID == 2 {
+					events = append(events, event)
+				}
+			},
+		}
+
+		// Run detection twice to ensure samples are only processed once.
+		db.Now = func() time.Time { return start.Add(6 * time.Hour) }
+		for i := 0; i < 2; i++ {
+			if err := db.DetectDialAnomalies(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		anomalies, n, err := s.FindDialAnomalies(ctx1, wtf.DialAnomalyFilter{DialID: &dial.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		// Anomalies are listed newest first.
+		if got, want := anomalies[0].Kind, wtf.DialAnomalyKindSustained; got != want {
+			t.Fatalf("Kind=%v, want %v", got, want)
+		} else if got, want := anomalies[0].Timestamp, start.Add(4*time.Hour+45*time.Minute); !got.Equal(want) {
+			t.Fatalf("Timestamp=%v, want %v", got, want)
+		}
+
+		if got, want := anomalies[1].Kind, wtf.DialAnomalyKindSpike; got != want {
+			t.Fatalf("Kind=%v, want %v", got, want)
+		} else if got, want := anomalies[1].Timestamp, start.Add(4*time.Hour); !got.Equal(want) {
+			t.Fatalf("Timestamp=%v, want %v", got, want)
+		} else if got, want := anomalies[1].Value, 80.0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if got, want := anomalies[1].Baseline, 20.0; got != want {
+			t.Fatalf("Baseline=%v, want %v", got, want)
+		} else if got, want := anomalies[1].ZScore, 12.0; got != want {
+			t.Fatalf("ZScore=%v, want %v", got, want)
+		}
+
+		// Ensure members were notified of each anomaly.
+		if got, want := len(events), 2; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		} else if got, want := events[0].Type, wtf.EventTypeDialAnomalyDetected; got != want {
+			t.Fatalf("Type=%v, want %v", got, want)
+		} else if payload := events[0].Payload.(*wtf.DialAnomalyDetectedPayload); payload.ID != anomalies[1].ID || payload.Kind != wtf.DialAnomalyKindSpike || payload.DialName != "DIAL" {
+			t.Fatalf("unexpected payload: %#v", payload)
+		}
+	})
+
+	// Ensure each dial's thresholds are used.
+	t.Run("Threshold", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAnomalyService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", AnomalySpikeThreshold: 20, AnomalySustainedThreshold: 20})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+
+		db.Now = func() time.Time { return start.Add(4 * time.Hour) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+
+		db.Now = func() time.Time { return start.Add(6 * time.Hour) }
+		if err := db.DetectDialAnomalies(ctx); err != nil {
+			t.Fatal(err)
+		} else if _, n, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+
+	// Ensure anomalies are only visible to dial members.
+	t.Run("Permissions", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAnomalyService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		db.Now = func() time.Time { return start.Add(4 * time.Hour) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 100)
+
+		db.Now = func() time.Time { return start.Add(6 * time.Hour) }
+		if err := db.DetectDialAnomalies(ctx); err != nil {
+			t.Fatal(err)
+		} else if _, n, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n == 0 {
+			t.Fatal("expected anomalies")
+		} else if _, n, err := s.FindDialAnomalies(ctx1, wtf.DialAnomalyFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+}
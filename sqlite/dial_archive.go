@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// PurgeArchivedDials permanently deletes dials that have been archived for
+// longer than the DialArchiveRetention period. Archived dials are kept forever
+// if the retention period is zero. This is called periodically by the
+// background monitor but can also be called directly, such as from tests.
+func (db *DB) PurgeArchivedDials(ctx context.Context) error {
+	if db.DialArchiveRetention <= 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	cutoff := tx.now.Add(-db.DialArchiveRetention)
+	dials, _, err := queryDials(ctx, tx, []string{"archived_at <= ?"}, []interface{}{(*NullTime)(&cutoff)}, "")
+	if err != nil {
+		return err
+	}
+
+	for _, dial := range dials {
+		if err := purgeDial(ctx, tx, dial); err != nil {
+			return fmt.Errorf("purge dial: id=%d err=%w", dial.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// archiveDial marks a dial as archived. Returns EUNAUTHORIZED if the user does
+// not own the dial.
+func archiveDial(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
+	dial, err := findDialByID(ctx, tx, id)
+	if err != nil {
+		return dial, err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return dial, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner can archive a dial.")
+	} else if dial.IsArchived() {
+		return dial, wtf.Errorf(wtf.ECONFLICT, "Dial is already archived.")
+	}
+	return dial, setDialArchivedAt(ctx, tx, dial, tx.now, wtf.AuditActionDialArchive)
+}
+
+// restoreDial returns an archived dial to its normal state. Returns
+// EUNAUTHORIZED if the user does not own the dial.
+func restoreDial(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
+	dial, err := findDialByID(ctx, tx, id)
+	if err != nil {
+		return dial, err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return dial, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner can restore a dial.")
+	} else if !dial.IsArchived() {
+		return dial, wtf.Errorf(wtf.ECONFLICT, "Dial is not archived.")
+	}
+	if err := setDialArchivedAt(ctx, tx, dial, time.Time{}, wtf.AuditActionDialRestore); err != nil {
+		return dial, err
+	}
+
+	// Member values may have changed while the dial was frozen, such as when
+	// members left, so the value is brought up to date.
+	if err := refreshDialValue(ctx, tx, dial.ID); err != nil {
+		return dial, fmt.Errorf("refresh dial value: %w", err)
+	} else if err := tx.QueryRowContext(ctx, `SELECT value FROM dials WHERE id = ?`, dial.ID).Scan(&dial.Value); err != nil {
+		return dial, FormatError(err)
+	}
+	dial.Band = dial.Scale.BandLabel(dial.Value)
+	return dial, nil
+}
+
+// setDialArchivedAt sets or clears the archive time of a dial & records the
+// change in the audit log.
+func setDialArchivedAt(ctx context.Context, tx *Tx, dial *wtf.Dial, archivedAt time.Time, action string) error {
+	prev := *dial
+	dial.ArchivedAt = archivedAt
+	dial.UpdatedAt = tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dials
+		SET archived_at = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&dial.ArchivedAt),
+		(*NullTime)(&dial.UpdatedAt),
+		dial.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     action,
+		TargetType: wtf.AuditTargetDial,
+		TargetID:   dial.ID,
+		DialID:     dial.ID,
+	}, &prev, dial); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// checkDialNotArchived returns ECONFLICT if a dial is archived. This is used
+// to keep archived dials read-only & performs no permission checks. Returns
+// ENOTFOUND if the dial does not exist.
+func checkDialNotArchived(ctx context.Context, tx *Tx, id int) error {
+	var archived bool
+	if err := tx.QueryRowContext(ctx, `SELECT archived_at IS NOT NULL FROM dials WHERE id = ?`, id).Scan(&archived); err == sql.ErrNoRows {
+		return &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	} else if err != nil {
+		return FormatError(err)
+	} else if archived {
+		return wtf.Errorf(wtf.ECONFLICT, "Archived dials cannot be changed. Restore the dial first.")
+	}
+	return nil
+}
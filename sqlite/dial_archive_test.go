@@ -0,0 +1,190 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_ArchiveDial(t *testing.T) {
+	// Ensure archived dials are hidden from listings but keep their history.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B"})
+		now = now.Add(time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 40)
+
+		now = now.Add(time.Hour)
+		if other, err := s.ArchiveDial(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		} else if !other.ArchivedAt.Equal(now) || !other.IsArchived() {
+			t.Fatalf("ArchivedAt=%v, want %v", other.ArchivedAt, now)
+		}
+
+		if dials, n, err := s.FindDials(ctx0, wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 || dials[0].Name != "B" {
+			t.Fatalf("unexpected dials: n=%d", n)
+		}
+		if dials, n, err := s.FindDials(ctx0, wtf.DialFilter{IncludeArchived: true}); err != nil {
+			t.Fatal(err)
+		} else if n != 2 || !dials[0].IsArchived() || dials[1].IsArchived() {
+			t.Fatalf("unexpected dials: n=%d", n)
+		}
+
+		if other := MustFindDialByID(t, ctx0, db, dial.ID); !other.IsArchived() || other.Value != 40 {
+			t.Fatalf("unexpected dial: %#v", other)
+		} else if values, err := s.DialValues(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := values, []int{0, 40}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("DialValues=%v, want %v", got, want)
+		}
+
+		if _, err := s.ArchiveDial(ctx0, dial.ID); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Dial is already archived.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure archived dials cannot be changed.
+	t.Run("ReadOnly", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		if _, err := s.ArchiveDial(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		const msg = `Archived dials cannot be changed. Restore the dial first.`
+		name := "B"
+		if _, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Name: &name}); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != msg {
+			t.Fatal(err)
+		} else if err := s.SetDialMembershipValue(ctx0, dial.ID, 50, ""); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != msg {
+			t.Fatal(err)
+		} else if err := sqlite.NewDialMembershipService(db).CreateDialMembership(ctx1, &wtf.DialMembership{DialID: dial.ID}); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != msg {
+			t.Fatal(err)
+		} else if err := sqlite.NewDialAlertService(db).CreateDialAlertRule(ctx0, &wtf.DialAlertRule{DialID: dial.ID, Name: "RULE", Subject: wtf.DialAlertSubjectDial, Operator: wtf.DialAlertOperatorGTE, Threshold: 50, WebhookURL: "https://example.com"}); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != msg {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure only the owner can archive a dial.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if _, err := s.ArchiveDial(ctx1, dial.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the owner can archive a dial.` {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialService_RestoreDial(t *testing.T) {
+	// Ensure a restored dial is listed & can be changed again.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 80})
+		if _, err := s.ArchiveDial(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// Leaving does not change the value of an archived dial.
+		if err := sqlite.NewDialMembershipService(db).DeleteDialMembership(ctx1, membership.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 40; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Restoring brings the value up to date.
+		if other, err := s.RestoreDial(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		} else if other.IsArchived() {
+			t.Fatal("expected dial to be restored")
+		} else if other.Value != 0 {
+			t.Fatalf("Value=%v, want %v", other.Value, 0)
+		}
+
+		if _, n, err := s.FindDials(ctx0, wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("unexpected n: %d", n)
+		}
+		MustSetDialMembershipValue(t, ctx0, db, 1, 30)
+
+		if _, err := s.RestoreDial(ctx0, dial.ID); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Dial is not archived.` {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDB_PurgeArchivedDials(t *testing.T) {
+	// Ensure archived dials are deleted once the retention period has passed.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B"})
+		if _, err := s.ArchiveDial(ctx0, dial0.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// Archived dials are kept forever without a retention period.
+		now = now.Add(48 * time.Hour)
+		if err := db.PurgeArchivedDials(ctx); err != nil {
+			t.Fatal(err)
+		}
+		MustFindDialByID(t, ctx0, db, dial0.ID)
+
+		db.DialArchiveRetention = 72 * time.Hour
+		if err := db.PurgeArchivedDials(ctx); err != nil {
+			t.Fatal(err)
+		}
+		MustFindDialByID(t, ctx0, db, dial0.ID)
+
+		now = now.Add(24 * time.Hour)
+		if err := db.PurgeArchivedDials(ctx); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDialByID(ctx0, dial0.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		MustFindDialByID(t, ctx0, db, dial1.ID)
+	})
+}
@@ -0,0 +1,290 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.DialBanService = (*DialBanService)(nil)
+
+// DialBanService represents a service for managing dial ban lists.
+type DialBanService struct {
+	db *DB
+}
+
+// NewDialBanService returns a new instance of DialBanService.
+func NewDialBanService(db *DB) *DialBanService {
+	return &DialBanService{db: db}
+}
+
+// FindDialBans retrieves a list of bans based on a filter. Dial owners can see
+// all bans on their dials while other users can only see their own bans.
+func (s *DialBanService) FindDialBans(ctx context.Context, filter wtf.DialBanFilter) ([]*wtf.DialBan, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Fetch list of matching bans.
+	bans, n, err := findDialBans(ctx, tx, filter)
+	if err != nil {
+		return bans, n, err
+	}
+
+	// Attach the banned user to each ban.
+	for _, ban := range bans {
+		if err := attachDialBanAssociations(ctx, tx, ban); err != nil {
+			return bans, n, err
+		}
+	}
+	return bans, n, nil
+}
+
+// CreateDialBan bans a user from a dial and removes their membership.
+// Only the dial owner can ban users.
+func (s *DialBanService) CreateDialBan(ctx context.Context, ban *wtf.DialBan) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Create ban and attach the banned user.
+	if err := createDialBan(ctx, tx, ban); err != nil {
+		return err
+	} else if err := attachDialBanAssociations(ctx, tx, ban); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteDialBan lifts a ban so the user may rejoin the dial.
+// Only the dial owner can remove a ban.
+func (s *DialBanService) DeleteDialBan(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteDialBan(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findDialBanByID is a helper function to retrieve a ban by ID.
+// Returns ENOTFOUND if ban doesn't exist.
+func findDialBanByID(ctx context.Context, tx *Tx, id int) (*wtf.DialBan, error) {
+	bans, _, err := findDialBans(ctx, tx, wtf.DialBanFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(bans) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial ban not found."}
+	}
+	return bans[0], nil
+}
+
+// findDialBans retrieves a list of matching bans. Also returns a total
+// matching count which may differ from the number of results if filter.Limit
+// is set.
+func findDialBans(ctx context.Context, tx *Tx, filter wtf.DialBanFilter) (_ []*wtf.DialBan, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "b.id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "b.dial_id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "b.user_id = ?"), append(args, *v)
+	}
+
+	// Limit to bans on dials the user owns or bans against the user.
+	userID := wtf.UserIDFromContext(ctx)
+	where, args = append(where, "(d.user_id = ? OR b.user_id = ?)"), append(args, userID, userID)
+
+	// Execute query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    b.id,
+		    b.dial_id,
+		    b.user_id,
+		    b.reason,
+		    b.created_at,
+		    COUNT(*) OVER()
+		FROM dial_bans b
+		INNER JOIN dials d ON b.dial_id = d.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY b.id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialBan objects.
+	bans := make([]*wtf.DialBan, 0)
+	for rows.Next() {
+		var ban wtf.DialBan
+		if err := rows.Scan(
+			&ban.ID,
+			&ban.DialID,
+			&ban.UserID,
+			&ban.Reason,
+			(*NullTime)(&ban.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		bans = append(bans, &ban)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return bans, n, nil
+}
+
+// createDialBan bans a user from a dial. The user's membership is removed and
+// any pending invitations to the dial are revoked.
+func createDialBan(ctx context.Context, tx *Tx, ban *wtf.DialBan) error {
+	ban.CreatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := ban.Validate(); err != nil {
+		return err
+	}
+
+	// Only the dial owner can ban users & the owner cannot ban themselves.
+	dial, err := findDialByID(ctx, tx, ban.DialID)
+	if err != nil {
+		return err
+	} else if dial.UserID != wtf.UserIDFromContext(ctx) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can ban users.")
+	} else if dial.UserID == ban.UserID {
+		return wtf.Errorf(wtf.EINVALID, "Dial owner may not ban themselves.")
+	} else if _, err := findUserByID(ctx, tx, ban.UserID); err != nil {
+		return err
+	}
+
+	// Ensure the user is not already banned.
+	if banned, err := isUserBannedFromDial(ctx, tx, ban.DialID, ban.UserID); err != nil {
+		return err
+	} else if banned {
+		return wtf.Errorf(wtf.ECONFLICT, "User is already banned from this dial.")
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_bans (
+			dial_id,
+			user_id,
+			reason,
+			created_at
+		)
+		VALUES (?, ?, ?, ?)
+	`,
+		ban.DialID,
+		ban.UserID,
+		ban.Reason,
+		(*NullTime)(&ban.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new ban ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	ban.ID = int(id)
+
+	// Record the ban in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialBanCreate,
+		TargetType: wtf.AuditTargetDialBan,
+		TargetID:   ban.ID,
+		DialID:     ban.DialID,
+	}, nil, ban); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Remove the user's membership & any outstanding invitations.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_memberships WHERE dial_id = ? AND user_id = ?`, ban.DialID, ban.UserID); err != nil {
+		return FormatError(err)
+	} else if _, err := tx.ExecContext(ctx, `DELETE FROM invitations WHERE dial_id = ? AND invitee_id = ? AND status = ?`, ban.DialID, ban.UserID, wtf.InvitationStatusPending); err != nil {
+		return FormatError(err)
+	}
+
+	// Ensure computed dial value is up to date.
+	if err := refreshDialValue(ctx, tx, ban.DialID); err != nil {
+		return fmt.Errorf("refresh dial value: %w", err)
+	}
+	return nil
+}
+
+// deleteDialBan permanently removes a ban by ID.
+// Returns EUNAUTHORIZED if current user is not the dial owner.
+func deleteDialBan(ctx context.Context, tx *Tx, id int) error {
+	// Verify ban exists & current user owns the dial.
+	ban, err := findDialBanByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if userID, _, err := findDialApprovalSettings(ctx, tx, ban.DialID); err != nil {
+		return err
+	} else if userID != wtf.UserIDFromContext(ctx) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can unban users.")
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_bans WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the lifted ban in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialBanDelete,
+		TargetType: wtf.AuditTargetDialBan,
+		TargetID:   ban.ID,
+		DialID:     ban.DialID,
+	}, ban, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// isUserBannedFromDial returns true if the user is on the dial's ban list.
+// This bypasses permission checks so that it can be used when joining a dial.
+func isUserBannedFromDial(ctx context.Context, tx *Tx, dialID, userID int) (bool, error) {
+	var n int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(1)
+		FROM dial_bans
+		WHERE dial_id = ? AND user_id = ?
+	`,
+		dialID,
+		userID,
+	).Scan(&n); err != nil {
+		return false, FormatError(err)
+	}
+	return n != 0, nil
+}
+
+// attachDialBanAssociations attaches the banned user.
+func attachDialBanAssociations(ctx context.Context, tx *Tx, ban *wtf.DialBan) (err error) {
+	if ban.User, err = findUserByID(ctx, tx, ban.UserID); err != nil {
+		return fmt.Errorf("attach ban user: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialBanService_CreateDialBan(t *testing.T) {
+	// Ensure banning a member removes them & prevents them from rejoining.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialBanService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+
+		ban := &wtf.DialBan{DialID: dial.ID, UserID: user1.ID, Reason: "spam"}
+		if err := s.CreateDialBan(ctx0, ban); err != nil {
+			t.Fatal(err)
+		} else if got, want := ban.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := ban.User.Name, "jim"; got != want {
+			t.Fatalf("User.Name=%v, want %v", got, want)
+		}
+
+		// Ensure the membership was removed and the dial value recomputed.
+		if _, n, err := sqlite.NewDialMembershipService(db).FindDialMemberships(ctx0, wtf.DialMembershipFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Ensure the user cannot rejoin with the invite code.
+		if err := sqlite.NewDialMembershipService(db).CreateDialMembership(ctx1, &wtf.DialMembership{DialID: dial.ID}); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `You have been banned from this dial.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Ensure the banned user can see their own ban.
+		if _, n, err := s.FindDialBans(ctx1, wtf.DialBanFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure banned users cannot be directly invited.
+	t.Run("ErrInvitation", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialBanService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, _ := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		if err := s.CreateDialBan(ctx0, &wtf.DialBan{DialID: dial.ID, UserID: user1.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := sqlite.NewInvitationService(db).CreateInvitation(ctx0, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"}); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `User is banned from this dial.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure only the dial owner can ban users.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialBanService(db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.CreateDialBan(ctx1, &wtf.DialBan{DialID: dial.ID, UserID: user0.ID}); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can ban users.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestDialBanService_DeleteDialBan(t *testing.T) {
+	// Ensure an unbanned user can rejoin the dial.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialBanService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		ban := &wtf.DialBan{DialID: dial.ID, UserID: user1.ID}
+		if err := s.CreateDialBan(ctx0, ban); err != nil {
+			t.Fatal(err)
+		}
+
+		// Ensure the banned user cannot lift their own ban.
+		if err := s.DeleteDialBan(ctx1, ban.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if err := s.DeleteDialBan(ctx0, ban.ID); err != nil {
+			t.Fatal(err)
+		}
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+	})
+}
@@ -0,0 +1,500 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.DialChildService = (*DialChildService)(nil)
+
+// DialChildService represents a service for managing the hierarchy of dials.
+type DialChildService struct {
+	db *DB
+}
+
+// NewDialChildService returns a new instance of DialChildService.
+func NewDialChildService(db *DB) *DialChildService {
+	return &DialChildService{db: db}
+}
+
+// FindDialChildByID retrieves a single child by ID along with its parent &
+// child dials. Returns ENOTFOUND if the child does not exist or the user is not
+// a member of the parent dial & does not own the child dial.
+func (s *DialChildService) FindDialChildByID(ctx context.Context, id int) (*wtf.DialChild, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	child, err := findDialChildByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if err := attachDialChildAssociations(ctx, tx, child); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// FindDialChildren retrieves a list of children based on a filter. Only
+// returns children of dials the user is a member of & children of dials the
+// user owns.
+func (s *DialChildService) FindDialChildren(ctx context.Context, filter wtf.DialChildFilter) ([]*wtf.DialChild, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Fetch list of matching children.
+	children, n, err := findDialChildren(ctx, tx, filter)
+	if err != nil {
+		return children, n, err
+	}
+
+	// Attach the parent & child dials to each child.
+	for _, child := range children {
+		if err := attachDialChildAssociations(ctx, tx, child); err != nil {
+			return children, n, err
+		}
+	}
+	return children, n, nil
+}
+
+// CreateDialChild attaches a dial to a parent dial. Only the child dial's owner
+// can attach it & they must be an active member of the parent dial.
+func (s *DialChildService) CreateDialChild(ctx context.Context, child *wtf.DialChild) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Create child and attach the parent & child dials.
+	if err := createDialChild(ctx, tx, child); err != nil {
+		return err
+	} else if err := attachDialChildAssociations(ctx, tx, child); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateDialChild updates the weight of a child. Only the owner of the parent
+// or child dial can update it.
+func (s *DialChildService) UpdateDialChild(ctx context.Context, id int, upd wtf.DialChildUpdate) (*wtf.DialChild, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	child, err := updateDialChild(ctx, tx, id, upd)
+	if err != nil {
+		return child, err
+	} else if err := tx.Commit(); err != nil {
+		return child, err
+	}
+	return child, nil
+}
+
+// DeleteDialChild detaches a child from its parent. Only the owner of the
+// parent or child dial can detach it.
+func (s *DialChildService) DeleteDialChild(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteDialChild(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findDialChildByID is a helper function to retrieve a child by ID.
+// Returns ENOTFOUND if child doesn't exist.
+func findDialChildByID(ctx context.Context, tx *Tx, id int) (*wtf.DialChild, error) {
+	children, _, err := findDialChildren(ctx, tx, wtf.DialChildFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(children) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial child not found."}
+	}
+	return children[0], nil
+}
+
+// findDialChildren retrieves a list of matching children. Also returns a total
+// matching count which may differ from the number of results if filter.Limit
+// is set.
+func findDialChildren(ctx context.Context, tx *Tx, filter wtf.DialChildFilter) (_ []*wtf.DialChild, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "c.id = ?"), append(args, *v)
+	}
+	if v := filter.ParentDialID; v != nil {
+		where, args = append(where, "c.parent_dial_id = ?"), append(args, *v)
+	}
+	if v := filter.ChildDialID; v != nil {
+		where, args = append(where, "c.child_dial_id = ?"), append(args, *v)
+	}
+
+	// Limit to children of dials the user is an active member of & children
+	// of dials the user owns.
+	userID := wtf.UserIDFromContext(ctx)
+	where = append(where, `(
+		c.parent_dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?) OR
+		c.child_dial_id IN (SELECT id FROM dials WHERE user_id = ?)
+	)`)
+	args = append(args, userID, wtf.DialMembershipStatusActive, userID)
+
+	return queryDialChildren(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// findDialParent returns the child record attaching a dial to its parent.
+// Returns nil if the dial does not have a parent. This performs no permission
+// checks as it is used when propagating values up the hierarchy.
+func findDialParent(ctx context.Context, tx *Tx, dialID int) (*wtf.DialChild, error) {
+	children, _, err := queryDialChildren(ctx, tx, []string{"c.child_dial_id = ?"}, []interface{}{dialID}, "")
+	if err != nil || len(children) == 0 {
+		return nil, err
+	}
+	return children[0], nil
+}
+
+// queryDialChildren executes a query for children with the given WHERE clause
+// parts. This performs no permission checks so callers must add them.
+func queryDialChildren(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.DialChild, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    c.id,
+		    c.parent_dial_id,
+		    c.child_dial_id,
+		    c.weight,
+		    c.created_at,
+		    c.updated_at,
+		    COUNT(*) OVER()
+		FROM dial_children c
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY c.id ASC
+		`+limitOffset,
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialChild objects.
+	children := make([]*wtf.DialChild, 0)
+	for rows.Next() {
+		var child wtf.DialChild
+		if err := rows.Scan(
+			&child.ID,
+			&child.ParentDialID,
+			&child.ChildDialID,
+			&child.Weight,
+			(*NullTime)(&child.CreatedAt),
+			(*NullTime)(&child.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		children = append(children, &child)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return children, n, nil
+}
+
+// createDialChild attaches a dial to a parent dial & recomputes the parent's
+// value. Returns EUNAUTHORIZED if the current user does not own the child dial
+// or is not an active member of the parent dial.
+func createDialChild(ctx context.Context, tx *Tx, child *wtf.DialChild) error {
+	if child.Weight == 0 {
+		child.Weight = wtf.DefaultDialChildWeight
+	}
+	child.CreatedAt = tx.now
+	child.UpdatedAt = child.CreatedAt
+
+	// Perform basic field validation.
+	if err := child.Validate(); err != nil {
+		return err
+	}
+
+	// Attaching changes how the parent's value is computed so the user must
+	// own both the child & the parent dial.
+	if dial, err := findDialByID(ctx, tx, child.ChildDialID); err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can attach it to a parent dial.")
+	} else if parent, err := findDialByID(ctx, tx, child.ParentDialID); err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, parent) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner of the parent dial can attach dials to it.")
+	} else if err := checkDialNotArchived(ctx, tx, child.ParentDialID); err != nil {
+		return err
+	}
+
+	// Each dial may only have one parent.
+	if parent, err := findDialParent(ctx, tx, child.ChildDialID); err != nil {
+		return err
+	} else if parent != nil {
+		return wtf.Errorf(wtf.ECONFLICT, "Dial is already attached to a parent dial.")
+	}
+
+	// The parent will depend on the child so ensure the child does not
+	// already depend on the parent, which would create a cycle.
+	if ok, err := dialDependsOn(ctx, tx, child.ChildDialID, child.ParentDialID); err != nil {
+		return err
+	} else if ok {
+		return wtf.Errorf(wtf.EINVALID, "Dial cannot be attached to a dial that it depends on.")
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_children (
+			parent_dial_id,
+			child_dial_id,
+			weight,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		child.ParentDialID,
+		child.ChildDialID,
+		child.Weight,
+		(*NullTime)(&child.CreatedAt),
+		(*NullTime)(&child.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new child ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	child.ID = int(id)
+
+	// Record the new child in the parent dial's audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialChildCreate,
+		TargetType: wtf.AuditTargetDialChild,
+		TargetID:   child.ID,
+		DialID:     child.ParentDialID,
+	}, nil, child); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Parent now takes its value from its children.
+	if err := refreshDialValue(ctx, tx, child.ParentDialID); err != nil {
+		return fmt.Errorf("refresh dial value: %w", err)
+	}
+	return nil
+}
+
+// updateDialChild updates the weight of a child by ID & recomputes the
+// parent's value. Returns EUNAUTHORIZED if the current user does not own the
+// parent or child dial.
+func updateDialChild(ctx context.Context, tx *Tx, id int, upd wtf.DialChildUpdate) (*wtf.DialChild, error) {
+	// Fetch current object state & verify the current user can edit it.
+	child, err := findDialChildByID(ctx, tx, id)
+	if err != nil {
+		return child, err
+	} else if err := attachDialChildAssociations(ctx, tx, child); err != nil {
+		return child, err
+	} else if !wtf.CanEditDialChild(ctx, child) {
+		return child, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner of the parent or child dial can update it.")
+	} else if err := checkDialNotArchived(ctx, tx, child.ParentDialID); err != nil {
+		return child, err
+	}
+
+	// Save state of child for the audit log.
+	prev := *child
+	prev.ParentDial, prev.ChildDial = nil, nil
+
+	// Update fields, if set.
+	if v := upd.Weight; v != nil {
+		child.Weight = *v
+	}
+	child.UpdatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := child.Validate(); err != nil {
+		return child, err
+	}
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_children
+		SET weight = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		child.Weight,
+		(*NullTime)(&child.UpdatedAt),
+		id,
+	); err != nil {
+		return child, FormatError(err)
+	}
+
+	// Record change in the parent dial's audit log.
+	next := *child
+	next.ParentDial, next.ChildDial = nil, nil
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialChildUpdate,
+		TargetType: wtf.AuditTargetDialChild,
+		TargetID:   child.ID,
+		DialID:     child.ParentDialID,
+	}, &prev, &next); err != nil {
+		return child, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Recompute the parent's value with the new weight.
+	if err := refreshDialValue(ctx, tx, child.ParentDialID); err != nil {
+		return child, fmt.Errorf("refresh dial value: %w", err)
+	} else if err := attachDialChildAssociations(ctx, tx, child); err != nil {
+		return child, err
+	}
+	return child, nil
+}
+
+// deleteDialChild detaches a child by ID & recomputes the parent's value.
+// Returns EUNAUTHORIZED if the current user does not own the parent or child
+// dial.
+func deleteDialChild(ctx context.Context, tx *Tx, id int) error {
+	// Verify child exists & the current user can edit it.
+	child, err := findDialChildByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if err := attachDialChildAssociations(ctx, tx, child); err != nil {
+		return err
+	} else if !wtf.CanEditDialChild(ctx, child) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner of the parent or child dial can detach it.")
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_children WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the detached child in the parent dial's audit log.
+	prev := *child
+	prev.ParentDial, prev.ChildDial = nil, nil
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialChildDelete,
+		TargetType: wtf.AuditTargetDialChild,
+		TargetID:   child.ID,
+		DialID:     child.ParentDialID,
+	}, &prev, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Parent returns to its member values once its last child is detached.
+	if err := refreshDialValue(ctx, tx, child.ParentDialID); err != nil {
+		return fmt.Errorf("refresh dial value: %w", err)
+	}
+	return nil
+}
+
+// attachDialChildAssociations is a helper function to look up and attach the
+// parent & child dials to the child. This bypasses dial permissions so that
+// members of the parent dial can see the name & value of its children.
+func attachDialChildAssociations(ctx context.Context, tx *Tx, child *wtf.DialChild) error {
+	if dials, _, err := queryDials(ctx, tx, []string{"id = ?"}, []interface{}{child.ParentDialID}, ""); err != nil {
+		return fmt.Errorf("attach parent dial: %w", err)
+	} else if len(dials) == 0 {
+		return fmt.Errorf("parent dial not found: id=%d", child.ParentDialID)
+	} else {
+		child.ParentDial = dials[0]
+	}
+
+	if dials, _, err := queryDials(ctx, tx, []string{"id = ?"}, []interface{}{child.ChildDialID}, ""); err != nil {
+		return fmt.Errorf("attach child dial: %w", err)
+	} else if len(dials) == 0 {
+		return fmt.Errorf("child dial not found: id=%d", child.ChildDialID)
+	} else {
+		child.ChildDial = dials[0]
+	}
+	return nil
+}
+
+// rollUpDialChildren computes a parent dial's value from its children. Each
+// child's value is placed on the parent's scale by its position within its
+// own scale & weighted against its siblings. Returns false if the dial has no
+// children.
+func rollUpDialChildren(ctx context.Context, tx *Tx, parentID int) (value int, ok bool, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT d.value, d.scale_min, d.scale_max, c.weight
+		FROM dial_children c
+		INNER JOIN dials d ON c.child_dial_id = d.id
+		WHERE c.parent_dial_id = ?
+	`,
+		parentID,
+	)
+	if err != nil {
+		return 0, false, FormatError(err)
+	}
+	defer rows.Close()
+
+	var sum, totalWeight float64
+	for rows.Next() {
+		var v int
+		var scale wtf.DialScale
+		var weight float64
+		if err := rows.Scan(&v, &scale.Min, &scale.Max, &weight); err != nil {
+			return 0, false, err
+		}
+		sum += scale.Fraction(v) * weight
+		totalWeight += weight
+	}
+	if err := rows.Err(); err != nil {
+		return 0, false, err
+	} else if totalWeight == 0 {
+		return 0, false, nil
+	}
+
+	scale, err := findDialScale(ctx, tx, parentID)
+	if err != nil {
+		return 0, false, err
+	}
+	return scale.ValueAt(sum / totalWeight), true, nil
+}
+
+// propagateDialValue notifies the members of a dial's parent that the dial's
+// value has changed & recomputes the parent's value. The change continues up
+// the hierarchy from there. This is a no-op if the dial has no parent.
+func propagateDialValue(ctx context.Context, tx *Tx, id, value int) error {
+	parent, err := findDialParent(ctx, tx, id)
+	if err != nil || parent == nil {
+		return err
+	}
+
+	if err := publishDialEvent(ctx, tx, parent.ParentDialID, wtf.Event{
+		Type: wtf.EventTypeDialChildValueChanged,
+		Payload: &wtf.DialChildValueChangedPayload{
+			ID:           parent.ID,
+			ParentDialID: parent.ParentDialID,
+			ChildDialID:  id,
+			Value:        value,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+
+	if err := refreshDialValue(ctx, tx, parent.ParentDialID); err != nil {
+		return fmt.Errorf("refresh parent dial value: %w", err)
+	}
+	return nil
+}
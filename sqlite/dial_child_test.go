@@ -0,0 +1,288 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialChildService_CreateDialChild(t *testing.T) {
+	// Ensure a parent's value is the weighted average of its children, each
+	// normalized to its own scale, & that child changes roll up through it.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		parent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "ORG"})
+		childA := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD A"})
+		childB := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD B", Scale: wtf.DialScale{Min: 0, Max: 10, Step: 1}})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 60)
+		MustSetDialMembershipValue(t, ctx0, db, 2, 80)
+		MustSetDialMembershipValue(t, ctx0, db, 3, 4)
+
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if event.Type == wtf.EventTypeDialChildValueChanged {
+					events = append(events, event)
+				}
+			},
+		}
+
+		now = now.Add(time.Hour)
+		a := &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: childA.ID}
+		if err := s.CreateDialChild(ctx0, a); err != nil {
+			t.Fatal(err)
+		} else if a.ID == 0 || a.Weight != wtf.DefaultDialChildWeight {
+			t.Fatalf("unexpected dial child: %#v", a)
+		} else if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 80; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		now = now.Add(time.Hour)
+		b := &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: childB.ID, Weight: 3}
+		if err := s.CreateDialChild(ctx0, b); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 50; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Changing a child's member value should recompute the parent.
+		now = now.Add(time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 2, 0)
+		if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 30; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		if len(events) != 1 {
+			t.Fatalf("unexpected event count: %d", len(events))
+		} else if payload := events[0].Payload.(*wtf.DialChildValueChangedPayload); payload.ID != a.ID || payload.ChildDialID != childA.ID || payload.Value != 0 {
+			t.Fatalf("unexpected payload: %#v", payload)
+		}
+
+		// Ensure rolled up values are recorded in the parent's history.
+		if values, err := sqlite.NewDialService(db).DialValues(ctx0, parent.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := values, []int{60, 80, 50, 30}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("DialValues=%v, want %v", got, want)
+		}
+
+		if children, n, err := s.FindDialChildren(ctx0, wtf.DialChildFilter{ParentDialID: &parent.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 2 || len(children) != 2 {
+			t.Fatalf("unexpected children: n=%d", n)
+		} else if children[0].ChildDial.Name != "SQUAD A" || children[1].ParentDial.Name != "ORG" {
+			t.Fatalf("unexpected associations: %#v", children)
+		}
+	})
+
+	// Ensure changes are propagated through multiple levels.
+	t.Run("Nested", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		grandparent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "COMPANY"})
+		parent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "ORG"})
+		child := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD"})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: grandparent.ID, ChildDialID: parent.ID})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: child.ID})
+
+		MustSetDialMembershipValue(t, ctx0, db, 3, 70)
+		if got, want := MustFindDialByID(t, ctx0, db, grandparent.ID).Value, 70; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a dial cannot be attached to one of its own descendants.
+	t.Run("ErrCycle", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B"})
+		dial2 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "C"})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: dial0.ID, ChildDialID: dial1.ID})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: dial1.ID, ChildDialID: dial2.ID})
+
+		if err := s.CreateDialChild(ctx0, &wtf.DialChild{ParentDialID: dial2.ID, ChildDialID: dial0.ID}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dial cannot be attached to a dial that it depends on.` {
+			t.Fatal(err)
+		} else if err := s.CreateDialChild(ctx0, &wtf.DialChild{ParentDialID: dial0.ID, ChildDialID: dial0.ID}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dial cannot be attached to itself.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure a dial can only have one parent.
+	t.Run("ErrAlreadyAttached", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B"})
+		dial2 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "C"})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: dial0.ID, ChildDialID: dial2.ID})
+
+		if err := s.CreateDialChild(ctx0, &wtf.DialChild{ParentDialID: dial1.ID, ChildDialID: dial2.ID}); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Dial is already attached to a parent dial.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure only the child's owner may attach it & only to a dial they own.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		dial1 := MustCreateDial(t, ctx1, db, &wtf.Dial{Name: "B"})
+		MustCreateDialMembership(t, ctx0, db, &wtf.DialMembership{DialID: dial1.ID})
+
+		if err := s.CreateDialChild(ctx0, &wtf.DialChild{ParentDialID: dial0.ID, ChildDialID: dial1.ID}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can attach it to a parent dial.` {
+			t.Fatal(err)
+		} else if err := s.CreateDialChild(ctx1, &wtf.DialChild{ParentDialID: dial0.ID, ChildDialID: dial1.ID}); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatal(err)
+		}
+
+		// Members of the parent dial cannot attach their own dials to it.
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial0.ID})
+		if err := s.CreateDialChild(ctx1, &wtf.DialChild{ParentDialID: dial0.ID, ChildDialID: dial1.ID}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the owner of the parent dial can attach dials to it.` {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialChildService_UpdateDialChild(t *testing.T) {
+	// Ensure changing a child's weight recomputes the parent.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		parent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "ORG"})
+		childA := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD A"})
+		childB := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD B"})
+		MustSetDialMembershipValue(t, ctx0, db, 2, 80)
+		MustSetDialMembershipValue(t, ctx0, db, 3, 20)
+		a := MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: childA.ID})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: childB.ID})
+		if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 50; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		weight := 3.0
+		if child, err := s.UpdateDialChild(ctx0, a.ID, wtf.DialChildUpdate{Weight: &weight}); err != nil {
+			t.Fatal(err)
+		} else if child.Weight != 3 {
+			t.Fatalf("Weight=%v, want %v", child.Weight, 3)
+		} else if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 65; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure only the owner of the parent or child may update the weight.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		parent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "ORG"})
+		child := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: parent.ID})
+		c := MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: child.ID})
+
+		weight := 2.0
+		if _, err := s.UpdateDialChild(ctx1, c.ID, wtf.DialChildUpdate{Weight: &weight}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the owner of the parent or child dial can update it.` {
+			t.Fatal(err)
+		} else if err := s.DeleteDialChild(ctx1, c.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the owner of the parent or child dial can detach it.` {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialChildService_DeleteDialChild(t *testing.T) {
+	// Ensure detaching the last child returns the parent to its member average.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialChildService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		parent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "ORG"})
+		child := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 40)
+		MustSetDialMembershipValue(t, ctx0, db, 2, 90)
+		c := MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: child.ID})
+		if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 90; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		if err := s.DeleteDialChild(ctx0, c.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDialChildByID(ctx0, c.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 40; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure deleting a child dial detaches it & recomputes the parent.
+	t.Run("DeleteChildDial", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		parent := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "ORG"})
+		childA := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD A"})
+		childB := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SQUAD B"})
+		MustSetDialMembershipValue(t, ctx0, db, 2, 80)
+		MustSetDialMembershipValue(t, ctx0, db, 3, 20)
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: childA.ID})
+		MustCreateDialChild(t, ctx0, db, &wtf.DialChild{ParentDialID: parent.ID, ChildDialID: childB.ID})
+
+		s := sqlite.NewDialService(db)
+		if _, err := s.ArchiveDial(ctx0, childA.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteDial(ctx0, childA.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, parent.ID).Value, 20; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+}
+
+// MustCreateDialChild attaches a child dial in the database. Fatal on error.
+func MustCreateDialChild(tb testing.TB, ctx context.Context, db *sqlite.DB, child *wtf.DialChild) *wtf.DialChild {
+	tb.Helper()
+	if err := sqlite.NewDialChildService(db).CreateDialChild(ctx, child); err != nil {
+		tb.Fatal(err)
+	}
+	return child
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// cloneDial creates a new dial from the settings of an existing dial. The
+// clone is created through createDial() so it receives a new invite code, its
+// own history & the usual audit entry. Returns EUNAUTHORIZED if the user does
+// not own the dial.
+func cloneDial(ctx context.Context, tx *Tx, id int, opt wtf.DialClone) (*wtf.Dial, error) {
+	src, err := findDialByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if !wtf.CanEditDial(ctx, src) {
+		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the owner can clone a dial.")
+	}
+
+	dial := &wtf.Dial{
+		Name:                      src.Name,
+		RequireApproval:           src.RequireApproval,
+		AnomalySpikeThreshold:     src.AnomalySpikeThreshold,
+		AnomalySustainedThreshold: src.AnomalySustainedThreshold,
+		StalePolicy:               src.StalePolicy,
+		StaleAfterDays:            src.StaleAfterDays,
+		StaleBaseline:             src.StaleBaseline,
+		StaleHalfLifeDays:         src.StaleHalfLifeDays,
+		ResetIntervalDays:         src.ResetIntervalDays,
+		ResetValue:                src.ResetValue,
+		ResetTimezone:             src.ResetTimezone,
+		Scale:                     src.Scale,
+		Expression:                src.Expression,
+	}
+	if opt.Name != "" {
+		dial.Name = opt.Name
+	}
+
+	// The next reset of an archived dial may have passed so the schedule is
+	// moved forward to its next occurrence.
+	dial.NextResetAt = src.NextResetAfter(tx.now)
+
+	// Copy bands & dimensions so the clone does not share them with the source.
+	dial.Scale.Bands = make([]*wtf.DialBand, len(src.Scale.Bands))
+	for i, band := range src.Scale.Bands {
+		other := *band
+		dial.Scale.Bands[i] = &other
+	}
+	for _, dim := range src.Dimensions {
+		dial.Dimensions = append(dial.Dimensions, &wtf.DialDimension{Name: dim.Name})
+	}
+
+	if err := createDial(ctx, tx, dial); err != nil {
+		return nil, err
+	}
+
+	// Copy alert rules & check-in schedules. Their run state starts over.
+	rules, _, err := findDialAlertRules(ctx, tx, wtf.DialAlertRuleFilter{DialID: &src.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		other := *rule
+		other.ID, other.DialID = 0, dial.ID
+		if err := createDialAlertRule(ctx, tx, &other); err != nil {
+			return nil, fmt.Errorf("clone alert rule: id=%d err=%w", rule.ID, err)
+		}
+	}
+
+	schedules, _, err := findDialReminderSchedules(ctx, tx, wtf.DialReminderScheduleFilter{DialID: &src.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range schedules {
+		other := *schedule
+		other.ID, other.DialID = 0, dial.ID
+		if err := createDialReminderSchedule(ctx, tx, &other); err != nil {
+			return nil, fmt.Errorf("clone check-in schedule: id=%d err=%w", schedule.ID, err)
+		}
+	}
+
+	// Re-send pending invitations so invitees can join the clone. They are
+	// sent to the same address so invitees are resolved again.
+	pending, expired := wtf.InvitationStatusPending, false
+	invitations, _, err := findInvitations(ctx, tx, wtf.InvitationFilter{DialID: &src.ID, Status: &pending, Expired: &expired})
+	if err != nil {
+		return nil, err
+	}
+	for _, invitation := range invitations {
+		if err := createInvitation(ctx, tx, &wtf.Invitation{
+			DialID:      dial.ID,
+			Email:       invitation.Email,
+			GitHubLogin: invitation.GitHubLogin,
+		}); err != nil {
+			return nil, fmt.Errorf("clone invitation: id=%d err=%w", invitation.ID, err)
+		}
+	}
+
+	// Optionally add the active members. Values start over at the bottom of
+	// the scale, the same as when a member first joins.
+	if opt.IncludeMembers {
+		active := wtf.DialMembershipStatusActive
+		memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{DialID: &src.ID, Status: &active})
+		if err != nil {
+			return nil, err
+		}
+		for _, membership := range memberships {
+			if membership.UserID == dial.UserID {
+				continue
+			}
+			if err := createDialMembership(ctx, tx, &wtf.DialMembership{
+				DialID: dial.ID,
+				UserID: membership.UserID,
+				Value:  dial.Scale.Min,
+				Status: wtf.DialMembershipStatusActive,
+			}); err != nil {
+				return nil, fmt.Errorf("clone membership: id=%d err=%w", membership.ID, err)
+			}
+		}
+	}
+
+	// Return the clone with its computed value & dimensions.
+	return findDialByID(ctx, tx, dial.ID)
+}
@@ -0,0 +1,138 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_CloneDial(t *testing.T) {
+	// Ensure a clone copies the dial settings, rules, schedules & invitations
+	// and, optionally, its members with their values reset.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		_, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		src := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:            "Sprint 1",
+			RequireApproval: true,
+			Scale: wtf.DialScale{
+				Min:  1,
+				Max:  5,
+				Step: 1,
+				Bands: []*wtf.DialBand{
+					{Label: "calm", Color: "#00d27a", Min: 1, Max: 2},
+				},
+			},
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "Clarity"}},
+		})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: src.ID, Value: 4})
+		if _, err := sqlite.NewDialMembershipService(db).ApproveDialMembership(ctx0, membership.ID); err != nil {
+			t.Fatal(err)
+		} else if err := sqlite.NewDialAlertService(db).CreateDialAlertRule(ctx0, &wtf.DialAlertRule{DialID: src.ID, Name: "RULE", Subject: wtf.DialAlertSubjectDial, Operator: wtf.DialAlertOperatorGTE, Threshold: 4, NotifyInApp: true}); err != nil {
+			t.Fatal(err)
+		} else if err := sqlite.NewDialReminderService(db).CreateDialReminderSchedule(ctx0, &wtf.DialReminderSchedule{DialID: src.ID, Weekdays: []time.Weekday{time.Monday}, Hour: 10, Timezone: "UTC", NotifyInApp: true}); err != nil {
+			t.Fatal(err)
+		} else if err := sqlite.NewInvitationService(db).CreateInvitation(ctx0, &wtf.Invitation{DialID: src.ID, Email: "jim@gmail.com"}); err != nil {
+			t.Fatal(err)
+		}
+
+		dial, err := s.CloneDial(ctx0, src.ID, wtf.DialClone{Name: "Sprint 2", IncludeMembers: true})
+		if err != nil {
+			t.Fatal(err)
+		} else if dial.ID == src.ID || dial.InviteCode == "" || dial.InviteCode == src.InviteCode {
+			t.Fatalf("unexpected clone: id=%d invite=%q", dial.ID, dial.InviteCode)
+		} else if got, want := dial.Name, "Sprint 2"; got != want {
+			t.Fatalf("Name=%v, want %v", got, want)
+		} else if !dial.RequireApproval {
+			t.Fatal("expected RequireApproval")
+		} else if !reflect.DeepEqual(dial.Scale, MustFindDialByID(t, ctx0, db, src.ID).Scale) {
+			t.Fatalf("unexpected scale: %#v", dial.Scale)
+		} else if len(dial.Dimensions) != 2 || dial.Dimensions[0].Name != "Workload" || dial.Dimensions[1].Name != "Clarity" {
+			t.Fatalf("unexpected dimensions: %#v", dial.Dimensions)
+		}
+
+		// Members are added as active with their values at the bottom of the scale.
+		if memberships, n, err := sqlite.NewDialMembershipService(db).FindDialMemberships(ctx0, wtf.DialMembershipFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 2 {
+			t.Fatalf("memberships n=%d, want %d", n, 2)
+		} else {
+			for _, membership := range memberships {
+				if membership.UserID == user1.ID && (membership.Value != 1 || membership.IsPending()) {
+					t.Fatalf("unexpected membership: %#v", membership)
+				}
+			}
+		}
+		if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 1; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Rules, schedules & invitations are copied onto the clone.
+		if _, n, err := sqlite.NewDialAlertService(db).FindDialAlertRules(ctx0, wtf.DialAlertRuleFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("alert rules n=%d, want %d", n, 1)
+		}
+		if _, n, err := sqlite.NewDialReminderService(db).FindDialReminderSchedules(ctx0, wtf.DialReminderScheduleFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("check-in schedules n=%d, want %d", n, 1)
+		}
+		if invitations, n, err := sqlite.NewInvitationService(db).FindInvitations(ctx2, wtf.InvitationFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 || invitations[0].Email != "jim@gmail.com" || !invitations[0].IsPending() {
+			t.Fatalf("unexpected invitations: n=%d", n)
+		}
+	})
+
+	// Ensure members are not copied unless requested & the name defaults to
+	// the name of the cloned dial.
+	t.Run("WithoutMembers", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		src := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: src.ID})
+
+		if dial, err := s.CloneDial(ctx0, src.ID, wtf.DialClone{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := dial.Name, "DIAL"; got != want {
+			t.Fatalf("Name=%v, want %v", got, want)
+		} else if _, n, err := sqlite.NewDialMembershipService(db).FindDialMemberships(ctx0, wtf.DialMembershipFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("memberships n=%d, want %d", n, 1)
+		}
+	})
+
+	// Ensure only the owner can clone a dial.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		src := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: src.ID})
+
+		if _, err := s.CloneDial(ctx1, src.ID, wtf.DialClone{}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the owner can clone a dial.` {
+			t.Fatal(err)
+		}
+	})
+}
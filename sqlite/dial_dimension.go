@@ -0,0 +1,530 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// findDialDimensions returns the dimensions of a dial in order. Bands are
+// computed from the given scale. This performs no permission checks.
+func findDialDimensions(ctx context.Context, tx *Tx, dialID int, scale *wtf.DialScale) ([]*wtf.DialDimension, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, dial_id, name, position, value, created_at, updated_at
+		FROM dial_dimensions
+		WHERE dial_id = ?
+		ORDER BY position, id
+	`,
+		dialID,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	dims := make([]*wtf.DialDimension, 0)
+	for rows.Next() {
+		var dim wtf.DialDimension
+		if err := rows.Scan(
+			&dim.ID,
+			&dim.DialID,
+			&dim.Name,
+			&dim.Position,
+			&dim.Value,
+			(*NullTime)(&dim.CreatedAt),
+			(*NullTime)(&dim.UpdatedAt),
+		); err != nil {
+			return nil, err
+		}
+		dim.Band = scale.BandLabel(dim.Value)
+		dims = append(dims, &dim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return dims, nil
+}
+
+// applyDialDimensionsUpdate replaces the dimensions of a dial with the list
+// from an update. Entries are matched to existing dimensions by ID or, if no
+// ID is given, by name so that existing values are kept.
+func applyDialDimensionsUpdate(dial *wtf.Dial, dims []*wtf.DialDimension) error {
+	prev := dial.Dimensions
+
+	dial.Dimensions = make([]*wtf.DialDimension, 0, len(dims))
+	for i, dim := range dims {
+		other := &wtf.DialDimension{DialID: dial.ID, Name: strings.TrimSpace(dim.Name), Position: i}
+
+		// Copy the state of the existing dimension, if any.
+		var existing *wtf.DialDimension
+		for _, p := range prev {
+			if (dim.ID != 0 && p.ID == dim.ID) || (dim.ID == 0 && strings.EqualFold(p.Name, other.Name)) {
+				existing = p
+				break
+			}
+		}
+		if existing != nil {
+			other.ID, other.Value, other.Band = existing.ID, existing.Value, existing.Band
+			other.CreatedAt, other.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+		} else if dim.ID != 0 {
+			return wtf.Errorf(wtf.ENOTFOUND, "Dial dimension not found.")
+		}
+
+		dial.Dimensions = append(dial.Dimensions, other)
+	}
+	return nil
+}
+
+// saveDialDimensions writes the dial's dimensions to the database. New
+// dimensions are inserted & start at the dial's value, changed dimensions are
+// updated and dimensions in prev that are no longer on the dial are deleted
+// along with their member values. Returns true if any were deleted.
+func saveDialDimensions(ctx context.Context, tx *Tx, dial *wtf.Dial, prev []*wtf.DialDimension) (removed bool, err error) {
+	// Delete dimensions that were removed from the dial.
+	for _, p := range prev {
+		var found bool
+		for _, dim := range dial.Dimensions {
+			found = found || dim.ID == p.ID
+		}
+		if found {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM dial_dimensions WHERE id = ?`, p.ID); err != nil {
+			return false, FormatError(err)
+		}
+		removed = true
+	}
+
+	for _, dim := range dial.Dimensions {
+		// Update existing dimensions if they have been renamed or reordered.
+		if dim.ID != 0 {
+			var changed bool
+			for _, p := range prev {
+				changed = changed || (p.ID == dim.ID && (p.Name != dim.Name || p.Position != dim.Position))
+			}
+			if !changed {
+				continue
+			}
+
+			dim.UpdatedAt = tx.now
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE dial_dimensions
+				SET name = ?,
+				    position = ?,
+				    updated_at = ?
+				WHERE id = ?
+			`,
+				dim.Name,
+				dim.Position,
+				(*NullTime)(&dim.UpdatedAt),
+				dim.ID,
+			); err != nil {
+				return false, FormatError(err)
+			}
+			continue
+		}
+
+		// Insert new dimensions. Members start at their current value so the
+		// dimension starts at the dial's value until members change it.
+		dim.DialID = dial.ID
+		dim.Value = dial.Value
+		dim.Band = dial.Scale.BandLabel(dim.Value)
+		dim.CreatedAt = tx.now
+		dim.UpdatedAt = dim.CreatedAt
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO dial_dimensions (
+				dial_id,
+				name,
+				position,
+				value,
+				created_at,
+				updated_at
+			)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`,
+			dim.DialID,
+			dim.Name,
+			dim.Position,
+			dim.Value,
+			(*NullTime)(&dim.CreatedAt),
+			(*NullTime)(&dim.UpdatedAt),
+		)
+		if err != nil {
+			return false, FormatError(err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return false, err
+		}
+		dim.ID = int(id)
+
+		// Record initial value to the dimension's history.
+		if err := insertDialDimensionValue(ctx, tx, dim.ID, dim.Value, dim.CreatedAt); err != nil {
+			return false, fmt.Errorf("insert initial dimension value: %w", err)
+		}
+	}
+
+	return removed, nil
+}
+
+// rollUpDialMembershipDimensions recomputes the value of each membership from
+// its remaining dimension values after dimensions are removed from a dial.
+// Values are changed the same way as a member update so the value history,
+// audit log & events reflect it.
+func rollUpDialMembershipDimensions(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
+	memberships, err := listDialMemberships(ctx, tx, dial.ID)
+	if err != nil {
+		return err
+	}
+
+	note := wtf.DialDimensionChangeNote
+	for _, membership := range memberships {
+		if len(membership.Dimensions) == 0 {
+			continue
+		} else if dial.Scale.Average(dialMembershipDimensionValues(membership)) == membership.Value {
+			continue
+		}
+
+		if err := applyDialMembershipUpdate(ctx, tx, membership, wtf.DialMembershipUpdate{Note: &note}); err != nil {
+			return fmt.Errorf("roll up membership: id=%d err=%w", membership.ID, err)
+		}
+	}
+	return nil
+}
+
+// refreshDialDimensionValues recomputes the aggregate value of each of a
+// dial's dimensions from its active members after applying the dial's stale
+// policy. Changed values are saved, recorded in the dimension's history and
+// announced to the dial's members.
+func refreshDialDimensionValues(ctx context.Context, tx *Tx, dialID int) error {
+	dial, err := findDialStalePolicy(ctx, tx, dialID)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the value of every active member for every dimension. Members
+	// without a stored value for a dimension contribute their overall value.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    dd.id,
+		    dd.value,
+		    COALESCE(dmd.value, dm.value),
+		    dm.value,
+		    dm.away_until,
+		    dm.updated_at
+		FROM dial_dimensions dd
+		INNER JOIN dial_memberships dm ON dm.dial_id = dd.dial_id AND dm.status = ?
+		LEFT JOIN dial_membership_dimensions dmd ON dmd.dial_dimension_id = dd.id AND dmd.dial_membership_id = dm.id
+		WHERE dd.dial_id = ?
+		ORDER BY dd.id
+	`,
+		wtf.DialMembershipStatusActive,
+		dialID,
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	// Sum the effective values for each dimension.
+	type aggregate struct {
+		id       int
+		oldValue int
+		sum, n   int
+	}
+	var aggs []*aggregate
+	for rows.Next() {
+		var id, oldValue int
+		var membership wtf.DialMembership
+		var dim wtf.DialMembershipDimension
+		if err := rows.Scan(
+			&id,
+			&oldValue,
+			&dim.Value,
+			&membership.Value,
+			(*NullTime)(&membership.AwayUntil),
+			(*NullTime)(&membership.UpdatedAt),
+		); err != nil {
+			return err
+		}
+
+		if len(aggs) == 0 || aggs[len(aggs)-1].id != id {
+			aggs = append(aggs, &aggregate{id: id, oldValue: oldValue})
+		}
+		agg := aggs[len(aggs)-1]
+
+		if membership.IsAway(tx.now) {
+			continue
+		} else if value, ok := dial.EffectiveMembershipDimensionValue(&membership, &dim, tx.now); ok {
+			agg.sum, agg.n = agg.sum+value, agg.n+1
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	// Update each dimension whose value changed. Dimensions without any
+	// contributing members sit at the bottom of the scale, the same as the
+	// dial value.
+	scale, err := findDialScale(ctx, tx, dialID)
+	if err != nil {
+		return err
+	}
+	for _, agg := range aggs {
+		newValue := scale.Min
+		if agg.n > 0 {
+			newValue = int(math.Round(float64(agg.sum) / float64(agg.n)))
+		}
+		if newValue == agg.oldValue {
+			continue
+		}
+
+		if err := updateDialDimensionValue(ctx, tx, dialID, agg.id, newValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findDialStalePolicy returns a dial with only its stale policy fields set.
+// This bypasses permission checks as it is used when computing values.
+func findDialStalePolicy(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
+	var dial wtf.Dial
+	if err := tx.QueryRowContext(ctx, `
+		SELECT stale_policy, stale_after_days, stale_baseline, stale_half_life_days
+		FROM dials
+		WHERE id = ?
+	`,
+		id,
+	).Scan(
+		&dial.StalePolicy,
+		&dial.StaleAfterDays,
+		&dial.StaleBaseline,
+		&dial.StaleHalfLifeDays,
+	); err == sql.ErrNoRows {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	} else if err != nil {
+		return nil, FormatError(err)
+	}
+	dial.ID = id
+	return &dial, nil
+}
+
+// updateDialDimensionValue sets a new computed value on a dimension, records
+// it in the dimension's history & notifies the dial's active members.
+func updateDialDimensionValue(ctx context.Context, tx *Tx, dialID, id, newValue int) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_dimensions
+		SET value = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		newValue,
+		(*NullTime)(&tx.now),
+		id,
+	); err != nil {
+		return FormatError(err)
+	}
+
+	// Record historical value into "dial_dimension_values" table.
+	if err := insertDialDimensionValue(ctx, tx, id, newValue, tx.now); err != nil {
+		return fmt.Errorf("insert historical dimension value: %w", err)
+	}
+
+	// Publish event to notify members that the value has changed.
+	if err := publishDialEvent(ctx, tx, dialID, wtf.Event{
+		Type: wtf.EventTypeDialDimensionValueChanged,
+		Payload: &wtf.DialDimensionValueChangedPayload{
+			ID:     id,
+			DialID: dialID,
+			Value:  newValue,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+	return nil
+}
+
+// insertDialDimensionValue records a dimension value at specific point in
+// time. Like dial values, only one value is kept per minute.
+func insertDialDimensionValue(ctx context.Context, tx *Tx, id int, value int, timestamp time.Time) error {
+	timestamp = timestamp.Truncate(1 * time.Minute)
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_dimension_values (dial_dimension_id, "timestamp", value)
+		VALUES (?, ?, ?)
+		ON CONFLICT (dial_dimension_id, "timestamp") DO UPDATE SET value = ?
+	`,
+		id, (*NullTime)(&timestamp), value, value,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// findDialDimensionValueChangesBetween returns the dimension value in effect
+// at start and the list of recorded values between start & end, in time order.
+func findDialDimensionValueChangesBetween(ctx context.Context, tx *Tx, id int, start, end time.Time) (initial int, changes []dialValueChange, err error) {
+	// Determine initial value at start of report time range.
+	if err := tx.QueryRowContext(ctx, `
+		SELECT value
+		FROM dial_dimension_values
+		WHERE dial_dimension_id = ? AND "timestamp" < ?
+		ORDER BY "timestamp" DESC
+		LIMIT 1
+	`,
+		id,
+		(*NullTime)(&start),
+	).Scan(&initial); err != nil && err != sql.ErrNoRows {
+		return 0, nil, FormatError(err)
+	}
+
+	// Find all values between start & end.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT value, "timestamp"
+		FROM dial_dimension_values
+		WHERE dial_dimension_id = ? AND "timestamp" >= ? AND "timestamp" < ?
+		ORDER BY "timestamp" ASC
+	`,
+		id,
+		(*NullTime)(&start),
+		(*NullTime)(&end),
+	)
+	if err != nil {
+		return 0, nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var change dialValueChange
+		if err := rows.Scan(&change.Value, (*NullTime)(&change.Timestamp)); err != nil {
+			return 0, nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return initial, changes, nil
+}
+
+// findDialMembershipDimensions returns a membership's value for each of the
+// dial's dimensions, in dimension order. Dimensions the member has not set a
+// value for since they were added use the membership's overall value. Bands
+// are computed from the given scale. Returns nil if the dial has no
+// dimensions.
+func findDialMembershipDimensions(ctx context.Context, tx *Tx, membership *wtf.DialMembership, scale *wtf.DialScale) ([]*wtf.DialMembershipDimension, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT dd.id, dd.name, COALESCE(dmd.value, ?)
+		FROM dial_dimensions dd
+		LEFT JOIN dial_membership_dimensions dmd ON dmd.dial_dimension_id = dd.id AND dmd.dial_membership_id = ?
+		WHERE dd.dial_id = ?
+		ORDER BY dd.position, dd.id
+	`,
+		membership.Value,
+		membership.ID,
+		membership.DialID,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var dims []*wtf.DialMembershipDimension
+	for rows.Next() {
+		var dim wtf.DialMembershipDimension
+		if err := rows.Scan(&dim.DimensionID, &dim.Name, &dim.Value); err != nil {
+			return nil, err
+		}
+		dim.Band = scale.BandLabel(dim.Value)
+		dims = append(dims, &dim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return dims, nil
+}
+
+// saveDialMembershipDimensions stores every dimension value of a membership.
+func saveDialMembershipDimensions(ctx context.Context, tx *Tx, membership *wtf.DialMembership) error {
+	for _, dim := range membership.Dimensions {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO dial_membership_dimensions (dial_membership_id, dial_dimension_id, value)
+			VALUES (?, ?, ?)
+			ON CONFLICT (dial_membership_id, dial_dimension_id) DO UPDATE SET value = ?
+		`,
+			membership.ID, dim.DimensionID, dim.Value, dim.Value,
+		); err != nil {
+			return FormatError(err)
+		}
+	}
+	return nil
+}
+
+// applyDialMembershipDimensionsUpdate sets the dimension values of a
+// membership from an update. Value sets every dimension & named dimensions
+// override it. Returns EINVALID if the dial has no dimension with a name.
+func applyDialMembershipDimensionsUpdate(membership *wtf.DialMembership, upd wtf.DialMembershipUpdate) error {
+	if v := upd.Value; v != nil {
+		for _, dim := range membership.Dimensions {
+			dim.Value = *v
+		}
+	}
+
+	if len(upd.Dimensions) > 0 && len(membership.Dimensions) == 0 {
+		return wtf.Errorf(wtf.EINVALID, "Dial does not have any dimensions.")
+	}
+	for name, value := range upd.Dimensions {
+		dim := membership.DimensionByName(name)
+		if dim == nil {
+			return wtf.Errorf(wtf.EINVALID, "Dial does not have a %q dimension.", strings.TrimSpace(name))
+		}
+		dim.Value = value
+	}
+	return nil
+}
+
+// dialMembershipDimensionValues returns the values of a membership's dimensions.
+func dialMembershipDimensionValues(membership *wtf.DialMembership) []int {
+	values := make([]int, len(membership.Dimensions))
+	for i, dim := range membership.Dimensions {
+		values[i] = dim.Value
+	}
+	return values
+}
+
+// copyDialMembershipDimensions returns a deep copy of a list of dimension
+// values so that changes can be compared against the original.
+func copyDialMembershipDimensions(dims []*wtf.DialMembershipDimension) []*wtf.DialMembershipDimension {
+	if dims == nil {
+		return nil
+	}
+	other := make([]*wtf.DialMembershipDimension, len(dims))
+	for i, dim := range dims {
+		v := *dim
+		other[i] = &v
+	}
+	return other
+}
+
+// dialMembershipDimensionsEqual returns true if a & b hold the same values.
+func dialMembershipDimensionsEqual(a, b []*wtf.DialMembershipDimension) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].DimensionID != b[i].DimensionID || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
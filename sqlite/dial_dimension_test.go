@@ -0,0 +1,206 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_Dimensions(t *testing.T) {
+	// Ensure member values are the average of their dimensions & dimension
+	// values are the average across members.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:       "DIAL",
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "Clarity"}},
+		})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.SetDialMembershipDimensionValues(ctx0, dial.ID, map[string]int{"workload": 80}, ""); err != nil {
+			t.Fatal(err)
+		} else if err := s.SetDialMembershipDimensionValues(ctx1, dial.ID, map[string]int{"Clarity": 40}, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if other := MustFindDialMembershipByID(t, ctx0, db, 1); other.Value != 40 {
+			t.Fatalf("Value=%v, want %v", other.Value, 40)
+		} else if dim := other.DimensionByName("Workload"); dim == nil || dim.Value != 80 {
+			t.Fatalf("unexpected dimension: %#v", dim)
+		} else if dim := other.DimensionByName("Clarity"); dim == nil || dim.Value != 0 {
+			t.Fatalf("unexpected dimension: %#v", dim)
+		}
+
+		other := MustFindDialByID(t, ctx0, db, dial.ID)
+		if other.Value != 30 {
+			t.Fatalf("Value=%v, want %v", other.Value, 30)
+		} else if got, want := len(other.Dimensions), 2; got != want {
+			t.Fatalf("len(Dimensions)=%v, want %v", got, want)
+		} else if dim := other.DimensionByName("Workload"); dim.Value != 40 || dim.Position != 0 {
+			t.Fatalf("unexpected dimension: %#v", dim)
+		} else if dim := other.DimensionByName("Clarity"); dim.Value != 20 || dim.Position != 1 {
+			t.Fatalf("unexpected dimension: %#v", dim)
+		}
+	})
+
+	// Ensure setting the overall value sets every dimension.
+	t.Run("SetValue", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:       "DIAL",
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "Clarity"}},
+		})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 60)
+
+		other := MustFindDialByID(t, ctx0, db, dial.ID)
+		for _, dim := range other.Dimensions {
+			if dim.Value != 60 {
+				t.Fatalf("%s=%v, want %v", dim.Name, dim.Value, 60)
+			}
+		}
+	})
+
+	// Ensure unknown dimensions are rejected.
+	t.Run("ErrDimensionNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", Dimensions: []*wtf.DialDimension{{Name: "Workload"}}})
+
+		if err := s.SetDialMembershipDimensionValues(ctx0, dial.ID, map[string]int{"Morale": 10}, ""); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dial does not have a "Morale" dimension.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure dimension names must be unique.
+	t.Run("ErrDuplicateName", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		if err := s.CreateDial(ctx0, &wtf.Dial{
+			Name:       "DIAL",
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "workload"}},
+		}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dimension "workload" is listed more than once.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure removing a dimension recomputes member values from the rest.
+	t.Run("RemoveDimension", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:       "DIAL",
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "Clarity"}},
+		})
+		if err := s.SetDialMembershipDimensionValues(ctx0, dial.ID, map[string]int{"Workload": 80, "Clarity": 20}, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		workload := dial.DimensionByName("Workload")
+		dial, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Dimensions: []*wtf.DialDimension{{ID: workload.ID, Name: "Load"}}})
+		if err != nil {
+			t.Fatal(err)
+		} else if dial.Value != 80 {
+			t.Fatalf("Value=%v, want %v", dial.Value, 80)
+		} else if len(dial.Dimensions) != 1 || dial.Dimensions[0].Name != "Load" || dial.Dimensions[0].Value != 80 {
+			t.Fatalf("unexpected dimensions: %#v", dial.Dimensions)
+		}
+
+		if other := MustFindDialMembershipByID(t, ctx0, db, 1); other.Value != 80 {
+			t.Fatalf("Value=%v, want %v", other.Value, 80)
+		} else if other.Note != wtf.DialDimensionChangeNote {
+			t.Fatalf("Note=%q, want %q", other.Note, wtf.DialDimensionChangeNote)
+		}
+	})
+
+	// Ensure changing the scale moves dimension values onto the new scale.
+	t.Run("UpdateScale", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:       "DIAL",
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "Clarity"}},
+		})
+		if err := s.SetDialMembershipDimensionValues(ctx0, dial.ID, map[string]int{"Workload": 100, "Clarity": 0}, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Scale: &wtf.DialScale{Min: 1, Max: 5, Step: 1}}); err != nil {
+			t.Fatal(err)
+		}
+
+		other := MustFindDialMembershipByID(t, ctx0, db, 1)
+		if dim := other.DimensionByName("Workload"); dim.Value != 5 {
+			t.Fatalf("Workload=%v, want %v", dim.Value, 5)
+		} else if dim := other.DimensionByName("Clarity"); dim.Value != 1 {
+			t.Fatalf("Clarity=%v, want %v", dim.Value, 1)
+		} else if other.Value != 3 {
+			t.Fatalf("Value=%v, want %v", other.Value, 3)
+		}
+	})
+
+	// Ensure the value history of a dimension can be reported.
+	t.Run("Report", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:       "DIAL",
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}, {Name: "Clarity"}},
+		})
+
+		db.Now = func() time.Time { return start.Add(90 * time.Minute) }
+		if err := s.SetDialMembershipDimensionValues(ctx0, dial.ID, map[string]int{"Workload": 80}, ""); err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := s.DialDimensionValueReport(ctx0, dial.ID, dial.DimensionByName("Workload").ID, start, start.Add(3*time.Hour), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(report.Records), 3; got != want {
+			t.Fatalf("len(Records)=%v, want %v", got, want)
+		} else if got, want := report.Records[0].Value, 0; got != want {
+			t.Fatalf("Records[0].Value=%v, want %v", got, want)
+		} else if got, want := report.Records[2].Value, 80; got != want {
+			t.Fatalf("Records[2].Value=%v, want %v", got, want)
+		}
+
+		if _, err := s.DialDimensionValueReport(ctx0, dial.ID, 100, start, start.Add(3*time.Hour), time.Hour); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatal(err)
+		}
+	})
+}
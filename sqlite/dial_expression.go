@@ -0,0 +1,229 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// saveDialExpressionInputs replaces the list of dials referenced by a dial's
+// expression. Each input must be visible to the current user & to every
+// active member so that a computed dial cannot be used to read a dial that
+// someone who can view it cannot. Inputs must also not depend on the dial
+// itself, which would create a cycle.
+func saveDialExpressionInputs(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
+	var inputs []int
+	if dial.IsComputed() {
+		expr, err := wtf.ParseDialExpression(dial.Expression)
+		if err != nil {
+			return err
+		}
+		inputs = expr.DialIDs()
+	}
+
+	for _, inputID := range inputs {
+		if inputID == dial.ID {
+			return wtf.Errorf(wtf.EINVALID, "Expression cannot reference its own dial.")
+		} else if _, err := findDialByID(ctx, tx, inputID); wtf.ErrorCode(err) == wtf.ENOTFOUND {
+			return wtf.Errorf(wtf.EINVALID, "Expression references dial(%d) which does not exist or you cannot view.", inputID)
+		} else if err != nil {
+			return err
+		} else if n, err := countDialMembersWithoutAccess(ctx, tx, dial.ID, inputID); err != nil {
+			return err
+		} else if n > 0 {
+			return wtf.Errorf(wtf.EINVALID, "Expression references dial(%d) which not every member of this dial can view.", inputID)
+		} else if ok, err := dialDependsOn(ctx, tx, inputID, dial.ID); err != nil {
+			return err
+		} else if ok {
+			return wtf.Errorf(wtf.EINVALID, "Expression cannot reference dial(%d) because it depends on this dial.", inputID)
+		}
+	}
+
+	// Replace existing inputs.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_expression_inputs WHERE dial_id = ?`, dial.ID); err != nil {
+		return FormatError(err)
+	}
+	for _, inputID := range inputs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO dial_expression_inputs (dial_id, input_dial_id)
+			VALUES (?, ?)
+		`,
+			dial.ID, inputID,
+		); err != nil {
+			return FormatError(err)
+		}
+	}
+	return nil
+}
+
+// evaluateDialExpression computes the value of a dial from its expression.
+// Returns false if the dial is not a computed dial.
+//
+// Inputs are read with the permissions of the dial owner & are skipped if any
+// active member cannot view them so the dial never reflects a dial that one
+// of its viewers cannot see. If an input is missing or the expression cannot
+// be evaluated then the current value is kept.
+func evaluateDialExpression(ctx context.Context, tx *Tx, id int) (value int, ok bool, err error) {
+	var userID int
+	var text string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT user_id, value, expression
+		FROM dials
+		WHERE id = ?
+	`,
+		id,
+	).Scan(&userID, &value, &text); err != nil {
+		return 0, false, FormatError(err)
+	} else if text == "" {
+		return 0, false, nil
+	}
+
+	expr, err := wtf.ParseDialExpression(text)
+	if err != nil {
+		return value, true, nil
+	}
+	scale, err := findDialScale(ctx, tx, id)
+	if err != nil {
+		return 0, false, err
+	}
+
+	ownerCtx := wtf.NewContextWithUser(ctx, &wtf.User{ID: userID})
+	values := make(map[int]float64)
+	for _, inputID := range expr.DialIDs() {
+		input, err := findDialByID(ownerCtx, tx, inputID)
+		if wtf.ErrorCode(err) == wtf.ENOTFOUND {
+			continue
+		} else if err != nil {
+			return 0, false, err
+		}
+
+		if n, err := countDialMembersWithoutAccess(ctx, tx, id, inputID); err != nil {
+			return 0, false, err
+		} else if n > 0 {
+			continue
+		}
+		values[inputID] = float64(input.Value)
+	}
+
+	v, err := expr.Eval(values)
+	if wtf.ErrorCode(err) == wtf.EINVALID {
+		return value, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	// Limit the result to the dial's scale before rounding onto a step.
+	v = math.Max(float64(scale.Min), math.Min(float64(scale.Max), v))
+	return scale.Nearest(int(math.Round(v))), true, nil
+}
+
+// countDialMembersWithoutAccess returns the number of active members of a dial
+// who cannot view another dial. A dial is visible to its owner & its active
+// members.
+func countDialMembersWithoutAccess(ctx context.Context, tx *Tx, dialID, otherID int) (int, error) {
+	var n int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM dial_memberships
+		WHERE dial_id = ?1
+		  AND status = ?3
+		  AND user_id NOT IN (SELECT user_id FROM dials WHERE id = ?2)
+		  AND user_id NOT IN (SELECT user_id FROM dial_memberships WHERE dial_id = ?2 AND status = ?3)
+	`,
+		dialID, otherID, wtf.DialMembershipStatusActive,
+	).Scan(&n); err != nil {
+		return 0, FormatError(err)
+	}
+	return n, nil
+}
+
+// checkDialExpressionInputsVisible returns EUNAUTHORIZED if a user cannot view
+// every input of a computed dial. Users must be able to view all inputs before
+// they can become an active member of the dial.
+func checkDialExpressionInputsVisible(ctx context.Context, tx *Tx, dialID, userID int) error {
+	ids, err := findDialIDs(ctx, tx, `
+		SELECT i.input_dial_id
+		FROM dial_expression_inputs i
+		INNER JOIN dials d ON d.id = i.input_dial_id
+		WHERE i.dial_id = ?1
+		  AND d.user_id <> ?2
+		  AND i.input_dial_id NOT IN (SELECT dial_id FROM dial_memberships WHERE user_id = ?2 AND status = ?3)
+		ORDER BY i.input_dial_id
+	`, dialID, userID, wtf.DialMembershipStatusActive)
+	if err != nil {
+		return err
+	} else if len(ids) > 0 {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Members of this dial must be able to view dial(%d) which it is computed from.", ids[0])
+	}
+	return nil
+}
+
+// refreshDependentDials recomputes each computed dial that references a dial.
+func refreshDependentDials(ctx context.Context, tx *Tx, id int) error {
+	ids, err := findDialIDs(ctx, tx, `
+		SELECT dial_id
+		FROM dial_expression_inputs
+		WHERE input_dial_id = ?
+		ORDER BY dial_id
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	for _, dependentID := range ids {
+		if err := refreshDialValue(ctx, tx, dependentID); err != nil {
+			return fmt.Errorf("refresh dependent dial value: id=%d err=%w", dependentID, err)
+		}
+	}
+	return nil
+}
+
+// dialDependsOn returns true if the value of a dial is computed, directly or
+// indirectly, from the target dial through child dials or expression inputs.
+func dialDependsOn(ctx context.Context, tx *Tx, id, target int) (bool, error) {
+	seen := map[int]bool{id: true}
+	for queue := []int{id}; len(queue) > 0; queue = queue[1:] {
+		deps, err := findDialIDs(ctx, tx, `
+			SELECT child_dial_id FROM dial_children WHERE parent_dial_id = ?1
+			UNION
+			SELECT input_dial_id FROM dial_expression_inputs WHERE dial_id = ?1
+		`, queue[0])
+		if err != nil {
+			return false, err
+		}
+
+		for _, dep := range deps {
+			if dep == target {
+				return true, nil
+			} else if !seen[dep] {
+				seen[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return false, nil
+}
+
+// findDialIDs returns the dial IDs returned by a single column query.
+func findDialIDs(ctx context.Context, tx *Tx, query string, args ...interface{}) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
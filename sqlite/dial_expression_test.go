@@ -0,0 +1,223 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_Expression(t *testing.T) {
+	// Ensure a computed dial is evaluated on creation & recomputed whenever
+	// one of its inputs changes.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 40)
+		MustSetDialMembershipValue(t, ctx0, db, 2, 90)
+
+		now = now.Add(time.Hour)
+		computed := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "C", Expression: " max(dial(1), dial(2)) * 0.8 + 10 "})
+		if other := MustFindDialByID(t, ctx0, db, computed.ID); other.Value != 82 {
+			t.Fatalf("Value=%v, want %v", other.Value, 82)
+		} else if other.Expression != "max(dial(1), dial(2)) * 0.8 + 10" {
+			t.Fatalf("Expression=%q", other.Expression)
+		}
+
+		// Changing an input should recompute the dial & record its history.
+		now = now.Add(time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 2, 50)
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 50; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+		if values, err := s.DialValues(ctx0, computed.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := values, []int{82, 50}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("DialValues=%v, want %v", got, want)
+		}
+
+		// Computed values are limited to the dial's scale.
+		expr := "dial(1) * 10"
+		if other, err := s.UpdateDial(ctx0, computed.ID, wtf.DialUpdate{Expression: &expr}); err != nil {
+			t.Fatal(err)
+		} else if other.Value != 100 {
+			t.Fatalf("Value=%v, want %v", other.Value, 100)
+		}
+
+		// Removing the expression returns the dial to its member average.
+		expr = ""
+		if other, err := s.UpdateDial(ctx0, computed.ID, wtf.DialUpdate{Expression: &expr}); err != nil {
+			t.Fatal(err)
+		} else if other.Value != 0 {
+			t.Fatalf("Value=%v, want %v", other.Value, 0)
+		}
+		MustSetDialMembershipValue(t, ctx0, db, 1, 10)
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure changes propagate through chains of computed dials.
+	t.Run("Chained", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		computed0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B", Expression: "dial(1) / 2"})
+		computed1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "C", Expression: "dial(2) + 5"})
+
+		MustSetDialMembershipValue(t, ctx0, db, 1, 60)
+		if got, want := MustFindDialByID(t, ctx0, db, computed0.ID).Value, 30; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if got, want := MustFindDialByID(t, ctx0, db, computed1.ID).Value, 35; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the value is kept when the expression cannot be evaluated.
+	t.Run("EvalError", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 50)
+		computed := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B", Expression: "100 / (dial(1) - 20)"})
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 3; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 3; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure inputs are only read while the owner can view them.
+	t.Run("OwnerCannotView", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		private := MustCreateDial(t, ctx1, db, &wtf.Dial{Name: "PRIVATE"})
+		MustSetDialMembershipValue(t, ctx1, db, 1, 70)
+
+		if err := s.CreateDial(ctx0, &wtf.Dial{Name: "LEAK", Expression: "dial(1)"}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression references dial(1) which does not exist or you cannot view.` {
+			t.Fatal(err)
+		}
+
+		// Once a member, the owner can reference the dial.
+		membership := MustCreateDialMembership(t, ctx0, db, &wtf.DialMembership{DialID: private.ID, Value: 70})
+		computed := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "C", Expression: "dial(1)"})
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 70; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// After leaving, changes to the input no longer reach the computed dial.
+		if err := sqlite.NewDialMembershipService(db).DeleteDialMembership(ctx0, membership.ID); err != nil {
+			t.Fatal(err)
+		}
+		MustSetDialMembershipValue(t, ctx1, db, 1, 10)
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 70; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a computed dial cannot expose an input to members who cannot
+	// view it.
+	t.Run("MemberCannotView", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		private := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "PRIVATE"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 70)
+		shared := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "SHARED"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: shared.ID})
+
+		// The private dial cannot be referenced while a member cannot view it.
+		expr := "dial(1)"
+		if _, err := s.UpdateDial(ctx0, shared.ID, wtf.DialUpdate{Expression: &expr}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression references dial(1) which not every member of this dial can view.` {
+			t.Fatal(err)
+		}
+
+		// Users cannot join a computed dial until they can view its inputs.
+		computed := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "C", Expression: "dial(1)"})
+		if err := sqlite.NewDialMembershipService(db).CreateDialMembership(ctx1, &wtf.DialMembership{DialID: computed.ID}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Members of this dial must be able to view dial(1) which it is computed from.` {
+			t.Fatal(err)
+		}
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: private.ID, Value: 70})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: computed.ID})
+
+		// Once a member loses access, changes to the input no longer reach
+		// the computed dial.
+		if err := sqlite.NewDialMembershipService(db).DeleteDialMembership(ctx1, membership.ID); err != nil {
+			t.Fatal(err)
+		}
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+		if got, want := MustFindDialByID(t, ctx0, db, computed.ID).Value, 70; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure expressions cannot create dependency cycles.
+	t.Run("ErrCycle", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "A"})
+		computed := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "B", Expression: "dial(1)"})
+
+		expr := "dial(1) + 1"
+		if _, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Expression: &expr}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression cannot reference its own dial.` {
+			t.Fatal(err)
+		}
+
+		expr = "dial(2)"
+		if _, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Expression: &expr}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Expression cannot reference dial(2) because it depends on this dial.` {
+			t.Fatal(err)
+		}
+
+		// Cycles through child dials are also rejected.
+		if err := sqlite.NewDialChildService(db).CreateDialChild(ctx0, &wtf.DialChild{ParentDialID: dial.ID, ChildDialID: computed.ID}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dial cannot be attached to a dial that it depends on.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure malformed expressions are rejected.
+	t.Run("ErrSyntax", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		if err := s.CreateDial(ctx0, &wtf.Dial{Name: "A", Expression: "dial(1) +"}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Unexpected end of expression.` {
+			t.Fatal(err)
+		}
+	})
+}
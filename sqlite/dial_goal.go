@@ -0,0 +1,663 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.DialGoalService = (*DialGoalService)(nil)
+
+// DialGoalService represents a service for managing dial goals.
+type DialGoalService struct {
+	db *DB
+}
+
+// NewDialGoalService returns a new instance of DialGoalService.
+func NewDialGoalService(db *DB) *DialGoalService {
+	return &DialGoalService{db: db}
+}
+
+// FindDialGoalByID retrieves a single goal by ID along with its current
+// status. Returns ENOTFOUND if the goal does not exist or the user is not a
+// member of the goal's dial.
+func (s *DialGoalService) FindDialGoalByID(ctx context.Context, id int) (*wtf.DialGoal, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	goal, err := findDialGoalByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if err := attachDialGoalAssociations(ctx, tx, goal); err != nil {
+		return nil, err
+	}
+	return goal, nil
+}
+
+// FindDialGoals retrieves a list of goals based on a filter along with their
+// current status. Only returns goals for dials that the user is a member of.
+func (s *DialGoalService) FindDialGoals(ctx context.Context, filter wtf.DialGoalFilter) ([]*wtf.DialGoal, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	goals, n, err := findDialGoals(ctx, tx, filter)
+	if err != nil {
+		return goals, n, err
+	}
+	for _, goal := range goals {
+		if err := attachDialGoalAssociations(ctx, tx, goal); err != nil {
+			return goals, n, err
+		}
+	}
+	return goals, n, nil
+}
+
+// CreateDialGoal creates a new goal on a dial. Only the dial owner can create
+// goals. The goal is evaluated immediately.
+func (s *DialGoalService) CreateDialGoal(ctx context.Context, goal *wtf.DialGoal) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createDialGoal(ctx, tx, goal); err != nil {
+		return err
+	} else if err := attachDialGoalAssociations(ctx, tx, goal); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateDialGoal updates an existing goal. Only the dial owner can update
+// goals. The goal's exhaustion state is reset & it is re-evaluated.
+func (s *DialGoalService) UpdateDialGoal(ctx context.Context, id int, upd wtf.DialGoalUpdate) (*wtf.DialGoal, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	goal, err := updateDialGoal(ctx, tx, id, upd)
+	if err != nil {
+		return goal, err
+	} else if err := attachDialGoalAssociations(ctx, tx, goal); err != nil {
+		return goal, err
+	} else if err := tx.Commit(); err != nil {
+		return goal, err
+	}
+	return goal, nil
+}
+
+// DeleteDialGoal permanently deletes a goal. Only the dial owner can delete
+// goals.
+func (s *DialGoalService) DeleteDialGoal(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteDialGoal(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DialGoalReport returns the compliance of a goal over its most recent
+// windows, oldest first. The last window is the current one.
+func (s *DialGoalService) DialGoalReport(ctx context.Context, id, windows int) ([]*wtf.DialGoalStatus, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if windows < 1 || windows > wtf.MaxDialGoalReportWindows {
+		return nil, wtf.Errorf(wtf.EINVALID, "Report must include between 1 & %d windows.", wtf.MaxDialGoalReportWindows)
+	}
+
+	goal, err := findDialGoalByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Raw values older than the retention period have been pruned so past
+	// windows starting before then would be inaccurate.
+	cutoff, err := findDialValueRetentionCutoff(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk backward from the current window.
+	report := make([]*wtf.DialGoalStatus, 0, windows)
+	start, end := goal.WindowAt(tx.now)
+	for i := 0; i < windows; i++ {
+		if i > 0 && start.Before(cutoff) {
+			break
+		}
+
+		status, err := computeDialGoalStatus(ctx, tx, goal, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("compute status: window=%s err=%w", start.Format(time.RFC3339), err)
+		} else if status == nil {
+			break // before dial was created
+		}
+		report = append([]*wtf.DialGoalStatus{status}, report...)
+
+		start, end = goal.WindowAt(start.Add(-time.Nanosecond))
+	}
+	return report, nil
+}
+
+// EvaluateDialGoals evaluates the goals on every dial & notifies members of
+// goals whose error budget has been exhausted in the current window. This is
+// called periodically by the background monitor but can also be called
+// directly, such as from tests.
+func (db *DB) EvaluateDialGoals(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Archived dials are frozen so their goals are not evaluated.
+	goals, _, err := queryDialGoals(ctx, tx, []string{"g.dial_id IN (SELECT id FROM dials WHERE archived_at IS NULL)"}, nil, "")
+	if err != nil {
+		return err
+	}
+
+	for _, goal := range goals {
+		if err := evaluateDialGoal(ctx, tx, goal); err != nil {
+			return fmt.Errorf("evaluate dial goal: id=%d err=%w", goal.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// findDialGoalByID is a helper function to retrieve a goal by ID.
+// Returns ENOTFOUND if goal doesn't exist.
+func findDialGoalByID(ctx context.Context, tx *Tx, id int) (*wtf.DialGoal, error) {
+	goals, _, err := findDialGoals(ctx, tx, wtf.DialGoalFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(goals) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial goal not found."}
+	}
+	return goals[0], nil
+}
+
+// findDialGoals retrieves a list of matching goals. Also returns a total
+// matching count which may differ from the number of results if filter.Limit
+// is set.
+func findDialGoals(ctx context.Context, tx *Tx, filter wtf.DialGoalFilter) (_ []*wtf.DialGoal, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "g.id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "g.dial_id = ?"), append(args, *v)
+	}
+
+	// Limit to goals on dials the user is a member of.
+	where = append(where, `g.dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)`)
+	args = append(args, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive)
+
+	return queryDialGoals(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// queryDialGoals executes a query for goals with the given WHERE clause
+// parts. This performs no permission checks so callers must add them.
+func queryDialGoals(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.DialGoal, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    g.id,
+		    g.dial_id,
+		    g.name,
+		    g.operator,
+		    g.threshold,
+		    g.target,
+		    g.time_window,
+		    g.weekdays,
+		    g.start_hour,
+		    g.end_hour,
+		    g.timezone,
+		    g.budget_exhausted_at,
+		    g.created_at,
+		    g.updated_at,
+		    COUNT(*) OVER()
+		FROM dial_goals g
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY g.id ASC
+		`+limitOffset,
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialGoal objects.
+	goals := make([]*wtf.DialGoal, 0)
+	for rows.Next() {
+		var goal wtf.DialGoal
+		var weekdays string
+		if err := rows.Scan(
+			&goal.ID,
+			&goal.DialID,
+			&goal.Name,
+			&goal.Operator,
+			&goal.Threshold,
+			&goal.Target,
+			&goal.Window,
+			&weekdays,
+			&goal.StartHour,
+			&goal.EndHour,
+			&goal.Timezone,
+			(*NullTime)(&goal.BudgetExhaustedAt),
+			(*NullTime)(&goal.CreatedAt),
+			(*NullTime)(&goal.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if goal.Weekdays, err = parseWeekdays(weekdays); err != nil {
+			return nil, 0, fmt.Errorf("parse weekdays: id=%d err=%w", goal.ID, err)
+		}
+		goals = append(goals, &goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return goals, n, nil
+}
+
+// createDialGoal creates a new goal on a dial & evaluates it.
+// Returns EUNAUTHORIZED if the current user is not the dial owner.
+func createDialGoal(ctx context.Context, tx *Tx, goal *wtf.DialGoal) error {
+	goal.Weekdays = normalizeWeekdays(goal.Weekdays)
+	goal.BudgetExhaustedAt = time.Time{}
+	goal.CreatedAt = tx.now
+	goal.UpdatedAt = goal.CreatedAt
+
+	// Perform basic field validation.
+	if err := goal.Validate(); err != nil {
+		return err
+	}
+
+	// Only the dial owner can create goals.
+	dial, err := findDialByID(ctx, tx, goal.DialID)
+	if err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can create goals.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return err
+	} else if err := validateDialGoalThreshold(goal, dial); err != nil {
+		return err
+	} else if err := validateDialGoalWindow(tx, goal); err != nil {
+		return err
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_goals (
+			dial_id,
+			name,
+			operator,
+			threshold,
+			target,
+			time_window,
+			weekdays,
+			start_hour,
+			end_hour,
+			timezone,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		goal.DialID,
+		goal.Name,
+		goal.Operator,
+		goal.Threshold,
+		goal.Target,
+		goal.Window,
+		formatWeekdays(goal.Weekdays),
+		goal.StartHour,
+		goal.EndHour,
+		goal.Timezone,
+		(*NullTime)(&goal.CreatedAt),
+		(*NullTime)(&goal.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new goal ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	goal.ID = int(id)
+
+	// Record the new goal in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialGoalCreate,
+		TargetType: wtf.AuditTargetDialGoal,
+		TargetID:   goal.ID,
+		DialID:     goal.DialID,
+	}, nil, goal); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Evaluate the new goal against the dial's value history.
+	if err := evaluateDialGoal(ctx, tx, goal); err != nil {
+		return fmt.Errorf("evaluate dial goal: %w", err)
+	}
+	return nil
+}
+
+// updateDialGoal updates fields on a goal by ID, resets its exhaustion state
+// & re-evaluates it. Returns EUNAUTHORIZED if the current user is not the dial
+// owner.
+func updateDialGoal(ctx context.Context, tx *Tx, id int, upd wtf.DialGoalUpdate) (*wtf.DialGoal, error) {
+	// Fetch current object state & verify the current user owns the dial.
+	goal, err := findDialGoalByID(ctx, tx, id)
+	if err != nil {
+		return goal, err
+	}
+	dial, err := findDialByID(ctx, tx, goal.DialID)
+	if err != nil {
+		return goal, err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return goal, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can update goals.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return goal, err
+	}
+
+	// Save state of goal for the audit log.
+	prev := *goal
+
+	// Update fields, if set.
+	if v := upd.Name; v != nil {
+		goal.Name = *v
+	}
+	if v := upd.Operator; v != nil {
+		goal.Operator = *v
+	}
+	if v := upd.Threshold; v != nil {
+		goal.Threshold = *v
+	}
+	if v := upd.Target; v != nil {
+		goal.Target = *v
+	}
+	if v := upd.Window; v != nil {
+		goal.Window = *v
+	}
+	if v := upd.Weekdays; v != nil {
+		goal.Weekdays = normalizeWeekdays(*v)
+	}
+	if v := upd.StartHour; v != nil {
+		goal.StartHour = *v
+	}
+	if v := upd.EndHour; v != nil {
+		goal.EndHour = *v
+	}
+	if v := upd.Timezone; v != nil {
+		goal.Timezone = *v
+	}
+	goal.BudgetExhaustedAt = time.Time{}
+	goal.UpdatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := goal.Validate(); err != nil {
+		return goal, err
+	} else if err := validateDialGoalThreshold(goal, dial); err != nil {
+		return goal, err
+	} else if err := validateDialGoalWindow(tx, goal); err != nil {
+		return goal, err
+	}
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_goals
+		SET name = ?,
+		    operator = ?,
+		    threshold = ?,
+		    target = ?,
+		    time_window = ?,
+		    weekdays = ?,
+		    start_hour = ?,
+		    end_hour = ?,
+		    timezone = ?,
+		    budget_exhausted_at = NULL,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		goal.Name,
+		goal.Operator,
+		goal.Threshold,
+		goal.Target,
+		goal.Window,
+		formatWeekdays(goal.Weekdays),
+		goal.StartHour,
+		goal.EndHour,
+		goal.Timezone,
+		(*NullTime)(&goal.UpdatedAt),
+		id,
+	); err != nil {
+		return goal, FormatError(err)
+	}
+
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialGoalUpdate,
+		TargetType: wtf.AuditTargetDialGoal,
+		TargetID:   goal.ID,
+		DialID:     goal.DialID,
+	}, &prev, goal); err != nil {
+		return goal, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Re-evaluate the goal with its new condition.
+	if err := evaluateDialGoal(ctx, tx, goal); err != nil {
+		return goal, fmt.Errorf("evaluate dial goal: %w", err)
+	}
+	return goal, nil
+}
+
+// validateDialGoalThreshold returns an error if the goal's threshold is
+// outside of the dial's scale.
+func validateDialGoalThreshold(goal *wtf.DialGoal, dial *wtf.Dial) error {
+	if goal.Threshold < dial.Scale.Min || goal.Threshold > dial.Scale.Max {
+		return wtf.Errorf(wtf.EINVALID, "Goal threshold must be between %d & %d.", dial.Scale.Min, dial.Scale.Max)
+	}
+	return nil
+}
+
+// validateDialGoalWindow returns an error if the goal's window is longer than
+// the dial value retention period. Goals are tracked from raw values so the
+// current window must always start within the retention period.
+func validateDialGoalWindow(tx *Tx, goal *wtf.DialGoal) error {
+	if retention := tx.db.DialValueRetention; retention > 0 && goal.MaxWindowDuration() > retention {
+		return wtf.Errorf(wtf.EINVALID, "Goal window cannot be longer than the %s dial value retention period.", retention)
+	}
+	return nil
+}
+
+// deleteDialGoal permanently removes a goal by ID. Returns EUNAUTHORIZED if
+// the current user is not the dial owner.
+func deleteDialGoal(ctx context.Context, tx *Tx, id int) error {
+	// Verify goal exists & the current user owns the dial.
+	goal, err := findDialGoalByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if dial, err := findDialByID(ctx, tx, goal.DialID); err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can delete goals.")
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_goals WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the deleted goal in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialGoalDelete,
+		TargetType: wtf.AuditTargetDialGoal,
+		TargetID:   goal.ID,
+		DialID:     goal.DialID,
+	}, goal, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// attachDialGoalAssociations computes & attaches the goal's status in the
+// current window.
+func attachDialGoalAssociations(ctx context.Context, tx *Tx, goal *wtf.DialGoal) (err error) {
+	start, end := goal.WindowAt(tx.now)
+	if goal.Status, err = computeDialGoalStatus(ctx, tx, goal, start, end); err != nil {
+		return fmt.Errorf("attach dial goal status: %w", err)
+	}
+	return nil
+}
+
+// evaluateDialGoal computes the goal's status in the current window & notifies
+// the dial's active members the first time its error budget is exhausted in
+// the window. This bypasses permission checks as it is run by the background
+// monitor or on behalf of the dial owner.
+func evaluateDialGoal(ctx context.Context, tx *Tx, goal *wtf.DialGoal) error {
+	start, end := goal.WindowAt(tx.now)
+	status, err := computeDialGoalStatus(ctx, tx, goal, start, end)
+	if wtf.ErrorCode(err) == wtf.EINVALID {
+		return nil // window is longer than the retention period
+	} else if err != nil {
+		return err
+	} else if status == nil || !status.IsExhausted() || !goal.BudgetExhaustedAt.Before(status.WindowStart) {
+		return nil
+	}
+
+	goal.BudgetExhaustedAt = tx.now
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_goals
+		SET budget_exhausted_at = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&goal.BudgetExhaustedAt),
+		goal.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+
+	var dialName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM dials WHERE id = ?`, goal.DialID).Scan(&dialName); err != nil {
+		return FormatError(err)
+	}
+
+	if err := publishDialEvent(ctx, tx, goal.DialID, wtf.Event{
+		Type: wtf.EventTypeDialGoalBudgetExhausted,
+		Payload: &wtf.DialGoalBudgetExhaustedPayload{
+			ID:          goal.ID,
+			Name:        goal.Name,
+			DialID:      goal.DialID,
+			DialName:    dialName,
+			WindowStart: status.WindowStart,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+	return nil
+}
+
+// computeDialGoalStatus computes the compliance of a goal during the window
+// from start to end using the dial's value history. Only working time after
+// the dial was created counts & archived dials stop counting once archived.
+// Returns nil if the window ended before the dial was created.
+func computeDialGoalStatus(ctx context.Context, tx *Tx, goal *wtf.DialGoal, start, end time.Time) (*wtf.DialGoalStatus, error) {
+	var createdAt, archivedAt time.Time
+	if err := tx.QueryRowContext(ctx, `
+		SELECT created_at, archived_at
+		FROM dials
+		WHERE id = ?
+	`,
+		goal.DialID,
+	).Scan((*NullTime)(&createdAt), (*NullTime)(&archivedAt)); err != nil {
+		return nil, FormatError(err)
+	} else if !end.After(createdAt) {
+		return nil, nil
+	}
+
+	status := &wtf.DialGoalStatus{WindowStart: start, WindowEnd: end}
+
+	// Working time before the dial existed does not count toward the goal.
+	from := start
+	if createdAt.After(from) {
+		from = createdAt
+	}
+	total := goal.WorkingDuration(from, end)
+	status.TotalMinutes = int(total / time.Minute)
+	status.BudgetMinutes = int(float64(status.TotalMinutes) * (100 - goal.Target) / 100)
+
+	// Only time up to now, or until the dial was archived, has elapsed.
+	until := end
+	if tx.now.Before(until) {
+		until = tx.now
+	}
+	if !archivedAt.IsZero() && archivedAt.Before(until) {
+		until = archivedAt
+	}
+	if !until.After(from) {
+		return status, nil
+	}
+
+	// Raw values before the retention cutoff may have been pruned.
+	if cutoff, err := findDialValueRetentionCutoff(ctx, tx); err != nil {
+		return nil, err
+	} else if from.Before(cutoff) {
+		return nil, wtf.Errorf(wtf.EINVALID, "Goal window must start within the last %s while dial values are retained.", tx.db.DialValueRetention)
+	}
+
+	initial, changes, err := findDialValueChangesBetween(ctx, tx, goal.DialID, from, until)
+	if err != nil {
+		return nil, fmt.Errorf("dial value changes between: %w", err)
+	}
+
+	// Sum the working time of each span of time the value was constant.
+	var elapsed, missed time.Duration
+	value, t := initial, from
+	observe := func(next time.Time) {
+		d := goal.WorkingDuration(t, next)
+		elapsed += d
+		if !goal.Compare(value) {
+			missed += d
+		}
+		t = next
+	}
+	for _, change := range changes {
+		observe(change.Timestamp)
+		value = change.Value
+	}
+	observe(until)
+
+	status.ElapsedMinutes = int(elapsed / time.Minute)
+	status.MissedMinutes = int(missed / time.Minute)
+	return status, nil
+}
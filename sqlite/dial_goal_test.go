@@ -0,0 +1,316 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialGoalService_CreateDialGoal(t *testing.T) {
+	// Ensure a goal can be created & its status is computed.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		now := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC) // Monday
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now = now.Add(10 * time.Hour)
+		goal := &wtf.DialGoal{
+			DialID:    dial.ID,
+			Name:      "Calm",
+			Operator:  wtf.DialGoalOperatorLT,
+			Threshold: 50,
+			Target:    90,
+			Window:    wtf.DialGoalWindowWeek,
+			Weekdays:  []time.Weekday{time.Friday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Monday},
+			StartHour: 9,
+			EndHour:   17,
+			Timezone:  "UTC",
+		}
+		if err := s.CreateDialGoal(ctx0, goal); err != nil {
+			t.Fatal(err)
+		} else if got, want := goal.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := goal.Weekdays, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Weekdays=%v, want %v", got, want)
+		} else if got, want := goal.Condition(), "value < 50 for 90% of working hours each week"; got != want {
+			t.Fatalf("Condition()=%q, want %q", got, want)
+		} else if got, want := goal.WorkingHours(), "Weekdays 09:00-17:00 UTC"; got != want {
+			t.Fatalf("WorkingHours()=%q, want %q", got, want)
+		}
+
+		// Status covers the working hours of the current week so far.
+		if got, want := goal.Status, (&wtf.DialGoalStatus{
+			WindowStart:    time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC),
+			WindowEnd:      time.Date(2000, time.January, 10, 0, 0, 0, 0, time.UTC),
+			TotalMinutes:   2400,
+			ElapsedMinutes: 60,
+			MissedMinutes:  0,
+			BudgetMinutes:  240,
+		}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Status=%#v, want %#v", got, want)
+		} else if got, want := goal.Status.Compliance(), 1.0; got != want {
+			t.Fatalf("Compliance()=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure invalid goals are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		newGoal := func() *wtf.DialGoal {
+			return &wtf.DialGoal{DialID: dial.ID, Name: "GOAL", Operator: wtf.DialGoalOperatorLT, Threshold: 50, Target: 90, Window: wtf.DialGoalWindowMonth, Weekdays: []time.Weekday{time.Monday}, StartHour: 9, EndHour: 17, Timezone: "UTC"}
+		}
+		for _, tt := range []struct {
+			fn  func(*wtf.DialGoal)
+			msg string
+		}{
+			{func(g *wtf.DialGoal) { g.Name = "" }, `Goal name required.`},
+			{func(g *wtf.DialGoal) { g.Operator = "!=" }, `Invalid goal operator.`},
+			{func(g *wtf.DialGoal) { g.Target = 0 }, `Goal target must be above 0% & at most 100%.`},
+			{func(g *wtf.DialGoal) { g.Window = "year" }, `Invalid goal window.`},
+			{func(g *wtf.DialGoal) { g.Weekdays = nil }, `At least one working day required.`},
+			{func(g *wtf.DialGoal) { g.StartHour, g.EndHour = 17, 9 }, `Working hours must end after they start.`},
+			{func(g *wtf.DialGoal) { g.Timezone = "Mars/Olympus" }, `Unknown time zone.`},
+			{func(g *wtf.DialGoal) { g.Threshold = 101 }, `Goal threshold must be between 0 & 100.`},
+		} {
+			goal := newGoal()
+			tt.fn(goal)
+			if err := s.CreateDialGoal(ctx0, goal); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+
+		// Windows must fit within the raw value retention period.
+		db.DialValueRetention = 7 * 24 * time.Hour
+		if err := s.CreateDialGoal(ctx0, newGoal()); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Goal window cannot be longer than the 168h0m0s dial value retention period.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure only the owner can create a goal.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.CreateDialGoal(ctx1, &wtf.DialGoal{DialID: dial.ID, Name: "GOAL", Operator: wtf.DialGoalOperatorLT, Threshold: 50, Target: 90, Window: wtf.DialGoalWindowMonth, Weekdays: []time.Weekday{time.Monday}, StartHour: 9, EndHour: 17, Timezone: "UTC"}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can create goals.` {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDB_EvaluateDialGoals(t *testing.T) {
+	// Ensure members are notified once per window when the budget is exhausted.
+	t.Run("BudgetExhausted", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		start := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC) // Monday
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		goal := &wtf.DialGoal{DialID: dial.ID, Name: "Calm", Operator: wtf.DialGoalOperatorLT, Threshold: 50, Target: 90, Window: wtf.DialGoalWindowWeek, Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, StartHour: 9, EndHour: 17, Timezone: "UTC"}
+		if err := s.CreateDialGoal(ctx0, goal); err != nil {
+			t.Fatal(err)
+		}
+
+		// Track events sent to the other member.
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if userID == 2 && event.Type == wtf.EventTypeDialGoalBudgetExhausted {
+					events = append(events, event)
+				}
+			},
+		}
+
+		// The dial rises to 50 at 08:00, before working hours start.
+		now = start.Add(8 * time.Hour)
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+
+		// Four hours of the 240 minute budget have been used by 13:00.
+		now = start.Add(13 * time.Hour)
+		if err := db.EvaluateDialGoals(ctx); err != nil {
+			t.Fatal(err)
+		} else if len(events) != 0 {
+			t.Fatalf("unexpected events: %#v", events)
+		} else if other, err := s.FindDialGoalByID(ctx1, goal.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Status.MissedMinutes != 240 || other.Status.RemainingBudgetMinutes() != 0 || other.Status.IsExhausted() {
+			t.Fatalf("unexpected status: %#v", other.Status)
+		}
+
+		// The budget is exhausted a minute later. Evaluate twice.
+		now = start.Add(13*time.Hour + time.Minute)
+		for i := 0; i < 2; i++ {
+			if err := db.EvaluateDialGoals(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, want := len(events), 1; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		} else if got, want := events[0].Payload, (&wtf.DialGoalBudgetExhaustedPayload{ID: goal.ID, Name: "Calm", DialID: dial.ID, DialName: "DIAL", WindowStart: start}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Payload=%#v, want %#v", got, want)
+		} else if other, err := s.FindDialGoalByID(ctx0, goal.ID); err != nil {
+			t.Fatal(err)
+		} else if !other.BudgetExhaustedAt.Equal(now) {
+			t.Fatalf("BudgetExhaustedAt=%v, want %v", other.BudgetExhaustedAt, now)
+		} else if got, want := other.Status.Compliance(), 0.0; got != want {
+			t.Fatalf("Compliance()=%v, want %v", got, want)
+		}
+
+		// The budget starts over the next week & is exhausted again.
+		now = start.Add(7*24*time.Hour + 13*time.Hour + time.Minute)
+		if err := db.EvaluateDialGoals(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(events), 2; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		}
+	})
+}
+
+func TestDialGoalService_DialGoalReport(t *testing.T) {
+	// Ensure the report lists compliance for each window since the dial was created.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		start := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC) // Monday
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		goal := &wtf.DialGoal{DialID: dial.ID, Name: "Calm", Operator: wtf.DialGoalOperatorLT, Threshold: 50, Target: 50, Window: wtf.DialGoalWindowWeek, Weekdays: []time.Weekday{time.Monday}, StartHour: 0, EndHour: 24, Timezone: "UTC"}
+		if err := s.CreateDialGoal(ctx0, goal); err != nil {
+			t.Fatal(err)
+		}
+
+		// Miss the goal for six hours on the first Monday.
+		now = start.Add(6 * time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 60)
+		now = start.Add(12 * time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 10)
+
+		now = start.Add(7*24*time.Hour + 12*time.Hour)
+		report, err := s.DialGoalReport(ctx0, goal.ID, 6)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(report), 2; got != want {
+			t.Fatalf("len(report)=%v, want %v", got, want)
+		} else if got, want := report[0], (&wtf.DialGoalStatus{WindowStart: start, WindowEnd: start.AddDate(0, 0, 7), TotalMinutes: 1440, ElapsedMinutes: 1440, MissedMinutes: 360, BudgetMinutes: 720}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("report[0]=%#v, want %#v", got, want)
+		} else if got, want := report[0].Compliance(), 0.75; got != want {
+			t.Fatalf("Compliance()=%v, want %v", got, want)
+		} else if got, want := report[1], (&wtf.DialGoalStatus{WindowStart: start.AddDate(0, 0, 7), WindowEnd: start.AddDate(0, 0, 14), TotalMinutes: 1440, ElapsedMinutes: 720, MissedMinutes: 0, BudgetMinutes: 720}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("report[1]=%#v, want %#v", got, want)
+		}
+
+		if _, err := s.DialGoalReport(ctx0, goal.ID, 0); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure windows starting before raw values were pruned are left out &
+	// that later windows carry forward the value from before the cutoff.
+	t.Run("Retention", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		start := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC) // Monday
+		now := start
+		db.Now = func() time.Time { return now }
+		db.DialValueRetention = 8 * 24 * time.Hour
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		goal := &wtf.DialGoal{DialID: dial.ID, Name: "Calm", Operator: wtf.DialGoalOperatorLT, Threshold: 50, Target: 50, Window: wtf.DialGoalWindowWeek, Weekdays: []time.Weekday{time.Monday}, StartHour: 0, EndHour: 24, Timezone: "UTC"}
+		if err := s.CreateDialGoal(ctx0, goal); err != nil {
+			t.Fatal(err)
+		}
+
+		// Miss the goal from the second week onward.
+		now = start.AddDate(0, 0, 9)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 60)
+
+		// Roll up & prune values in the fourth week. Each run rolls up at most
+		// a week so run until caught up.
+		now = start.AddDate(0, 0, 21).Add(12 * time.Hour)
+		for i := 0; i < 4; i++ {
+			if err := db.UpdateDialValueRollups(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		report, err := s.DialGoalReport(ctx0, goal.ID, 6)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(report), 2; got != want {
+			t.Fatalf("len(report)=%v, want %v", got, want)
+		} else if got, want := report[0], (&wtf.DialGoalStatus{WindowStart: start.AddDate(0, 0, 14), WindowEnd: start.AddDate(0, 0, 21), TotalMinutes: 1440, ElapsedMinutes: 1440, MissedMinutes: 1440, BudgetMinutes: 720}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("report[0]=%#v, want %#v", got, want)
+		} else if got, want := report[1], (&wtf.DialGoalStatus{WindowStart: start.AddDate(0, 0, 21), WindowEnd: start.AddDate(0, 0, 28), TotalMinutes: 1440, ElapsedMinutes: 720, MissedMinutes: 720, BudgetMinutes: 720}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("report[1]=%#v, want %#v", got, want)
+		}
+	})
+}
+
+func TestDialGoalService_DeleteDialGoal(t *testing.T) {
+	// Ensure only the owner can delete a goal.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialGoalService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		goal := &wtf.DialGoal{DialID: dial.ID, Name: "GOAL", Operator: wtf.DialGoalOperatorLT, Threshold: 50, Target: 90, Window: wtf.DialGoalWindowMonth, Weekdays: []time.Weekday{time.Monday}, StartHour: 9, EndHour: 17, Timezone: "UTC"}
+		if err := s.CreateDialGoal(ctx0, goal); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.DeleteDialGoal(ctx1, goal.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can delete goals.` {
+			t.Fatal(err)
+		} else if err := s.DeleteDialGoal(ctx0, goal.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDialGoalByID(ctx0, goal.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
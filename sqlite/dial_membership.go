@@ -2,8 +2,10 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/benbjohnson/wtf"
 )
@@ -82,6 +84,9 @@ func (s *DialMembershipService) CreateDialMembership(ctx context.Context, member
 	}
 	membership.UserID = wtf.UserIDFromContext(ctx)
 
+	// Status is determined by the dial's approval settings.
+	membership.Status = ""
+
 	// Create new membership and attach associated user & dial to returned data.
 	if err := createDialMembership(ctx, tx, membership); err != nil {
 		return err
@@ -111,6 +116,92 @@ func (s *DialMembershipService) UpdateDialMembership(ctx context.Context, id int
 	return membership, tx.Commit()
 }
 
+// ApproveDialMembership approves a pending membership so that it contributes
+// to the dial value. Only the dial owner can approve a membership. Returns
+// ECONFLICT if the membership is not pending.
+func (s *DialMembershipService) ApproveDialMembership(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Approve membership and attach associated user & dial to returned data.
+	membership, err := approveDialMembership(ctx, tx, id)
+	if err != nil {
+		return membership, err
+	} else if err := attachDialMembershipAssociations(ctx, tx, membership); err != nil {
+		return membership, err
+	}
+	return membership, tx.Commit()
+}
+
+// SetDialMembershipAway marks the current user's membership in a dial as away
+// until the given time so that it is excluded from the dial value. A zero time
+// marks the member as returned. Returns ENOTFOUND if the user is not a member.
+func (s *DialMembershipService) SetDialMembershipAway(ctx context.Context, dialID int, until time.Time) (*wtf.DialMembership, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Find user's membership.
+	userID := wtf.UserIDFromContext(ctx)
+	memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{
+		DialID: &dialID,
+		UserID: &userID,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(memberships) == 0 {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "User is not a member of this dial.")
+	}
+	membership := memberships[0]
+
+	if !until.IsZero() && !until.After(tx.now) {
+		return membership, wtf.Errorf(wtf.EINVALID, "Away date must be in the future.")
+	}
+
+	// Update away status and attach associated user & dial to returned data.
+	if err := setDialMembershipAway(ctx, tx, membership, until); err != nil {
+		return membership, err
+	} else if err := attachDialMembershipAssociations(ctx, tx, membership); err != nil {
+		return membership, err
+	}
+	return membership, tx.Commit()
+}
+
+// SetAway marks all of the current user's memberships as away until the given
+// time. A zero time marks the user as returned on all their dials.
+func (s *DialMembershipService) SetAway(ctx context.Context, until time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userID := wtf.UserIDFromContext(ctx)
+	if userID == 0 {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You must be logged in to set yourself away.")
+	} else if !until.IsZero() && !until.After(tx.now) {
+		return wtf.Errorf(wtf.EINVALID, "Away date must be in the future.")
+	}
+
+	// Update every membership the user has, including pending ones, so they
+	// are still away if they are approved while gone.
+	memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{UserID: &userID})
+	if err != nil {
+		return err
+	}
+	for _, membership := range memberships {
+		if err := setDialMembershipAway(ctx, tx, membership, until); err != nil {
+			return fmt.Errorf("set membership away: id=%d err=%w", membership.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
 // DeleteDialMembership permanently deletes a membership by ID. Only the
 // membership owner and the parent dial's owner can delete a membership.
 func (s *DialMembershipService) DeleteDialMembership(ctx context.Context, id int) error {
@@ -126,6 +217,81 @@ func (s *DialMembershipService) DeleteDialMembership(ctx context.Context, id int
 	return tx.Commit()
 }
 
+// MembershipValueReport returns a report of a single member's value on a dial
+// between start & end time, slotted into given intervals. The minimum
+// interval size is one minute.
+func (s *DialMembershipService) MembershipValueReport(ctx context.Context, dialID, userID int, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Ensure the current user can view the dial.
+	dial, err := findDialByID(ctx, tx, dialID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure start/end line up with the interval unit.
+	start = start.Truncate(interval).UTC()
+	end = end.Truncate(interval).UTC()
+
+	// Fetch the member's value changes & fold them into slots.
+	initial, changes, err := findDialMembershipValueChangesBetween(ctx, tx, dialID, userID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("membership value changes between: %w", err)
+	}
+	records := buildDialValueRecords(initial, changes, start, end, interval)
+	for _, record := range records {
+		record.Band = dial.Scale.BandLabel(record.Value)
+	}
+	return &wtf.DialValueReport{Records: records}, nil
+}
+
+// MembershipValueReports returns a report for every active member of a dial,
+// keyed by user ID. Values for all members are fetched in a single query.
+func (s *DialMembershipService) MembershipValueReports(ctx context.Context, dialID int, start, end time.Time, interval time.Duration) (map[int]*wtf.DialValueReport, error) {
+	if err := wtf.ValidateDialValueReportRange(start, end, interval); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Ensure the current user can view the dial.
+	dial, err := findDialByID(ctx, tx, dialID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure start/end line up with the interval unit.
+	start = start.Truncate(interval).UTC()
+	end = end.Truncate(interval).UTC()
+
+	// Fetch every member's value changes & fold them into slots.
+	series, err := findDialMembershipValueSeriesBetween(ctx, tx, dialID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("membership value series between: %w", err)
+	}
+	reports := make(map[int]*wtf.DialValueReport, len(series))
+	for userID, v := range series {
+		records := buildDialValueRecords(v.initial, v.changes, start, end, interval)
+		for _, record := range records {
+			record.Band = dial.Scale.BandLabel(record.Value)
+		}
+		reports[userID] = &wtf.DialValueReport{Records: records}
+	}
+	return reports, nil
+}
+
 // findDialMembershipByID returns a membership object by ID.
 // Returns ENOTFOUND if membership does not exist.
 func findDialMembershipByID(ctx context.Context, tx *Tx, id int) (*wtf.DialMembership, error) {
@@ -151,14 +317,20 @@ func findDialMemberships(ctx context.Context, tx *Tx, filter wtf.DialMembershipF
 	if v := filter.UserID; v != nil {
 		where, args = append(where, "dm.user_id = ?"), append(args, *v)
 	}
+	if v := filter.Status; v != nil {
+		where, args = append(where, "dm.status = ?"), append(args, *v)
+	}
 
-	// Limit to user's memberships or memberships of dials they belong to.
+	// Limit to user's memberships, memberships of dials they own, or
+	// memberships of dials they are an active member of. Pending members can
+	// only see their own membership until they are approved.
 	userID := wtf.UserIDFromContext(ctx)
 	where = append(where, `(
 		d.user_id = ? OR
-		dm.dial_id IN (SELECT dm1.dial_id FROM dial_memberships dm1 WHERE dm1.user_id = ?)
+		dm.user_id = ? OR
+		dm.dial_id IN (SELECT dm1.dial_id FROM dial_memberships dm1 WHERE dm1.user_id = ? AND dm1.status = ?)
 	)`)
-	args = append(args, userID, userID, userID)
+	args = append(args, userID, userID, userID, wtf.DialMembershipStatusActive)
 
 	// Determine sorting.
 	var sortBy string
@@ -178,9 +350,20 @@ func findDialMemberships(ctx context.Context, tx *Tx, filter wtf.DialMembershipF
 		    dm.dial_id,
 		    dm.user_id,
 		    dm.value,
+		    dm.note,
+		    dm.status,
+		    dm.away_until,
 		    dm.created_at,
 		    dm.updated_at,
 		    d.user_id AS dial_user_id,
+		    d.stale_policy,
+		    d.stale_after_days,
+		    d.stale_baseline,
+		    d.stale_half_life_days,
+		    d.scale_min,
+		    d.scale_max,
+		    d.scale_step,
+		    d.scale_bands,
 		    COUNT(*) OVER()
 		FROM dial_memberships dm
 		INNER JOIN dials d ON dm.dial_id = d.id
@@ -197,31 +380,111 @@ func findDialMemberships(ctx context.Context, tx *Tx, filter wtf.DialMembershipF
 
 	// Iterate over rows and deserialized into DialMembership objects.
 	memberships := make([]*wtf.DialMembership, 0)
+	scales := make(map[int]*wtf.DialScale)
 	for rows.Next() {
 		var dialUserID int
+		var dial wtf.Dial
+		var bands string
 		var membership wtf.DialMembership
-		if rows.Scan(
+		if err := rows.Scan(
 			&membership.ID,
 			&membership.DialID,
 			&membership.UserID,
 			&membership.Value,
+			&membership.Note,
+			&membership.Status,
+			(*NullTime)(&membership.AwayUntil),
 			(*NullTime)(&membership.CreatedAt),
 			(*NullTime)(&membership.UpdatedAt),
 			&dialUserID,
+			&dial.StalePolicy,
+			&dial.StaleAfterDays,
+			&dial.StaleBaseline,
+			&dial.StaleHalfLifeDays,
+			&dial.Scale.Min,
+			&dial.Scale.Max,
+			&dial.Scale.Step,
+			&bands,
 			&n,
 		); err != nil {
 			return nil, 0, err
+		} else if dial.Scale.Bands, err = unmarshalDialBands(bands); err != nil {
+			return nil, 0, err
 		}
 
+		membership.Band = dial.Scale.BandLabel(membership.Value)
+		setDialMembershipEffectiveValue(tx, &dial, &membership)
+
 		memberships = append(memberships, &membership)
+		scales[membership.DialID] = &dial.Scale
 	}
 	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
+	rows.Close()
+
+	// Attach dimension values once the membership rows have been read.
+	for _, membership := range memberships {
+		if membership.Dimensions, err = findDialMembershipDimensions(ctx, tx, membership, scales[membership.DialID]); err != nil {
+			return nil, 0, fmt.Errorf("membership dimensions: %w", err)
+		}
+	}
 
 	return memberships, n, nil
 }
 
+// listDialMemberships returns all memberships of a dial, including pending
+// memberships. This bypasses permission checks as it is used by background
+// jobs & by the dial owner's changes to the dial.
+func listDialMemberships(ctx context.Context, tx *Tx, dialID int) ([]*wtf.DialMembership, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, dial_id, user_id, status, value, note, created_at, updated_at
+		FROM dial_memberships
+		WHERE dial_id = ?
+		ORDER BY id
+	`,
+		dialID,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var memberships []*wtf.DialMembership
+	for rows.Next() {
+		var membership wtf.DialMembership
+		if err := rows.Scan(
+			&membership.ID,
+			&membership.DialID,
+			&membership.UserID,
+			&membership.Status,
+			&membership.Value,
+			&membership.Note,
+			(*NullTime)(&membership.CreatedAt),
+			(*NullTime)(&membership.UpdatedAt),
+		); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, &membership)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// Attach dimension values once the membership rows have been read.
+	scale, err := findDialScale(ctx, tx, dialID)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		if membership.Dimensions, err = findDialMembershipDimensions(ctx, tx, membership, scale); err != nil {
+			return nil, fmt.Errorf("membership dimensions: %w", err)
+		}
+	}
+	return memberships, nil
+}
+
 // createDialMembership creates a new membership. Assigns the new database ID
 // to membership.ID and updates the timestamps.
 func createDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembership) error {
@@ -240,10 +503,47 @@ func createDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembe
 	// we need to avoid since we are not yet a member. Normally we could use
 	// FOREIGN KEY errors to report a non-existent dial but SQLite FOREIGN KEY
 	// errors are not descriptive enough.
-	if err := checkDialExists(ctx, tx, membership.DialID); err != nil {
+	scale, err := findDialScale(ctx, tx, membership.DialID)
+	if err != nil {
 		return err
 	} else if _, err := findUserByID(ctx, tx, membership.UserID); err != nil {
 		return err
+	} else if err := checkDialNotArchived(ctx, tx, membership.DialID); err != nil {
+		return err
+	}
+
+	// Ensure the value is on the dial's scale.
+	if err := scale.ValidateValue(membership.Value); err != nil {
+		return err
+	}
+	membership.Band = scale.BandLabel(membership.Value)
+
+	// Users on the dial's ban list cannot rejoin.
+	if banned, err := isUserBannedFromDial(ctx, tx, membership.DialID, membership.UserID); err != nil {
+		return err
+	} else if banned {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You have been banned from this dial.")
+	}
+
+	// Memberships start out as pending if the dial requires approval. The dial
+	// owner's own membership is always active. Callers may set the status
+	// ahead of time, such as when the owner has invited the user directly.
+	dialUserID, requireApproval, err := findDialApprovalSettings(ctx, tx, membership.DialID)
+	if err != nil {
+		return err
+	}
+	if membership.Status == "" {
+		membership.Status = wtf.DialMembershipStatusActive
+		if requireApproval && membership.UserID != dialUserID {
+			membership.Status = wtf.DialMembershipStatusPending
+		}
+	}
+
+	// Active members of a computed dial must be able to view its inputs.
+	if !membership.IsPending() {
+		if err := checkDialExpressionInputsVisible(ctx, tx, membership.DialID, membership.UserID); err != nil {
+			return err
+		}
 	}
 
 	// Execute query to insert membership.
@@ -252,14 +552,18 @@ func createDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembe
 			dial_id,
 			user_id,
 			value,
+			note,
+			status,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`,
 		membership.DialID,
 		membership.UserID,
 		membership.Value,
+		membership.Note,
+		membership.Status,
 		(*NullTime)(&membership.CreatedAt),
 		(*NullTime)(&membership.UpdatedAt),
 	)
@@ -274,6 +578,35 @@ func createDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembe
 	}
 	membership.ID = int(id)
 
+	// Members start at their initial value on every dimension.
+	if membership.Dimensions, err = findDialMembershipDimensions(ctx, tx, membership, scale); err != nil {
+		return fmt.Errorf("membership dimensions: %w", err)
+	}
+
+	// Record initial value to the membership's value history.
+	if err := insertDialMembershipValue(ctx, tx, membership); err != nil {
+		return fmt.Errorf("insert initial membership value: %w", err)
+	}
+
+	// Record creation in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialMembershipCreate,
+		TargetType: wtf.AuditTargetDialMembership,
+		TargetID:   membership.ID,
+		DialID:     membership.DialID,
+	}, nil, membership); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Notify the dial owner of pending memberships. The dial value does not
+	// change until the membership is approved.
+	if membership.IsPending() {
+		if err := publishDialMembershipPendingEvent(ctx, tx, membership, dialUserID); err != nil {
+			return fmt.Errorf("publish pending event: %w", err)
+		}
+		return nil
+	}
+
 	// Ensure computed parent dial value is up to date.
 	if err := refreshDialValue(ctx, tx, membership.DialID); err != nil {
 		return fmt.Errorf("refresh dial value: %w", err)
@@ -291,62 +624,334 @@ func updateDialMembership(ctx context.Context, tx *Tx, id int, upd wtf.DialMembe
 		return membership, err
 	} else if membership.UserID != wtf.UserIDFromContext(ctx) {
 		return membership, wtf.Errorf(wtf.EUNAUTHORIZED, "You do not have permission to update the dial membership.")
+	} else if membership.IsPending() {
+		return membership, wtf.Errorf(wtf.ECONFLICT, "Your dial membership is awaiting approval.")
 	}
+	return membership, applyDialMembershipUpdate(ctx, tx, membership, upd)
+}
 
+// applyDialMembershipUpdate updates the value & note of a membership, records
+// the change in the value history & audit log, updates the dial value and
+// notifies the dial's members. This performs no permission checks so callers
+// must verify the change is allowed.
+func applyDialMembershipUpdate(ctx context.Context, tx *Tx, membership *wtf.DialMembership, upd wtf.DialMembershipUpdate) error {
 	// Save state of membership to compare later in the function.
 	prev := *membership
+	prev.Dimensions = copyDialMembershipDimensions(membership.Dimensions)
+
+	scale, err := findDialScale(ctx, tx, membership.DialID)
+	if err != nil {
+		return err
+	} else if err := checkDialNotArchived(ctx, tx, membership.DialID); err != nil {
+		return err
+	}
 
-	// Update fields.
+	// Update fields. A note only describes the change it was submitted with
+	// so it is cleared when a new value is set without one.
 	if v := upd.Value; v != nil {
 		membership.Value = *v
+		membership.Note = ""
+	}
+	if err := applyDialMembershipDimensionsUpdate(membership, upd); err != nil {
+		return err
+	} else if len(upd.Dimensions) > 0 {
+		membership.Note = ""
+	}
+	if v := upd.Note; v != nil {
+		membership.Note = strings.TrimSpace(*v)
+	}
+
+	// Ensure each dimension is on the dial's scale & roll them up into the
+	// membership value.
+	if len(membership.Dimensions) > 0 {
+		for _, dim := range membership.Dimensions {
+			if err := scale.ValidateValue(dim.Value); err != nil {
+				return err
+			}
+			dim.Band = scale.BandLabel(dim.Value)
+		}
+		membership.Value = scale.Average(dialMembershipDimensionValues(membership))
 	}
 
 	// Exit if membership did not change.
-	if prev.Value == membership.Value {
-		return membership, nil
+	if prev.Value == membership.Value && prev.Note == membership.Note && dialMembershipDimensionsEqual(prev.Dimensions, membership.Dimensions) {
+		return nil
 	}
 
 	// Set last updated date to current time.
 	membership.UpdatedAt = tx.now
 
-	// Perform basic field validation.
+	// Perform basic field validation & ensure the value is on the dial's scale.
 	if err := membership.Validate(); err != nil {
-		return membership, err
+		return err
+	} else if err := scale.ValidateValue(membership.Value); err != nil {
+		return err
 	}
+	membership.Band = scale.BandLabel(membership.Value)
 
 	// Execute query to update membership value.
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE dial_memberships
 		SET value = ?,
+		    note = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
 		membership.Value,
+		membership.Note,
 		(*NullTime)(&membership.UpdatedAt),
-		id,
+		membership.ID,
 	); err != nil {
-		return membership, FormatError(err)
+		return FormatError(err)
+	} else if err := saveDialMembershipDimensions(ctx, tx, membership); err != nil {
+		return fmt.Errorf("save membership dimensions: %w", err)
+	}
+
+	// Record change to the membership's value history.
+	if err := insertDialMembershipValue(ctx, tx, membership); err != nil {
+		return fmt.Errorf("insert membership value: %w", err)
+	}
+
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialMembershipUpdate,
+		TargetType: wtf.AuditTargetDialMembership,
+		TargetID:   membership.ID,
+		DialID:     membership.DialID,
+	}, &prev, membership); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
 	}
 
 	// Ensure computed dial value is up to date.
 	if err := refreshDialValue(ctx, tx, membership.DialID); err != nil {
-		return membership, fmt.Errorf("refresh dial value: %w", err)
+		return fmt.Errorf("refresh dial value: %w", err)
 	}
 
 	// Publish event to all dial members.
 	if err := publishDialEvent(ctx, tx, membership.DialID, wtf.Event{
 		Type: wtf.EventTypeDialMembershipValueChanged,
 		Payload: &wtf.DialMembershipValueChangedPayload{
-			ID:    id,
-			Value: membership.Value,
+			ID:         membership.ID,
+			Value:      membership.Value,
+			Note:       membership.Note,
+			Dimensions: membership.Dimensions,
 		},
 	}); err != nil {
-		return membership, fmt.Errorf("publish dial event: %w", err)
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+
+	return nil
+}
+
+// approveDialMembership marks a pending membership as active and updates the
+// dial value. Returns EUNAUTHORIZED if user is not the dial owner.
+func approveDialMembership(ctx context.Context, tx *Tx, id int) (*wtf.DialMembership, error) {
+	// Fetch current object state along with the parent dial.
+	membership, err := findDialMembershipByID(ctx, tx, id)
+	if err != nil {
+		return membership, err
+	} else if err := attachDialMembershipAssociations(ctx, tx, membership); err != nil {
+		return membership, err
 	}
 
+	// Only the dial owner can approve members & only pending members can be approved.
+	if !wtf.CanApproveDialMembership(ctx, membership) {
+		return membership, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can approve members.")
+	} else if !membership.IsPending() {
+		return membership, wtf.Errorf(wtf.ECONFLICT, "Dial membership is not awaiting approval.")
+	}
+
+	if err := activateDialMembership(ctx, tx, membership); err != nil {
+		return membership, err
+	}
 	return membership, nil
 }
 
+// activateDialMembership marks a pending membership as active so that it
+// contributes to the dial value & notifies the member. The caller must check
+// that the current user is allowed to approve the membership. The membership
+// must have its dial attached.
+func activateDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembership) error {
+	if err := checkDialNotArchived(ctx, tx, membership.DialID); err != nil {
+		return err
+	} else if err := checkDialExpressionInputsVisible(ctx, tx, membership.DialID, membership.UserID); err != nil {
+		return err
+	}
+
+	prev := *membership
+	membership.Status = wtf.DialMembershipStatusActive
+	membership.UpdatedAt = tx.now
+
+	// Execute query to update membership status.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_memberships
+		SET status = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		membership.Status,
+		(*NullTime)(&membership.UpdatedAt),
+		membership.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+
+	// Record approval in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialMembershipApprove,
+		TargetType: wtf.AuditTargetDialMembership,
+		TargetID:   membership.ID,
+		DialID:     membership.DialID,
+	}, &prev, membership); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// The approved member now contributes to the computed dial value.
+	if err := refreshDialValue(ctx, tx, membership.DialID); err != nil {
+		return fmt.Errorf("refresh dial value: %w", err)
+	}
+
+	// Let the new member know they have been approved.
+	tx.db.EventService.PublishEvent(membership.UserID, wtf.Event{
+		Type: wtf.EventTypeDialMembershipApproved,
+		Payload: &wtf.DialMembershipApprovedPayload{
+			ID:       membership.ID,
+			DialID:   membership.DialID,
+			DialName: membership.Dial.Name,
+		},
+	})
+
+	return nil
+}
+
+// setDialMembershipAway updates the time until which a member is away, then
+// updates the dial value & notifies the dial's members. This does not change
+// the membership's last updated time so it does not affect staleness.
+func setDialMembershipAway(ctx context.Context, tx *Tx, membership *wtf.DialMembership, until time.Time) error {
+	// Times are stored with second precision so normalize before comparing.
+	if !until.IsZero() {
+		until = until.UTC().Truncate(time.Second)
+	}
+	if membership.AwayUntil.Equal(until) {
+		return nil
+	}
+
+	prev := *membership
+	membership.AwayUntil = until
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_memberships
+		SET away_until = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&membership.AwayUntil),
+		membership.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialMembershipAway,
+		TargetType: wtf.AuditTargetDialMembership,
+		TargetID:   membership.ID,
+		DialID:     membership.DialID,
+	}, &prev, membership); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Away members are excluded from the computed dial value.
+	if err := refreshDialValue(ctx, tx, membership.DialID); err != nil {
+		return fmt.Errorf("refresh dial value: %w", err)
+	}
+	return publishDialMembershipAwayChangedEvent(ctx, tx, membership.ID, membership.DialID, membership.AwayUntil)
+}
+
+// ExpireDialMembershipAways marks members as returned once their away time
+// has passed, then updates their dial values & notifies the dial's members.
+// Each return is recorded in the audit log as a system change. This is called
+// periodically by the background monitor but can also be called directly,
+// such as from tests.
+func (db *DB) ExpireDialMembershipAways(ctx context.Context) error {
+	ctx = wtf.NewContextWithAuditSource(ctx, wtf.AuditSourceSystem)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Find all memberships whose away time has passed. This bypasses
+	// permission checks as it runs on behalf of the system.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, dial_id, user_id, away_until
+		FROM dial_memberships
+		WHERE away_until <= ?
+		ORDER BY id
+	`,
+		(*NullTime)(&tx.now),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	var expired []*wtf.DialMembership
+	for rows.Next() {
+		var m wtf.DialMembership
+		if err := rows.Scan(&m.ID, &m.DialID, &m.UserID, (*NullTime)(&m.AwayUntil)); err != nil {
+			return err
+		}
+		expired = append(expired, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range expired {
+		prev := *m
+		m.AwayUntil = time.Time{}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE dial_memberships SET away_until = NULL WHERE id = ?`, m.ID); err != nil {
+			return FormatError(err)
+		}
+
+		// Record the automatic return in the audit log. Only the fields
+		// involved in the change are loaded so the snapshots are partial.
+		if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+			Action:     wtf.AuditActionDialMembershipAway,
+			TargetType: wtf.AuditTargetDialMembership,
+			TargetID:   m.ID,
+			DialID:     m.DialID,
+		}, &prev, m); err != nil {
+			return fmt.Errorf("create audit entry: %w", err)
+		}
+
+		if err := refreshDialValue(ctx, tx, m.DialID); err != nil {
+			return fmt.Errorf("refresh dial value: id=%d err=%w", m.DialID, err)
+		} else if err := publishDialMembershipAwayChangedEvent(ctx, tx, m.ID, m.DialID, time.Time{}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// publishDialMembershipAwayChangedEvent notifies all dial members that a
+// member has gone away or returned.
+func publishDialMembershipAwayChangedEvent(ctx context.Context, tx *Tx, id, dialID int, awayUntil time.Time) error {
+	if err := publishDialEvent(ctx, tx, dialID, wtf.Event{
+		Type: wtf.EventTypeDialMembershipAwayChanged,
+		Payload: &wtf.DialMembershipAwayChangedPayload{
+			ID:        id,
+			DialID:    dialID,
+			AwayUntil: awayUntil,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+	return nil
+}
+
 // deleteDialMembership permanently deletes a membership and updates the dial value.
 func deleteDialMembership(ctx context.Context, tx *Tx, id int) error {
 	// Fetch user ID of currently logged in user.
@@ -375,6 +980,16 @@ func deleteDialMembership(ctx context.Context, tx *Tx, id int) error {
 		return FormatError(err)
 	}
 
+	// Record deletion in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialMembershipDelete,
+		TargetType: wtf.AuditTargetDialMembership,
+		TargetID:   membership.ID,
+		DialID:     membership.DialID,
+	}, membership, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
 	// Ensure computed dial value is up to date.
 	if err := refreshDialValue(ctx, tx, membership.DialID); err != nil {
 		return fmt.Errorf("refresh dial value: %w", err)
@@ -382,11 +997,196 @@ func deleteDialMembership(ctx context.Context, tx *Tx, id int) error {
 	return nil
 }
 
+// insertDialMembershipValue records the current value & note of a membership
+// in the membership value history. Unlike dial values, every change is kept.
+func insertDialMembershipValue(ctx context.Context, tx *Tx, membership *wtf.DialMembership) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_membership_values (dial_id, user_id, value, note, "timestamp")
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		membership.DialID,
+		membership.UserID,
+		membership.Value,
+		membership.Note,
+		(*NullTime)(&membership.UpdatedAt),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// findDialMembershipValueChangesBetween returns the member's value in effect
+// at start and the list of value changes between start & end, in time order.
+func findDialMembershipValueChangesBetween(ctx context.Context, tx *Tx, dialID, userID int, start, end time.Time) (initial int, changes []dialValueChange, err error) {
+	// Determine the value in effect at the start of the time range.
+	if err := tx.QueryRowContext(ctx, `
+		SELECT value
+		FROM dial_membership_values
+		WHERE dial_id = ? AND user_id = ? AND "timestamp" < ?
+		ORDER BY "timestamp" DESC, id DESC
+		LIMIT 1
+	`,
+		dialID,
+		userID,
+		(*NullTime)(&start),
+	).Scan(&initial); err != nil && err != sql.ErrNoRows {
+		return 0, nil, FormatError(err)
+	}
+
+	// Find all changes between start & end.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT value, "timestamp"
+		FROM dial_membership_values
+		WHERE dial_id = ? AND user_id = ? AND "timestamp" >= ? AND "timestamp" < ?
+		ORDER BY "timestamp" ASC, id ASC
+	`,
+		dialID,
+		userID,
+		(*NullTime)(&start),
+		(*NullTime)(&end),
+	)
+	if err != nil {
+		return 0, nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var change dialValueChange
+		if err := rows.Scan(&change.Value, (*NullTime)(&change.Timestamp)); err != nil {
+			return 0, nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return initial, changes, nil
+}
+
+// dialMembershipValueSeries is a member's value at the start of a time range
+// & their changes within it.
+type dialMembershipValueSeries struct {
+	initial int
+	changes []dialValueChange
+}
+
+// findDialMembershipValueSeriesBetween returns the value series of every
+// active member of a dial between start & end, keyed by user ID. This is the
+// same as calling findDialMembershipValueChangesBetween() for each member but
+// uses a single query. Each member's rows start from the last value they set
+// before start so the initial value can be read from the same result.
+func findDialMembershipValueSeriesBetween(ctx context.Context, tx *Tx, dialID int, start, end time.Time) (map[int]*dialMembershipValueSeries, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT dm.user_id, dmv.value, dmv."timestamp"
+		FROM dial_memberships dm
+		LEFT JOIN dial_membership_values dmv
+		    ON dmv.dial_id = dm.dial_id
+		   AND dmv.user_id = dm.user_id
+		   AND dmv."timestamp" < ?
+		   AND dmv."timestamp" >= IFNULL((
+		       SELECT MAX(prev."timestamp")
+		       FROM dial_membership_values prev
+		       WHERE prev.dial_id = dm.dial_id AND prev.user_id = dm.user_id AND prev."timestamp" < ?
+		   ), '')
+		WHERE dm.dial_id = ? AND dm.status = ?
+		ORDER BY dm.user_id ASC, dmv."timestamp" ASC, dmv.id ASC
+	`,
+		(*NullTime)(&end),
+		(*NullTime)(&start),
+		dialID,
+		wtf.DialMembershipStatusActive,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	series := make(map[int]*dialMembershipValueSeries)
+	for rows.Next() {
+		var userID int
+		var value sql.NullInt64
+		var timestamp time.Time
+		if err := rows.Scan(&userID, &value, (*NullTime)(&timestamp)); err != nil {
+			return nil, err
+		}
+
+		s := series[userID]
+		if s == nil {
+			s = &dialMembershipValueSeries{}
+			series[userID] = s
+		}
+
+		// Members without any values in range still get an empty series.
+		// Values before the start only set the initial value.
+		if !value.Valid {
+			continue
+		} else if timestamp.Before(start) {
+			s.initial = int(value.Int64)
+			continue
+		}
+		s.changes = append(s.changes, dialValueChange{Value: int(value.Int64), Timestamp: timestamp})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// publishDialMembershipPendingEvent notifies the dial owner that a user is
+// waiting for their membership to be approved.
+func publishDialMembershipPendingEvent(ctx context.Context, tx *Tx, membership *wtf.DialMembership, dialUserID int) error {
+	var dialName, userName string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT d.name, u.name
+		FROM dials d, users u
+		WHERE d.id = ? AND u.id = ?
+	`,
+		membership.DialID,
+		membership.UserID,
+	).Scan(&dialName, &userName); err != nil {
+		return FormatError(err)
+	}
+
+	tx.db.EventService.PublishEvent(dialUserID, wtf.Event{
+		Type: wtf.EventTypeDialMembershipPending,
+		Payload: &wtf.DialMembershipPendingPayload{
+			ID:       membership.ID,
+			DialID:   membership.DialID,
+			DialName: dialName,
+			UserName: userName,
+		},
+	})
+	return nil
+}
+
+// attachDialMembershipAssociations attaches the parent dial & member user.
 func attachDialMembershipAssociations(ctx context.Context, tx *Tx, membership *wtf.DialMembership) (err error) {
-	if membership.Dial, err = findDialByID(ctx, tx, membership.DialID); err != nil {
+	// Users waiting for approval can only see the parts of the dial needed
+	// for the waiting page.
+	if membership.IsPending() && membership.UserID == wtf.UserIDFromContext(ctx) {
+		if membership.Dial, err = findPendingDialByID(ctx, tx, membership.DialID); err != nil {
+			return fmt.Errorf("attach membership dial: %w", err)
+		}
+	} else if membership.Dial, err = findDialByID(ctx, tx, membership.DialID); err != nil {
 		return fmt.Errorf("attach membership dial: %w", err)
-	} else if membership.User, err = findUserByID(ctx, tx, membership.UserID); err != nil {
+	}
+
+	if membership.User, err = findUserByID(ctx, tx, membership.UserID); err != nil {
 		return fmt.Errorf("attach membership user: %w", err)
 	}
+	membership.Band = membership.Dial.Scale.BandLabel(membership.Value)
+	setDialMembershipEffectiveValue(tx, membership.Dial, membership)
 	return nil
 }
+
+// setDialMembershipEffectiveValue computes whether the membership is stale &
+// the value it contributes under the dial's stale policy. Pending & away
+// members do not contribute so they are never stale.
+func setDialMembershipEffectiveValue(tx *Tx, dial *wtf.Dial, membership *wtf.DialMembership) {
+	membership.Stale, membership.EffectiveValue = false, 0
+	if membership.IsPending() || membership.IsAway(tx.now) {
+		return
+	}
+	membership.Stale = dial.IsMembershipStale(membership, tx.now)
+	membership.EffectiveValue, _ = dial.EffectiveMembershipValue(membership, tx.now)
+}
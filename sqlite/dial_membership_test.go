@@ -3,10 +3,12 @@ package sqlite_test
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
 	"github.com/benbjohnson/wtf/sqlite"
 )
 
@@ -45,6 +47,55 @@ func TestDialMembershipService_CreateDialMembership(t *testing.T) {
 		}
 	})
 
+	// Ensure memberships are pending if the dial requires approval.
+	t.Run("Pending", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+
+		// Track events sent to the dial owner.
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if userID == dial.UserID {
+					events = append(events, event)
+				}
+			},
+		}
+
+		membership := &wtf.DialMembership{DialID: dial.ID, Value: 80}
+		if err := s.CreateDialMembership(ctx1, membership); err != nil {
+			t.Fatal(err)
+		} else if got, want := membership.Status, wtf.DialMembershipStatusPending; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		} else if got, want := membership.Dial.Value, 0; got != want {
+			t.Fatalf("Dial.Value=%v, want %v", got, want)
+		}
+
+		// Ensure the dial owner has been notified.
+		if got, want := len(events), 1; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		} else if got, want := events[0].Type, wtf.EventTypeDialMembershipPending; got != want {
+			t.Fatalf("Type=%v, want %v", got, want)
+		} else if got, want := events[0].Payload.(*wtf.DialMembershipPendingPayload).UserName, "jim"; got != want {
+			t.Fatalf("UserName=%v, want %v", got, want)
+		}
+
+		// Ensure pending member can only see their own membership.
+		if a, _, err := s.FindDialMemberships(ctx1, wtf.DialMembershipFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(a), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := a[0].ID, membership.ID; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		}
+	})
+
 	// Ensure an error is returned if we do not have an associated dial.
 	t.Run("ErrDialRequired", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -159,6 +210,61 @@ func TestDialMembershipService_UpdateDialMembership(t *testing.T) {
 		}
 	})
 
+	// Ensure a note is stored with the value & sent to dial members.
+	t.Run("Note", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		// Track membership events sent to the dial owner.
+		var payloads []*wtf.DialMembershipValueChangedPayload
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if userID == user0.ID && event.Type == wtf.EventTypeDialMembershipValueChanged {
+					payloads = append(payloads, event.Payload.(*wtf.DialMembershipValueChangedPayload))
+				}
+			},
+		}
+
+		if err := s.SetDialMembershipValue(ctx0, dial.ID, 80, "prod db at 98% disk"); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialMembershipByID(t, ctx0, db, 1).Note, "prod db at 98% disk"; got != want {
+			t.Fatalf("Note=%v, want %v", got, want)
+		} else if got, want := len(payloads), 1; got != want {
+			t.Fatalf("len(payloads)=%v, want %v", got, want)
+		} else if got, want := payloads[0].Note, "prod db at 98% disk"; got != want {
+			t.Fatalf("payload.Note=%v, want %v", got, want)
+		}
+
+		// Ensure the note is cleared when the value changes without one.
+		if err := s.SetDialMembershipValue(ctx0, dial.ID, 60, ""); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialMembershipByID(t, ctx0, db, 1).Note, ""; got != want {
+			t.Fatalf("Note=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure notes are limited in length.
+	t.Run("ErrNoteTooLong", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		if err := s.SetDialMembershipValue(ctx0, dial.ID, 80, strings.Repeat("x", wtf.MaxDialMembershipNoteLen+1)); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Note must be 140 characters or less.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
 	// Ensure an error is returned if another user tries to update a membership.
 	t.Run("ErrUnauthorized", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -182,6 +288,26 @@ func TestDialMembershipService_UpdateDialMembership(t *testing.T) {
 		}
 	})
 
+	// Ensure a pending member cannot update their value.
+	t.Run("ErrPending", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		newValue := 25
+		if _, err := s.UpdateDialMembership(ctx1, membership.ID, wtf.DialMembershipUpdate{Value: &newValue}); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Your dial membership is awaiting approval.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
 	// Ensure membership value is between 0 & 100.
 	t.Run("ErrValueOutOfRange", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -201,6 +327,229 @@ func TestDialMembershipService_UpdateDialMembership(t *testing.T) {
 	})
 }
 
+func TestDialMembershipService_ApproveDialMembership(t *testing.T) {
+	// Ensure the dial owner can approve a pending membership.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 50})
+
+		if other, err := s.ApproveDialMembership(ctx0, membership.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Status, wtf.DialMembershipStatusActive; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		} else if got, want := other.Dial.Value, 25; got != want {
+			t.Fatalf("Dial.Value=%v, want %v", got, want)
+		}
+
+		// Ensure approved member can now update their value.
+		MustSetDialMembershipValue(t, ctx1, db, membership.ID, 100)
+		if other := MustFindDialByID(t, ctx0, db, dial.ID); other.Value != 50 {
+			t.Fatalf("unexpected dial value: %d", other.Value)
+		}
+	})
+
+	// Ensure only the dial owner can approve a membership.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if _, err := s.ApproveDialMembership(ctx1, membership.ID); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can approve members.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure an active membership cannot be approved again.
+	t.Run("ErrNotPending", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if _, err := s.ApproveDialMembership(ctx0, membership.ID); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Dial membership is not awaiting approval.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestDialMembershipService_MembershipValueReport(t *testing.T) {
+	// Ensure member values are slotted & carried forward between changes.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 1, 0, 0, time.UTC) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 50)
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 3, 0, 0, time.UTC) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 90)
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 3, 30, 0, time.UTC) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+
+		// Ensure another member can view the report.
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if report, err := s.MembershipValueReport(ctx1, dial.ID, user0.ID, start, start.Add(5*time.Minute), time.Minute); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records, []*wtf.DialValueRecord{
+			{Value: 0, Min: 0, Max: 0, Avg: 0, Timestamp: start},
+			{Value: 50, Min: 50, Max: 50, Avg: 50, Timestamp: start.Add(1 * time.Minute)},
+			{Value: 50, Min: 50, Max: 50, Avg: 50, Timestamp: start.Add(2 * time.Minute)},
+			{Value: 80, Min: 80, Max: 90, Avg: 85, Timestamp: start.Add(3 * time.Minute)},
+			{Value: 80, Min: 80, Max: 80, Avg: 80, Timestamp: start.Add(4 * time.Minute)},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Records=%#v, want %#v", got, want)
+		}
+
+		// Ensure the value before the range is carried into the first slot.
+		if report, err := s.MembershipValueReport(ctx0, dial.ID, user0.ID, start.Add(2*time.Minute), start.Add(4*time.Minute), time.Minute); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0].Value, 50; got != want {
+			t.Fatalf("Records[0].Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure non-members cannot view a member's history.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		if _, err := s.MembershipValueReport(ctx1, dial.ID, user0.ID, time.Now().Add(-time.Hour), time.Now(), time.Minute); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure reversed, empty & oversized ranges are rejected.
+	t.Run("ErrInvalidRange", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		for _, tt := range []struct {
+			name       string
+			start, end time.Time
+			interval   time.Duration
+			msg        string
+		}{
+			{"Reversed", start, start.Add(-time.Hour), time.Minute, `Report end time must be after start time.`},
+			{"Empty", start, start, time.Minute, `Report end time must be after start time.`},
+			{"ZeroInterval", start, start.Add(time.Hour), 0, `Report interval must be at least one minute.`},
+			{"TooManySlots", start, start.Add(7 * 24 * time.Hour), time.Minute, `Report cannot have more than 1000 intervals.`},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				if _, err := s.MembershipValueReport(ctx0, dial.ID, user0.ID, tt.start, tt.end, tt.interval); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+					t.Fatalf("unexpected error: %#v", err)
+				}
+			})
+		}
+	})
+}
+
+func TestDialMembershipService_MembershipValueReports(t *testing.T) {
+	// Ensure every active member gets the same report as when fetched on
+	// their own, including the value carried in from before the range.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		_, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "joe"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		membership1 := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		if _, err := s.ApproveDialMembership(ctx0, membership1.ID); err != nil {
+			t.Fatal(err)
+		}
+		MustCreateDialMembership(t, ctx2, db, &wtf.DialMembership{DialID: dial.ID})
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 1, 0, 0, time.UTC) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 50)
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 3, 0, 0, time.UTC) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 90)
+		MustSetDialMembershipValue(t, ctx1, db, membership1.ID, 20)
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 3, 30, 0, time.UTC) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		for _, offset := range []time.Duration{0, 2 * time.Minute, 4 * time.Minute} {
+			reports, err := s.MembershipValueReports(ctx1, dial.ID, start.Add(offset), start.Add(5*time.Minute), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := len(reports), 2; got != want {
+				t.Fatalf("len=%v, want %v", got, want)
+			}
+
+			for _, userID := range []int{user0.ID, user1.ID} {
+				if report, err := s.MembershipValueReport(ctx1, dial.ID, userID, start.Add(offset), start.Add(5*time.Minute), time.Minute); err != nil {
+					t.Fatal(err)
+				} else if !reflect.DeepEqual(reports[userID], report) {
+					t.Fatalf("offset=%s user=%d: Records=%#v, want %#v", offset, userID, reports[userID].Records, report.Records)
+				}
+			}
+		}
+	})
+
+	// Ensure non-members cannot view the members' history.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		if _, err := s.MembershipValueReports(ctx1, dial.ID, time.Now().Add(-time.Hour), time.Now(), time.Minute); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
 func TestDialMembershipService_FindDialMemberships(t *testing.T) {
 	// Ensure dial member can see all memberships in dial.
 	t.Run("RestrictToDialMember", func(t *testing.T) {
@@ -400,6 +749,162 @@ func TestDialMembershipService_DeleteDialMembership(t *testing.T) {
 }
 
 // MustFindDialMembershipByID finds a membership in the database. Fatal on error.
+func TestDialMembershipService_SetDialMembershipAway(t *testing.T) {
+	// Ensure away members are excluded from the dial value & members are notified.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+
+		// Track away events sent to the dial owner.
+		var payloads []*wtf.DialMembershipAwayChangedPayload
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if userID == user0.ID && event.Type == wtf.EventTypeDialMembershipAwayChanged {
+					payloads = append(payloads, event.Payload.(*wtf.DialMembershipAwayChangedPayload))
+				}
+			},
+		}
+
+		until := now.AddDate(0, 0, 7)
+		if membership, err := s.SetDialMembershipAway(ctx1, dial.ID, until); err != nil {
+			t.Fatal(err)
+		} else if got, want := membership.AwayUntil, until; !got.Equal(want) {
+			t.Fatalf("AwayUntil=%v, want %v", got, want)
+		} else if got, want := membership.Dial.Value, 20; got != want {
+			t.Fatalf("Dial.Value=%v, want %v", got, want)
+		} else if got, want := len(payloads), 1; got != want {
+			t.Fatalf("len(payloads)=%v, want %v", got, want)
+		} else if got, want := payloads[0].AwayUntil, until; !got.Equal(want) {
+			t.Fatalf("AwayUntil=%v, want %v", got, want)
+		}
+
+		// Ensure away status is visible to other members.
+		if m := MustFindDialMembershipByID(t, ctx0, db, 2); !m.AwayUntil.Equal(until) {
+			t.Fatalf("AwayUntil=%v, want %v", m.AwayUntil, until)
+		} else if got, want := m.EffectiveValue, 0; got != want {
+			t.Fatalf("EffectiveValue=%v, want %v", got, want)
+		}
+
+		// Returning early restores the member's value.
+		if membership, err := s.SetDialMembershipAway(ctx1, dial.ID, time.Time{}); err != nil {
+			t.Fatal(err)
+		} else if !membership.AwayUntil.IsZero() {
+			t.Fatalf("unexpected AwayUntil: %v", membership.AwayUntil)
+		} else if got, want := membership.Dial.Value, 60; got != want {
+			t.Fatalf("Dial.Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure members are automatically returned once their away time passes.
+	t.Run("Expire", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+		if _, err := s.SetDialMembershipAway(ctx1, dial.ID, now.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Nothing changes before the away time.
+		if err := db.ExpireDialMembershipAways(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		now = now.Add(time.Hour)
+		if err := db.ExpireDialMembershipAways(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 50; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if m := MustFindDialMembershipByID(t, ctx0, db, 2); !m.AwayUntil.IsZero() {
+			t.Fatalf("unexpected AwayUntil: %v", m.AwayUntil)
+		}
+
+		// The return is recorded as a system change.
+		action := wtf.AuditActionDialMembershipAway
+		if entries, _, err := sqlite.NewAuditService(db).FindAuditEntries(ctx0, wtf.AuditEntryFilter{DialID: &dial.ID, Action: &action}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := entries[0].Source, wtf.AuditSourceSystem; got != want {
+			t.Fatalf("Source=%v, want %v", got, want)
+		} else if got, want := entries[0].UserID, 0; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		} else if got, want := entries[0].TargetID, 2; got != want {
+			t.Fatalf("TargetID=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a user can be marked away on all their dials at once.
+	t.Run("SetAway", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL1"})
+
+		until := time.Now().AddDate(0, 0, 7).Truncate(time.Second)
+		if err := s.SetAway(ctx0, until); err != nil {
+			t.Fatal(err)
+		} else if m := MustFindDialMembershipByID(t, ctx0, db, 1); m.DialID != dial0.ID || !m.AwayUntil.Equal(until) {
+			t.Fatalf("unexpected membership: dialID=%d awayUntil=%v", m.DialID, m.AwayUntil)
+		} else if m := MustFindDialMembershipByID(t, ctx0, db, 2); m.DialID != dial1.ID || !m.AwayUntil.Equal(until) {
+			t.Fatalf("unexpected membership: dialID=%d awayUntil=%v", m.DialID, m.AwayUntil)
+		}
+	})
+
+	// Ensure the away time must be in the future.
+	t.Run("ErrPast", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		if _, err := s.SetDialMembershipAway(ctx0, dial.ID, time.Now().Add(-time.Hour)); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Away date must be in the future.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure non-members cannot set themselves away on a dial.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		if _, err := s.SetDialMembershipAway(ctx1, dial.ID, time.Now().Add(time.Hour)); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatal(err)
+		}
+	})
+}
+
 func MustFindDialMembershipByID(tb testing.TB, ctx context.Context, db *sqlite.DB, id int) *wtf.DialMembership {
 	tb.Helper()
 	membership, err := sqlite.NewDialMembershipService(db).FindDialMembershipByID(ctx, id)
@@ -0,0 +1,492 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.DialPeriodService = (*DialPeriodService)(nil)
+
+// DialPeriodService represents a service for closing periods on dials.
+type DialPeriodService struct {
+	db *DB
+}
+
+// NewDialPeriodService returns a new instance of DialPeriodService.
+func NewDialPeriodService(db *DB) *DialPeriodService {
+	return &DialPeriodService{db: db}
+}
+
+// FindDialPeriodByID retrieves a single period by ID along with its dial.
+// Returns ENOTFOUND if the period does not exist or the user cannot view its
+// dial.
+func (s *DialPeriodService) FindDialPeriodByID(ctx context.Context, id int) (*wtf.DialPeriod, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	period, err := findDialPeriodByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if err := attachDialPeriodAssociations(ctx, tx, period); err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+// FindDialPeriods retrieves a list of periods based on a filter, most recent
+// first. Only returns periods on dials the user is a member of.
+func (s *DialPeriodService) FindDialPeriods(ctx context.Context, filter wtf.DialPeriodFilter) ([]*wtf.DialPeriod, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	periods, n, err := findDialPeriods(ctx, tx, filter)
+	if err != nil {
+		return periods, n, err
+	}
+	for _, period := range periods {
+		if err := attachDialPeriodAssociations(ctx, tx, period); err != nil {
+			return periods, n, err
+		}
+	}
+	return periods, n, nil
+}
+
+// CloseDialPeriod computes & stores the summary of a period on a dial. Only
+// the dial owner can close a period.
+func (s *DialPeriodService) CloseDialPeriod(ctx context.Context, period *wtf.DialPeriod) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := closeDialPeriod(ctx, tx, period); err != nil {
+		return err
+	} else if err := attachDialPeriodAssociations(ctx, tx, period); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteDialPeriod permanently removes a closed period. Only the dial owner
+// can delete it.
+func (s *DialPeriodService) DeleteDialPeriod(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteDialPeriod(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findDialPeriodByID is a helper function to retrieve a period by ID.
+// Returns ENOTFOUND if period doesn't exist.
+func findDialPeriodByID(ctx context.Context, tx *Tx, id int) (*wtf.DialPeriod, error) {
+	periods, _, err := findDialPeriods(ctx, tx, wtf.DialPeriodFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(periods) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial period not found."}
+	}
+	return periods[0], nil
+}
+
+// findDialPeriods retrieves a list of matching periods. Also returns a total
+// matching count which may differ from the number of results if filter.Limit
+// is set.
+func findDialPeriods(ctx context.Context, tx *Tx, filter wtf.DialPeriodFilter) (_ []*wtf.DialPeriod, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "p.id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "p.dial_id = ?"), append(args, *v)
+	}
+	if v := filter.IDs; v != nil {
+		placeholders := make([]string, len(v))
+		for i, id := range v {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		where = append(where, "p.id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	// Limit to periods on dials the user is a member of.
+	where = append(where, `p.dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)`)
+	args = append(args, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive)
+
+	// Execute query to fetch period rows.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    p.id,
+		    p.dial_id,
+		    p.name,
+		    p.start_at,
+		    p.end_at,
+		    p.threshold,
+		    p.average,
+		    p.peak,
+		    p.minutes_above_threshold,
+		    p.members,
+		    p.notes,
+		    p.created_at,
+		    COUNT(*) OVER()
+		FROM dial_periods p
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY p.end_at DESC, p.id DESC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialPeriod objects.
+	periods := make([]*wtf.DialPeriod, 0)
+	for rows.Next() {
+		var period wtf.DialPeriod
+		var members, notes string
+		if err := rows.Scan(
+			&period.ID,
+			&period.DialID,
+			&period.Name,
+			(*NullTime)(&period.StartAt),
+			(*NullTime)(&period.EndAt),
+			&period.Threshold,
+			&period.Average,
+			&period.Peak,
+			&period.MinutesAboveThreshold,
+			&members,
+			&notes,
+			(*NullTime)(&period.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if err := json.Unmarshal([]byte(members), &period.Members); err != nil {
+			return nil, 0, fmt.Errorf("unmarshal dial period members: %w", err)
+		} else if err := json.Unmarshal([]byte(notes), &period.Notes); err != nil {
+			return nil, 0, fmt.Errorf("unmarshal dial period notes: %w", err)
+		}
+		periods = append(periods, &period)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return periods, n, nil
+}
+
+// closeDialPeriod computes the summary of a period from the dial's value
+// history & the history of its members' values and stores it. Returns
+// EUNAUTHORIZED if the current user does not own the dial.
+//
+// Summaries are computed from raw values. Values older than the dial value
+// retention period have been pruned, so, like DialGoalReport, the part of the
+// period the dial existed for must start within the retention period.
+func closeDialPeriod(ctx context.Context, tx *Tx, period *wtf.DialPeriod) error {
+	period.Name = strings.TrimSpace(period.Name)
+	period.StartAt = period.StartAt.UTC().Truncate(time.Second)
+	period.EndAt = period.EndAt.UTC().Truncate(time.Second)
+	period.CreatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := period.Validate(); err != nil {
+		return err
+	} else if period.EndAt.After(tx.now) {
+		return wtf.Errorf(wtf.EINVALID, "Period cannot end in the future.")
+	}
+
+	// Only the dial owner can close a period.
+	dial, err := findDialByID(ctx, tx, period.DialID)
+	if err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can close a period.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return err
+	} else if !period.EndAt.After(dial.CreatedAt) {
+		return wtf.Errorf(wtf.EINVALID, "Period must end after the dial was created.")
+	} else if period.Threshold < dial.Scale.Min || period.Threshold > dial.Scale.Max {
+		return wtf.Errorf(wtf.EINVALID, "Period threshold must be between %d & %d.", dial.Scale.Min, dial.Scale.Max)
+	}
+
+	// Summarize the dial value over the part of the period it existed.
+	start := period.StartAt
+	if dial.CreatedAt.After(start) {
+		start = dial.CreatedAt
+	}
+	if cutoff, err := findDialValueRetentionCutoff(ctx, tx); err != nil {
+		return err
+	} else if start.Before(cutoff) {
+		return wtf.Errorf(wtf.EINVALID, "Period must start within the last %s while dial values are retained.", tx.db.DialValueRetention)
+	}
+	initial, changes, err := findDialValueChangesBetween(ctx, tx, dial.ID, start, period.EndAt)
+	if err != nil {
+		return fmt.Errorf("dial value changes between: %w", err)
+	}
+	summary := summarizeDialValueChanges(initial, changes, start, period.EndAt, period.Threshold)
+	period.Average, period.Peak = summary.Average, summary.Peak
+	period.MinutesAboveThreshold = int(summary.AboveThreshold / time.Minute)
+
+	if period.Members, err = summarizeDialPeriodMembers(ctx, tx, period); err != nil {
+		return fmt.Errorf("summarize members: %w", err)
+	} else if period.Notes, err = findDialPeriodNotes(ctx, tx, period); err != nil {
+		return fmt.Errorf("find notes: %w", err)
+	}
+
+	members, err := json.Marshal(period.Members)
+	if err != nil {
+		return err
+	}
+	notes, err := json.Marshal(period.Notes)
+	if err != nil {
+		return err
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_periods (
+			dial_id,
+			name,
+			start_at,
+			end_at,
+			threshold,
+			average,
+			peak,
+			minutes_above_threshold,
+			members,
+			notes,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		period.DialID,
+		period.Name,
+		(*NullTime)(&period.StartAt),
+		(*NullTime)(&period.EndAt),
+		period.Threshold,
+		period.Average,
+		period.Peak,
+		period.MinutesAboveThreshold,
+		string(members),
+		string(notes),
+		(*NullTime)(&period.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new period ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	period.ID = int(id)
+
+	// Record the closed period in the dial's audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialPeriodClose,
+		TargetType: wtf.AuditTargetDialPeriod,
+		TargetID:   period.ID,
+		DialID:     period.DialID,
+	}, nil, period); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// summarizeDialPeriodMembers returns the summary of each member's value
+// during a period, ordered by name. This includes current active members &
+// former members who changed their value during the period. Each member is
+// only summarized from the time they first joined.
+func summarizeDialPeriodMembers(ctx context.Context, tx *Tx, period *wtf.DialPeriod) ([]*wtf.DialPeriodMember, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT v.user_id, u.name, MIN(v."timestamp")
+		FROM dial_membership_values v
+		INNER JOIN users u ON u.id = v.user_id
+		WHERE v.dial_id = ?1 AND v."timestamp" < ?3
+		  AND v.user_id NOT IN (SELECT user_id FROM dial_memberships WHERE dial_id = ?1 AND status = ?4)
+		GROUP BY v.user_id, u.name
+		HAVING MAX(v."timestamp") >= ?2
+		    OR v.user_id IN (SELECT user_id FROM dial_memberships WHERE dial_id = ?1)
+		ORDER BY u.name ASC, v.user_id ASC
+	`,
+		period.DialID,
+		(*NullTime)(&period.StartAt),
+		(*NullTime)(&period.EndAt),
+		wtf.DialMembershipStatusPending,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	type member struct {
+		*wtf.DialPeriodMember
+		joinedAt time.Time
+	}
+	var list []member
+	for rows.Next() {
+		m := member{DialPeriodMember: &wtf.DialPeriodMember{}}
+		if err := rows.Scan(&m.UserID, &m.Name, (*NullTime)(&m.joinedAt)); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	members := make([]*wtf.DialPeriodMember, 0, len(list))
+	for _, m := range list {
+		start := period.StartAt
+		if m.joinedAt.After(start) {
+			start = m.joinedAt
+		}
+
+		initial, changes, err := findDialMembershipValueChangesBetween(ctx, tx, period.DialID, m.UserID, start, period.EndAt)
+		if err != nil {
+			return nil, err
+		}
+		summary := summarizeDialValueChanges(initial, changes, start, period.EndAt, period.Threshold)
+		m.Average, m.Peak = summary.Average, summary.Peak
+		members = append(members, m.DialPeriodMember)
+	}
+	return members, nil
+}
+
+// findDialPeriodNotes returns the notes members left with value changes
+// during a period, in time order.
+func findDialPeriodNotes(ctx context.Context, tx *Tx, period *wtf.DialPeriod) ([]*wtf.DialPeriodNote, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT v.user_id, u.name, v.value, v.note, v."timestamp"
+		FROM dial_membership_values v
+		INNER JOIN users u ON u.id = v.user_id
+		WHERE v.dial_id = ? AND v.note != '' AND v."timestamp" >= ? AND v."timestamp" < ?
+		ORDER BY v."timestamp" ASC, v.id ASC
+	`,
+		period.DialID,
+		(*NullTime)(&period.StartAt),
+		(*NullTime)(&period.EndAt),
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	notes := make([]*wtf.DialPeriodNote, 0)
+	for rows.Next() {
+		var note wtf.DialPeriodNote
+		if err := rows.Scan(&note.UserID, &note.Name, &note.Value, &note.Note, (*NullTime)(&note.Timestamp)); err != nil {
+			return nil, err
+		}
+		notes = append(notes, &note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// deleteDialPeriod permanently removes a period by ID. Returns EUNAUTHORIZED
+// if the current user does not own the dial.
+func deleteDialPeriod(ctx context.Context, tx *Tx, id int) error {
+	// Verify period exists & the current user owns the dial.
+	period, err := findDialPeriodByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if err := attachDialPeriodAssociations(ctx, tx, period); err != nil {
+		return err
+	} else if !wtf.CanEditDialPeriod(ctx, period) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can delete a period.")
+	} else if err := checkDialNotArchived(ctx, tx, period.DialID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_periods WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the deletion in the dial's audit log.
+	period.Dial = nil
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialPeriodDelete,
+		TargetType: wtf.AuditTargetDialPeriod,
+		TargetID:   period.ID,
+		DialID:     period.DialID,
+	}, period, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// attachDialPeriodAssociations attaches the dial to the period.
+func attachDialPeriodAssociations(ctx context.Context, tx *Tx, period *wtf.DialPeriod) (err error) {
+	if period.Dial, err = findDialByID(ctx, tx, period.DialID); err != nil {
+		return fmt.Errorf("attach dial period dial: %w", err)
+	}
+	return nil
+}
+
+// dialValueSummary represents the time-weighted summary of a value history.
+type dialValueSummary struct {
+	Average        float64
+	Peak           int
+	AboveThreshold time.Duration
+}
+
+// summarizeDialValueChanges folds a time-ordered list of value changes between
+// start & end into a summary. The initial value is the value in effect at
+// start. Only values in effect for some amount of time count toward the peak.
+func summarizeDialValueChanges(initial int, changes []dialValueChange, start, end time.Time, threshold int) dialValueSummary {
+	var summary dialValueSummary
+	var sum float64
+	var seen bool
+
+	value, t := initial, start
+	observe := func(until time.Time) {
+		if !until.After(t) {
+			return
+		}
+		if !seen || value > summary.Peak {
+			summary.Peak, seen = value, true
+		}
+		if value > threshold {
+			summary.AboveThreshold += until.Sub(t)
+		}
+		sum += float64(value) * float64(until.Sub(t))
+		t = until
+	}
+
+	for _, change := range changes {
+		observe(change.Timestamp)
+		value = change.Value
+	}
+	observe(end)
+
+	if d := end.Sub(start); d > 0 {
+		summary.Average = sum / float64(d)
+	}
+	return summary
+}
@@ -0,0 +1,239 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialPeriodService_CloseDialPeriod(t *testing.T) {
+	// Ensure closing a period stores a summary of the dial & its members.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialPeriodService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now = start.Add(1 * time.Hour)
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 80})
+
+		now = start.Add(2 * time.Hour)
+		if err := sqlite.NewDialService(db).SetDialMembershipValue(ctx0, dial.ID, 20, "rough day"); err != nil {
+			t.Fatal(err)
+		}
+
+		now = start.Add(5 * time.Hour)
+		period := &wtf.DialPeriod{DialID: dial.ID, Name: " Sprint 1 ", StartAt: start, EndAt: start.Add(4 * time.Hour), Threshold: 45}
+		if err := s.CloseDialPeriod(ctx0, period); err != nil {
+			t.Fatal(err)
+		} else if got, want := period.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := period.Name, "Sprint 1"; got != want {
+			t.Fatalf("Name=%v, want %v", got, want)
+		} else if got, want := period.Average, 35.0; got != want {
+			t.Fatalf("Average=%v, want %v", got, want)
+		} else if got, want := period.Peak, 50; got != want {
+			t.Fatalf("Peak=%v, want %v", got, want)
+		} else if got, want := period.MinutesAboveThreshold, 120; got != want {
+			t.Fatalf("MinutesAboveThreshold=%v, want %v", got, want)
+		} else if got, want := period.AboveThresholdRatio(), 0.5; got != want {
+			t.Fatalf("AboveThresholdRatio()=%v, want %v", got, want)
+		} else if period.Dial == nil || period.Dial.ID != dial.ID {
+			t.Fatalf("unexpected dial: %#v", period.Dial)
+		}
+
+		// Members are only summarized from when they joined.
+		if m := period.Member(user0.ID); m == nil || m.Name != "jane" || m.Average != 10 || m.Peak != 20 {
+			t.Fatalf("unexpected member: %#v", m)
+		} else if m := period.Member(user1.ID); m == nil || m.Average != 80 || m.Peak != 80 {
+			t.Fatalf("unexpected member: %#v", m)
+		} else if len(period.Notes) != 1 || period.Notes[0].Note != "rough day" || period.Notes[0].UserID != user0.ID || period.Notes[0].Value != 20 {
+			t.Fatalf("unexpected notes: %#v", period.Notes)
+		}
+
+		// Later changes do not affect the stored summary.
+		MustSetDialMembershipValue(t, ctx0, db, 1, 100)
+		if other, err := s.FindDialPeriodByID(ctx1, period.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Average != 35 || other.Peak != 50 || len(other.Members) != 2 || len(other.Notes) != 1 {
+			t.Fatalf("unexpected period: %#v", other)
+		} else if !other.StartAt.Equal(period.StartAt) || !other.EndAt.Equal(period.EndAt) || !other.CreatedAt.Equal(now) {
+			t.Fatalf("unexpected period times: %#v", other)
+		}
+	})
+
+	// Ensure summaries carry forward the value in effect before the period
+	// after older raw values have been pruned.
+	t.Run("Pruned", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialPeriodService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now = start.Add(1 * time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 40)
+		now = start.Add(2 * time.Hour)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 60)
+
+		// Roll up & prune raw values older than a day.
+		now = start.Add(72 * time.Hour)
+		db.DialValueRetention = 24 * time.Hour
+		if err := db.UpdateDialValueRollups(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		period := &wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start.Add(48 * time.Hour), EndAt: start.Add(60 * time.Hour), Threshold: 50}
+		if err := s.CloseDialPeriod(ctx0, period); err != nil {
+			t.Fatal(err)
+		} else if period.Average != 60 || period.Peak != 60 || period.MinutesAboveThreshold != 720 {
+			t.Fatalf("unexpected summary: avg=%v peak=%v above=%v", period.Average, period.Peak, period.MinutesAboveThreshold)
+		} else if m := period.Member(1); m == nil || m.Average != 60 || m.Peak != 60 {
+			t.Fatalf("unexpected member: %#v", m)
+		}
+
+		// Periods starting before the retention period are rejected.
+		period = &wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start, EndAt: start.Add(60 * time.Hour), Threshold: 50}
+		if err := s.CloseDialPeriod(ctx0, period); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Period must start within the last 24h0m0s while dial values are retained.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure periods are listed most recent first for dial members only.
+	t.Run("FindDialPeriods", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialPeriodService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := start.Add(48 * time.Hour)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now = start.Add(96 * time.Hour)
+		for i, name := range []string{"A", "B"} {
+			begin := dial.CreatedAt.Add(time.Duration(i) * 24 * time.Hour)
+			if err := s.CloseDialPeriod(ctx0, &wtf.DialPeriod{DialID: dial.ID, Name: name, StartAt: begin, EndAt: begin.Add(24 * time.Hour), Threshold: 50}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if periods, n, err := s.FindDialPeriods(ctx0, wtf.DialPeriodFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 2 || periods[0].Name != "B" || periods[1].Name != "A" {
+			t.Fatalf("unexpected periods: n=%d", n)
+		}
+		if _, n, err := s.FindDialPeriods(ctx1, wtf.DialPeriodFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("unexpected n: %d", n)
+		}
+	})
+
+	// Ensure invalid periods are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialPeriodService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		now = start.Add(24 * time.Hour)
+
+		for _, tt := range []struct {
+			period *wtf.DialPeriod
+			msg    string
+		}{
+			{&wtf.DialPeriod{DialID: dial.ID, StartAt: start, EndAt: now}, `Period name required.`},
+			{&wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: now, EndAt: start}, `Period must end after it starts.`},
+			{&wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start, EndAt: now.Add(time.Hour)}, `Period cannot end in the future.`},
+			{&wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start, EndAt: now, Threshold: 101}, `Period threshold must be between 0 & 100.`},
+			{&wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start.Add(-2 * time.Hour), EndAt: start.Add(-time.Hour)}, `Period must end after the dial was created.`},
+		} {
+			if err := s.CloseDialPeriod(ctx0, tt.period); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+	})
+
+	// Ensure only the owner can close a period.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialPeriodService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		now = start.Add(time.Hour)
+
+		if err := s.CloseDialPeriod(ctx1, &wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start, EndAt: now}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can close a period.` {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialPeriodService_DeleteDialPeriod(t *testing.T) {
+	// Ensure the owner can delete a period.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialPeriodService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		now := start
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		now = start.Add(time.Hour)
+
+		period := &wtf.DialPeriod{DialID: dial.ID, Name: "P", StartAt: start, EndAt: now}
+		if err := s.CloseDialPeriod(ctx0, period); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.DeleteDialPeriod(ctx1, period.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can delete a period.` {
+			t.Fatal(err)
+		} else if err := s.DeleteDialPeriod(ctx0, period.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDialPeriodByID(ctx0, period.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
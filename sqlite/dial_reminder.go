@@ -0,0 +1,808 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.DialReminderService = (*DialReminderService)(nil)
+
+// DialReminderService represents a service for managing dial check-in schedules.
+type DialReminderService struct {
+	db *DB
+}
+
+// NewDialReminderService returns a new instance of DialReminderService.
+func NewDialReminderService(db *DB) *DialReminderService {
+	return &DialReminderService{db: db}
+}
+
+// FindDialReminderScheduleByID retrieves a single schedule by ID. Returns
+// ENOTFOUND if the schedule does not exist or the user is not a member of the
+// schedule's dial.
+func (s *DialReminderService) FindDialReminderScheduleByID(ctx context.Context, id int) (*wtf.DialReminderSchedule, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return findDialReminderScheduleByID(ctx, tx, id)
+}
+
+// FindDialReminderSchedules retrieves a list of schedules based on a filter.
+// Only returns schedules for dials that the user is a member of.
+func (s *DialReminderService) FindDialReminderSchedules(ctx context.Context, filter wtf.DialReminderScheduleFilter) ([]*wtf.DialReminderSchedule, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	return findDialReminderSchedules(ctx, tx, filter)
+}
+
+// CreateDialReminderSchedule creates a new schedule on a dial. Only the dial
+// owner can create schedules.
+func (s *DialReminderService) CreateDialReminderSchedule(ctx context.Context, schedule *wtf.DialReminderSchedule) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createDialReminderSchedule(ctx, tx, schedule); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateDialReminderSchedule updates an existing schedule. Only the dial owner
+// can update schedules.
+func (s *DialReminderService) UpdateDialReminderSchedule(ctx context.Context, id int, upd wtf.DialReminderScheduleUpdate) (*wtf.DialReminderSchedule, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	schedule, err := updateDialReminderSchedule(ctx, tx, id, upd)
+	if err != nil {
+		return schedule, err
+	} else if err := tx.Commit(); err != nil {
+		return schedule, err
+	}
+	return schedule, nil
+}
+
+// DeleteDialReminderSchedule permanently deletes a schedule & its reminder
+// history. Only the dial owner can delete schedules.
+func (s *DialReminderService) DeleteDialReminderSchedule(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteDialReminderSchedule(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindDialReminders retrieves a list of reminders based on a filter. Only
+// returns the user's own reminders & reminders on dials the user owns.
+func (s *DialReminderService) FindDialReminders(ctx context.Context, filter wtf.DialReminderFilter) ([]*wtf.DialReminder, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Fetch list of matching reminders.
+	reminders, n, err := findDialReminders(ctx, tx, filter)
+	if err != nil {
+		return reminders, n, err
+	}
+
+	// Attach the reminded member to each reminder.
+	for _, reminder := range reminders {
+		if reminder.User, err = findUserByID(ctx, tx, reminder.UserID); err != nil {
+			return reminders, n, fmt.Errorf("attach reminder user: %w", err)
+		}
+	}
+	return reminders, n, nil
+}
+
+// SendDialReminders reminds members on every schedule whose most recent
+// check-in has not been handled yet. Webhook & email reminders are left
+// pending for DeliverDialReminders(). This is called periodically by the
+// wtfd scheduler but can also be called directly, such as from tests.
+func (s *DialReminderService) SendDialReminders(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Members of archived dials are not reminded as they cannot check in.
+	schedules, _, err := queryDialReminderSchedules(ctx, tx, []string{"dial_id IN (SELECT id FROM dials WHERE archived_at IS NULL)"}, nil, "")
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if err := sendDialReminders(ctx, tx, schedule); err != nil {
+			return fmt.Errorf("send dial reminders: id=%d err=%w", schedule.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// DeliverDialReminders sends pending reminders to their webhook & email
+// targets. Notifiers are called outside of a transaction so slow targets do
+// not block writes. Each reminder is attempted once & is marked as failed if
+// any target could not be delivered to. This is called periodically by the
+// wtfd scheduler but can also be called directly, such as from tests.
+func (s *DialReminderService) DeliverDialReminders(ctx context.Context) error {
+	reminders, err := s.db.findPendingDialReminders(ctx)
+	if err != nil {
+		return fmt.Errorf("find pending reminders: %w", err)
+	}
+
+	for _, reminder := range reminders {
+		reminder.Status, reminder.Error = wtf.DialReminderStatusSent, ""
+		if err := s.db.notifyDialReminder(ctx, reminder); err != nil {
+			reminder.Status, reminder.Error = wtf.DialReminderStatusFailed, err.Error()
+		}
+
+		if err := s.db.updateDialReminderStatus(ctx, reminder); err != nil {
+			return fmt.Errorf("update reminder status: id=%d err=%w", reminder.ID, err)
+		}
+	}
+	return nil
+}
+
+// findPendingDialReminders returns all reminders awaiting delivery along with
+// their schedule, dial & member. This bypasses permission checks as it is used
+// by the background delivery job.
+func (db *DB) findPendingDialReminders(ctx context.Context) ([]*wtf.DialReminder, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reminders, _, err := queryDialReminders(ctx, tx, []string{"status = ?"}, []interface{}{wtf.DialReminderStatusPending}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reminder := range reminders {
+		schedules, _, err := queryDialReminderSchedules(ctx, tx, []string{"s.id = ?"}, []interface{}{reminder.ScheduleID}, "")
+		if err != nil {
+			return nil, err
+		} else if len(schedules) == 0 {
+			return nil, fmt.Errorf("reminder schedule not found: id=%d", reminder.ScheduleID)
+		}
+		reminder.Schedule = schedules[0]
+
+		reminder.Dial = &wtf.Dial{ID: reminder.DialID}
+		if err := tx.QueryRowContext(ctx, `SELECT user_id, name, value FROM dials WHERE id = ?`, reminder.DialID).Scan(
+			&reminder.Dial.UserID,
+			&reminder.Dial.Name,
+			&reminder.Dial.Value,
+		); err != nil {
+			return nil, FormatError(err)
+		}
+
+		if reminder.User, err = findUserByID(ctx, tx, reminder.UserID); err != nil {
+			return nil, fmt.Errorf("find reminder user: %w", err)
+		}
+	}
+	return reminders, nil
+}
+
+// notifyDialReminder sends a reminder to each of its schedule's external
+// targets. Returns an error describing every target that could not be
+// delivered to.
+func (db *DB) notifyDialReminder(ctx context.Context, reminder *wtf.DialReminder) error {
+	var errs []string
+	if reminder.Schedule.WebhookURL != "" {
+		if db.DialReminderWebhookNotifier == nil {
+			errs = append(errs, "webhook: notifier not configured")
+		} else if err := db.DialReminderWebhookNotifier.NotifyDialReminder(ctx, reminder); err != nil {
+			errs = append(errs, fmt.Sprintf("webhook: %s", err))
+		}
+	}
+	if reminder.Schedule.NotifyEmail {
+		if reminder.User.Email == "" {
+			errs = append(errs, "email: member has no email address")
+		} else if db.DialReminderEmailNotifier == nil {
+			errs = append(errs, "email: notifier not configured")
+		} else if err := db.DialReminderEmailNotifier.NotifyDialReminder(ctx, reminder); err != nil {
+			errs = append(errs, fmt.Sprintf("email: %s", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// updateDialReminderStatus saves the delivery status of a reminder.
+func (db *DB) updateDialReminderStatus(ctx context.Context, reminder *wtf.DialReminder) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_reminders
+		SET status = ?,
+		    error = ?
+		WHERE id = ?
+	`,
+		reminder.Status,
+		reminder.Error,
+		reminder.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return tx.Commit()
+}
+
+// findDialReminderScheduleByID is a helper function to retrieve a schedule by
+// ID. Returns ENOTFOUND if schedule doesn't exist.
+func findDialReminderScheduleByID(ctx context.Context, tx *Tx, id int) (*wtf.DialReminderSchedule, error) {
+	schedules, _, err := findDialReminderSchedules(ctx, tx, wtf.DialReminderScheduleFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(schedules) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial reminder schedule not found."}
+	}
+	return schedules[0], nil
+}
+
+// findDialReminderSchedules retrieves a list of matching schedules. Also
+// returns a total matching count which may differ from the number of results
+// if filter.Limit is set.
+func findDialReminderSchedules(ctx context.Context, tx *Tx, filter wtf.DialReminderScheduleFilter) (_ []*wtf.DialReminderSchedule, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "s.id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "s.dial_id = ?"), append(args, *v)
+	}
+
+	// Limit to schedules on dials the user is a member of.
+	where = append(where, `s.dial_id IN (SELECT dial_id FROM dial_memberships WHERE user_id = ? AND status = ?)`)
+	args = append(args, wtf.UserIDFromContext(ctx), wtf.DialMembershipStatusActive)
+
+	return queryDialReminderSchedules(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// queryDialReminderSchedules executes a query for schedules with the given
+// WHERE clause parts. This performs no permission checks so callers must add
+// them.
+func queryDialReminderSchedules(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.DialReminderSchedule, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    s.id,
+		    s.dial_id,
+		    s.weekdays,
+		    s.hour,
+		    s.minute,
+		    s.timezone,
+		    s.notify_in_app,
+		    s.notify_email,
+		    s.webhook_url,
+		    s.last_check_in_at,
+		    s.created_at,
+		    s.updated_at,
+		    COUNT(*) OVER()
+		FROM dial_reminder_schedules s
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY s.id ASC
+		`+limitOffset,
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialReminderSchedule objects.
+	schedules := make([]*wtf.DialReminderSchedule, 0)
+	for rows.Next() {
+		var schedule wtf.DialReminderSchedule
+		var weekdays string
+		if err := rows.Scan(
+			&schedule.ID,
+			&schedule.DialID,
+			&weekdays,
+			&schedule.Hour,
+			&schedule.Minute,
+			&schedule.Timezone,
+			&schedule.NotifyInApp,
+			&schedule.NotifyEmail,
+			&schedule.WebhookURL,
+			(*NullTime)(&schedule.LastCheckInAt),
+			(*NullTime)(&schedule.CreatedAt),
+			(*NullTime)(&schedule.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		if schedule.Weekdays, err = parseWeekdays(weekdays); err != nil {
+			return nil, 0, fmt.Errorf("parse weekdays: id=%d err=%w", schedule.ID, err)
+		}
+		schedules = append(schedules, &schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return schedules, n, nil
+}
+
+// createDialReminderSchedule creates a new schedule on a dial.
+// Returns EUNAUTHORIZED if the current user is not the dial owner.
+func createDialReminderSchedule(ctx context.Context, tx *Tx, schedule *wtf.DialReminderSchedule) error {
+	schedule.Weekdays = normalizeWeekdays(schedule.Weekdays)
+	schedule.LastCheckInAt = time.Time{}
+	schedule.CreatedAt = tx.now
+	schedule.UpdatedAt = schedule.CreatedAt
+
+	// Perform basic field validation.
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	// Only the dial owner can create schedules.
+	if dial, err := findDialByID(ctx, tx, schedule.DialID); err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can create check-in schedules.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return err
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_reminder_schedules (
+			dial_id,
+			weekdays,
+			hour,
+			minute,
+			timezone,
+			notify_in_app,
+			notify_email,
+			webhook_url,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		schedule.DialID,
+		formatWeekdays(schedule.Weekdays),
+		schedule.Hour,
+		schedule.Minute,
+		schedule.Timezone,
+		schedule.NotifyInApp,
+		schedule.NotifyEmail,
+		schedule.WebhookURL,
+		(*NullTime)(&schedule.CreatedAt),
+		(*NullTime)(&schedule.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new schedule ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	schedule.ID = int(id)
+
+	// Record the new schedule in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialReminderScheduleCreate,
+		TargetType: wtf.AuditTargetDialReminderSchedule,
+		TargetID:   schedule.ID,
+		DialID:     schedule.DialID,
+	}, nil, schedule); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// updateDialReminderSchedule updates fields on a schedule by ID.
+// Returns EUNAUTHORIZED if the current user is not the dial owner.
+func updateDialReminderSchedule(ctx context.Context, tx *Tx, id int, upd wtf.DialReminderScheduleUpdate) (*wtf.DialReminderSchedule, error) {
+	// Fetch current object state & verify the current user owns the dial.
+	schedule, err := findDialReminderScheduleByID(ctx, tx, id)
+	if err != nil {
+		return schedule, err
+	} else if dial, err := findDialByID(ctx, tx, schedule.DialID); err != nil {
+		return schedule, err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return schedule, wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can update check-in schedules.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return schedule, err
+	}
+
+	// Save state of schedule for the audit log.
+	prev := *schedule
+
+	// Update fields, if set.
+	if v := upd.Weekdays; v != nil {
+		schedule.Weekdays = normalizeWeekdays(*v)
+	}
+	if v := upd.Hour; v != nil {
+		schedule.Hour = *v
+	}
+	if v := upd.Minute; v != nil {
+		schedule.Minute = *v
+	}
+	if v := upd.Timezone; v != nil {
+		schedule.Timezone = *v
+	}
+	if v := upd.NotifyInApp; v != nil {
+		schedule.NotifyInApp = *v
+	}
+	if v := upd.NotifyEmail; v != nil {
+		schedule.NotifyEmail = *v
+	}
+	if v := upd.WebhookURL; v != nil {
+		schedule.WebhookURL = *v
+	}
+	schedule.UpdatedAt = tx.now
+
+	// Perform basic field validation.
+	if err := schedule.Validate(); err != nil {
+		return schedule, err
+	}
+
+	// Execute update query.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_reminder_schedules
+		SET weekdays = ?,
+		    hour = ?,
+		    minute = ?,
+		    timezone = ?,
+		    notify_in_app = ?,
+		    notify_email = ?,
+		    webhook_url = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		formatWeekdays(schedule.Weekdays),
+		schedule.Hour,
+		schedule.Minute,
+		schedule.Timezone,
+		schedule.NotifyInApp,
+		schedule.NotifyEmail,
+		schedule.WebhookURL,
+		(*NullTime)(&schedule.UpdatedAt),
+		id,
+	); err != nil {
+		return schedule, FormatError(err)
+	}
+
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialReminderScheduleUpdate,
+		TargetType: wtf.AuditTargetDialReminderSchedule,
+		TargetID:   schedule.ID,
+		DialID:     schedule.DialID,
+	}, &prev, schedule); err != nil {
+		return schedule, fmt.Errorf("create audit entry: %w", err)
+	}
+	return schedule, nil
+}
+
+// deleteDialReminderSchedule permanently removes a schedule by ID. Reminders
+// are removed by cascade. Returns EUNAUTHORIZED if the current user is not the
+// dial owner.
+func deleteDialReminderSchedule(ctx context.Context, tx *Tx, id int) error {
+	// Verify schedule exists & the current user owns the dial.
+	schedule, err := findDialReminderScheduleByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if dial, err := findDialByID(ctx, tx, schedule.DialID); err != nil {
+		return err
+	} else if !wtf.CanEditDial(ctx, dial) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can delete check-in schedules.")
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_reminder_schedules WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the deleted schedule in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialReminderScheduleDelete,
+		TargetType: wtf.AuditTargetDialReminderSchedule,
+		TargetID:   schedule.ID,
+		DialID:     schedule.DialID,
+	}, schedule, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// sendDialReminders reminds members for the schedule's most recent check-in
+// if it has not been handled yet. Active members who are not away & have not
+// updated their value since the previous check-in are reminded. Check-ins
+// before the schedule was created are skipped so that creating a schedule
+// does not immediately send reminders.
+func sendDialReminders(ctx context.Context, tx *Tx, schedule *wtf.DialReminderSchedule) error {
+	checkIn := schedule.PrevCheckIn(tx.now)
+	if checkIn.IsZero() || checkIn.Before(schedule.CreatedAt) || !checkIn.After(schedule.LastCheckInAt) {
+		return nil
+	}
+	since := schedule.PrevCheckIn(checkIn.Add(-time.Second))
+
+	var dialName string
+	if err := tx.QueryRowContext(ctx, `SELECT name FROM dials WHERE id = ?`, schedule.DialID).Scan(&dialName); err != nil {
+		return FormatError(err)
+	}
+
+	// Find members who have not checked in since the previous check-in.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id
+		FROM dial_memberships
+		WHERE dial_id = ?
+		  AND status = ?
+		  AND updated_at < ?
+		  AND (away_until IS NULL OR away_until <= ?)
+		ORDER BY user_id
+	`,
+		schedule.DialID,
+		wtf.DialMembershipStatusActive,
+		(*NullTime)(&since),
+		(*NullTime)(&tx.now),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		reminder := &wtf.DialReminder{
+			ScheduleID: schedule.ID,
+			DialID:     schedule.DialID,
+			UserID:     userID,
+			CheckInAt:  checkIn,
+			Status:     wtf.DialReminderStatusSent,
+		}
+		if schedule.NotifyEmail || schedule.WebhookURL != "" {
+			reminder.Status = wtf.DialReminderStatusPending
+		}
+		if err := createDialReminder(ctx, tx, reminder); err != nil {
+			return fmt.Errorf("create reminder: %w", err)
+		}
+
+		if schedule.NotifyInApp {
+			tx.db.EventService.PublishEvent(userID, wtf.Event{
+				Type: wtf.EventTypeDialCheckInReminder,
+				Payload: &wtf.DialCheckInReminderPayload{
+					ID:         reminder.ID,
+					ScheduleID: schedule.ID,
+					DialID:     schedule.DialID,
+					DialName:   dialName,
+					CheckInAt:  checkIn,
+				},
+			})
+		}
+	}
+
+	// Mark the check-in as handled.
+	schedule.LastCheckInAt = checkIn
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE dial_reminder_schedules
+		SET last_check_in_at = ?
+		WHERE id = ?
+	`,
+		(*NullTime)(&schedule.LastCheckInAt),
+		schedule.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// findDialReminders retrieves a list of matching reminders. Also returns a
+// total matching count which may differ from the number of results if
+// filter.Limit is set.
+func findDialReminders(ctx context.Context, tx *Tx, filter wtf.DialReminderFilter) (_ []*wtf.DialReminder, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.ScheduleID; v != nil {
+		where, args = append(where, "schedule_id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "dial_id = ?"), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+	if v := filter.Status; v != nil {
+		where, args = append(where, "status = ?"), append(args, *v)
+	}
+
+	// Limit to the user's own reminders & reminders on dials they own.
+	userID := wtf.UserIDFromContext(ctx)
+	where = append(where, `(user_id = ? OR dial_id IN (SELECT id FROM dials WHERE user_id = ?))`)
+	args = append(args, userID, userID)
+
+	return queryDialReminders(ctx, tx, where, args, FormatLimitOffset(filter.Limit, filter.Offset))
+}
+
+// queryDialReminders executes a query for reminders with the given WHERE
+// clause parts. This performs no permission checks so callers must add them.
+func queryDialReminders(ctx context.Context, tx *Tx, where []string, args []interface{}, limitOffset string) (_ []*wtf.DialReminder, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    schedule_id,
+		    dial_id,
+		    user_id,
+		    check_in_at,
+		    status,
+		    error,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM dial_reminders
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at DESC, id DESC
+		`+limitOffset,
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialReminder objects.
+	reminders := make([]*wtf.DialReminder, 0)
+	for rows.Next() {
+		var reminder wtf.DialReminder
+		if err := rows.Scan(
+			&reminder.ID,
+			&reminder.ScheduleID,
+			&reminder.DialID,
+			&reminder.UserID,
+			(*NullTime)(&reminder.CheckInAt),
+			&reminder.Status,
+			&reminder.Error,
+			(*NullTime)(&reminder.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		reminders = append(reminders, &reminder)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reminders, n, nil
+}
+
+// createDialReminder inserts a reminder into the schedule's history.
+func createDialReminder(ctx context.Context, tx *Tx, reminder *wtf.DialReminder) error {
+	reminder.CreatedAt = tx.now
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_reminders (
+			schedule_id,
+			dial_id,
+			user_id,
+			check_in_at,
+			status,
+			error,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		reminder.ScheduleID,
+		reminder.DialID,
+		reminder.UserID,
+		(*NullTime)(&reminder.CheckInAt),
+		reminder.Status,
+		reminder.Error,
+		(*NullTime)(&reminder.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new reminder ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	reminder.ID = int(id)
+
+	return nil
+}
+
+// normalizeWeekdays returns the weekdays sorted & without duplicates.
+func normalizeWeekdays(weekdays []time.Weekday) []time.Weekday {
+	a := make([]time.Weekday, 0, len(weekdays))
+	for _, weekday := range weekdays {
+		if !containsWeekday(a, weekday) {
+			a = append(a, weekday)
+		}
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	return a
+}
+
+func containsWeekday(a []time.Weekday, weekday time.Weekday) bool {
+	for _, v := range a {
+		if v == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// formatWeekdays encodes weekdays as comma-separated day numbers for storage.
+func formatWeekdays(weekdays []time.Weekday) string {
+	a := make([]string, len(weekdays))
+	for i, weekday := range weekdays {
+		a[i] = strconv.Itoa(int(weekday))
+	}
+	return strings.Join(a, ",")
+}
+
+// parseWeekdays decodes comma-separated day numbers from storage.
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	if s == "" {
+		return []time.Weekday{}, nil
+	}
+
+	a := strings.Split(s, ",")
+	weekdays := make([]time.Weekday, len(a))
+	for i := range a {
+		v, err := strconv.Atoi(a[i])
+		if err != nil {
+			return nil, err
+		}
+		weekdays[i] = time.Weekday(v)
+	}
+	return weekdays, nil
+}
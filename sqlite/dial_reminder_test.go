@@ -0,0 +1,360 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialReminderService_CreateDialReminderSchedule(t *testing.T) {
+	// Ensure a schedule can be created by the dial owner.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		schedule := &wtf.DialReminderSchedule{
+			DialID:      dial.ID,
+			Weekdays:    []time.Weekday{time.Friday, time.Monday, time.Friday},
+			Hour:        10,
+			Minute:      30,
+			Timezone:    "America/Denver",
+			NotifyInApp: true,
+		}
+		if err := s.CreateDialReminderSchedule(ctx0, schedule); err != nil {
+			t.Fatal(err)
+		} else if got, want := schedule.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := schedule.Weekdays, []time.Weekday{time.Monday, time.Friday}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Weekdays=%v, want %v", got, want)
+		} else if got, want := schedule.Description(), "Mon, Fri at 10:30 America/Denver"; got != want {
+			t.Fatalf("Description()=%v, want %v", got, want)
+		}
+
+		// Fetch schedule from database & compare.
+		if other, err := s.FindDialReminderScheduleByID(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(schedule, other) {
+			t.Fatalf("mismatch: %#v != %#v", schedule, other)
+		}
+	})
+
+	// Ensure invalid schedules are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		for _, tt := range []struct {
+			schedule wtf.DialReminderSchedule
+			msg      string
+		}{
+			{wtf.DialReminderSchedule{Timezone: "UTC", NotifyInApp: true}, `At least one check-in day required.`},
+			{wtf.DialReminderSchedule{Weekdays: []time.Weekday{time.Monday}, Hour: 24, Timezone: "UTC", NotifyInApp: true}, `Invalid check-in time.`},
+			{wtf.DialReminderSchedule{Weekdays: []time.Weekday{time.Monday}, Timezone: "Mars/Olympus", NotifyInApp: true}, `Unknown time zone.`},
+			{wtf.DialReminderSchedule{Weekdays: []time.Weekday{time.Monday}, Timezone: "UTC"}, `At least one reminder notification target required.`},
+			{wtf.DialReminderSchedule{Weekdays: []time.Weekday{time.Monday}, Timezone: "UTC", WebhookURL: "ftp://example.com"}, `Invalid reminder webhook URL.`},
+		} {
+			tt.schedule.DialID = dial.ID
+			if err := s.CreateDialReminderSchedule(ctx0, &tt.schedule); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+				t.Fatalf("unexpected error: %v, want %q", err, tt.msg)
+			}
+		}
+	})
+
+	// Ensure members who do not own the dial cannot create schedules.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.CreateDialReminderSchedule(ctx1, &wtf.DialReminderSchedule{
+			DialID:      dial.ID,
+			Weekdays:    []time.Weekday{time.Monday},
+			Timezone:    "UTC",
+			NotifyInApp: true,
+		}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialReminderService_UpdateDialReminderSchedule(t *testing.T) {
+	// Ensure the owner can update & delete a schedule.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		schedule := MustCreateDialReminderSchedule(t, ctx0, db, &wtf.DialReminderSchedule{DialID: dial.ID})
+
+		weekdays, hour := []time.Weekday{time.Sunday}, 9
+		if other, err := s.UpdateDialReminderSchedule(ctx0, schedule.ID, wtf.DialReminderScheduleUpdate{Weekdays: &weekdays, Hour: &hour}); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Description(), "Sun at 09:00 UTC"; got != want {
+			t.Fatalf("Description()=%v, want %v", got, want)
+		}
+
+		if err := s.DeleteDialReminderSchedule(ctx0, schedule.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDialReminderScheduleByID(ctx0, schedule.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	// Ensure members who do not own the dial cannot update schedules.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		schedule := MustCreateDialReminderSchedule(t, ctx0, db, &wtf.DialReminderSchedule{DialID: dial.ID})
+
+		hour := 9
+		if _, err := s.UpdateDialReminderSchedule(ctx1, schedule.ID, wtf.DialReminderScheduleUpdate{Hour: &hour}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatal(err)
+		} else if err := s.DeleteDialReminderSchedule(ctx1, schedule.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDialReminderService_SendDialReminders(t *testing.T) {
+	// Ensure members who have not updated their value since the previous
+	// check-in are reminded once per check-in & that away members are skipped.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		// Monday at 09:00 in Denver.
+		start := time.Date(2000, time.January, 3, 16, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		_, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "susy"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		MustCreateDialMembership(t, ctx2, db, &wtf.DialMembership{DialID: dial.ID})
+		if _, err := sqlite.NewDialMembershipService(db).SetDialMembershipAway(ctx2, dial.ID, start.AddDate(0, 1, 0)); err != nil {
+			t.Fatal(err)
+		}
+		MustCreateDialReminderSchedule(t, ctx0, db, &wtf.DialReminderSchedule{
+			DialID:      dial.ID,
+			Weekdays:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			Hour:        10,
+			Timezone:    "America/Denver",
+			NotifyInApp: true,
+		})
+
+		var events []int
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if event.Type == wtf.EventTypeDialCheckInReminder {
+					events = append(events, userID)
+				}
+			},
+		}
+
+		// No check-in has passed since the schedule was created.
+		if err := s.SendDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(events), 0; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		}
+
+		// Everyone joined after the previous check-in so no one is reminded
+		// at Monday's check-in.
+		db.Now = func() time.Time { return start.Add(1 * time.Hour) }
+		if err := s.SendDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(events), 0; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		}
+
+		// Jim checks in after Monday's check-in.
+		db.Now = func() time.Time { return start.Add(2 * time.Hour) }
+		MustSetDialMembershipValue(t, ctx1, db, 2, 30)
+
+		// Only jane is reminded on Tuesday as susy is away.
+		db.Now = func() time.Time { return start.Add(25 * time.Hour) }
+		if err := s.SendDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := events, []int{1}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("events=%v, want %v", got, want)
+		}
+
+		// Reminders are only sent once per check-in.
+		db.Now = func() time.Time { return start.Add(25*time.Hour + 5*time.Minute) }
+		if err := s.SendDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(events), 1; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		}
+
+		reminders, n, err := s.FindDialReminders(ctx0, wtf.DialReminderFilter{DialID: &dial.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := reminders[0].User.Name, "jane"; got != want {
+			t.Fatalf("User.Name=%v, want %v", got, want)
+		} else if got, want := reminders[0].CheckInAt, start.Add(25*time.Hour); !got.Equal(want) {
+			t.Fatalf("CheckInAt=%v, want %v", got, want)
+		} else if got, want := reminders[0].Status, wtf.DialReminderStatusSent; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		}
+
+		// Other members cannot see jane's reminder.
+		if _, n, err := s.FindDialReminders(ctx1, wtf.DialReminderFilter{DialID: &dial.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 0; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+	})
+}
+
+func TestDialReminderService_DeliverDialReminders(t *testing.T) {
+	// Ensure pending reminders are delivered to their external targets & that
+	// failures are recorded on the reminder.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialReminderService(db)
+
+		var webhooks, emails []*wtf.DialReminder
+		db.DialReminderWebhookNotifier = &mock.DialReminderNotifier{
+			NotifyDialReminderFn: func(ctx context.Context, reminder *wtf.DialReminder) error {
+				webhooks = append(webhooks, reminder)
+				return nil
+			},
+		}
+		db.DialReminderEmailNotifier = &mock.DialReminderNotifier{
+			NotifyDialReminderFn: func(ctx context.Context, reminder *wtf.DialReminder) error {
+				emails = append(emails, reminder)
+				return errors.New("connection refused")
+			},
+		}
+
+		// Sunday at 12:00 UTC.
+		start := time.Date(2000, time.January, 2, 12, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@example.com"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+		MustCreateDialReminderSchedule(t, ctx0, db, &wtf.DialReminderSchedule{
+			DialID:      dial.ID,
+			Weekdays:    []time.Weekday{time.Monday, time.Tuesday},
+			Hour:        10,
+			NotifyEmail: true,
+			WebhookURL:  "https://example.com/hook",
+		})
+
+		// Both members joined after the check-in before Monday's so they are only
+		// reminded on Tuesday.
+		db.Now = func() time.Time { return start.AddDate(0, 0, 1) }
+		if err := s.SendDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		}
+		db.Now = func() time.Time { return start.AddDate(0, 0, 2) }
+		if err := s.SendDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// Reminders wait for the delivery job.
+		status := wtf.DialReminderStatusPending
+		if _, n, err := s.FindDialReminders(ctx0, wtf.DialReminderFilter{Status: &status}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		if err := s.DeliverDialReminders(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := len(webhooks), 2; got != want {
+			t.Fatalf("len(webhooks)=%v, want %v", got, want)
+		} else if got, want := webhooks[0].Dial.Name, "DIAL"; got != want {
+			t.Fatalf("Dial.Name=%v, want %v", got, want)
+		} else if got, want := webhooks[0].Schedule.WebhookURL, "https://example.com/hook"; got != want {
+			t.Fatalf("Schedule.WebhookURL=%v, want %v", got, want)
+		} else if got, want := len(emails), 1; got != want {
+			t.Fatalf("len(emails)=%v, want %v", got, want)
+		} else if got, want := emails[0].User.Email, "jane@example.com"; got != want {
+			t.Fatalf("User.Email=%v, want %v", got, want)
+		}
+
+		reminders, _, err := s.FindDialReminders(ctx0, wtf.DialReminderFilter{DialID: &dial.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(reminders), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+		for _, reminder := range reminders {
+			if got, want := reminder.Status, wtf.DialReminderStatusFailed; got != want {
+				t.Fatalf("Status=%v, want %v", got, want)
+			}
+
+			switch reminder.User.Name {
+			case "jane":
+				if got, want := reminder.Error, "email: connection refused"; got != want {
+					t.Fatalf("Error=%v, want %v", got, want)
+				}
+			case "jim":
+				if got, want := reminder.Error, "email: member has no email address"; got != want {
+					t.Fatalf("Error=%v, want %v", got, want)
+				}
+			}
+		}
+	})
+}
+
+// MustCreateDialReminderSchedule creates a schedule in the database. Unset
+// fields default to an in-app schedule on Mondays at midnight UTC.
+func MustCreateDialReminderSchedule(tb testing.TB, ctx context.Context, db *sqlite.DB, schedule *wtf.DialReminderSchedule) *wtf.DialReminderSchedule {
+	tb.Helper()
+	if len(schedule.Weekdays) == 0 {
+		schedule.Weekdays = []time.Weekday{time.Monday}
+	}
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	if !schedule.NotifyEmail && schedule.WebhookURL == "" {
+		schedule.NotifyInApp = true
+	}
+	if err := sqlite.NewDialReminderService(db).CreateDialReminderSchedule(ctx, schedule); err != nil {
+		tb.Fatal(err)
+	}
+	return schedule
+}
@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// ResetDialValues resets the value of every active member to the dial's reset
+// value on dials whose next scheduled reset has passed. Values are changed the
+// same way as a member update so the value history, audit log & events are
+// produced as usual. This is called periodically by the wtfd scheduler but
+// can also be called directly, such as from tests.
+func (s *DialMembershipService) ResetDialValues(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Find all dials with a reset that is due.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id
+		FROM dials
+		WHERE reset_interval_days > 0
+		  AND next_reset_at <= ?
+		  AND archived_at IS NULL
+		ORDER BY id
+	`,
+		(*NullTime)(&tx.now),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := resetDialValues(ctx, tx, id); err != nil {
+			return fmt.Errorf("reset dial values: id=%d err=%w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// resetDialValues resets the active members of a dial to its reset value,
+// records the reset & schedules the next one. Missed resets, such as while
+// the server was down, are collapsed into a single reset.
+func resetDialValues(ctx context.Context, tx *Tx, id int) error {
+	dials, _, err := queryDials(ctx, tx, []string{"id = ?"}, []interface{}{id}, "")
+	if err != nil {
+		return err
+	} else if len(dials) == 0 {
+		return &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	}
+	dial := dials[0]
+
+	// Fetch all members of the dial.
+	memberships, err := listDialMemberships(ctx, tx, dial.ID)
+	if err != nil {
+		return err
+	}
+
+	// Apply the reset to each active member as a regular value update.
+	// Pending members do not contribute to the dial so they are skipped.
+	reset := &wtf.DialReset{DialID: dial.ID, Value: dial.ResetValue, Timestamp: tx.now}
+	value, note := dial.ResetValue, wtf.DialResetNote
+	for _, membership := range memberships {
+		if membership.IsPending() {
+			continue
+		}
+
+		prevUpdatedAt := membership.UpdatedAt
+		if err := applyDialMembershipUpdate(ctx, tx, membership, wtf.DialMembershipUpdate{Value: &value, Note: &note}); err != nil {
+			return fmt.Errorf("reset membership: id=%d err=%w", membership.ID, err)
+		} else if !membership.UpdatedAt.Equal(prevUpdatedAt) {
+			reset.N++
+		}
+	}
+
+	// Record the reset so it can be marked in the dial's history.
+	if err := createDialReset(ctx, tx, reset); err != nil {
+		return fmt.Errorf("create dial reset: %w", err)
+	}
+
+	// Schedule the next reset.
+	prev := *dial
+	dial.NextResetAt = dial.NextResetAfter(tx.now).UTC()
+	if _, err := tx.ExecContext(ctx, `UPDATE dials SET next_reset_at = ? WHERE id = ?`, (*NullTime)(&dial.NextResetAt), dial.ID); err != nil {
+		return FormatError(err)
+	}
+
+	// Record the reset in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionDialReset,
+		TargetType: wtf.AuditTargetDial,
+		TargetID:   dial.ID,
+		DialID:     dial.ID,
+	}, &prev, dial); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Let members know their values were reset.
+	if err := publishDialEvent(ctx, tx, dial.ID, wtf.Event{
+		Type: wtf.EventTypeDialReset,
+		Payload: &wtf.DialResetPayload{
+			ID:       reset.ID,
+			DialID:   dial.ID,
+			DialName: dial.Name,
+			Value:    reset.Value,
+		},
+	}); err != nil {
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+	return nil
+}
+
+// createDialReset inserts a reset into the dial's reset history.
+func createDialReset(ctx context.Context, tx *Tx, reset *wtf.DialReset) error {
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_resets (dial_id, value, n, "timestamp")
+		VALUES (?, ?, ?, ?)
+	`,
+		reset.DialID,
+		reset.Value,
+		reset.N,
+		(*NullTime)(&reset.Timestamp),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	reset.ID = int(id)
+	return nil
+}
+
+// findDialResetsBetween returns the resets of a dial between start & end, in
+// time order.
+func findDialResetsBetween(ctx context.Context, tx *Tx, dialID int, start, end time.Time) ([]*wtf.DialReset, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, dial_id, value, n, "timestamp"
+		FROM dial_resets
+		WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ?
+		ORDER BY "timestamp", id
+	`,
+		dialID,
+		(*NullTime)(&start),
+		(*NullTime)(&end),
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	resets := make([]*wtf.DialReset, 0)
+	for rows.Next() {
+		var reset wtf.DialReset
+		if err := rows.Scan(
+			&reset.ID,
+			&reset.DialID,
+			&reset.Value,
+			&reset.N,
+			(*NullTime)(&reset.Timestamp),
+		); err != nil {
+			return nil, err
+		}
+		resets = append(resets, &reset)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return resets, nil
+}
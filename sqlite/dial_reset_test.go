@@ -0,0 +1,143 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialMembershipService_ResetDialValues(t *testing.T) {
+	// Ensure member values are reset once the next reset time passes.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:              "DIAL",
+			ResetIntervalDays: 1,
+			ResetValue:        10,
+			ResetTimezone:     "UTC",
+			NextResetAt:       time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC),
+		})
+		membership1 := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 70})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 50)
+
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if event.Type == wtf.EventTypeDialReset && userID == 1 {
+					events = append(events, event)
+				}
+			},
+		}
+
+		// Nothing should happen before the reset is due.
+		db.Now = func() time.Time { return start.Add(11 * time.Hour) }
+		if err := sqlite.NewDialMembershipService(db).ResetDialValues(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 60; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Reset once due. Missed resets are collapsed into one.
+		db.Now = func() time.Time { return start.Add(37 * time.Hour) }
+		if err := sqlite.NewDialMembershipService(db).ResetDialValues(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if other := MustFindDialByID(t, ctx0, db, dial.ID); other.Value != 10 {
+			t.Fatalf("Value=%v, want %v", other.Value, 10)
+		} else if got, want := other.NextResetAt, time.Date(2000, time.January, 4, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Fatalf("NextResetAt=%v, want %v", got, want)
+		}
+		if other := MustFindDialMembershipByID(t, ctx1, db, membership1.ID); other.Value != 10 {
+			t.Fatalf("Value=%v, want %v", other.Value, 10)
+		} else if other.Note != wtf.DialResetNote {
+			t.Fatalf("Note=%q, want %q", other.Note, wtf.DialResetNote)
+		}
+
+		if len(events) != 1 {
+			t.Fatalf("unexpected event count: %d", len(events))
+		} else if payload := events[0].Payload.(*wtf.DialResetPayload); payload.DialID != dial.ID || payload.Value != 10 {
+			t.Fatalf("unexpected payload: %#v", payload)
+		}
+
+		// Running again should not reset until the next interval.
+		if err := sqlite.NewDialMembershipService(db).ResetDialValues(ctx); err != nil {
+			t.Fatal(err)
+		} else if len(events) != 1 {
+			t.Fatalf("unexpected event count: %d", len(events))
+		}
+
+		// The reset should be marked in the dial's history.
+		report, err := s.DialValueReport(ctx0, dial.ID, start, start.Add(48*time.Hour), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(report.Resets) != 1 {
+			t.Fatalf("unexpected reset count: %d", len(report.Resets))
+		} else if reset := report.Resets[0]; reset.Value != 10 || reset.N != 2 || !reset.Timestamp.Equal(start.Add(37*time.Hour)) {
+			t.Fatalf("unexpected reset: %#v", reset)
+		}
+	})
+
+	// Ensure weekly resets are scheduled from the original anchor time.
+	t.Run("Weekly", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:              "DIAL",
+			ResetIntervalDays: 7,
+			ResetTimezone:     "UTC",
+			NextResetAt:       start.Add(time.Hour),
+		})
+
+		db.Now = func() time.Time { return start.Add(2 * time.Hour) }
+		if err := sqlite.NewDialMembershipService(db).ResetDialValues(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := s.DialValueReport(ctx0, dial.ID, start, start.Add(3*time.Hour), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(report.Resets) != 1 || report.Resets[0].N != 1 {
+			t.Fatalf("unexpected resets: %#v", report.Resets)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).NextResetAt, start.Add(7*24*time.Hour+time.Hour); !got.Equal(want) {
+			t.Fatalf("NextResetAt=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a reset policy cannot start in the past.
+	t.Run("ErrPastNextReset", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := &wtf.Dial{
+			Name:              "DIAL",
+			ResetIntervalDays: 1,
+			ResetTimezone:     "UTC",
+			NextResetAt:       time.Now().Add(-time.Hour),
+		}
+		if err := s.CreateDial(ctx0, dial); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Next reset must be in the future.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
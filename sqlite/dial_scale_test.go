@@ -0,0 +1,175 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_Scale(t *testing.T) {
+	// Ensure members start at the bottom of a custom scale & report their band.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name: "Retro",
+			Scale: wtf.DialScale{
+				Min:  1,
+				Max:  5,
+				Step: 1,
+				Bands: []*wtf.DialBand{
+					{Label: "on fire", Color: "#e63757", Min: 5, Max: 5},
+					{Label: "calm", Color: "#00d27a", Min: 1, Max: 2},
+				},
+			},
+		})
+
+		if other := MustFindDialByID(t, ctx0, db, dial.ID); other.Value != 1 {
+			t.Fatalf("Value=%v, want %v", other.Value, 1)
+		} else if other.Band != "calm" {
+			t.Fatalf("Band=%q, want %q", other.Band, "calm")
+		} else if got, want := other.Scale.Bands[0].Label, "calm"; got != want {
+			t.Fatalf("Bands[0].Label=%q, want %q", got, want)
+		}
+
+		MustSetDialMembershipValue(t, ctx0, db, 1, 5)
+		if other := MustFindDialMembershipByID(t, ctx0, db, 1); other.Band != "on fire" {
+			t.Fatalf("Band=%q, want %q", other.Band, "on fire")
+		}
+	})
+
+	// Ensure the dial & its dimensions fall to the bottom of the scale, not
+	// zero, when no member contributes a value.
+	t.Run("NoContributors", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:       "Retro",
+			Scale:      wtf.DialScale{Min: 1, Max: 5, Step: 1},
+			Dimensions: []*wtf.DialDimension{{Name: "Workload"}},
+		})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 5)
+
+		if _, err := sqlite.NewDialMembershipService(db).SetDialMembershipAway(ctx0, dial.ID, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		if other := MustFindDialByID(t, ctx0, db, dial.ID); other.Value != 1 {
+			t.Fatalf("Value=%v, want %v", other.Value, 1)
+		} else if got, want := other.Dimensions[0].Value, 1; got != want {
+			t.Fatalf("Dimensions[0].Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure values outside of the dial's scale are rejected.
+	t.Run("ErrValueOutOfRange", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "Retro", Scale: wtf.DialScale{Min: 1, Max: 5, Step: 1}})
+
+		value := 80
+		if _, err := s.UpdateDialMembership(ctx0, 1, wtf.DialMembershipUpdate{Value: &value}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dial value must be between 1 & 5.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure values between steps are rejected.
+	t.Run("ErrValueStep", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", Scale: wtf.DialScale{Min: 0, Max: 10, Step: 2}})
+
+		value := 3
+		if _, err := s.UpdateDialMembership(ctx0, 1, wtf.DialMembershipUpdate{Value: &value}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Dial value must be in steps of 2.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure changing the scale moves member values onto the new scale.
+	t.Run("UpdateScale", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+
+		dial, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Scale: &wtf.DialScale{Min: 1, Max: 5, Step: 1}})
+		if err != nil {
+			t.Fatal(err)
+		} else if dial.Value != 5 {
+			t.Fatalf("Value=%v, want %v", dial.Value, 5)
+		}
+
+		if other := MustFindDialMembershipByID(t, ctx0, db, 1); other.Value != 5 {
+			t.Fatalf("Value=%v, want %v", other.Value, 5)
+		} else if other.Note != wtf.DialScaleChangeNote {
+			t.Fatalf("Note=%q, want %q", other.Note, wtf.DialScaleChangeNote)
+		}
+	})
+
+	// Ensure overlapping bands are rejected.
+	t.Run("ErrBandOverlap", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		if err := s.CreateDial(ctx0, &wtf.Dial{
+			Name: "DIAL",
+			Scale: wtf.DialScale{
+				Min:  1,
+				Max:  5,
+				Step: 1,
+				Bands: []*wtf.DialBand{
+					{Label: "calm", Color: "#00d27a", Min: 1, Max: 3},
+					{Label: "uneasy", Color: "#f5803e", Min: 3, Max: 4},
+				},
+			},
+		}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Bands "calm" & "uneasy" overlap.` {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure alert rule thresholds must be on the dial's scale.
+	t.Run("ErrAlertRuleThreshold", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAlertService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "Retro", Scale: wtf.DialScale{Min: 1, Max: 5, Step: 1}})
+
+		if err := s.CreateDialAlertRule(ctx0, &wtf.DialAlertRule{
+			DialID:      dial.ID,
+			Name:        "High",
+			Subject:     wtf.DialAlertSubjectDial,
+			Operator:    wtf.DialAlertOperatorGTE,
+			Threshold:   75,
+			NotifyInApp: true,
+		}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Alert rule threshold must be between 1 & 5.` {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// ApplyDialStalePolicies recomputes the value of every dial that has a stale
+// membership policy so that members are excluded or decayed as they go stale.
+// Changed values are recorded in the dial's history. This is called
+// periodically by the background monitor but can also be called directly,
+// such as from tests.
+func (db *DB) ApplyDialStalePolicies(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Find all dials with a stale policy.
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM dials WHERE stale_policy != ? AND archived_at IS NULL ORDER BY id`, wtf.DialStalePolicyNone)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := refreshDialValue(ctx, tx, id); err != nil {
+			return fmt.Errorf("refresh dial value: id=%d err=%w", id, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// findEffectiveDialMemberValues returns the values that active members
+// contribute to a dial after applying the dial's stale policy. Away members &
+// members excluded by the stale policy are omitted. This bypasses permission checks as it is used when
+// computing the dial value.
+func findEffectiveDialMemberValues(ctx context.Context, tx *Tx, dialID int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    d.stale_policy,
+		    d.stale_after_days,
+		    d.stale_baseline,
+		    d.stale_half_life_days,
+		    dm.value,
+		    dm.away_until,
+		    dm.updated_at
+		FROM dial_memberships dm
+		INNER JOIN dials d ON dm.dial_id = d.id
+		WHERE dm.dial_id = ?
+		  AND dm.status = ?
+	`,
+		dialID, wtf.DialMembershipStatusActive,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var values []int
+	for rows.Next() {
+		var dial wtf.Dial
+		var membership wtf.DialMembership
+		if err := rows.Scan(
+			&dial.StalePolicy,
+			&dial.StaleAfterDays,
+			&dial.StaleBaseline,
+			&dial.StaleHalfLifeDays,
+			&membership.Value,
+			(*NullTime)(&membership.AwayUntil),
+			(*NullTime)(&membership.UpdatedAt),
+		); err != nil {
+			return nil, err
+		} else if membership.IsAway(tx.now) {
+			continue
+		}
+
+		if value, ok := dial.EffectiveMembershipValue(&membership, tx.now); ok {
+			values = append(values, value)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
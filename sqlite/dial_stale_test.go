@@ -0,0 +1,112 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDB_ApplyDialStalePolicies(t *testing.T) {
+	// Ensure stale members are dropped from the dial value under the exclude policy.
+	t.Run("Exclude", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", StalePolicy: wtf.DialStalePolicyExclude, StaleAfterDays: 7})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 20)
+		if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 60; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Owner keeps updating while the other member goes quiet.
+		now = now.AddDate(0, 0, 7)
+		MustSetDialMembershipValue(t, ctx0, db, 1, 30)
+		if err := db.ApplyDialStalePolicies(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 30; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Ensure stale member is marked & contributes nothing.
+		if m := MustFindDialMembershipByID(t, ctx0, db, 2); !m.Stale {
+			t.Fatal("expected stale")
+		} else if got, want := m.EffectiveValue, 0; got != want {
+			t.Fatalf("EffectiveValue=%v, want %v", got, want)
+		} else if m := MustFindDialMembershipByID(t, ctx0, db, 1); m.Stale {
+			t.Fatal("expected not stale")
+		}
+
+		// Updating the membership makes it count again.
+		MustSetDialMembershipValue(t, ctx1, db, 2, 80)
+		if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 55; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure stale values decay toward the baseline under the decay policy.
+	t.Run("Decay", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{
+			Name:              "DIAL",
+			StalePolicy:       wtf.DialStalePolicyDecay,
+			StaleAfterDays:    7,
+			StaleBaseline:     20,
+			StaleHalfLifeDays: 2,
+		})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 100)
+
+		// Value is unchanged until the member is stale.
+		now = now.AddDate(0, 0, 7)
+		if err := db.ApplyDialStalePolicies(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 100; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// After one half-life, value is halfway to the baseline.
+		now = now.AddDate(0, 0, 2)
+		if err := db.ApplyDialStalePolicies(ctx); err != nil {
+			t.Fatal(err)
+		} else if got, want := MustFindDialByID(t, ctx0, db, dial.ID).Value, 60; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if m := MustFindDialMembershipByID(t, ctx0, db, 1); !m.Stale || m.Value != 100 || m.EffectiveValue != 60 {
+			t.Fatalf("unexpected membership: stale=%v value=%v effective=%v", m.Stale, m.Value, m.EffectiveValue)
+		}
+
+		// Ensure decayed values are recorded in the dial's history.
+		report, err := sqlite.NewDialService(db).DialValueReport(ctx0, dial.ID, now.Add(-time.Minute), now.Add(time.Minute), time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[len(report.Records)-1].Value, 60; got != want {
+			t.Fatalf("Records[-1].Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an invalid policy is rejected.
+	t.Run("ErrInvalidPolicy", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		if err := sqlite.NewDialService(db).CreateDial(ctx0, &wtf.Dial{Name: "DIAL", StalePolicy: "forget"}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Invalid stale membership policy.` {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// findDialStats computes the spread of the values that active members
+// contribute to a dial after applying the dial's stale policy.
+func findDialStats(ctx context.Context, tx *Tx, id int) (*wtf.DialStats, error) {
+	scale, err := findDialScale(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	values, err := findEffectiveDialMemberValues(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return computeDialStats(values, scale), nil
+}
+
+// findDialStatsSlots computes the spread of member values at the end of each
+// interval between start & end. Only current active members are included and
+// each member is only counted from the slot in which they joined.
+func findDialStatsSlots(ctx context.Context, tx *Tx, id int, start, end time.Time, interval time.Duration) ([]*wtf.DialStats, error) {
+	scale, err := findDialScale(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the active members & when they joined.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, created_at
+		FROM dial_memberships
+		WHERE dial_id = ?
+		  AND status = ?
+		ORDER BY id
+	`,
+		id, wtf.DialMembershipStatusActive,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	type member struct {
+		userID    int
+		createdAt time.Time
+	}
+	var members []member
+	for rows.Next() {
+		var m member
+		if err := rows.Scan(&m.userID, (*NullTime)(&m.createdAt)); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	} else if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// Collect each member's value at the end of every slot they belong to.
+	values := make([][]int, end.Sub(start)/interval)
+	for _, m := range members {
+		initial, changes, err := findDialMembershipValueChangesBetween(ctx, tx, id, m.userID, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, bucket := range buildDialValueBuckets(initial, changes, start, end, interval) {
+			if m.createdAt.Before(bucket.Timestamp.Add(interval)) {
+				values[i] = append(values[i], bucket.Value)
+			}
+		}
+	}
+
+	stats := make([]*wtf.DialStats, len(values))
+	for i := range values {
+		stats[i] = computeDialStats(values[i], scale)
+	}
+	return stats, nil
+}
+
+// computeDialStats returns the spread of a set of member values on a scale.
+// Percentiles use the nearest-rank method. Returns zero stats if there are no
+// values.
+func computeDialStats(values []int, scale *wtf.DialScale) *wtf.DialStats {
+	stats := &wtf.DialStats{N: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+
+	stats.Min, stats.Max = sorted[0], sorted[len(sorted)-1]
+	stats.P50 = sorted[int(math.Ceil(0.5*float64(len(sorted))))-1]
+	stats.P90 = sorted[int(math.Ceil(0.9*float64(len(sorted))))-1]
+
+	// Compute population standard deviation.
+	var sum float64
+	for _, v := range sorted {
+		sum += float64(v)
+	}
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+	for _, v := range sorted {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(sorted)))
+
+	// The largest possible deviation is half of the scale's range.
+	stats.StdDev = math.Round(stddev*100) / 100
+	stats.Disagreement = math.Round(stddev/(float64(scale.Max-scale.Min)/2)*100) / 100
+	return stats
+}
@@ -2,6 +2,8 @@ package sqlite_test
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"reflect"
 	"strings"
 	"testing"
@@ -197,10 +199,94 @@ func TestDialService_FindDials(t *testing.T) {
 			t.Fatalf("n=%v, want %v", got, want)
 		}
 	})
+
+	// Ensure dials include the spread of active member values.
+	t.Run("Stats", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		_, ctx3 := MustCreateUser(t, ctx, db, &wtf.User{Name: "joe"})
+
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "dial0"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+		MustCreateDialMembership(t, ctx2, db, &wtf.DialMembership{DialID: dial.ID, Value: 100})
+		MustCreateDialMembership(t, ctx3, db, &wtf.DialMembership{DialID: dial.ID, Value: 0})
+
+		s := sqlite.NewDialService(db)
+		if a, _, err := s.FindDials(ctx0, wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := a[0].Stats, (&wtf.DialStats{N: 4, Min: 0, Max: 100, P50: 0, P90: 100, StdDev: 50, Disagreement: 1}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Stats=%#v, want %#v", got, want)
+		} else if !a[0].Stats.IsPolarized() {
+			t.Fatal("expected polarized")
+		}
+	})
+
+	// Ensure members awaiting approval cannot see the dial or its history.
+	t.Run("Pending", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		user2, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "joe"})
+
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 80})
+		if _, err := sqlite.NewDialMembershipService(db).ApproveDialMembership(ctx0, 2); err != nil {
+			t.Fatal(err)
+		}
+		pending := MustCreateDialMembership(t, ctx2, db, &wtf.DialMembership{DialID: dial.ID, Value: 80})
+		now = now.Add(time.Hour)
+
+		s := sqlite.NewDialService(db)
+		if _, err := s.FindDialByID(ctx2, dial.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if a, n, err := s.FindDials(ctx2, wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		} else if len(a) != 0 || n != 0 {
+			t.Fatalf("unexpected dials: %#v", a)
+		} else if _, err := s.DialValueReport(ctx2, dial.ID, now.Add(-time.Hour), now, time.Hour); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if report, err := s.AverageDialValueReport(ctx2, now.Add(-time.Hour), now, time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0].Value, 0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if _, err := sqlite.NewDialMembershipService(db).MembershipValueReport(ctx2, dial.ID, 1, now.Add(-time.Hour), now, time.Hour); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// The pending member can still see the dial's name for the waiting page.
+		if a, _, err := sqlite.NewDialMembershipService(db).FindDialMemberships(ctx2, wtf.DialMembershipFilter{DialID: &dial.ID, UserID: &user2.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := a[0].ID, pending.ID; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := a[0].Dial.Name, "DIAL"; got != want {
+			t.Fatalf("Dial.Name=%v, want %v", got, want)
+		} else if got, want := a[0].Dial.Value, 0; got != want {
+			t.Fatalf("Dial.Value=%v, want %v", got, want)
+		}
+
+		// The owner & active members can see the dial.
+		if other, err := s.FindDialByID(ctx1, dial.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Value, 80; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
 }
 
 func TestDialService_DeleteDial(t *testing.T) {
-	// Ensure a dial can be deleted by the owner.
+	// Ensure an archived dial can be deleted by the owner.
 	t.Run("OK", func(t *testing.T) {
 		db := MustOpenDB(t)
 		defer MustCloseDB(t, db)
@@ -209,12 +295,29 @@ func TestDialService_DeleteDial(t *testing.T) {
 		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
 		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "NAME"})
 
-		if err := s.DeleteDial(ctx0, dial.ID); err != nil {
+		if _, err := s.ArchiveDial(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteDial(ctx0, dial.ID); err != nil {
 			t.Fatal(err)
 		} else if _, err := s.FindDialByID(ctx0, dial.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
 			t.Fatalf("unexpected error: %#v", err)
 		}
 	})
+
+	// Ensure a dial must be archived before it is deleted.
+	t.Run("ErrNotArchived", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "NAME"})
+
+		if err := s.DeleteDial(ctx0, dial.ID); wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Dial must be archived before it can be deleted.` {
+			t.Fatal(err)
+		}
+		MustFindDialByID(t, ctx0, db, dial.ID)
+	})
 }
 
 func TestDialService_AverageDialValueReport(t *testing.T) {
@@ -264,6 +367,403 @@ func TestDialService_AverageDialValueReport(t *testing.T) {
 			t.Fatalf("[]=%#v, want %#v", got, want)
 		}
 	})
+
+	// Ensure slots without changes are filled with the last value of each dial.
+	t.Run("MultipleDials", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL1"})
+		dial2 := MustCreateDial(t, ctx1, db, &wtf.Dial{Name: "DIAL2"})
+
+		for _, v := range []struct {
+			ctx    context.Context
+			dialID int
+			d      time.Duration
+			value  int
+		}{
+			{ctx0, dial0.ID, 30 * time.Minute, 50},
+			{ctx0, dial1.ID, 65 * time.Minute, 20},
+			{ctx0, dial1.ID, 110 * time.Minute, 40},
+			{ctx0, dial0.ID, 130 * time.Minute, 100},
+			{ctx1, dial2.ID, 10 * time.Minute, 100}, // not visible to jane
+		} {
+			db.Now = func() time.Time { return start.Add(v.d) }
+			if err := s.SetDialMembershipValue(v.ctx, v.dialID, v.value, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Report from the start of the timeline.
+		if report, err := s.AverageDialValueReport(ctx0, start, start.Add(4*time.Hour), time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records, []*wtf.DialValueRecord{
+			{Value: 25, Timestamp: start},
+			{Value: 45, Timestamp: start.Add(1 * time.Hour)},
+			{Value: 70, Timestamp: start.Add(2 * time.Hour)},
+			{Value: 70, Timestamp: start.Add(3 * time.Hour)},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Records=%#v, want %#v", got, want)
+		}
+
+		// Report starting after values have been set.
+		if report, err := s.AverageDialValueReport(ctx0, start.Add(2*time.Hour), start.Add(4*time.Hour), 30*time.Minute); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records, []*wtf.DialValueRecord{
+			{Value: 70, Timestamp: start.Add(120 * time.Minute)},
+			{Value: 70, Timestamp: start.Add(150 * time.Minute)},
+			{Value: 70, Timestamp: start.Add(180 * time.Minute)},
+			{Value: 70, Timestamp: start.Add(210 * time.Minute)},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Records=%#v, want %#v", got, want)
+		}
+
+		// Report for a user with no dials.
+		_, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "joe"})
+		if report, err := s.AverageDialValueReport(ctx2, start, start.Add(2*time.Hour), time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records, []*wtf.DialValueRecord{
+			{Value: 0, Timestamp: start},
+			{Value: 0, Timestamp: start.Add(1 * time.Hour)},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Records=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure archived dials are left out of the average.
+	t.Run("Archived", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL1"})
+		MustSetDialMembershipValue(t, ctx0, db, 2, 100)
+		if _, err := s.ArchiveDial(ctx0, dial1.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if report, err := s.AverageDialValueReport(ctx0, start, start.Add(time.Hour), time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0].Value, 0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure reversed, empty & oversized ranges are rejected.
+	t.Run("ErrInvalidRange", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+
+		now := time.Now()
+		if _, err := s.AverageDialValueReport(ctx0, now, now.Add(-time.Hour), time.Minute); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := s.AverageDialValueReport(ctx0, now, now, time.Minute); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := s.AverageDialValueReport(ctx0, now.Add(-365*24*time.Hour), now, time.Minute); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func BenchmarkDialService_AverageDialValueReport(b *testing.B) {
+	const dialN, dayN = 1000, 30
+
+	// Generate a month of history across many dials. Each dial changes once
+	// per day at a random time.
+	db := MustOpenDB(b)
+	defer MustCloseDB(b, db)
+	s := sqlite.NewDialService(db)
+
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return start }
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(b, ctx, db, &wtf.User{Name: "jane"})
+	dials := make([]*wtf.Dial, dialN)
+	for i := range dials {
+		dials[i] = MustCreateDial(b, ctx0, db, &wtf.Dial{Name: fmt.Sprintf("DIAL%d", i)})
+	}
+
+	rand := rand.New(rand.NewSource(0))
+	for day := 0; day < dayN; day++ {
+		for _, dial := range dials {
+			now := start.Add(time.Duration(day)*24*time.Hour + time.Duration(rand.Intn(24*60))*time.Minute)
+			db.Now = func() time.Time { return now }
+			if err := s.SetDialMembershipValue(ctx0, dial.ID, rand.Intn(101), ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	end := start.Add(dayN * 24 * time.Hour)
+
+	// Report the last hour by minute, as shown on the dashboard.
+	b.Run("Dashboard", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.AverageDialValueReport(ctx0, end.Add(-time.Hour), end, time.Minute); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// Report the month by hour from raw values.
+	b.Run("Raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.AverageDialValueReport(ctx0, start, end, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// Roll up all values & report the month by hour from the hourly tier.
+	db.Now = func() time.Time { return end }
+	for i := 0; i < dayN/7+1; i++ {
+		if err := db.UpdateDialValueRollups(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.Run("Rollup", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := s.AverageDialValueReport(ctx0, start, end, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestDialService_DialValueReport(t *testing.T) {
+	// Ensure we can compute the min, max & average value of a single dial.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		membership := MustFindDialMembershipByID(t, ctx0, db, 1)
+
+		// Update value twice within the second hour.
+		db.Now = func() time.Time { return start.Add(60 * time.Minute) }
+		MustSetDialMembershipValue(t, ctx0, db, membership.ID, 40)
+		db.Now = func() time.Time { return start.Add(90 * time.Minute) }
+		MustSetDialMembershipValue(t, ctx0, db, membership.ID, 80)
+
+		report, err := s.DialValueReport(ctx0, dial.ID, start, start.Add(3*time.Hour), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records, []*wtf.DialValueRecord{
+			{Value: 0, Min: 0, Max: 0, Avg: 0, Stats: &wtf.DialStats{N: 1}, Timestamp: start},
+			{Value: 80, Min: 40, Max: 80, Avg: 60, Stats: &wtf.DialStats{N: 1, Min: 80, Max: 80, P50: 80, P90: 80}, Timestamp: start.Add(1 * time.Hour)},
+			{Value: 80, Min: 80, Max: 80, Avg: 80, Stats: &wtf.DialStats{N: 1, Min: 80, Max: 80, P50: 80, P90: 80}, Timestamp: start.Add(2 * time.Hour)},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Records=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure each record includes the spread of member values.
+	t.Run("Stats", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		// Add a second member partway through the first hour.
+		db.Now = func() time.Time { return start.Add(30 * time.Minute) }
+		membership1 := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 20})
+
+		db.Now = func() time.Time { return start.Add(90 * time.Minute) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 80)
+		db.Now = func() time.Time { return start.Add(150 * time.Minute) }
+		MustSetDialMembershipValue(t, ctx1, db, membership1.ID, 100)
+
+		report, err := s.DialValueReport(ctx0, dial.ID, start, start.Add(3*time.Hour), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []*wtf.DialStats
+		for _, record := range report.Records {
+			got = append(got, record.Stats)
+		}
+		if want := []*wtf.DialStats{
+			{N: 2, Min: 0, Max: 20, P50: 0, P90: 20, StdDev: 10, Disagreement: 0.2},
+			{N: 2, Min: 20, Max: 80, P50: 20, P90: 80, StdDev: 30, Disagreement: 0.6},
+			{N: 2, Min: 80, Max: 100, P50: 80, P90: 100, StdDev: 10, Disagreement: 0.2},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Stats=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure users cannot report on dials they are not members of.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now := time.Now()
+		if _, err := s.DialValueReport(ctx1, dial.ID, now.Add(-time.Hour), now, time.Minute); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure reversed, empty & oversized ranges are rejected.
+	t.Run("ErrInvalidRange", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now := time.Now()
+		if _, err := s.DialValueReport(ctx0, dial.ID, now, now.Add(-time.Hour), time.Minute); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := s.DialValueReport(ctx0, dial.ID, now, now, time.Minute); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := s.DialValueReport(ctx0, dial.ID, now.Add(-365*24*time.Hour), now, time.Minute); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestDialService_DialValueHeatmap(t *testing.T) {
+	// Ensure values are averaged by weekday & hour in the given time zone.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		// Start on a Monday at midnight UTC.
+		start := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		// Spike the value for one hour on the first Monday morning only.
+		db.Now = func() time.Time { return start.Add(9 * time.Hour) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 100)
+		db.Now = func() time.Time { return start.Add(10 * time.Hour) }
+		MustSetDialMembershipValue(t, ctx0, db, 1, 0)
+
+		end := start.Add(14 * 24 * time.Hour)
+		denver, err := time.LoadLocation("America/Denver")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range []struct {
+			loc  *time.Location
+			hour int
+		}{
+			{time.UTC, 9},
+			{denver, 2},
+		} {
+			heatmap, err := s.DialValueHeatmap(ctx0, dial.ID, start, end, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := heatmap.Timezone, tt.loc.String(); got != want {
+				t.Fatalf("Timezone=%v, want %v", got, want)
+			} else if got, want := len(heatmap.Cells), 7*24; got != want {
+				t.Fatalf("len(Cells)=%v, want %v", got, want)
+			}
+
+			// Every hour of the week appears twice & only the spike is non-zero.
+			for _, cell := range heatmap.Cells {
+				want := wtf.DialValueHeatmapCell{Weekday: cell.Weekday, Hour: cell.Hour, N: 2}
+				if cell.Weekday == time.Monday && cell.Hour == tt.hour {
+					want.Value = 50
+				}
+				if *cell != want {
+					t.Fatalf("%s: Cell=%#v, want %#v", tt.loc, cell, want)
+				}
+			}
+		}
+
+		// Ensure the average across all dials matches for a single dial.
+		if heatmap, err := s.AverageDialValueHeatmap(ctx0, start, end, denver); err != nil {
+			t.Fatal(err)
+		} else if got, want := *heatmap.Cell(time.Monday, 2), (wtf.DialValueHeatmapCell{Weekday: time.Monday, Hour: 2, Value: 50, N: 2}); got != want {
+			t.Fatalf("Cell=%#v, want %#v", got, want)
+		} else if got, want := heatmap.Cell(time.Monday, 3).Value, 0; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the range must not be empty or longer than the maximum.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now := time.Now()
+		for _, tt := range []struct {
+			start, end time.Time
+			msg        string
+		}{
+			{now, now, `Heatmap end time must be after start time.`},
+			{now.AddDate(-10, 0, 0), now, `Heatmap cannot cover more than 366 days.`},
+		} {
+			if _, err := s.DialValueHeatmap(ctx0, dial.ID, tt.start, tt.end, time.UTC); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+				t.Fatalf("unexpected error: %#v", err)
+			} else if _, err := s.AverageDialValueHeatmap(ctx0, tt.start, tt.end, time.UTC); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != tt.msg {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+	})
+
+	// Ensure users cannot view heatmaps for dials they are not members of.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		now := time.Now()
+		if _, err := s.DialValueHeatmap(ctx1, dial.ID, now.Add(-24*time.Hour), now, time.UTC); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
 }
 
 // MustFindDialByID finds a dial by ID. Fatal on error.
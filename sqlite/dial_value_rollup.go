@@ -0,0 +1,421 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dialValueRollupMaxSpan is the maximum time range rolled up per tier in a
+// single pass. This keeps transactions short while backfilling old data.
+const dialValueRollupMaxSpan = 7 * 24 * time.Hour
+
+// dialValueTier represents a table of dial values rolled up to a fixed interval.
+type dialValueTier struct {
+	Name     string
+	Table    string
+	Interval time.Duration
+}
+
+// Rollup tiers. Hourly buckets are computed from raw values and daily buckets
+// are computed from hourly buckets.
+var (
+	dialValueTierHourly = &dialValueTier{Name: "hourly", Table: "dial_values_hourly", Interval: time.Hour}
+	dialValueTierDaily  = &dialValueTier{Name: "daily", Table: "dial_values_daily", Interval: 24 * time.Hour}
+)
+
+// dialValueTiers lists the rollup tiers from coarsest to finest.
+var dialValueTiers = []*dialValueTier{
+	dialValueTierDaily,
+	dialValueTierHourly,
+}
+
+// planDialValueTiers returns the rollup tiers that can satisfy a report at the
+// given interval, starting with the coarsest. Returns nil if the report must
+// be computed from raw values.
+func planDialValueTiers(interval time.Duration) []*dialValueTier {
+	for i, tier := range dialValueTiers {
+		if interval%tier.Interval == 0 {
+			return dialValueTiers[i:]
+		}
+	}
+	return nil
+}
+
+// UpdateDialValueRollups rolls up completed hours & days of dial values into
+// the hourly & daily tiers and then removes raw values older than the
+// DialValueRetention period. This is called periodically by the background
+// monitor but can also be called directly, such as from tests.
+func (db *DB) UpdateDialValueRollups(ctx context.Context) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := rollupHourlyDialValues(ctx, tx); err != nil {
+		return fmt.Errorf("hourly rollup: %w", err)
+	} else if err := rollupDailyDialValues(ctx, tx); err != nil {
+		return fmt.Errorf("daily rollup: %w", err)
+	} else if err := pruneDialValues(ctx, tx, db.DialValueRetention); err != nil {
+		return fmt.Errorf("prune dial values: %w", err)
+	}
+	return tx.Commit()
+}
+
+// rollupHourlyDialValues computes hourly buckets from raw dial values for
+// every completed hour since the last rollup.
+func rollupHourlyDialValues(ctx context.Context, tx *Tx) error {
+	tier := dialValueTierHourly
+
+	// Determine the range of completed hours that have not been rolled up.
+	from, to, err := findDialValueRollupRange(ctx, tx, tier, tx.now.Truncate(tier.Interval), `SELECT MIN("timestamp") FROM dial_values`)
+	if err != nil {
+		return err
+	} else if !to.After(from) {
+		return nil
+	}
+
+	// Only dials that changed during the range need new buckets.
+	ids, err := findDialIDsWithValuesBetween(ctx, tx, "dial_values", from, to)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		initial, changes, err := findDialValueChangesBetween(ctx, tx, id, from, to)
+		if err != nil {
+			return fmt.Errorf("dial value changes: id=%d err=%w", id, err)
+		}
+
+		// Mark which hours had a change. Unchanged hours are not stored as
+		// they can be inferred from the previous bucket.
+		changed := make(map[int]bool)
+		for _, change := range changes {
+			changed[int(change.Timestamp.Sub(from)/tier.Interval)] = true
+		}
+
+		for i, bucket := range buildDialValueBuckets(initial, changes, from, to, tier.Interval) {
+			if !changed[i] {
+				continue
+			} else if err := insertDialValueRollup(ctx, tx, tier, id, bucket); err != nil {
+				return err
+			}
+		}
+	}
+
+	return setDialValueRollupWatermark(ctx, tx, tier, to)
+}
+
+// rollupDailyDialValues computes daily buckets from hourly buckets for every
+// day that has been completely rolled up into the hourly tier.
+func rollupDailyDialValues(ctx context.Context, tx *Tx) error {
+	tier := dialValueTierDaily
+
+	// Days can only be rolled up once all of their hours have been.
+	hourly, err := findDialValueRollupWatermark(ctx, tx, dialValueTierHourly)
+	if err != nil {
+		return err
+	} else if hourly.IsZero() {
+		return nil
+	}
+
+	// Determine the range of completed days that have not been rolled up.
+	from, to, err := findDialValueRollupRange(ctx, tx, tier, hourly.Truncate(tier.Interval), `SELECT MIN("timestamp") FROM dial_values_hourly`)
+	if err != nil {
+		return err
+	} else if !to.After(from) {
+		return nil
+	}
+
+	// Only dials with hourly buckets during the range need new buckets.
+	ids, err := findDialIDsWithValuesBetween(ctx, tx, dialValueTierHourly.Table, from, to)
+	if err != nil {
+		return err
+	}
+
+	n := int(tier.Interval / dialValueTierHourly.Interval)
+	for _, id := range ids {
+		initial, sparse, err := findDialValueRollupsBetween(ctx, tx, dialValueTierHourly, id, from, to)
+		if err != nil {
+			return fmt.Errorf("hourly dial values: id=%d err=%w", id, err)
+		}
+
+		// Mark which days had an hourly bucket.
+		changed := make(map[int]bool)
+		for _, bucket := range sparse {
+			changed[int(bucket.Timestamp.Sub(from)/tier.Interval)] = true
+		}
+
+		hours := fillDialValueBuckets(initial, sparse, from, to, dialValueTierHourly.Interval)
+		for i, bucket := range mergeDialValueBuckets(hours, n) {
+			if !changed[i] {
+				continue
+			} else if err := insertDialValueRollup(ctx, tx, tier, id, bucket); err != nil {
+				return err
+			}
+		}
+	}
+
+	return setDialValueRollupWatermark(ctx, tx, tier, to)
+}
+
+// findDialValueRollupRange returns the range of a tier to roll up next. The
+// range begins at the tier's watermark, or at the earliest source value on
+// the first run, and ends at limit. The range is capped so large backfills
+// are split across multiple passes.
+func findDialValueRollupRange(ctx context.Context, tx *Tx, tier *dialValueTier, limit time.Time, minQuery string) (from, to time.Time, err error) {
+	if from, err = findDialValueRollupWatermark(ctx, tx, tier); err != nil {
+		return from, to, err
+	}
+
+	// Start from the earliest source value if the tier has never been rolled
+	// up. If there are no values yet then start from the limit.
+	if from.IsZero() {
+		if err := tx.QueryRowContext(ctx, minQuery).Scan((*NullTime)(&from)); err != nil {
+			return from, to, FormatError(err)
+		} else if from.IsZero() {
+			from = limit
+		}
+		from = from.Truncate(tier.Interval)
+	}
+
+	if to = limit; to.Sub(from) > dialValueRollupMaxSpan {
+		to = from.Add(dialValueRollupMaxSpan)
+	}
+	return from, to, nil
+}
+
+// findDialIDsWithValuesBetween returns the IDs of dials that have rows in the
+// given value table between start & end.
+func findDialIDsWithValuesBetween(ctx context.Context, tx *Tx, table string, start, end time.Time) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT dial_id
+		FROM `+table+`
+		WHERE "timestamp" >= ? AND "timestamp" < ?
+		ORDER BY dial_id
+	`,
+		(*NullTime)(&start),
+		(*NullTime)(&end),
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// findDialValueRollupsBetween returns the value of a dial in effect at start
+// and the sparse list of the tier's buckets between start & end.
+func findDialValueRollupsBetween(ctx context.Context, tx *Tx, tier *dialValueTier, id int, start, end time.Time) (initial int, buckets []dialValueBucket, err error) {
+	// Determine initial value from the last bucket before the range.
+	if err := tx.QueryRowContext(ctx, `
+		SELECT value
+		FROM `+tier.Table+`
+		WHERE dial_id = ? AND "timestamp" < ?
+		ORDER BY "timestamp" DESC
+		LIMIT 1
+	`,
+		id,
+		(*NullTime)(&start),
+	).Scan(&initial); err != nil && err != sql.ErrNoRows {
+		return 0, nil, FormatError(err)
+	}
+
+	// Find all buckets between start & end.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT "timestamp", value, min, max, avg
+		FROM `+tier.Table+`
+		WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ?
+		ORDER BY "timestamp" ASC
+	`,
+		id,
+		(*NullTime)(&start),
+		(*NullTime)(&end),
+	)
+	if err != nil {
+		return 0, nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket dialValueBucket
+		if err := rows.Scan(
+			(*NullTime)(&bucket.Timestamp),
+			&bucket.Value,
+			&bucket.Min,
+			&bucket.Max,
+			&bucket.Avg,
+		); err != nil {
+			return 0, nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return initial, buckets, nil
+}
+
+// insertDialValueRollup stores a bucket in a tier, replacing any existing bucket.
+func insertDialValueRollup(ctx context.Context, tx *Tx, tier *dialValueTier, id int, bucket dialValueBucket) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO `+tier.Table+` (dial_id, "timestamp", value, min, max, avg)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		id,
+		(*NullTime)(&bucket.Timestamp),
+		bucket.Value,
+		bucket.Min,
+		bucket.Max,
+		bucket.Avg,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// findDialValueRollupWatermark returns the time a tier has been rolled up to.
+// Returns a zero time if the tier has never been rolled up.
+func findDialValueRollupWatermark(ctx context.Context, tx *Tx, tier *dialValueTier) (t time.Time, err error) {
+	if err := tx.QueryRowContext(ctx, `
+		SELECT "timestamp"
+		FROM dial_value_rollups
+		WHERE tier = ?
+	`,
+		tier.Name,
+	).Scan((*NullTime)(&t)); err != nil && err != sql.ErrNoRows {
+		return t, FormatError(err)
+	}
+	return t, nil
+}
+
+// setDialValueRollupWatermark records the time a tier has been rolled up to.
+func setDialValueRollupWatermark(ctx context.Context, tx *Tx, tier *dialValueTier, t time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_value_rollups (tier, "timestamp")
+		VALUES (?, ?)
+		ON CONFLICT (tier) DO UPDATE SET "timestamp" = excluded."timestamp"
+	`,
+		tier.Name,
+		(*NullTime)(&t),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// pruneDialValues removes raw dial values older than the retention period.
+// Values are only removed once they have been rolled up into the hourly tier
+// and the last value before the cutoff is kept for each dial so that the
+// value in effect at any later time can still be determined.
+func pruneDialValues(ctx context.Context, tx *Tx, retention time.Duration) error {
+	if retention <= 0 {
+		return nil
+	}
+
+	// Never remove values which have not been rolled up yet.
+	cutoff := tx.now.Add(-retention)
+	if watermark, err := findDialValueRollupWatermark(ctx, tx, dialValueTierHourly); err != nil {
+		return err
+	} else if watermark.Before(cutoff) {
+		cutoff = watermark
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM dial_values
+		WHERE "timestamp" < ?
+		  AND (dial_id, "timestamp") NOT IN (
+		    SELECT dial_id, MAX("timestamp")
+		    FROM dial_values
+		    WHERE "timestamp" < ?
+		    GROUP BY dial_id
+		  )
+	`,
+		(*NullTime)(&cutoff),
+		(*NullTime)(&cutoff),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// findDialValueRetentionCutoff returns the time before which raw dial values
+// may have been pruned. Values newer than the hourly watermark are never
+// pruned so the cutoff is the earlier of the two. Returns a zero time if raw
+// values are kept forever.
+//
+// Readers which need raw values, rather than rollups, must not read before the
+// cutoff as the values in effect at that time may no longer exist.
+func findDialValueRetentionCutoff(ctx context.Context, tx *Tx) (time.Time, error) {
+	retention := tx.db.DialValueRetention
+	if retention <= 0 {
+		return time.Time{}, nil
+	}
+
+	cutoff := tx.now.Add(-retention)
+	if watermark, err := findDialValueRollupWatermark(ctx, tx, dialValueTierHourly); err != nil {
+		return time.Time{}, err
+	} else if watermark.Before(cutoff) {
+		cutoff = watermark
+	}
+	return cutoff, nil
+}
+
+// dialValueSource represents a table holding dial values for part of the
+// timeline. Rows are limited to those between Min & Max. A zero time leaves
+// that side of the range unbounded.
+type dialValueSource struct {
+	Table string
+	Min   time.Time
+	Max   time.Time
+}
+
+// where returns a SQL condition limiting rows to the source's time range.
+func (src *dialValueSource) where() (string, []interface{}) {
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if !src.Min.IsZero() {
+		where, args = append(where, `"timestamp" >= ?`), append(args, (*NullTime)(&src.Min))
+	}
+	if !src.Max.IsZero() {
+		where, args = append(where, `"timestamp" < ?`), append(args, (*NullTime)(&src.Max))
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// findDialValueSources returns the sources to read dial values from for a
+// report at the given interval. Each planned tier is used up to its watermark
+// and raw values are used after the last tier. Rolled up rows are timestamped
+// at the start of their bucket but hold the value at the end of it, which is
+// the same as a raw value recorded at the start of the bucket when reports
+// are aligned to the tier's interval.
+func findDialValueSources(ctx context.Context, tx *Tx, interval time.Duration) ([]*dialValueSource, error) {
+	var sources []*dialValueSource
+	var min time.Time
+	for _, tier := range planDialValueTiers(interval) {
+		max, err := findDialValueRollupWatermark(ctx, tx, tier)
+		if err != nil {
+			return nil, err
+		} else if !max.After(min) {
+			continue
+		}
+
+		sources = append(sources, &dialValueSource{Table: tier.Table, Min: min, Max: max})
+		min = max
+	}
+	return append(sources, &dialValueSource{Table: "dial_values", Min: min}), nil
+}
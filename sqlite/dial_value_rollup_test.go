@@ -0,0 +1,141 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDB_UpdateDialValueRollups(t *testing.T) {
+	// Ensure reports are unchanged after values are rolled up & raw values are pruned.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		membership := MustFindDialMembershipByID(t, ctx0, db, 1)
+
+		for _, v := range []struct {
+			d     time.Duration
+			value int
+		}{
+			{30 * time.Minute, 50},
+			{75 * time.Minute, 100},
+			{27 * time.Hour, 20},
+			{27*time.Hour + 10*time.Minute, 30},
+		} {
+			db.Now = func() time.Time { return start.Add(v.d) }
+			MustSetDialMembershipValue(t, ctx0, db, membership.ID, v.value)
+		}
+
+		// Generate hourly & daily reports from raw values.
+		end := start.Add(72 * time.Hour)
+		hourly, err := s.DialValueReport(ctx0, dial.ID, start, end, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		average, err := s.AverageDialValueReport(ctx0, start, end, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		daily, err := s.DialValueReport(ctx0, dial.ID, start, end, 24*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := daily.Records[0], (&wtf.DialValueRecord{Value: 100, Min: 0, Max: 100, Avg: 96, Stats: &wtf.DialStats{N: 1, Min: 100, Max: 100, P50: 100, P90: 100}, Timestamp: start}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Records[0]=%#v, want %#v", got, want)
+		}
+
+		// Roll up values & prune raw values older than a day.
+		db.Now = func() time.Time { return end }
+		db.DialValueRetention = 24 * time.Hour
+		if err := db.UpdateDialValueRollups(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// Ensure only the last raw value before the cutoff is kept.
+		if values, err := s.DialValues(ctx0, dial.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := values, []int{30}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("DialValues=%v, want %v", got, want)
+		}
+
+		// Ensure reports from the rollup tiers match the raw reports.
+		if report, err := s.DialValueReport(ctx0, dial.ID, start, end, time.Hour); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(report, hourly) {
+			t.Fatalf("hourly report mismatch:\ngot=%#v\nwant=%#v", report.Records, hourly.Records)
+		}
+		if report, err := s.DialValueReport(ctx0, dial.ID, start, end, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(report, daily) {
+			t.Fatalf("daily report mismatch:\ngot=%#v\nwant=%#v", report.Records, daily.Records)
+		}
+		if report, err := s.AverageDialValueReport(ctx0, start, end, time.Hour); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(report, average) {
+			t.Fatalf("average report mismatch:\ngot=%#v\nwant=%#v", report.Records, average.Records)
+		}
+	})
+
+	// Ensure reports read from raw values reject ranges crossing the point
+	// raw values have been pruned up to.
+	t.Run("ErrRawValuesPruned", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		for i, value := range []int{20, 40, 60} {
+			db.Now = func() time.Time { return start.Add(time.Duration(i+1) * time.Hour) }
+			MustSetDialMembershipValue(t, ctx0, db, 1, value)
+		}
+
+		// Roll up values & prune raw values older than a day.
+		end := start.Add(72 * time.Hour)
+		db.Now = func() time.Time { return end }
+		db.DialValueRetention = 24 * time.Hour
+		if err := db.UpdateDialValueRollups(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// Ranges within the retention period carry forward the last value.
+		if report, err := s.DialValueReport(ctx0, dial.ID, end.Add(-2*time.Hour), end, 15*time.Minute); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0].Value, 60; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+		if report, err := s.AverageDialValueReport(ctx0, end.Add(-2*time.Hour), end, 15*time.Minute); err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0].Value, 60; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Hourly reports are read from rollups so they can cross the boundary.
+		if _, err := s.DialValueReport(ctx0, dial.ID, start, end, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		// Ranges crossing the boundary cannot be read from raw values.
+		msg := `Reports with intervals that are not whole hours must start within the last 24h0m0s while raw dial values are retained.`
+		if _, err := s.DialValueReport(ctx0, dial.ID, end.Add(-25*time.Hour), end.Add(-23*time.Hour), 15*time.Minute); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != msg {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := s.AverageDialValueReport(ctx0, end.Add(-25*time.Hour), end.Add(-23*time.Hour), 15*time.Minute); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != msg {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,616 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.InvitationService = (*InvitationService)(nil)
+
+// InvitationService represents a service for managing dial invitations.
+type InvitationService struct {
+	db *DB
+}
+
+// NewInvitationService returns a new instance of InvitationService.
+func NewInvitationService(db *DB) *InvitationService {
+	return &InvitationService{db: db}
+}
+
+// FindInvitationByID retrieves a single invitation by ID along with the
+// associated dial and users. Returns ENOTFOUND if invitation does not exist or
+// the user is neither the inviter nor the invitee.
+func (s *InvitationService) FindInvitationByID(ctx context.Context, id int) (*wtf.Invitation, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Fetch invitation and its associated dial & users.
+	invitation, err := findInvitationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if err := attachInvitationAssociations(ctx, tx, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// FindInvitations retrieves a list of invitations sent or received by the
+// current user. Also returns a count of total matching invitations which may
+// differ if filter.Limit is set.
+func (s *InvitationService) FindInvitations(ctx context.Context, filter wtf.InvitationFilter) ([]*wtf.Invitation, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Fetch list of matching invitations.
+	invitations, n, err := findInvitations(ctx, tx, filter)
+	if err != nil {
+		return invitations, n, err
+	}
+
+	// Attach dial & users to each invitation.
+	for _, invitation := range invitations {
+		if err := attachInvitationAssociations(ctx, tx, invitation); err != nil {
+			return invitations, n, err
+		}
+	}
+	return invitations, n, nil
+}
+
+// CreateInvitation creates a new invitation from the current user. The invitee
+// is resolved by email or GitHub login if they have an account. Only the dial
+// owner can invite users.
+func (s *InvitationService) CreateInvitation(ctx context.Context, invitation *wtf.Invitation) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Create invitation and attach associated dial & users.
+	if err := createInvitation(ctx, tx, invitation); err != nil {
+		return err
+	} else if err := attachInvitationAssociations(ctx, tx, invitation); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AcceptInvitation accepts a pending invitation and creates a membership for
+// the current user. Returns EUNAUTHORIZED if the user is not the invitee.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Accept invitation and attach associated user & dial to the new membership.
+	membership, err := acceptInvitation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if err := attachDialMembershipAssociations(ctx, tx, membership); err != nil {
+		return nil, err
+	}
+	return membership, tx.Commit()
+}
+
+// DeclineInvitation declines a pending invitation. Returns EUNAUTHORIZED if
+// the current user is not the invitee.
+func (s *InvitationService) DeclineInvitation(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := declineInvitation(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteInvitation permanently removes an invitation. Only the inviter can
+// delete an invitation.
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteInvitation(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findInvitationByID is a helper function to retrieve an invitation by ID.
+// Returns ENOTFOUND if invitation doesn't exist.
+func findInvitationByID(ctx context.Context, tx *Tx, id int) (*wtf.Invitation, error) {
+	invitations, _, err := findInvitations(ctx, tx, wtf.InvitationFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(invitations) == 0 {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Invitation not found."}
+	}
+	return invitations[0], nil
+}
+
+// findInvitations retrieves a list of matching invitations. Also returns a
+// total matching count which may differ from the number of results if
+// filter.Limit is set.
+func findInvitations(ctx context.Context, tx *Tx, filter wtf.InvitationFilter) (_ []*wtf.Invitation, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "dial_id = ?"), append(args, *v)
+	}
+	if v := filter.InviteeID; v != nil {
+		where, args = append(where, "invitee_id = ?"), append(args, *v)
+	}
+	if v := filter.Status; v != nil {
+		where, args = append(where, "status = ?"), append(args, *v)
+	}
+	if v := filter.Expired; v != nil && *v {
+		where, args = append(where, "expires_at <= ?"), append(args, (*NullTime)(&tx.now))
+	} else if v != nil {
+		where, args = append(where, "expires_at > ?"), append(args, (*NullTime)(&tx.now))
+	}
+
+	// Limit to invitations the user has sent or received.
+	userID := wtf.UserIDFromContext(ctx)
+	where, args = append(where, "(inviter_id = ? OR invitee_id = ?)"), append(args, userID, userID)
+
+	// Execute query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    dial_id,
+		    inviter_id,
+		    IFNULL(invitee_id, 0),
+		    email,
+		    github_login,
+		    status,
+		    expires_at,
+		    created_at,
+		    updated_at,
+		    COUNT(*) OVER()
+		FROM invitations
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into Invitation objects.
+	invitations := make([]*wtf.Invitation, 0)
+	for rows.Next() {
+		var invitation wtf.Invitation
+		if err := rows.Scan(
+			&invitation.ID,
+			&invitation.DialID,
+			&invitation.InviterID,
+			&invitation.InviteeID,
+			&invitation.Email,
+			&invitation.GitHubLogin,
+			&invitation.Status,
+			(*NullTime)(&invitation.ExpiresAt),
+			(*NullTime)(&invitation.CreatedAt),
+			(*NullTime)(&invitation.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		// Hide the invitee from everyone else until they accept so that
+		// invitations cannot be used to discover accounts.
+		if invitation.InviteeID != userID && invitation.Status != wtf.InvitationStatusAccepted {
+			invitation.InviteeID = 0
+		}
+
+		invitations = append(invitations, &invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return invitations, n, nil
+}
+
+// createInvitation creates a new invitation from the current user.
+func createInvitation(ctx context.Context, tx *Tx, invitation *wtf.Invitation) error {
+	// Assign invitation to the current user.
+	userID := wtf.UserIDFromContext(ctx)
+	if userID == 0 {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You must be logged in to invite users.")
+	}
+	invitation.InviterID = userID
+
+	// Set timestamps & expiration to the current time.
+	invitation.Status = wtf.InvitationStatusPending
+	invitation.CreatedAt = tx.now
+	invitation.UpdatedAt = invitation.CreatedAt
+	invitation.ExpiresAt = invitation.CreatedAt.Add(wtf.InvitationTTL)
+
+	// Perform basic field validation.
+	if err := invitation.Validate(); err != nil {
+		return err
+	}
+
+	// Only the dial owner can send invitations.
+	dial, err := findDialByID(ctx, tx, invitation.DialID)
+	if err != nil {
+		return err
+	} else if dial.UserID != userID {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the dial owner can invite users.")
+	} else if err := checkDialNotArchived(ctx, tx, dial.ID); err != nil {
+		return err
+	}
+
+	// Resolve the invitee by email address or by their GitHub account. If no
+	// user has the address then the invitation waits for them to sign up. The
+	// owner gets the same result either way & the resolved invitee is never
+	// returned to them so accounts cannot be discovered by inviting them.
+	var inviteeID *int
+	if invitation.Email != "" {
+		if invitee, err := findUserByEmail(ctx, tx, invitation.Email); err == nil {
+			inviteeID = &invitee.ID
+		} else if wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			return err
+		}
+	} else {
+		if auth, err := findAuthBySourceLogin(ctx, tx, wtf.AuthSourceGitHub, invitation.GitHubLogin); err == nil {
+			inviteeID = &auth.UserID
+		} else if wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			return err
+		}
+	}
+
+	if inviteeID != nil {
+		// Banned users must be unbanned before they can be invited again.
+		if banned, err := isUserBannedFromDial(ctx, tx, invitation.DialID, *inviteeID); err != nil {
+			return err
+		} else if banned {
+			return wtf.Errorf(wtf.ECONFLICT, "User is banned from this dial.")
+		}
+
+		// Ensure the invitee is not already a member.
+		if memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{
+			DialID: &invitation.DialID,
+			UserID: inviteeID,
+		}); err != nil {
+			return err
+		} else if len(memberships) != 0 {
+			return wtf.Errorf(wtf.ECONFLICT, "User is already a member of this dial.")
+		}
+	}
+
+	// Ensure the invitee or their address has not already been invited.
+	if exists, err := hasPendingInvitation(ctx, tx, invitation, inviteeID); err != nil {
+		return err
+	} else if exists {
+		return wtf.Errorf(wtf.ECONFLICT, "User has already been invited to this dial.")
+	}
+
+	// Execute insertion query.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO invitations (
+			dial_id,
+			inviter_id,
+			invitee_id,
+			email,
+			github_login,
+			status,
+			expires_at,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		invitation.DialID,
+		invitation.InviterID,
+		inviteeID,
+		invitation.Email,
+		invitation.GitHubLogin,
+		invitation.Status,
+		(*NullTime)(&invitation.ExpiresAt),
+		(*NullTime)(&invitation.CreatedAt),
+		(*NullTime)(&invitation.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new invitation ID into caller argument.
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	invitation.ID = int(id)
+
+	// Record creation in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionInvitationCreate,
+		TargetType: wtf.AuditTargetInvitation,
+		TargetID:   invitation.ID,
+		DialID:     invitation.DialID,
+	}, nil, invitation); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Let the invitee know so their dashboard can update.
+	if inviteeID == nil {
+		return nil
+	}
+	inviter, err := findUserByID(ctx, tx, invitation.InviterID)
+	if err != nil {
+		return err
+	}
+	tx.db.EventService.PublishEvent(*inviteeID, wtf.Event{
+		Type: wtf.EventTypeInvitationCreated,
+		Payload: &wtf.InvitationCreatedPayload{
+			ID:          invitation.ID,
+			DialID:      invitation.DialID,
+			DialName:    dial.Name,
+			InviterName: inviter.Name,
+		},
+	})
+
+	return nil
+}
+
+// hasPendingInvitation returns true if the dial has an unexpired pending
+// invitation for the same invitee or the same email address or GitHub login.
+func hasPendingInvitation(ctx context.Context, tx *Tx, invitation *wtf.Invitation, inviteeID *int) (bool, error) {
+	var n int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM invitations
+		WHERE dial_id = ?
+		  AND status = ?
+		  AND expires_at > ?
+		  AND (
+		      invitee_id = ? OR
+		      (email <> '' AND email = ?) OR
+		      (github_login <> '' AND github_login = ? COLLATE NOCASE)
+		  )
+	`,
+		invitation.DialID,
+		wtf.InvitationStatusPending,
+		(*NullTime)(&tx.now),
+		inviteeID,
+		invitation.Email,
+		invitation.GitHubLogin,
+	).Scan(&n); err != nil {
+		return false, FormatError(err)
+	}
+	return n != 0, nil
+}
+
+// claimInvitations assigns pending invitations sent to a user's email address
+// or GitHub login before they had an account. Invitations to dials the user
+// is banned from are left unclaimed.
+func claimInvitations(ctx context.Context, tx *Tx, userID int) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE invitations
+		SET invitee_id = ?,
+		    updated_at = ?
+		WHERE invitee_id IS NULL
+		  AND status = ?
+		  AND (
+		      email IN (SELECT email FROM users WHERE id = ?) OR
+		      github_login COLLATE NOCASE IN (SELECT source_login FROM auths WHERE user_id = ? AND source = ? AND source_login <> '')
+		  )
+		  AND dial_id NOT IN (SELECT dial_id FROM dial_bans WHERE user_id = ?)
+	`,
+		userID,
+		(*NullTime)(&tx.now),
+		wtf.InvitationStatusPending,
+		userID,
+		userID,
+		wtf.AuthSourceGitHub,
+		userID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// acceptInvitation marks an invitation as accepted and creates a membership
+// for the invitee. The membership is active even if the dial requires
+// approval since the owner sent the invitation. If the invitee already asked
+// to join through the invite link then that membership is approved instead.
+func acceptInvitation(ctx context.Context, tx *Tx, id int) (*wtf.DialMembership, error) {
+	invitation, err := findInvitationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if !wtf.CanRespondToInvitation(ctx, invitation) {
+		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "You do not have permission to accept the invitation.")
+	} else if !invitation.IsPending() {
+		return nil, wtf.Errorf(wtf.ECONFLICT, "Invitation is no longer pending.")
+	} else if invitation.IsExpired(tx.now) {
+		return nil, wtf.Errorf(wtf.ECONFLICT, "Invitation has expired.")
+	}
+
+	// Check if the user has joined through the invite link in the meantime.
+	memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{
+		DialID: &invitation.DialID,
+		UserID: &invitation.InviteeID,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(memberships) != 0 && !memberships[0].IsPending() {
+		return nil, wtf.Errorf(wtf.ECONFLICT, "You are already a member of this dial.")
+	}
+
+	prev := *invitation
+	if err := updateInvitationStatus(ctx, tx, invitation, wtf.InvitationStatusAccepted); err != nil {
+		return nil, err
+	} else if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionInvitationAccept,
+		TargetType: wtf.AuditTargetInvitation,
+		TargetID:   invitation.ID,
+		DialID:     invitation.DialID,
+	}, &prev, invitation); err != nil {
+		return nil, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// The invitation stands in for the owner's approval of a pending request.
+	if len(memberships) != 0 {
+		membership := memberships[0]
+		if err := attachDialMembershipAssociations(ctx, tx, membership); err != nil {
+			return nil, err
+		} else if err := activateDialMembership(ctx, tx, membership); err != nil {
+			return nil, err
+		}
+		return membership, nil
+	}
+
+	// Create the membership for the invitee at the bottom of the dial's scale.
+	scale, err := findDialScale(ctx, tx, invitation.DialID)
+	if err != nil {
+		return nil, err
+	}
+	membership := &wtf.DialMembership{
+		DialID: invitation.DialID,
+		UserID: invitation.InviteeID,
+		Value:  scale.Min,
+		Status: wtf.DialMembershipStatusActive,
+	}
+	if err := createDialMembership(ctx, tx, membership); err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+// declineInvitation marks an invitation as declined.
+func declineInvitation(ctx context.Context, tx *Tx, id int) error {
+	invitation, err := findInvitationByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if !wtf.CanRespondToInvitation(ctx, invitation) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You do not have permission to decline the invitation.")
+	} else if !invitation.IsPending() {
+		return wtf.Errorf(wtf.ECONFLICT, "Invitation is no longer pending.")
+	}
+
+	prev := *invitation
+	if err := updateInvitationStatus(ctx, tx, invitation, wtf.InvitationStatusDeclined); err != nil {
+		return err
+	} else if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionInvitationDecline,
+		TargetType: wtf.AuditTargetInvitation,
+		TargetID:   invitation.ID,
+		DialID:     invitation.DialID,
+	}, &prev, invitation); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// updateInvitationStatus sets the status of an invitation.
+func updateInvitationStatus(ctx context.Context, tx *Tx, invitation *wtf.Invitation, status string) error {
+	invitation.Status = status
+	invitation.UpdatedAt = tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE invitations
+		SET status = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		invitation.Status,
+		(*NullTime)(&invitation.UpdatedAt),
+		invitation.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// deleteInvitation permanently removes an invitation by ID.
+// Returns EUNAUTHORIZED if current user is not the inviter.
+func deleteInvitation(ctx context.Context, tx *Tx, id int) error {
+	// Verify object exists & the current user is the inviter.
+	invitation, err := findInvitationByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if invitation.InviterID != wtf.UserIDFromContext(ctx) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "Only the inviter can delete the invitation.")
+	}
+
+	// Remove row from database.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM invitations WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+
+	// Record deletion in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionInvitationDelete,
+		TargetType: wtf.AuditTargetInvitation,
+		TargetID:   invitation.ID,
+		DialID:     invitation.DialID,
+	}, invitation, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+	return nil
+}
+
+// attachInvitationAssociations attaches the dial, inviter & invitee.
+func attachInvitationAssociations(ctx context.Context, tx *Tx, invitation *wtf.Invitation) (err error) {
+	if invitation.Dial, err = findInvitationDial(ctx, tx, invitation.DialID); err != nil {
+		return fmt.Errorf("attach invitation dial: %w", err)
+	} else if invitation.Inviter, err = findUserByID(ctx, tx, invitation.InviterID); err != nil {
+		return fmt.Errorf("attach invitation inviter: %w", err)
+	}
+
+	// The invitee is hidden from the inviter until they accept.
+	if invitation.InviteeID != 0 {
+		if invitation.Invitee, err = findUserByID(ctx, tx, invitation.InviteeID); err != nil {
+			return fmt.Errorf("attach invitation invitee: %w", err)
+		}
+	}
+	return nil
+}
+
+// findInvitationDial returns the basic fields of the invited dial. This
+// bypasses the dial permission checks since invitees are not yet members. The
+// value, invite code & memberships are intentionally left out.
+func findInvitationDial(ctx context.Context, tx *Tx, id int) (*wtf.Dial, error) {
+	var dial wtf.Dial
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id, user_id, name
+		FROM dials
+		WHERE id = ?
+	`,
+		id,
+	).Scan(
+		&dial.ID,
+		&dial.UserID,
+		&dial.Name,
+	); err == sql.ErrNoRows {
+		return nil, &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."}
+	} else if err != nil {
+		return nil, FormatError(err)
+	}
+	return &dial, nil
+}
@@ -0,0 +1,354 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestInvitationService_CreateInvitation(t *testing.T) {
+	// Ensure an invitee can be resolved by email address.
+	t.Run("Email", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		invitation := &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"}
+		if err := s.CreateInvitation(ctx0, invitation); err != nil {
+			t.Fatal(err)
+		} else if invitation.InviteeID != 0 || invitation.Invitee != nil {
+			t.Fatalf("unexpected invitee visible to inviter: %#v", invitation)
+		} else if got, want := invitation.Status, wtf.InvitationStatusPending; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		} else if got, want := invitation.ExpiresAt, invitation.CreatedAt.Add(wtf.InvitationTTL); !got.Equal(want) {
+			t.Fatalf("ExpiresAt=%v, want %v", got, want)
+		}
+
+		// Ensure the invitee can see the invitation along with the dial but
+		// not the dial's value.
+		MustSetDialMembershipValue(t, ctx0, db, 1, 5)
+		if other, err := s.FindInvitationByID(ctx1, invitation.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.InviteeID, user1.ID; got != want {
+			t.Fatalf("InviteeID=%v, want %v", got, want)
+		} else if got, want := other.Dial.Name, "DIAL"; got != want {
+			t.Fatalf("Dial.Name=%v, want %v", got, want)
+		} else if got, want := other.Dial.Value, 0; got != want {
+			t.Fatalf("Dial.Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an invitee can be resolved by their GitHub login.
+	t.Run("GitHub", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		auth1, _ := MustCreateAuth(t, ctx, db, &wtf.Auth{
+			Source:      wtf.AuthSourceGitHub,
+			SourceID:    "1000",
+			SourceLogin: "octocat",
+			AccessToken: "X", User: &wtf.User{Name: "jim"},
+		})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		// Logins are not case sensitive on GitHub. The numeric GitHub ID is
+		// not a login so that invitation is not delivered.
+		MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, GitHubLogin: "OctoCat"})
+		MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, GitHubLogin: "1000"})
+
+		ctx1 := wtf.NewContextWithUser(ctx, auth1.User)
+		if invitations, n, err := s.FindInvitations(ctx1, wtf.InvitationFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 || invitations[0].GitHubLogin != "OctoCat" || invitations[0].InviteeID != auth1.UserID {
+			t.Fatalf("unexpected invitations: n=%d", n)
+		}
+	})
+
+	// Ensure the stored GitHub login follows the user when they rename their
+	// account and sign in again.
+	t.Run("GitHubRenamed", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		auth1, _ := MustCreateAuth(t, ctx, db, &wtf.Auth{
+			Source:      wtf.AuthSourceGitHub,
+			SourceID:    "1000",
+			SourceLogin: "octocat",
+			AccessToken: "X", User: &wtf.User{Name: "jim"},
+		})
+		MustCreateAuth(t, ctx, db, &wtf.Auth{
+			Source:      wtf.AuthSourceGitHub,
+			SourceID:    "1000",
+			SourceLogin: "monalisa",
+			AccessToken: "Y", User: &wtf.User{Name: "jim"},
+		})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, GitHubLogin: "octocat"})
+		MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, GitHubLogin: "monalisa"})
+
+		ctx1 := wtf.NewContextWithUser(ctx, auth1.User)
+		if invitations, n, err := s.FindInvitations(ctx1, wtf.InvitationFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 || invitations[0].GitHubLogin != "monalisa" {
+			t.Fatalf("unexpected invitations: n=%d", n)
+		}
+	})
+
+	// Ensure inviting an address without an account looks the same to the
+	// inviter & the invitation is delivered once someone signs up with it.
+	t.Run("UnknownAccount", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		known := &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"}
+		unknown := &wtf.Invitation{DialID: dial.ID, Email: "nobody@gmail.com"}
+		if err := s.CreateInvitation(ctx0, known); err != nil {
+			t.Fatal(err)
+		} else if err := s.CreateInvitation(ctx0, unknown); err != nil {
+			t.Fatal(err)
+		} else if known.InviteeID != unknown.InviteeID || known.Invitee != unknown.Invitee || known.Status != unknown.Status {
+			t.Fatalf("invitations differ: %#v, %#v", known, unknown)
+		}
+
+		// Inviting the same address again is a conflict either way.
+		if err := s.CreateInvitation(ctx0, &wtf.Invitation{DialID: dial.ID, Email: "nobody@gmail.com"}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// The invitation is delivered when the user signs up.
+		user2, ctx2 := MustCreateUser(t, ctx, db, &wtf.User{Name: "nobody", Email: "nobody@gmail.com"})
+		if other, err := s.FindInvitationByID(ctx2, unknown.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.InviteeID, user2.ID; got != want {
+			t.Fatalf("InviteeID=%v, want %v", got, want)
+		} else if _, err := s.AcceptInvitation(ctx2, unknown.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure invitations to a GitHub login are delivered when the user signs
+	// in with GitHub for the first time.
+	t.Run("UnknownGitHubAccount", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		invitation := MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, GitHubLogin: "octocat"})
+
+		auth1, _ := MustCreateAuth(t, ctx, db, &wtf.Auth{
+			Source:      wtf.AuthSourceGitHub,
+			SourceID:    "1000",
+			SourceLogin: "OctoCat",
+			AccessToken: "X", User: &wtf.User{Name: "jim"},
+		})
+		if other, err := s.FindInvitationByID(wtf.NewContextWithUser(ctx, auth1.User), invitation.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.InviteeID, auth1.UserID; got != want {
+			t.Fatalf("InviteeID=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure only the dial owner can invite users.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim"})
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "joe", Email: "joe@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.CreateInvitation(ctx1, &wtf.Invitation{DialID: dial.ID, Email: "joe@gmail.com"}); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.EUNAUTHORIZED || wtf.ErrorMessage(err) != `Only the dial owner can invite users.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure existing members & already invited users cannot be invited.
+	t.Run("ErrConflict", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "joe", Email: "joe@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if err := s.CreateInvitation(ctx0, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, Email: "joe@gmail.com"})
+		if err := s.CreateInvitation(ctx0, &wtf.Invitation{DialID: dial.ID, Email: "joe@gmail.com"}); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `User has already been invited to this dial.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestInvitationService_AcceptInvitation(t *testing.T) {
+	// Ensure accepting an invitation creates an active membership.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		invitation := MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"})
+
+		// Membership should be active even though the dial requires approval.
+		if membership, err := s.AcceptInvitation(ctx1, invitation.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := membership.UserID, user1.ID; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		} else if got, want := membership.Status, wtf.DialMembershipStatusActive; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		}
+
+		if other, err := s.FindInvitationByID(ctx1, invitation.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Status, wtf.InvitationStatusAccepted; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		}
+
+		// Ensure the invitation cannot be accepted twice.
+		if _, err := s.AcceptInvitation(ctx1, invitation.ID); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure accepting an invitation approves a membership that is already
+	// waiting for approval.
+	t.Run("PendingMembership", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL", RequireApproval: true})
+		invitation := MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"})
+		pending := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID})
+
+		if membership, err := s.AcceptInvitation(ctx1, invitation.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := membership.ID, pending.ID; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := membership.Status, wtf.DialMembershipStatusActive; got != want {
+			t.Fatalf("Status=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an expired invitation cannot be accepted.
+	t.Run("ErrExpired", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		invitation := MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"})
+
+		db.Now = func() time.Time { return time.Now().Add(wtf.InvitationTTL + time.Hour) }
+		if _, err := s.AcceptInvitation(ctx1, invitation.ID); err == nil {
+			t.Fatal("expected error")
+		} else if wtf.ErrorCode(err) != wtf.ECONFLICT || wtf.ErrorMessage(err) != `Invitation has expired.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure only the invitee can accept an invitation.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		invitation := MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"})
+
+		if _, err := s.AcceptInvitation(ctx0, invitation.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestInvitationService_DeclineInvitation(t *testing.T) {
+	// Ensure a declined invitation no longer shows up as pending.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewInvitationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		invitation := MustCreateInvitation(t, ctx0, db, &wtf.Invitation{DialID: dial.ID, Email: "jim@gmail.com"})
+
+		if err := s.DeclineInvitation(ctx1, invitation.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		status := wtf.InvitationStatusPending
+		if _, n, err := s.FindInvitations(ctx1, wtf.InvitationFilter{Status: &status}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("unexpected pending invitation count: %d", n)
+		}
+
+		// Ensure the invitee did not join the dial.
+		if _, err := sqlite.NewDialService(db).FindDialByID(ctx1, dial.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustCreateInvitation creates an invitation in the database. Fatal on error.
+func MustCreateInvitation(tb testing.TB, ctx context.Context, db *sqlite.DB, invitation *wtf.Invitation) *wtf.Invitation {
+	tb.Helper()
+	if err := sqlite.NewInvitationService(db).CreateInvitation(ctx, invitation); err != nil {
+		tb.Fatal(err)
+	}
+	return invitation
+}
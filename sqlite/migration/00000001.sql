@@ -0,0 +1,3 @@
+ALTER TABLE dials ADD COLUMN require_approval INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE dial_memberships ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
@@ -0,0 +1,15 @@
+CREATE TABLE invitations (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id    INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	inviter_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	invitee_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	email      TEXT NOT NULL,
+	github_id  TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	expires_at TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+CREATE INDEX invitations_dial_id_idx ON invitations (dial_id);
+CREATE INDEX invitations_invitee_id_idx ON invitations (invitee_id);
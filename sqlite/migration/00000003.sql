@@ -0,0 +1,9 @@
+CREATE TABLE dial_bans (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id    INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	reason     TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+
+	UNIQUE(dial_id, user_id)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE audit_entries (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id     INTEGER NOT NULL,
+	action      TEXT NOT NULL,
+	target_type TEXT NOT NULL,
+	target_id   INTEGER NOT NULL,
+	dial_id     INTEGER,
+	before      TEXT,
+	after       TEXT,
+	source      TEXT NOT NULL,
+	created_at  TEXT NOT NULL
+);
+
+CREATE INDEX audit_entries_dial_id_idx ON audit_entries (dial_id);
+CREATE INDEX audit_entries_user_id_idx ON audit_entries (user_id);
@@ -0,0 +1,17 @@
+ALTER TABLE dial_memberships ADD COLUMN note TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE dial_membership_values (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	user_id     INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	value       INTEGER NOT NULL,
+	note        TEXT NOT NULL,
+	"timestamp" TEXT NOT NULL
+);
+
+CREATE INDEX dial_membership_values_dial_id_user_id_idx ON dial_membership_values (dial_id, user_id, "timestamp");
+
+-- Seed each member's history with their current value so reports for
+-- existing memberships are not empty.
+INSERT INTO dial_membership_values (dial_id, user_id, value, note, "timestamp")
+SELECT dial_id, user_id, value, note, updated_at FROM dial_memberships;
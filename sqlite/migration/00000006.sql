@@ -0,0 +1,29 @@
+CREATE INDEX dial_values_timestamp_idx ON dial_values ("timestamp");
+
+CREATE TABLE dial_values_hourly (
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	"timestamp" TEXT NOT NULL, -- start of hour
+	value       INTEGER NOT NULL, -- value at end of hour
+	min         INTEGER NOT NULL,
+	max         INTEGER NOT NULL,
+	avg         REAL NOT NULL, -- time-weighted average
+
+	PRIMARY KEY (dial_id, "timestamp")
+);
+
+CREATE TABLE dial_values_daily (
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	"timestamp" TEXT NOT NULL, -- start of day (UTC)
+	value       INTEGER NOT NULL, -- value at end of day
+	min         INTEGER NOT NULL,
+	max         INTEGER NOT NULL,
+	avg         REAL NOT NULL, -- time-weighted average
+
+	PRIMARY KEY (dial_id, "timestamp")
+);
+
+-- Tracks the time each tier has been rolled up to.
+CREATE TABLE dial_value_rollups (
+	tier        TEXT PRIMARY KEY,
+	"timestamp" TEXT NOT NULL
+);
@@ -0,0 +1,28 @@
+ALTER TABLE dials ADD COLUMN anomaly_spike_threshold REAL NOT NULL DEFAULT 3;
+ALTER TABLE dials ADD COLUMN anomaly_sustained_threshold REAL NOT NULL DEFAULT 2;
+
+CREATE TABLE dial_anomalies (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	kind        TEXT NOT NULL,
+	value       REAL NOT NULL,
+	baseline    REAL NOT NULL,
+	stddev      REAL NOT NULL,
+	z_score     REAL NOT NULL,
+	"timestamp" TEXT NOT NULL, -- start of triggering sample
+	created_at  TEXT NOT NULL
+);
+
+CREATE INDEX dial_anomalies_dial_id_idx ON dial_anomalies (dial_id, "timestamp");
+
+-- Rolling baseline for each dial. Samples before "timestamp" have been
+-- processed by the detection job.
+CREATE TABLE dial_anomaly_baselines (
+	dial_id     INTEGER PRIMARY KEY REFERENCES dials (id) ON DELETE CASCADE,
+	"timestamp" TEXT NOT NULL,
+	n           INTEGER NOT NULL,
+	mean        REAL NOT NULL,
+	variance    REAL NOT NULL,
+	spiking     INTEGER NOT NULL,
+	run         INTEGER NOT NULL
+);
@@ -0,0 +1,33 @@
+CREATE TABLE dial_alert_rules (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id          INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	name             TEXT NOT NULL,
+	subject          TEXT NOT NULL,
+	operator         TEXT NOT NULL,
+	threshold        INTEGER NOT NULL,
+	duration_minutes INTEGER NOT NULL,
+	cooldown_minutes INTEGER NOT NULL,
+	notify_in_app    INTEGER NOT NULL,
+	webhook_url      TEXT NOT NULL,
+	email            TEXT NOT NULL,
+	condition_since  TEXT, -- NULL if condition is not met
+	last_fired_at    TEXT,
+	created_at       TEXT NOT NULL,
+	updated_at       TEXT NOT NULL
+);
+
+CREATE INDEX dial_alert_rules_dial_id_idx ON dial_alert_rules (dial_id);
+
+CREATE TABLE dial_alert_firings (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	rule_id    INTEGER NOT NULL REFERENCES dial_alert_rules (id) ON DELETE CASCADE,
+	dial_id    INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	value      INTEGER NOT NULL,
+	status     TEXT NOT NULL,
+	error      TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE INDEX dial_alert_firings_rule_id_idx ON dial_alert_firings (rule_id, created_at);
+CREATE INDEX dial_alert_firings_dial_id_idx ON dial_alert_firings (dial_id, created_at);
+CREATE INDEX dial_alert_firings_status_idx ON dial_alert_firings (status);
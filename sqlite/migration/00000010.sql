@@ -0,0 +1,4 @@
+ALTER TABLE dials ADD COLUMN stale_policy TEXT NOT NULL DEFAULT '';
+ALTER TABLE dials ADD COLUMN stale_after_days INTEGER NOT NULL DEFAULT 14;
+ALTER TABLE dials ADD COLUMN stale_baseline INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dials ADD COLUMN stale_half_life_days INTEGER NOT NULL DEFAULT 7;
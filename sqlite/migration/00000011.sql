@@ -0,0 +1 @@
+ALTER TABLE dial_memberships ADD COLUMN away_until TEXT;
@@ -0,0 +1,32 @@
+CREATE TABLE dial_reminder_schedules (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id          INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	weekdays         TEXT NOT NULL, -- comma-separated day numbers, Sunday is 0
+	hour             INTEGER NOT NULL,
+	minute           INTEGER NOT NULL,
+	timezone         TEXT NOT NULL,
+	notify_in_app    INTEGER NOT NULL,
+	notify_email     INTEGER NOT NULL,
+	webhook_url      TEXT NOT NULL,
+	last_check_in_at TEXT,
+	created_at       TEXT NOT NULL,
+	updated_at       TEXT NOT NULL
+);
+
+CREATE INDEX dial_reminder_schedules_dial_id_idx ON dial_reminder_schedules (dial_id);
+
+CREATE TABLE dial_reminders (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	schedule_id INTEGER NOT NULL REFERENCES dial_reminder_schedules (id) ON DELETE CASCADE,
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	user_id     INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	check_in_at TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	error       TEXT NOT NULL,
+	created_at  TEXT NOT NULL
+);
+
+CREATE INDEX dial_reminders_schedule_id_idx ON dial_reminders (schedule_id, created_at);
+CREATE INDEX dial_reminders_dial_id_idx ON dial_reminders (dial_id, created_at);
+CREATE INDEX dial_reminders_user_id_idx ON dial_reminders (user_id, created_at);
+CREATE INDEX dial_reminders_status_idx ON dial_reminders (status);
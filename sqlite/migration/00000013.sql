@@ -0,0 +1,16 @@
+ALTER TABLE dials ADD COLUMN reset_interval_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dials ADD COLUMN reset_value INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dials ADD COLUMN reset_timezone TEXT NOT NULL DEFAULT '';
+ALTER TABLE dials ADD COLUMN next_reset_at TEXT;
+
+CREATE INDEX dials_next_reset_at_idx ON dials (next_reset_at);
+
+CREATE TABLE dial_resets (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	value       INTEGER NOT NULL,
+	n           INTEGER NOT NULL,
+	"timestamp" TEXT NOT NULL
+);
+
+CREATE INDEX dial_resets_dial_id_idx ON dial_resets (dial_id, "timestamp");
@@ -0,0 +1,4 @@
+ALTER TABLE dials ADD COLUMN scale_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dials ADD COLUMN scale_max INTEGER NOT NULL DEFAULT 100;
+ALTER TABLE dials ADD COLUMN scale_step INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE dials ADD COLUMN scale_bands TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,29 @@
+CREATE TABLE dial_dimensions (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id    INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	name       TEXT NOT NULL,
+	position   INTEGER NOT NULL,
+	value      INTEGER NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+CREATE INDEX dial_dimensions_dial_id_idx ON dial_dimensions (dial_id, position);
+
+CREATE TABLE dial_membership_dimensions (
+	dial_membership_id INTEGER NOT NULL REFERENCES dial_memberships (id) ON DELETE CASCADE,
+	dial_dimension_id  INTEGER NOT NULL REFERENCES dial_dimensions (id) ON DELETE CASCADE,
+	value              INTEGER NOT NULL,
+
+	PRIMARY KEY (dial_membership_id, dial_dimension_id)
+);
+
+CREATE INDEX dial_membership_dimensions_dial_dimension_id_idx ON dial_membership_dimensions (dial_dimension_id);
+
+CREATE TABLE dial_dimension_values (
+	dial_dimension_id INTEGER NOT NULL REFERENCES dial_dimensions (id) ON DELETE CASCADE,
+	"timestamp"       TEXT NOT NULL, -- per-minute precision
+	value             INTEGER NOT NULL,
+
+	PRIMARY KEY (dial_dimension_id, "timestamp")
+);
@@ -0,0 +1,10 @@
+CREATE TABLE dial_children (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	parent_dial_id INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	child_dial_id  INTEGER NOT NULL UNIQUE REFERENCES dials (id) ON DELETE CASCADE,
+	weight         REAL NOT NULL,
+	created_at     TEXT NOT NULL,
+	updated_at     TEXT NOT NULL
+);
+
+CREATE INDEX dial_children_parent_dial_id_idx ON dial_children (parent_dial_id);
@@ -0,0 +1,12 @@
+ALTER TABLE dials ADD COLUMN expression TEXT NOT NULL DEFAULT '';
+
+-- Dials referenced by each computed dial's expression. Used to recompute
+-- computed dials when one of their inputs changes.
+CREATE TABLE dial_expression_inputs (
+	dial_id       INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	input_dial_id INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+
+	PRIMARY KEY (dial_id, input_dial_id)
+);
+
+CREATE INDEX dial_expression_inputs_input_dial_id_idx ON dial_expression_inputs (input_dial_id);
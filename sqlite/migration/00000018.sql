@@ -0,0 +1 @@
+ALTER TABLE dials ADD COLUMN archived_at TEXT;
@@ -0,0 +1,18 @@
+-- Closed periods on a dial. The summary is computed when the period is closed
+-- and does not change afterward. Members & notes are stored as JSON.
+CREATE TABLE dial_periods (
+	id                      INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id                 INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	name                    TEXT NOT NULL,
+	start_at                TEXT NOT NULL,
+	end_at                  TEXT NOT NULL,
+	threshold               INTEGER NOT NULL,
+	average                 REAL NOT NULL,
+	peak                    INTEGER NOT NULL,
+	minutes_above_threshold INTEGER NOT NULL,
+	members                 TEXT NOT NULL,
+	notes                   TEXT NOT NULL,
+	created_at              TEXT NOT NULL
+);
+
+CREATE INDEX dial_periods_dial_id_idx ON dial_periods (dial_id, end_at);
@@ -0,0 +1,20 @@
+-- Service level objectives on a dial. Compliance is computed from dial_values
+-- so only the time the error budget was last exhausted is stored.
+CREATE TABLE dial_goals (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id             INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	name                TEXT NOT NULL,
+	operator            TEXT NOT NULL,
+	threshold           INTEGER NOT NULL,
+	target              REAL NOT NULL,
+	time_window         TEXT NOT NULL, -- "week" or "month"
+	weekdays            TEXT NOT NULL, -- comma-separated day numbers, Sunday is 0
+	start_hour          INTEGER NOT NULL,
+	end_hour            INTEGER NOT NULL,
+	timezone            TEXT NOT NULL,
+	budget_exhausted_at TEXT,
+	created_at          TEXT NOT NULL,
+	updated_at          TEXT NOT NULL
+);
+
+CREATE INDEX dial_goals_dial_id_idx ON dial_goals (dial_id);
@@ -0,0 +1,6 @@
+-- GitHub login of the user at their most recent sign in. Invitations are
+-- matched against it since users know each other by login, not numeric ID.
+ALTER TABLE auths ADD COLUMN source_login TEXT NOT NULL DEFAULT '';
+
+-- Invitations now store the GitHub login used to look up the invitee.
+ALTER TABLE invitations RENAME COLUMN github_id TO github_login;
@@ -0,0 +1,7 @@
+-- Owner of the dial at the time of the change. Entries outlive their dial so
+-- this lets the owner keep seeing them after the dial is purged.
+ALTER TABLE audit_entries ADD COLUMN dial_user_id INTEGER;
+
+UPDATE audit_entries
+SET dial_user_id = (SELECT user_id FROM dials WHERE dials.id = audit_entries.dial_id)
+WHERE dial_id IS NOT NULL;
@@ -0,0 +1,25 @@
+-- Invitations can be sent to an email address or GitHub login that does not
+-- belong to a user yet. The invitee is NULL until someone signs in with that
+-- address. SQLite cannot drop a NOT NULL constraint so the table is rebuilt.
+CREATE TABLE invitations_new (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id      INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	inviter_id   INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	invitee_id   INTEGER REFERENCES users (id) ON DELETE CASCADE,
+	email        TEXT NOT NULL,
+	github_login TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	expires_at   TEXT NOT NULL,
+	created_at   TEXT NOT NULL,
+	updated_at   TEXT NOT NULL
+);
+
+INSERT INTO invitations_new (id, dial_id, inviter_id, invitee_id, email, github_login, status, expires_at, created_at, updated_at)
+SELECT id, dial_id, inviter_id, invitee_id, email, github_login, status, expires_at, created_at, updated_at
+FROM invitations;
+
+DROP TABLE invitations;
+ALTER TABLE invitations_new RENAME TO invitations;
+
+CREATE INDEX invitations_dial_id_idx ON invitations (dial_id);
+CREATE INDEX invitations_invitee_id_idx ON invitations (invitee_id);
@@ -52,6 +52,24 @@ type DB struct {
 	// Destination for events to be published.
 	EventService wtf.EventService
 
+	// Raw dial values older than this are removed once they have been rolled
+	// up into the hourly & daily tiers. Zero retains raw values forever.
+	DialValueRetention time.Duration
+
+	// Archived dials are permanently deleted after this period. Zero keeps
+	// archived dials until they are deleted by their owner.
+	DialArchiveRetention time.Duration
+
+	// Deliver fired dial alerts to webhook & email targets. Deliveries to
+	// targets without a notifier are recorded as failed.
+	DialAlertWebhookNotifier wtf.DialAlertNotifier
+	DialAlertEmailNotifier   wtf.DialAlertNotifier
+
+	// Deliver check-in reminders to webhook & email targets. Deliveries to
+	// targets without a notifier are recorded as failed.
+	DialReminderWebhookNotifier wtf.DialReminderNotifier
+	DialReminderEmailNotifier   wtf.DialReminderNotifier
+
 	// Returns the current time. Defaults to time.Now().
 	// Can be mocked for tests.
 	Now func() time.Time
@@ -204,7 +222,11 @@ func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	}, nil
 }
 
-// monitor runs in a goroutine and periodically calculates internal stats.
+// monitor runs in a goroutine and periodically calculates internal stats,
+// rolls up historical dial values, returns away members, applies stale
+// membership policies, detects dial anomalies, evaluates dial alert rules &
+// goals & purges expired archived dials. Scheduled resets & check-in
+// reminders are run by the wtfd scheduler instead.
 func (db *DB) monitor() {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
@@ -219,6 +241,30 @@ func (db *DB) monitor() {
 		if err := db.updateStats(db.ctx); err != nil {
 			log.Printf("stats error: %s", err)
 		}
+		if err := db.UpdateDialValueRollups(db.ctx); err != nil {
+			log.Printf("dial value rollup error: %s", err)
+		}
+		if err := db.DetectDialAnomalies(db.ctx); err != nil {
+			log.Printf("dial anomaly detection error: %s", err)
+		}
+		if err := db.ExpireDialMembershipAways(db.ctx); err != nil {
+			log.Printf("dial membership away expiry error: %s", err)
+		}
+		if err := db.ApplyDialStalePolicies(db.ctx); err != nil {
+			log.Printf("dial stale policy error: %s", err)
+		}
+		if err := db.EvaluateDialAlertRules(db.ctx); err != nil {
+			log.Printf("dial alert evaluation error: %s", err)
+		}
+		if err := db.DeliverDialAlertFirings(db.ctx); err != nil {
+			log.Printf("dial alert delivery error: %s", err)
+		}
+		if err := db.EvaluateDialGoals(db.ctx); err != nil {
+			log.Printf("dial goal evaluation error: %s", err)
+		}
+		if err := db.PurgeArchivedDials(db.ctx); err != nil {
+			log.Printf("archived dial purge error: %s", err)
+		}
 	}
 }
 
@@ -55,6 +55,40 @@ func (s *UserService) FindUsers(ctx context.Context, filter wtf.UserFilter) ([]*
 	return findUsers(ctx, tx, filter)
 }
 
+// AuthenticateAPIKey retrieves the user that owns an API key and records the
+// key's use in the audit log so that reads made with the key are attributed
+// as well as changes. Returns EUNAUTHORIZED if no user has the key.
+func (s *UserService) AuthenticateAPIKey(ctx context.Context, apiKey string) (*wtf.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	users, _, err := findUsers(ctx, tx, wtf.UserFilter{APIKey: &apiKey})
+	if err != nil {
+		return nil, err
+	} else if len(users) == 0 {
+		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "Invalid API key.")
+	}
+	user := users[0]
+
+	// Record use of the key against its owner.
+	if err := createAuditEntry(wtf.NewContextWithAuditSource(ctx, wtf.AuditSourceAPIKey), tx, &wtf.AuditEntry{
+		UserID:     user.ID,
+		Action:     wtf.AuditActionUserAPIKey,
+		TargetType: wtf.AuditTargetUser,
+		TargetID:   user.ID,
+	}, nil, nil); err != nil {
+		return nil, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // CreateUser creates a new user. This is only used for testing since users are
 // typically created during the OAuth creation process in AuthService.CreateAuth().
 func (s *UserService) CreateUser(ctx context.Context, user *wtf.User) error {
@@ -155,6 +189,7 @@ func findUsers(ctx context.Context, tx *Tx, filter wtf.UserFilter) (_ []*wtf.Use
 		    id,
 		    name,
 		    email,
+		    timezone,
 		    api_key,
 		    created_at,
 		    updated_at,
@@ -175,10 +210,11 @@ func findUsers(ctx context.Context, tx *Tx, filter wtf.UserFilter) (_ []*wtf.Use
 	for rows.Next() {
 		var email sql.NullString
 		var user wtf.User
-		if rows.Scan(
+		if err := rows.Scan(
 			&user.ID,
 			&user.Name,
 			&email,
+			&user.Timezone,
 			&user.APIKey,
 			(*NullTime)(&user.CreatedAt),
 			(*NullTime)(&user.UpdatedAt),
@@ -231,14 +267,16 @@ func createUser(ctx context.Context, tx *Tx, user *wtf.User) error {
 		INSERT INTO users (
 			name,
 			email,
+			timezone,
 			api_key,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`,
 		user.Name,
 		email,
+		user.Timezone,
 		user.APIKey,
 		(*NullTime)(&user.CreatedAt),
 		(*NullTime)(&user.UpdatedAt),
@@ -253,6 +291,22 @@ func createUser(ctx context.Context, tx *Tx, user *wtf.User) error {
 	}
 	user.ID = int(id)
 
+	// Record signup in the audit log. The new user is the actor since they
+	// are not yet logged in.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		UserID:     user.ID,
+		Action:     wtf.AuditActionUserCreate,
+		TargetType: wtf.AuditTargetUser,
+		TargetID:   user.ID,
+	}, nil, user); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Deliver any invitations sent to the user's email before they signed up.
+	if err := claimInvitations(ctx, tx, user.ID); err != nil {
+		return fmt.Errorf("claim invitations: %w", err)
+	}
+
 	return nil
 }
 
@@ -267,6 +321,9 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to update this user.")
 	}
 
+	// Save state of user for the audit log.
+	prev := *user
+
 	// Update fields.
 	if v := upd.Name; v != nil {
 		user.Name = *v
@@ -274,6 +331,9 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 	if v := upd.Email; v != nil {
 		user.Email = *v
 	}
+	if v := upd.Timezone; v != nil {
+		user.Timezone = *v
+	}
 
 	// Set last updated date to current time.
 	user.UpdatedAt = tx.now
@@ -295,17 +355,33 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 		UPDATE users
 		SET name = ?,
 		    email = ?,
+		    timezone = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
 		user.Name,
 		email,
+		user.Timezone,
 		(*NullTime)(&user.UpdatedAt),
 		id,
 	); err != nil {
 		return user, FormatError(err)
 	}
 
+	// Record change in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionUserUpdate,
+		TargetType: wtf.AuditTargetUser,
+		TargetID:   user.ID,
+	}, &prev, user); err != nil {
+		return user, fmt.Errorf("create audit entry: %w", err)
+	}
+
+	// Deliver any invitations sent to the user's new email address.
+	if err := claimInvitations(ctx, tx, user.ID); err != nil {
+		return user, fmt.Errorf("claim invitations: %w", err)
+	}
+
 	return user, nil
 }
 
@@ -313,7 +389,8 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 // user is not the one being deleted.
 func deleteUser(ctx context.Context, tx *Tx, id int) error {
 	// Verify object exists.
-	if user, err := findUserByID(ctx, tx, id); err != nil {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
 		return err
 	} else if user.ID != wtf.UserIDFromContext(ctx) {
 		return wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to delete this user.")
@@ -323,6 +400,15 @@ func deleteUser(ctx context.Context, tx *Tx, id int) error {
 	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id); err != nil {
 		return FormatError(err)
 	}
+
+	// Record deletion in the audit log.
+	if err := createAuditEntry(ctx, tx, &wtf.AuditEntry{
+		Action:     wtf.AuditActionUserDelete,
+		TargetType: wtf.AuditTargetUser,
+		TargetID:   user.ID,
+	}, user, nil); err != nil {
+		return fmt.Errorf("create audit entry: %w", err)
+	}
 	return nil
 }
 
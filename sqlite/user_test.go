@@ -94,6 +94,30 @@ func TestUserService_UpdateUser(t *testing.T) {
 		}
 	})
 
+	// Ensure the time zone can be set & must be a known IANA name.
+	t.Run("Timezone", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		user0, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "susy"})
+
+		timezone := "America/Denver"
+		if uu, err := s.UpdateUser(ctx0, user0.ID, wtf.UserUpdate{Timezone: &timezone}); err != nil {
+			t.Fatal(err)
+		} else if got, want := uu.Location().String(), "America/Denver"; got != want {
+			t.Fatalf("Location=%v, want %v", got, want)
+		}
+
+		timezone = "Mars/Olympus_Mons"
+		if _, err := s.UpdateUser(ctx0, user0.ID, wtf.UserUpdate{Timezone: &timezone}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Unknown time zone.` {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if other, err := s.FindUserByID(context.Background(), user0.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Timezone, "America/Denver"; got != want {
+			t.Fatalf("Timezone=%v, want %v", got, want)
+		}
+	})
+
 	// Ensure updating a user is restricted only to the current user.
 	t.Run("ErrUnauthorized", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -192,6 +216,45 @@ func TestUserService_FindUsers(t *testing.T) {
 	})
 }
 
+func TestUserService_AuthenticateAPIKey(t *testing.T) {
+	// Ensure the key's owner is returned & the use is recorded in the audit log.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+
+		ctx := context.Background()
+		user, _ := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+
+		if other, err := s.AuthenticateAPIKey(ctx, user.APIKey); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ID, user.ID; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		}
+
+		action := wtf.AuditActionUserAPIKey
+		if entries, _, err := sqlite.NewAuditService(db).FindAuditEntries(wtf.NewContextWithUser(ctx, user), wtf.AuditEntryFilter{Action: &action}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(entries), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := entries[0].UserID, user.ID; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		} else if got, want := entries[0].Source, wtf.AuditSourceAPIKey; got != want {
+			t.Fatalf("Source=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an unknown key is rejected.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		if _, err := s.AuthenticateAPIKey(context.Background(), "NOSUCHKEY"); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
 // MustCreateUser creates a user in the database. Fatal on error.
 func MustCreateUser(tb testing.TB, ctx context.Context, db *sqlite.DB, user *wtf.User) (*wtf.User, context.Context) {
 	tb.Helper()
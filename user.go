@@ -14,6 +14,10 @@ type User struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 
+	// IANA time zone name, such as "America/Denver", used when grouping
+	// values by local time of day. Blank means UTC.
+	Timezone string `json:"timezone"`
+
 	// Randomly generated API key for use with the CLI.
 	APIKey string `json:"-"`
 
@@ -31,10 +35,22 @@ type User struct {
 func (u *User) Validate() error {
 	if u.Name == "" {
 		return Errorf(EINVALID, "User name required.")
+	} else if _, err := time.LoadLocation(u.Timezone); err != nil {
+		return Errorf(EINVALID, "Unknown time zone.")
 	}
 	return nil
 }
 
+// Location returns the user's time zone. Returns UTC if the user has no time
+// zone set or if it is no longer recognized.
+func (u *User) Location() *time.Location {
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // AvatarURL returns a URL to the avatar image for the user.
 // This loops over all auth providers to find the first available avatar.
 // Currently only GitHub is supported. Returns blank string if no avatar URL available.
@@ -57,6 +73,10 @@ type UserService interface {
 	// users which may differ from returned results if filter.Limit is specified.
 	FindUsers(ctx context.Context, filter UserFilter) ([]*User, int, error)
 
+	// Retrieves the user that owns an API key and records the key's use in
+	// the audit log. Returns EUNAUTHORIZED if no user has the key.
+	AuthenticateAPIKey(ctx context.Context, apiKey string) (*User, error)
+
 	// Creates a new user. This is only used for testing since users are typically
 	// created during the OAuth creation process in AuthService.CreateAuth().
 	CreateUser(ctx context.Context, user *User) error
@@ -85,6 +105,7 @@ type UserFilter struct {
 
 // UserUpdate represents a set of fields to be updated via UpdateUser().
 type UserUpdate struct {
-	Name  *string `json:"name"`
-	Email *string `json:"email"`
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	Timezone *string `json:"timezone"`
 }